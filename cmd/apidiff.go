@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/agnivo988/Repo-lyzer/internal/analyzer"
+	"github.com/agnivo988/Repo-lyzer/internal/github"
+	"github.com/agnivo988/Repo-lyzer/internal/output"
+)
+
+var (
+	apidiffPath     string
+	apidiffMaxFiles int
+	apidiffAll      bool
+)
+
+// apidiffCmd compares the exported API of a Go module between two refs.
+// Usage example:
+//
+//	repo-lyzer apidiff spf13/cobra v1.7.0 v1.8.0
+//
+// Both refs are loaded through the GitHub tree and blob APIs and type-checked,
+// and the command exits with an error if breaking changes violate semver.
+var apidiffCmd = &cobra.Command{
+	Use:   "apidiff owner/repo old-ref new-ref",
+	Short: "Detect breaking API changes between two releases of a Go module",
+	Args:  cobra.ExactArgs(3),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		parts := strings.Split(args[0], "/")
+		if len(parts) != 2 {
+			return fmt.Errorf("repository must be in owner/repo format")
+		}
+
+		client := github.NewClient()
+		report, err := analyzer.CompareGoAPI(client, parts[0], parts[1], args[1], args[2], analyzer.APILoadOptions{
+			PathPrefix: apidiffPath,
+			MaxFiles:   apidiffMaxFiles,
+		})
+		if err != nil {
+			return err
+		}
+
+		output.PrintAPIDiff(report, apidiffAll)
+
+		if len(report.SemverViolations) > 0 {
+			cmd.SilenceUsage = true
+			return fmt.Errorf("semantic versioning violated")
+		}
+		return nil
+	},
+}
+
+func init() {
+	apidiffCmd.Flags().StringVar(&apidiffPath, "path", "", "only compare packages under this directory")
+	apidiffCmd.Flags().IntVar(&apidiffMaxFiles, "max-files", 400, "maximum number of Go files to fetch per ref")
+	apidiffCmd.Flags().BoolVar(&apidiffAll, "all", false, "also list compatible changes")
+	rootCmd.AddCommand(apidiffCmd)
+}
@@ -51,7 +51,8 @@ var compareCmd = &cobra.Command{
 		_, _ = client.GetLanguages(r1[0], r1[1])
 		commits1, _ := client.GetCommits(r1[0], r1[1], 14)
 		contributors1, _ := client.GetContributors(r1[0], r1[1])
-		_, _ = client.GetFileTree(r1[0], r1[1], repo1.DefaultBranch)
+		tree1, _ := client.GetFileTree(r1[0], r1[1], repo1.DefaultBranch)
+		files1, _, size1 := countTreeStats(tree1)
 		bus1, risk1 := analyzer.BusFactor(contributors1)
 
 		maturityScore1, maturityLevel1 :=
@@ -66,7 +67,8 @@ var compareCmd = &cobra.Command{
 		_, _ = client.GetLanguages(r2[0], r2[1])
 		commits2, _ := client.GetCommits(r2[0], r2[1], 14)
 		contributors2, _ := client.GetContributors(r2[0], r2[1])
-		_, _ = client.GetFileTree(r2[0], r2[1], repo2.DefaultBranch)
+		tree2, _ := client.GetFileTree(r2[0], r2[1], repo2.DefaultBranch)
+		files2, _, size2 := countTreeStats(tree2)
 		bus2, risk2 := analyzer.BusFactor(contributors2)
 
 		maturityScore2, maturityLevel2 :=
@@ -98,6 +100,11 @@ var compareCmd = &cobra.Command{
 			fmt.Sprintf("%d", len(contributors2)),
 		})
 
+		table.Append([]string{"💾 Size",
+			fmt.Sprintf("%s (%d files)", analyzer.FormatSize(size1), files1),
+			fmt.Sprintf("%s (%d files)", analyzer.FormatSize(size2), files2),
+		})
+
 		table.Append([]string{"⚠️ Bus Factor",
 			fmt.Sprintf("%d (%s)", bus1, risk1),
 			fmt.Sprintf("%d (%s)", bus2, risk2),
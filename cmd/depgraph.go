@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/agnivo988/Repo-lyzer/internal/analyzer"
+	"github.com/agnivo988/Repo-lyzer/internal/github"
+)
+
+var depgraphFormat string
+
+// depgraphCmd prints the dependency graph of a repository.
+// Usage example:
+//
+//	repo-lyzer depgraph expressjs/express --format dot | dot -Tsvg > deps.svg
+//
+// Edges come from the lockfiles in the repository, so projects without one
+// only show their direct dependencies.
+var depgraphCmd = &cobra.Command{
+	Use:   "depgraph owner/repo",
+	Short: "Print the dependency graph as DOT, Mermaid or JSON",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		parts := strings.Split(args[0], "/")
+		if len(parts) != 2 {
+			return fmt.Errorf("repository must be in owner/repo format")
+		}
+
+		client := github.NewClient()
+		repo, err := client.GetRepo(parts[0], parts[1])
+		if err != nil {
+			return err
+		}
+		tree, err := client.GetFileTree(parts[0], parts[1], repo.DefaultBranch)
+		if err != nil {
+			return err
+		}
+		deps, err := analyzer.AnalyzeDependencies(client, parts[0], parts[1], repo.DefaultBranch, tree)
+		if err != nil {
+			return err
+		}
+		graph := analyzer.BuildDependencyGraph(deps)
+
+		switch depgraphFormat {
+		case "dot":
+			fmt.Print(graph.ToDOT())
+		case "mermaid":
+			fmt.Print(graph.ToMermaid())
+		case "json":
+			data, err := graph.ToJSON()
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(data))
+		default:
+			return fmt.Errorf("unknown format %q (use dot, mermaid or json)", depgraphFormat)
+		}
+		return nil
+	},
+}
+
+func init() {
+	depgraphCmd.Flags().StringVar(&depgraphFormat, "format", "dot", "output format: dot, mermaid or json")
+	rootCmd.AddCommand(depgraphCmd)
+}
@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/agnivo988/Repo-lyzer/internal/analyzer"
+	"github.com/agnivo988/Repo-lyzer/internal/config"
+	"github.com/agnivo988/Repo-lyzer/internal/github"
+	"github.com/agnivo988/Repo-lyzer/internal/output"
+)
+
+var (
+	licensesPolicy  string
+	licensesStrict  bool
+	licensesOffline bool
+	licensesAll     bool
+)
+
+// licensesCmd checks the licenses of a repository's dependencies against a
+// license policy.
+// Usage example:
+//
+//	repo-lyzer licenses expressjs/express --policy license_policy.txt --strict
+//
+// Licenses come from lockfiles, the package registries and, with a GitHub
+// token, the dependencies' LICENSE files. The command exits with an error if
+// a dependency's license is denied or conflicts with the project's license;
+// with --strict, also if one needs review or is unknown.
+var licensesCmd = &cobra.Command{
+	Use:   "licenses owner/repo",
+	Short: "Check dependency licenses against an allow/deny policy",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		parts := strings.Split(args[0], "/")
+		if len(parts) != 2 {
+			return fmt.Errorf("repository must be in owner/repo format")
+		}
+
+		opts := analyzer.DefaultLicenseComplianceOptions()
+		policyPath := licensesPolicy
+		if policyPath == "" {
+			// The policy the dashboard uses, if there is one
+			if path, err := config.LicensePolicyPath(); err == nil {
+				if _, err := os.Stat(path); err == nil {
+					policyPath = path
+				}
+			}
+		}
+		if policyPath != "" {
+			policy, err := analyzer.LoadLicensePolicy(policyPath)
+			if err != nil {
+				return err
+			}
+			opts.Policy = policy
+		}
+
+		client := github.NewClient()
+		repo, err := client.GetRepo(parts[0], parts[1])
+		if err != nil {
+			return err
+		}
+		tree, err := client.GetFileTree(parts[0], parts[1], repo.DefaultBranch)
+		if err != nil {
+			return err
+		}
+		deps, err := analyzer.AnalyzeDependencies(client, parts[0], parts[1], repo.DefaultBranch, tree)
+		if err != nil {
+			return err
+		}
+		license, err := analyzer.AnalyzeLicense(client, parts[0], parts[1], tree)
+		if err != nil {
+			return err
+		}
+		if !licensesOffline {
+			githubLookups := 0
+			if client.HasToken() {
+				githubLookups = 200
+			}
+			opts.Resolver = analyzer.NewRegistryLicenseResolver(analyzer.DefaultRegistryOptions(), client, githubLookups)
+		}
+
+		compliance := analyzer.CheckLicenseCompliance(license, deps, opts)
+		output.PrintLicenseCompliance(compliance, licensesAll)
+
+		if !compliance.Passed(licensesStrict) {
+			cmd.SilenceUsage = true
+			return fmt.Errorf("license policy violated")
+		}
+		return nil
+	},
+}
+
+func init() {
+	licensesCmd.Flags().StringVar(&licensesPolicy, "policy", "", "policy file (default ~/.repo-lyzer/license_policy.txt)")
+	licensesCmd.Flags().BoolVar(&licensesStrict, "strict", false, "also fail on licenses needing review and unknown licenses")
+	licensesCmd.Flags().BoolVar(&licensesOffline, "offline", false, "only use licenses recorded in lockfiles")
+	licensesCmd.Flags().BoolVar(&licensesAll, "all", false, "also list allowed licenses")
+	rootCmd.AddCommand(licensesCmd)
+}
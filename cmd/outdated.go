@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/agnivo988/Repo-lyzer/internal/analyzer"
+	"github.com/agnivo988/Repo-lyzer/internal/github"
+	"github.com/agnivo988/Repo-lyzer/internal/output"
+)
+
+var (
+	outdatedAll        bool
+	outdatedMinScore   int
+	outdatedNPMURL     string
+	outdatedPyPIURL    string
+	outdatedCratesURL  string
+	outdatedGemsURL    string
+	outdatedGoProxyURL string
+)
+
+// outdatedCmd reports how far a repository's direct dependencies are behind
+// their latest releases.
+// Usage example:
+//
+//	repo-lyzer outdated expressjs/express --min-score 60
+//
+// Versions come from lockfiles, or else from the manifests, and are compared
+// with the latest stable release in npm, PyPI, crates.io, RubyGems and the Go
+// module proxy. Each registry URL can be pointed at a mirror.
+var outdatedCmd = &cobra.Command{
+	Use:   "outdated owner/repo",
+	Short: "Report outdated dependencies with libyears and majors behind",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		parts := strings.Split(args[0], "/")
+		if len(parts) != 2 {
+			return fmt.Errorf("repository must be in owner/repo format")
+		}
+
+		client := github.NewClient()
+		repo, err := client.GetRepo(parts[0], parts[1])
+		if err != nil {
+			return err
+		}
+		tree, err := client.GetFileTree(parts[0], parts[1], repo.DefaultBranch)
+		if err != nil {
+			return err
+		}
+		deps, err := analyzer.AnalyzeDependencies(client, parts[0], parts[1], repo.DefaultBranch, tree)
+		if err != nil {
+			return err
+		}
+
+		opts := analyzer.DefaultFreshnessOptions()
+		for _, o := range []struct{ flag, target *string }{
+			{&outdatedNPMURL, &opts.Registry.NPMURL},
+			{&outdatedPyPIURL, &opts.Registry.PyPIURL},
+			{&outdatedCratesURL, &opts.Registry.CratesURL},
+			{&outdatedGemsURL, &opts.Registry.RubyGemsURL},
+			{&outdatedGoProxyURL, &opts.Registry.GoProxyURL},
+		} {
+			if *o.flag != "" {
+				*o.target = *o.flag
+			}
+		}
+		report := analyzer.AnalyzeFreshness(deps, opts)
+		output.PrintFreshness(report, outdatedAll)
+
+		if report.Score < outdatedMinScore {
+			cmd.SilenceUsage = true
+			return fmt.Errorf("freshness score %d is below %d", report.Score, outdatedMinScore)
+		}
+		return nil
+	},
+}
+
+func init() {
+	outdatedCmd.Flags().BoolVar(&outdatedAll, "all", false, "also list up-to-date dependencies")
+	outdatedCmd.Flags().IntVar(&outdatedMinScore, "min-score", 0, "exit with an error below this freshness score")
+	outdatedCmd.Flags().StringVar(&outdatedNPMURL, "npm-registry", "", "npm registry URL")
+	outdatedCmd.Flags().StringVar(&outdatedPyPIURL, "pypi-url", "", "PyPI URL")
+	outdatedCmd.Flags().StringVar(&outdatedCratesURL, "crates-url", "", "crates.io URL")
+	outdatedCmd.Flags().StringVar(&outdatedGemsURL, "rubygems-url", "", "RubyGems URL")
+	outdatedCmd.Flags().StringVar(&outdatedGoProxyURL, "goproxy", "", "Go module proxy URL")
+	rootCmd.AddCommand(outdatedCmd)
+}
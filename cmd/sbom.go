@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/agnivo988/Repo-lyzer/internal/analyzer"
+	"github.com/agnivo988/Repo-lyzer/internal/github"
+)
+
+var sbomFormat string
+
+// sbomCmd prints a Software Bill of Materials for a repository.
+// Usage example:
+//
+//	repo-lyzer sbom expressjs/express --format spdx-json > express.spdx.json
+//
+// Packages come from the manifests and lockfiles in the repository; without
+// a lockfile only direct dependencies are listed, at their declared ranges.
+var sbomCmd = &cobra.Command{
+	Use:   "sbom owner/repo",
+	Short: "Print an SBOM in CycloneDX or SPDX format",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		parts := strings.Split(args[0], "/")
+		if len(parts) != 2 {
+			return fmt.Errorf("repository must be in owner/repo format")
+		}
+		if !slices.Contains(analyzer.SBOMFormats, sbomFormat) {
+			return fmt.Errorf("unknown format %q (use %s)", sbomFormat, strings.Join(analyzer.SBOMFormats, ", "))
+		}
+
+		client := github.NewClient()
+		repo, err := client.GetRepo(parts[0], parts[1])
+		if err != nil {
+			return err
+		}
+		tree, err := client.GetFileTree(parts[0], parts[1], repo.DefaultBranch)
+		if err != nil {
+			return err
+		}
+		deps, err := analyzer.AnalyzeDependencies(client, parts[0], parts[1], repo.DefaultBranch, tree)
+		if err != nil {
+			return err
+		}
+		license, _ := analyzer.AnalyzeLicense(client, parts[0], parts[1], tree)
+
+		subject := analyzer.SBOMSubject{
+			Name:    repo.FullName,
+			Version: repo.DefaultBranch,
+			URL:     repo.HTMLURL,
+			License: license.SPDXExpression(),
+		}
+		sbom := analyzer.BuildSBOM(subject, deps, nil)
+		out, err := analyzer.EncodeSBOM(sbom, sbomFormat)
+		if err != nil {
+			return err
+		}
+		fmt.Println(strings.TrimRight(string(out), "\n"))
+		return nil
+	},
+}
+
+func init() {
+	sbomCmd.Flags().StringVar(&sbomFormat, "format", analyzer.SBOMCycloneDXJSON, "output format: "+strings.Join(analyzer.SBOMFormats, ", "))
+	rootCmd.AddCommand(sbomCmd)
+}
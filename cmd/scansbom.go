@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/agnivo988/Repo-lyzer/internal/analyzer"
+	"github.com/agnivo988/Repo-lyzer/internal/ui"
+)
+
+var scanSBOMNoTUI bool
+
+// scanSBOMCmd vets the dependencies listed in an SBOM, without access to
+// the project's source.
+// Usage example:
+//
+//	repo-lyzer scan-sbom vendor-app.cdx.json
+//
+// CycloneDX and SPDX 2.x JSON documents are accepted. The results open in
+// the dashboard's Security and Dependencies views; --no-tui prints a
+// summary instead.
+var scanSBOMCmd = &cobra.Command{
+	Use:   "scan-sbom file.json",
+	Short: "Scan the dependencies listed in a CycloneDX or SPDX SBOM",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		result, imported, err := ui.AnalyzeSBOM(args[0])
+		if imported == nil {
+			return err
+		}
+		if err != nil && !scanSBOMNoTUI {
+			// The dashboard shows the scan as incomplete
+			fmt.Println("Warning:", err)
+		}
+		if !scanSBOMNoTUI {
+			return ui.RunDashboard(result)
+		}
+
+		printSBOMSummary(result, imported)
+		return err
+	},
+}
+
+// printSBOMSummary prints the scan results of an imported SBOM
+func printSBOMSummary(result ui.AnalysisResult, imported *analyzer.ImportedSBOM) {
+	fmt.Printf("%s (%s): %d components, %d dependencies in %s\n",
+		result.Repo.FullName, imported.Format, imported.Components,
+		result.Dependencies.TotalDeps, strings.Join(result.Dependencies.Languages, ", "))
+	if len(imported.Skipped) > 0 {
+		fmt.Printf("%d components without a known package URL were not scanned\n", len(imported.Skipped))
+	}
+
+	sec := result.Security
+	if sec.Failed() {
+		fmt.Printf("\nVulnerability scan failed: none of %d packages could be checked\n", sec.ScannedPackages)
+	} else {
+		fmt.Printf("\nSecurity score: %d/100 (%s)\n", sec.SecurityScore, analyzer.GetSecurityGrade(sec.SecurityScore))
+		fmt.Printf("Vulnerabilities: %d (%d critical, %d high, %d medium, %d low)\n",
+			sec.TotalCount, sec.CriticalCount, sec.HighCount, sec.MediumCount, sec.LowCount)
+	}
+	if sec.Incomplete() && !sec.Failed() {
+		fmt.Printf("Scan incomplete: %d packages not checked\n", sec.FailedPackages)
+	}
+	for _, item := range result.Remediation.Items {
+		fix := "no fixed release yet"
+		if item.FixVersion != "" {
+			fix = "upgrade to " + item.FixVersion
+			if item.MajorUpgrade {
+				fix += " (major)"
+			}
+		}
+		if !item.Direct && len(item.Bump) > 0 {
+			fix += " via " + strings.Join(item.Bump, ", ")
+		}
+		fmt.Printf("  %s %s %s: %s - %s\n", analyzer.GetSeverityEmoji(item.Severity), item.Package, item.Version,
+			strings.Join(item.Vulnerabilities, ", "), fix)
+	}
+
+	if lic := result.License; lic != nil {
+		license := "not declared"
+		if lic.MainLicense != nil {
+			license = lic.MainLicense.SPDX
+		}
+		fmt.Printf("\nLicense: %s (%s)\n", license, lic.Compatibility)
+		for _, w := range lic.Warnings {
+			fmt.Println("  ⚠️ ", w)
+		}
+	}
+	if c := result.Compliance; c != nil {
+		fmt.Printf("License policy: %d allowed, %d to review, %d denied, %d unknown\n",
+			c.AllowedCount, c.ReviewCount, c.DeniedCount, c.UnknownCount)
+		for _, p := range c.Violations() {
+			reason := p.Reason
+			if p.Conflict != "" {
+				reason = p.Conflict
+			}
+			fmt.Printf("  ⛔ %s %s (%s): %s\n", p.Name, p.Version, p.License, reason)
+		}
+	}
+}
+
+func init() {
+	scanSBOMCmd.Flags().BoolVar(&scanSBOMNoTUI, "no-tui", false, "print a summary instead of opening the dashboard")
+	rootCmd.AddCommand(scanSBOMCmd)
+}
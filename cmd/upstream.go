@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/agnivo988/Repo-lyzer/internal/analyzer"
+	"github.com/agnivo988/Repo-lyzer/internal/cache"
+	"github.com/agnivo988/Repo-lyzer/internal/github"
+	"github.com/agnivo988/Repo-lyzer/internal/output"
+	"github.com/agnivo988/Repo-lyzer/internal/ui"
+)
+
+var (
+	upstreamBudget  int
+	upstreamNoCache bool
+	upstreamAll     bool
+	upstreamFail    bool
+)
+
+// upstreamCmd checks whether a repository's direct dependencies are still
+// maintained upstream.
+// Usage example:
+//
+//	repo-lyzer upstream expressjs/express --budget 150
+//
+// Each dependency that maps to a GitHub repository gets a light analysis:
+// health score, bus factor, last push and archived flag. Each repository
+// costs three GitHub requests unless it is cached; --budget caps the total.
+var upstreamCmd = &cobra.Command{
+	Use:   "upstream owner/repo",
+	Short: "Flag archived, single-maintainer and stale dependencies",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		parts := strings.Split(args[0], "/")
+		if len(parts) != 2 {
+			return fmt.Errorf("repository must be in owner/repo format")
+		}
+
+		client := github.NewClient()
+		repo, err := client.GetRepo(parts[0], parts[1])
+		if err != nil {
+			return err
+		}
+		tree, err := client.GetFileTree(parts[0], parts[1], repo.DefaultBranch)
+		if err != nil {
+			return err
+		}
+		deps, err := analyzer.AnalyzeDependencies(client, parts[0], parts[1], repo.DefaultBranch, tree)
+		if err != nil {
+			return err
+		}
+
+		opts := analyzer.DefaultUpstreamOptions()
+		opts.Budget = upstreamBudget
+		if !upstreamNoCache {
+			if repoCache, err := cache.NewCache(); err == nil {
+				opts.Cache = ui.NewUpstreamCache(repoCache)
+			}
+		}
+		result := analyzer.CheckUpstreamHealth(client, deps, opts)
+		output.PrintUpstreamHealth(result, upstreamAll)
+
+		if upstreamFail && len(result.AtRisk()) > 0 {
+			cmd.SilenceUsage = true
+			return fmt.Errorf("%d dependencies have risky upstreams", len(result.AtRisk()))
+		}
+		return nil
+	},
+}
+
+func init() {
+	upstreamCmd.Flags().IntVar(&upstreamBudget, "budget", analyzer.DefaultUpstreamOptions().Budget, "most GitHub API requests to spend")
+	upstreamCmd.Flags().BoolVar(&upstreamNoCache, "no-cache", false, "analyze every upstream again instead of using the cache")
+	upstreamCmd.Flags().BoolVar(&upstreamAll, "all", false, "also list dependencies without risk flags")
+	upstreamCmd.Flags().BoolVar(&upstreamFail, "fail", false, "exit with an error if any upstream is at risk")
+	rootCmd.AddCommand(upstreamCmd)
+}
@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/agnivo988/Repo-lyzer/internal/analyzer"
+	"github.com/agnivo988/Repo-lyzer/internal/config"
+)
+
+var vulndbEcosystems []string
+
+// vulndbCmd manages the offline vulnerability database used when
+// api.osv.dev cannot be reached.
+var vulndbCmd = &cobra.Command{
+	Use:   "vulndb",
+	Short: "Manage the offline vulnerability database",
+}
+
+// vulndbSyncCmd imports OSV ecosystem dumps.
+// Usage examples:
+//
+//	repo-lyzer vulndb sync                      # download npm, PyPI, Go, ... from OSV
+//	repo-lyzer vulndb sync --ecosystem npm,Go
+//	repo-lyzer vulndb sync ./npm-all.zip        # air-gapped: a dump copied in
+//
+// Dumps are the all.zip files OSV publishes per ecosystem.
+var vulndbSyncCmd = &cobra.Command{
+	Use:   "sync [zip file or URL...]",
+	Short: "Import OSV advisory dumps from files or URLs",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		db, err := openVulnDB()
+		if err != nil {
+			return err
+		}
+
+		sources := args
+		if len(sources) == 0 {
+			ecosystems := vulndbEcosystems
+			if len(ecosystems) == 0 {
+				ecosystems = analyzer.VulnDBEcosystems()
+			}
+			for _, eco := range ecosystems {
+				sources = append(sources, analyzer.VulnDBDumpURL(eco))
+			}
+		}
+
+		for _, source := range sources {
+			fmt.Printf("Importing %s ...\n", source)
+			counts, err := db.Sync(source, nil)
+			if err != nil {
+				return err
+			}
+			for _, eco := range sortedEcosystems(counts) {
+				fmt.Printf("  %-10s %6d advisories\n", eco, counts[eco])
+			}
+		}
+		fmt.Printf("Vulnerability database updated in %s\n", db.Dir)
+		return nil
+	},
+}
+
+// vulndbStatusCmd shows what the offline database contains
+var vulndbStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the contents and age of the offline vulnerability database",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		db, err := openVulnDB()
+		if err != nil {
+			return err
+		}
+		if db.Empty() {
+			fmt.Println("The offline vulnerability database is empty; run `repo-lyzer vulndb sync`.")
+			return nil
+		}
+		fmt.Printf("Database: %s\n\n", db.Dir)
+		for _, eco := range sortedEcosystems(db.Meta.Ecosystems) {
+			info := db.Meta.Ecosystems[eco]
+			fmt.Printf("  %-10s %6d advisories  %6d packages  synced %s\n",
+				eco, info.Advisories, info.Packages, info.UpdatedAt.Format(time.DateTime))
+		}
+		return nil
+	},
+}
+
+func openVulnDB() (*analyzer.VulnDB, error) {
+	dir, err := config.VulnDBDir()
+	if err != nil {
+		return nil, err
+	}
+	return analyzer.OpenVulnDB(dir)
+}
+
+// sortedEcosystems returns the keys of a per-ecosystem map in order
+func sortedEcosystems[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return strings.ToLower(keys[i]) < strings.ToLower(keys[j]) })
+	return keys
+}
+
+func init() {
+	vulndbSyncCmd.Flags().StringSliceVar(&vulndbEcosystems, "ecosystem", nil, "OSV ecosystems to download when no source is given (default: all supported)")
+	vulndbCmd.AddCommand(vulndbSyncCmd, vulndbStatusCmd)
+	rootCmd.AddCommand(vulndbCmd)
+}
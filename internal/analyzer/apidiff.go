@@ -0,0 +1,755 @@
+// Package analyzer provides functions for analyzing GitHub repository data.
+// This file implements exported API comparison between two refs of a Go module.
+package analyzer
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/agnivo988/Repo-lyzer/internal/github"
+)
+
+// APIChange is a single difference in a module's exported API
+type APIChange struct {
+	Package  string `json:"package"` // import path relative to the module root
+	Name     string `json:"name"`    // identifier, Type.Method or Type.Field; empty for the package itself
+	Change   string `json:"change"`  // added, removed or changed
+	Breaking bool   `json:"breaking"`
+	Detail   string `json:"detail"`
+}
+
+// APIDiffReport is the result of comparing a module's API at two refs
+type APIDiffReport struct {
+	Module           string      `json:"module"`
+	OldRef           string      `json:"old_ref"`
+	NewRef           string      `json:"new_ref"`
+	Changes          []APIChange `json:"changes"`
+	BreakingCount    int         `json:"breaking_count"`
+	CompatibleCount  int         `json:"compatible_count"`
+	SemverViolations []string    `json:"semver_violations"`
+	TypeErrors       int         `json:"type_errors"` // errors ignored while type-checking either ref
+}
+
+// GoAPI is the type-checked API of a Go module at one ref
+type GoAPI struct {
+	Module     string
+	Packages   map[string]*types.Package // keyed by directory relative to the module root
+	TypeErrors int
+}
+
+// APILoadOptions controls which packages are loaded
+type APILoadOptions struct {
+	PathPrefix string // only load packages under this directory
+	MaxFiles   int    // 0 means no limit
+}
+
+var goModulePattern = regexp.MustCompile(`(?m)^module\s+"?([^\s"]+)"?`)
+
+// CompareGoAPI loads the Go packages of owner/repo at oldRef and newRef,
+// type-checks them and reports exported API changes, classified as breaking
+// or compatible, together with semantic versioning violations.
+func CompareGoAPI(client *github.Client, owner, repo, oldRef, newRef string, opts APILoadOptions) (*APIDiffReport, error) {
+	oldTree, err := client.GetFileTree(owner, repo, oldRef)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tree for %s: %w", oldRef, err)
+	}
+	newTree, err := client.GetFileTree(owner, repo, newRef)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tree for %s: %w", newRef, err)
+	}
+
+	// Files unchanged between the refs share a blob SHA and are fetched once
+	cache := make(map[string][]byte)
+	oldAPI, err := LoadGoAPI(NewGitHubBlobSource(client, owner, repo, oldTree, cache), oldTree, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load %s: %w", oldRef, err)
+	}
+	newAPI, err := LoadGoAPI(NewGitHubBlobSource(client, owner, repo, newTree, cache), newTree, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load %s: %w", newRef, err)
+	}
+
+	return BuildAPIDiffReport(oldAPI, newAPI, oldRef, newRef), nil
+}
+
+// BuildAPIDiffReport diffs two loaded APIs and summarises the result
+func BuildAPIDiffReport(oldAPI, newAPI *GoAPI, oldRef, newRef string) *APIDiffReport {
+	report := &APIDiffReport{
+		Module:     newAPI.Module,
+		OldRef:     oldRef,
+		NewRef:     newRef,
+		Changes:    DiffGoAPI(oldAPI, newAPI),
+		TypeErrors: oldAPI.TypeErrors + newAPI.TypeErrors,
+	}
+	for _, c := range report.Changes {
+		if c.Breaking {
+			report.BreakingCount++
+		} else {
+			report.CompatibleCount++
+		}
+	}
+	report.SemverViolations = SemverViolations(oldRef, newRef, report.Changes)
+	return report
+}
+
+// LoadGoAPI reads the module's non-test Go files from source and type-checks
+// every package. Imports from outside the module are replaced by stub
+// packages declaring the names the module uses, so that no network access
+// or GOPATH is needed; type errors caused by this are counted, not fatal.
+func LoadGoAPI(source FileSource, fileTree []github.TreeEntry, opts APILoadOptions) (*GoAPI, error) {
+	api := &GoAPI{Packages: make(map[string]*types.Package)}
+
+	if content, err := source.ReadFile("go.mod"); err == nil {
+		if m := goModulePattern.FindSubmatch(content); m != nil {
+			api.Module = string(m[1])
+		}
+	}
+
+	// Nested modules are versioned separately
+	var nested []string
+	for _, entry := range fileTree {
+		if entry.Type == "blob" && baseName(entry.Path) == "go.mod" && entry.Path != "go.mod" {
+			nested = append(nested, path.Dir(entry.Path)+"/")
+		}
+	}
+
+	var files []string
+	for _, entry := range fileTree {
+		p := entry.Path
+		if entry.Type != "blob" || !strings.HasSuffix(p, ".go") || strings.HasSuffix(p, "_test.go") {
+			continue
+		}
+		if opts.PathPrefix != "" && !strings.HasPrefix(p, strings.TrimSuffix(opts.PathPrefix, "/")+"/") {
+			continue
+		}
+		if isVendoredPath(strings.ToLower(p)) || isIgnoredGoDir(path.Dir(p)) || hasAnyPrefix(p, nested) {
+			continue
+		}
+		files = append(files, p)
+	}
+	if opts.MaxFiles > 0 && len(files) > opts.MaxFiles {
+		return nil, fmt.Errorf("%d Go files exceed the limit of %d; narrow the comparison with a path", len(files), opts.MaxFiles)
+	}
+
+	contents := make(map[string][]byte)
+	for _, p := range files {
+		content, err := source.ReadFile(p)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", p, err)
+		}
+		contents[p] = content
+	}
+
+	// Evaluate build constraints as for a linux/amd64 build
+	ctx := build.Default
+	ctx.GOOS, ctx.GOARCH, ctx.CgoEnabled = "linux", "amd64", true
+	ctx.JoinPath = path.Join
+	ctx.OpenFile = func(p string) (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(contents[p])), nil
+	}
+
+	fset := token.NewFileSet()
+	byDir := make(map[string][]*ast.File)
+	for _, p := range files {
+		if ok, err := ctx.MatchFile(path.Dir(p), baseName(p)); err != nil || !ok {
+			continue
+		}
+		f, err := parser.ParseFile(fset, p, contents[p], parser.SkipObjectResolution)
+		if err != nil {
+			api.TypeErrors++
+			continue
+		}
+		byDir[path.Dir(p)] = append(byDir[path.Dir(p)], f)
+	}
+
+	l := &apiLoader{
+		api:      api,
+		fset:     fset,
+		byDir:    byDir,
+		stubs:    make(map[string]*types.Package),
+		checking: make(map[string]bool),
+		loaded:   make(map[string]*types.Package),
+		stubUses: collectStubUses(byDir, api.Module),
+	}
+	dirs := make([]string, 0, len(byDir))
+	for dir := range byDir {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+	for _, dir := range dirs {
+		l.check(dir)
+	}
+
+	// Only packages other modules can import are part of the API
+	for dir, pkg := range api.Packages {
+		if !isPublicGoPackage(dir, pkg.Name()) {
+			delete(api.Packages, dir)
+		}
+	}
+
+	return api, nil
+}
+
+// apiLoader type-checks packages on demand so module-internal imports resolve
+type apiLoader struct {
+	api      *GoAPI
+	fset     *token.FileSet
+	byDir    map[string][]*ast.File
+	stubs    map[string]*types.Package
+	checking map[string]bool
+	loaded   map[string]*types.Package
+	stubUses map[string]map[string]bool // external import path -> selected names
+}
+
+// check type-checks the package in dir, returning nil if there is none
+func (l *apiLoader) check(dir string) *types.Package {
+	if pkg, ok := l.loaded[dir]; ok {
+		return pkg
+	}
+	files := l.byDir[dir]
+	if len(files) == 0 || l.checking[dir] {
+		return nil
+	}
+	l.checking[dir] = true
+	defer delete(l.checking, dir)
+
+	files = majorityPackage(files)
+	conf := types.Config{
+		Importer:         importerFunc(l.importPackage),
+		IgnoreFuncBodies: true,
+		FakeImportC:      true,
+		Error:            func(error) { l.api.TypeErrors++ },
+	}
+	pkg, _ := conf.Check(modulePackagePath(l.api.Module, dir), l.fset, files, nil)
+	l.loaded[dir] = pkg
+	if pkg != nil && pkg.Name() != "main" {
+		l.api.Packages[dir] = pkg
+	}
+	return pkg
+}
+
+// importPackage resolves module packages from source and stubs the rest
+func (l *apiLoader) importPackage(importPath string) (*types.Package, error) {
+	if importPath == "unsafe" {
+		return types.Unsafe, nil
+	}
+	if dir, ok := moduleRelativeDir(l.api.Module, importPath); ok {
+		if pkg := l.check(dir); pkg != nil {
+			return pkg, nil
+		}
+	}
+	if pkg, ok := l.stubs[importPath]; ok {
+		return pkg, nil
+	}
+
+	// Stub every selected name as a distinct named type; this keeps type
+	// strings such as "context.Context" stable across both refs.
+	pkg := types.NewPackage(importPath, guessPackageName(importPath))
+	names := make([]string, 0, len(l.stubUses[importPath]))
+	for name := range l.stubUses[importPath] {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		tn := types.NewTypeName(token.NoPos, pkg, name, nil)
+		types.NewNamed(tn, types.NewInterfaceType(nil, nil), nil)
+		pkg.Scope().Insert(tn)
+	}
+	pkg.MarkComplete()
+	l.stubs[importPath] = pkg
+	return pkg, nil
+}
+
+// importerFunc adapts a function to types.Importer
+type importerFunc func(path string) (*types.Package, error)
+
+func (f importerFunc) Import(path string) (*types.Package, error) { return f(path) }
+
+// collectStubUses records which names are selected from each external import
+func collectStubUses(byDir map[string][]*ast.File, module string) map[string]map[string]bool {
+	uses := make(map[string]map[string]bool)
+	for _, files := range byDir {
+		for _, f := range files {
+			local := make(map[string]string)
+			for _, imp := range f.Imports {
+				importPath, _ := strconv.Unquote(imp.Path.Value)
+				if _, inModule := moduleRelativeDir(module, importPath); inModule {
+					continue
+				}
+				name := guessPackageName(importPath)
+				if imp.Name != nil {
+					name = imp.Name.Name
+				}
+				local[name] = importPath
+			}
+			ast.Inspect(f, func(n ast.Node) bool {
+				sel, ok := n.(*ast.SelectorExpr)
+				if !ok {
+					return true
+				}
+				if id, ok := sel.X.(*ast.Ident); ok {
+					if importPath, ok := local[id.Name]; ok {
+						if uses[importPath] == nil {
+							uses[importPath] = make(map[string]bool)
+						}
+						uses[importPath][sel.Sel.Name] = true
+					}
+				}
+				return true
+			})
+		}
+	}
+	return uses
+}
+
+// majorityPackage drops files whose package clause differs from the most
+// common one in a directory (for example ignored "package documentation")
+func majorityPackage(files []*ast.File) []*ast.File {
+	counts := make(map[string]int)
+	best := ""
+	for _, f := range files {
+		counts[f.Name.Name]++
+		if counts[f.Name.Name] > counts[best] {
+			best = f.Name.Name
+		}
+	}
+	var kept []*ast.File
+	for _, f := range files {
+		if f.Name.Name == best {
+			kept = append(kept, f)
+		}
+	}
+	return kept
+}
+
+// DiffGoAPI compares the exported API of two loads of the same module.
+// Packages are matched by directory so a module path change (such as a new
+// /v2 suffix) does not hide the differences.
+func DiffGoAPI(oldAPI, newAPI *GoAPI) []APIChange {
+	var changes []APIChange
+	d := &apiDiffer{oldAPI: oldAPI, newAPI: newAPI}
+
+	for _, dir := range sortedPackageDirs(oldAPI, newAPI) {
+		oldPkg, newPkg := oldAPI.Packages[dir], newAPI.Packages[dir]
+		switch {
+		case newPkg == nil:
+			changes = append(changes, APIChange{Package: dir, Change: "removed", Breaking: true, Detail: "package removed"})
+		case oldPkg == nil:
+			changes = append(changes, APIChange{Package: dir, Change: "added", Detail: "package added"})
+		default:
+			changes = append(changes, d.diffPackage(dir, oldPkg, newPkg)...)
+		}
+	}
+
+	return changes
+}
+
+// apiDiffer holds both APIs so type strings can be qualified consistently
+type apiDiffer struct {
+	oldAPI, newAPI *GoAPI
+}
+
+func (d *apiDiffer) diffPackage(dir string, oldPkg, newPkg *types.Package) []APIChange {
+	var changes []APIChange
+	add := func(name, change string, breaking bool, detail string) {
+		changes = append(changes, APIChange{Package: dir, Name: name, Change: change, Breaking: breaking, Detail: detail})
+	}
+
+	oldScope, newScope := oldPkg.Scope(), newPkg.Scope()
+	for _, name := range oldScope.Names() {
+		oldObj := oldScope.Lookup(name)
+		if !oldObj.Exported() {
+			continue
+		}
+		newObj := newScope.Lookup(name)
+		if newObj == nil || !newObj.Exported() {
+			add(name, "removed", true, objectKind(oldObj)+" removed")
+			continue
+		}
+		if objectKind(oldObj) != objectKind(newObj) {
+			add(name, "changed", true, fmt.Sprintf("changed from %s to %s", objectKind(oldObj), objectKind(newObj)))
+			continue
+		}
+
+		switch o := oldObj.(type) {
+		case *types.Const:
+			n := newObj.(*types.Const)
+			if ot, nt := d.typeString(d.oldAPI, o.Type()), d.typeString(d.newAPI, n.Type()); ot != nt {
+				add(name, "changed", true, fmt.Sprintf("type changed from %s to %s", ot, nt))
+			} else if o.Val().ExactString() != n.Val().ExactString() {
+				add(name, "changed", true, fmt.Sprintf("value changed from %s to %s", o.Val().ExactString(), n.Val().ExactString()))
+			}
+		case *types.Var, *types.Func:
+			if ot, nt := d.typeString(d.oldAPI, oldObj.Type()), d.typeString(d.newAPI, newObj.Type()); ot != nt {
+				add(name, "changed", true, fmt.Sprintf("%s changed from %s to %s", describeType(oldObj), ot, nt))
+			}
+		case *types.TypeName:
+			for _, c := range d.diffTypeName(o, newObj.(*types.TypeName)) {
+				add(c.Name, c.Change, c.Breaking, c.Detail)
+			}
+		}
+	}
+
+	for _, name := range newScope.Names() {
+		if obj := newScope.Lookup(name); obj.Exported() {
+			if old := oldScope.Lookup(name); old == nil || !old.Exported() {
+				add(name, "added", false, objectKind(obj)+" added")
+			}
+		}
+	}
+
+	return changes
+}
+
+// diffTypeName compares two declarations of an exported type
+func (d *apiDiffer) diffTypeName(oldTN, newTN *types.TypeName) []APIChange {
+	name := oldTN.Name()
+	var changes []APIChange
+	add := func(member, change string, breaking bool, detail string) {
+		full := name
+		if member != "" {
+			full += "." + member
+		}
+		changes = append(changes, APIChange{Name: full, Change: change, Breaking: breaking, Detail: detail})
+	}
+
+	if oldTN.IsAlias() != newTN.IsAlias() {
+		add("", "changed", true, "changed between alias and defined type")
+		return changes
+	}
+	if oldTN.IsAlias() {
+		if ot, nt := d.typeString(d.oldAPI, oldTN.Type()), d.typeString(d.newAPI, newTN.Type()); ot != nt {
+			add("", "changed", true, fmt.Sprintf("alias target changed from %s to %s", ot, nt))
+		}
+		return changes
+	}
+
+	oldNamed, ok1 := oldTN.Type().(*types.Named)
+	newNamed, ok2 := newTN.Type().(*types.Named)
+	if !ok1 || !ok2 {
+		return changes
+	}
+	if ot, nt := d.typeParams(d.oldAPI, oldNamed), d.typeParams(d.newAPI, newNamed); ot != nt {
+		add("", "changed", true, fmt.Sprintf("type parameters changed from [%s] to [%s]", ot, nt))
+	}
+
+	switch ou := oldNamed.Underlying().(type) {
+	case *types.Struct:
+		nu, ok := newNamed.Underlying().(*types.Struct)
+		if !ok {
+			add("", "changed", true, "no longer a struct")
+			return changes
+		}
+		oldFields, newFields := exportedFields(ou), exportedFields(nu)
+		for _, f := range sortedKeys(oldFields) {
+			nf, ok := newFields[f]
+			if !ok {
+				add(f, "removed", true, "field removed")
+				continue
+			}
+			if ot, nt := d.typeString(d.oldAPI, oldFields[f].Type()), d.typeString(d.newAPI, nf.Type()); ot != nt {
+				add(f, "changed", true, fmt.Sprintf("field type changed from %s to %s", ot, nt))
+			}
+		}
+		for _, f := range sortedKeys(newFields) {
+			if _, ok := oldFields[f]; !ok {
+				add(f, "added", false, "field added")
+			}
+		}
+
+	case *types.Interface:
+		nu, ok := newNamed.Underlying().(*types.Interface)
+		if !ok {
+			add("", "changed", true, "no longer an interface")
+			return changes
+		}
+		// Interfaces with unexported methods cannot be implemented outside
+		// the package, so adding methods to them breaks nobody
+		sealed := false
+		for i := 0; i < ou.NumMethods(); i++ {
+			if !ou.Method(i).Exported() {
+				sealed = true
+			}
+		}
+		oldMethods, newMethods := interfaceMethods(ou), interfaceMethods(nu)
+		for _, m := range sortedKeys(oldMethods) {
+			nm, ok := newMethods[m]
+			if !ok {
+				add(m, "removed", true, "interface method removed")
+				continue
+			}
+			if ot, nt := d.typeString(d.oldAPI, oldMethods[m].Type()), d.typeString(d.newAPI, nm.Type()); ot != nt {
+				add(m, "changed", true, fmt.Sprintf("interface method changed from %s to %s", ot, nt))
+			}
+		}
+		for _, m := range sortedKeys(newMethods) {
+			if _, ok := oldMethods[m]; !ok {
+				add(m, "added", !sealed, "interface method added; existing implementations no longer satisfy it")
+			}
+		}
+		return changes
+
+	default:
+		if ot, nt := d.typeString(d.oldAPI, oldNamed.Underlying()), d.typeString(d.newAPI, newNamed.Underlying()); ot != nt {
+			add("", "changed", true, fmt.Sprintf("underlying type changed from %s to %s", ot, nt))
+		}
+	}
+
+	oldMethods, newMethods := methodSet(types.NewPointer(oldNamed)), methodSet(types.NewPointer(newNamed))
+	for _, m := range sortedKeys(oldMethods) {
+		nm, ok := newMethods[m]
+		if !ok {
+			add(m, "removed", true, "method removed")
+			continue
+		}
+		if ot, nt := d.typeString(d.oldAPI, oldMethods[m].Type()), d.typeString(d.newAPI, nm.Type()); ot != nt {
+			add(m, "changed", true, fmt.Sprintf("method changed from %s to %s", ot, nt))
+		}
+	}
+	for _, m := range sortedKeys(newMethods) {
+		if _, ok := oldMethods[m]; !ok {
+			add(m, "added", false, "method added")
+		}
+	}
+	// A method moved to a pointer receiver is gone from values of T and
+	// from interfaces they were used as
+	oldValue, newValue := methodSet(oldNamed), methodSet(newNamed)
+	for _, m := range sortedKeys(oldValue) {
+		if _, ok := newValue[m]; !ok && newMethods[m] != nil {
+			add(m, "changed", true, "method moved to a pointer receiver; values of the type no longer have it")
+		}
+	}
+
+	return changes
+}
+
+// typeString formats t, naming the module's own packages by directory
+func (d *apiDiffer) typeString(api *GoAPI, t types.Type) string {
+	return types.TypeString(t, func(p *types.Package) string {
+		if dir, ok := moduleRelativeDir(api.Module, p.Path()); ok {
+			return dir
+		}
+		return p.Path()
+	})
+}
+
+// typeParams formats a named type's type parameter constraints
+func (d *apiDiffer) typeParams(api *GoAPI, named *types.Named) string {
+	tparams := named.TypeParams()
+	parts := make([]string, tparams.Len())
+	for i := range parts {
+		parts[i] = d.typeString(api, tparams.At(i).Constraint())
+	}
+	return strings.Join(parts, ", ")
+}
+
+// SemverViolations checks the API changes against the version bump between
+// two refs. Refs that are not semantic versions are not checked.
+func SemverViolations(oldRef, newRef string, changes []APIChange) []string {
+	oldVer, ok1 := parseSemver(oldRef)
+	newVer, ok2 := parseSemver(newRef)
+	if !ok1 || !ok2 {
+		return []string{}
+	}
+
+	breaking, added := 0, 0
+	for _, c := range changes {
+		if c.Breaking {
+			breaking++
+		} else if c.Change == "added" {
+			added++
+		}
+	}
+
+	violations := []string{}
+	sameMajor := oldVer[0] == newVer[0]
+	switch {
+	case breaking > 0 && sameMajor && newVer[0] >= 1:
+		release := "minor"
+		if oldVer[1] == newVer[1] {
+			release = "patch"
+		}
+		violations = append(violations, fmt.Sprintf("%d breaking changes in a %s release (%s → %s); a new major version is required",
+			breaking, release, oldRef, newRef))
+	case added > 0 && sameMajor && oldVer[1] == newVer[1] && newVer[0] >= 1:
+		violations = append(violations, fmt.Sprintf("%d API additions in a patch release (%s → %s); a minor version bump is expected",
+			added, oldRef, newRef))
+	}
+	return violations
+}
+
+// parseSemver parses vMAJOR.MINOR.PATCH, ignoring pre-release and build suffixes
+func parseSemver(ref string) ([3]int, bool) {
+	var v [3]int
+	s := strings.TrimPrefix(ref, "v")
+	if i := strings.IndexAny(s, "-+"); i >= 0 {
+		s = s[:i]
+	}
+	parts := strings.Split(s, ".")
+	if len(parts) == 0 || len(parts) > 3 {
+		return v, false
+	}
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return v, false
+		}
+		v[i] = n
+	}
+	return v, true
+}
+
+// objectKind names the kind of a package-level object
+func objectKind(obj types.Object) string {
+	switch obj.(type) {
+	case *types.Const:
+		return "const"
+	case *types.Var:
+		return "var"
+	case *types.Func:
+		return "func"
+	case *types.TypeName:
+		return "type"
+	}
+	return "object"
+}
+
+// describeType returns the noun used when an object's type changes
+func describeType(obj types.Object) string {
+	if _, ok := obj.(*types.Func); ok {
+		return "signature"
+	}
+	return "type"
+}
+
+// exportedFields returns a struct's exported fields, including embedded ones
+func exportedFields(s *types.Struct) map[string]*types.Var {
+	fields := make(map[string]*types.Var)
+	for i := 0; i < s.NumFields(); i++ {
+		if f := s.Field(i); f.Exported() {
+			fields[f.Name()] = f
+		}
+	}
+	return fields
+}
+
+// interfaceMethods returns all methods of an interface, including embedded ones
+func interfaceMethods(iface *types.Interface) map[string]*types.Func {
+	methods := make(map[string]*types.Func)
+	for i := 0; i < iface.NumMethods(); i++ {
+		if m := iface.Method(i); m.Exported() {
+			methods[m.Name()] = m
+		}
+	}
+	return methods
+}
+
+// methodSet returns the exported methods callable on a value of type t,
+// which for a named type T excludes those with pointer receivers
+func methodSet(t types.Type) map[string]*types.Func {
+	methods := make(map[string]*types.Func)
+	mset := types.NewMethodSet(t)
+	for i := 0; i < mset.Len(); i++ {
+		if fn, ok := mset.At(i).Obj().(*types.Func); ok && fn.Exported() {
+			methods[fn.Name()] = fn
+		}
+	}
+	return methods
+}
+
+// sortedKeys returns the keys of a map in sorted order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// sortedPackageDirs returns the union of both APIs' package directories
+func sortedPackageDirs(oldAPI, newAPI *GoAPI) []string {
+	seen := make(map[string]bool)
+	for dir := range oldAPI.Packages {
+		seen[dir] = true
+	}
+	for dir := range newAPI.Packages {
+		seen[dir] = true
+	}
+	return sortedKeys(seen)
+}
+
+// modulePackagePath returns the import path of the package in dir
+func modulePackagePath(module, dir string) string {
+	switch {
+	case module == "":
+		return dir
+	case dir == ".":
+		return module
+	}
+	return module + "/" + dir
+}
+
+// moduleRelativeDir maps an import path inside module to its directory
+func moduleRelativeDir(module, importPath string) (string, bool) {
+	if module == "" {
+		return "", false
+	}
+	if importPath == module {
+		return ".", true
+	}
+	if strings.HasPrefix(importPath, module+"/") {
+		return strings.TrimPrefix(importPath, module+"/"), true
+	}
+	return "", false
+}
+
+// guessPackageName derives a package name from its import path, skipping
+// major version suffixes such as /v2 and common gopkg.in/go- decorations
+func guessPackageName(importPath string) string {
+	parts := strings.Split(importPath, "/")
+	name := parts[len(parts)-1]
+	if len(parts) > 1 && len(name) > 1 && name[0] == 'v' {
+		if _, err := strconv.Atoi(name[1:]); err == nil {
+			name = parts[len(parts)-2]
+		}
+	}
+	if i := strings.Index(name, ".v"); i > 0 {
+		name = name[:i]
+	}
+	name = strings.TrimPrefix(name, "go-")
+	name = strings.TrimSuffix(name, "-go")
+	return strings.NewReplacer("-", "", ".", "").Replace(name)
+}
+
+// isIgnoredGoDir reports whether the go tool ignores a directory: testdata
+// and names starting with "." or "_"
+func isIgnoredGoDir(dir string) bool {
+	for _, part := range strings.Split(dir, "/") {
+		if part == "testdata" || (part != "." && (strings.HasPrefix(part, ".") || strings.HasPrefix(part, "_"))) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasAnyPrefix reports whether s starts with any of the prefixes
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, p := range prefixes {
+		if strings.HasPrefix(s, p) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,188 @@
+package analyzer
+
+import (
+	"testing"
+
+	"github.com/agnivo988/Repo-lyzer/internal/github"
+)
+
+func loadTestAPI(t *testing.T, files mapFileSource) *GoAPI {
+	t.Helper()
+	var tree []github.TreeEntry
+	for path := range files {
+		tree = append(tree, github.TreeEntry{Path: path, Type: "blob"})
+	}
+	api, err := LoadGoAPI(files, tree, APILoadOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return api
+}
+
+func findChange(changes []APIChange, pkg, name string) *APIChange {
+	for i := range changes {
+		if changes[i].Package == pkg && changes[i].Name == name {
+			return &changes[i]
+		}
+	}
+	return nil
+}
+
+func TestDiffGoAPI(t *testing.T) {
+	oldAPI := loadTestAPI(t, mapFileSource{
+		"go.mod": "module example.com/lib\n\ngo 1.21\n",
+		"lib.go": `package lib
+
+import (
+	"context"
+
+	"example.com/lib/internal/conf"
+)
+
+const Mode = 1
+
+type Options = conf.Options
+
+type Client struct {
+	Name    string
+	Timeout int
+}
+
+func (c *Client) Get(ctx context.Context, key string) (string, error) { return "", nil }
+func (c *Client) Close() error                                       { return nil }
+
+type Store interface {
+	Load(key string) ([]byte, error)
+}
+
+func New(opts Options) *Client { return nil }
+
+type Point struct{ X int }
+
+func (p Point) String() string { return "" }
+func (p *Point) Reset()        {}
+`,
+		"internal/conf/conf.go": "package conf\n\ntype Options struct{ Debug bool }\n",
+		"util/util.go":          "package util\n\nfunc Helper() {}\n",
+		"cmd/tool/main.go":      "package main\n\nfunc main() {}\n",
+		"lib_windows.go":        "package lib\n\nfunc WindowsOnly() {}\n",
+	})
+	newAPI := loadTestAPI(t, mapFileSource{
+		"go.mod": "module example.com/lib/v2\n\ngo 1.21\n",
+		"lib.go": `package lib
+
+import (
+	"context"
+
+	"example.com/lib/v2/internal/conf"
+)
+
+const Mode = 2
+
+type Options = conf.Options
+
+type Client struct {
+	Name    string
+	Timeout int64
+	Retries int
+}
+
+func (c *Client) Get(ctx context.Context, key string, opts ...string) (string, error) { return "", nil }
+func (c *Client) Ping() error                                                         { return nil }
+
+type Store interface {
+	Load(key string) ([]byte, error)
+	Save(key string, v []byte) error
+}
+
+func New(opts Options) *Client { return nil }
+func NewWithContext(ctx context.Context) *Client { return nil }
+
+type Point struct{ X int }
+
+func (p *Point) String() string { return "" }
+func (p Point) Reset()          {}
+`,
+		"internal/conf/conf.go": "package conf\n\ntype Options struct{ Debug bool }\n",
+	})
+
+	if oldAPI.Module != "example.com/lib" || len(oldAPI.Packages) != 2 {
+		t.Fatalf("old API: module %q, packages %v", oldAPI.Module, oldAPI.Packages)
+	}
+	if oldAPI.Packages["."].Scope().Lookup("WindowsOnly") != nil {
+		t.Error("files for other GOOS should be excluded")
+	}
+
+	changes := DiffGoAPI(oldAPI, newAPI)
+	want := map[string]struct {
+		change   string
+		breaking bool
+	}{
+		"Mode":           {"changed", true},
+		"Client.Timeout": {"changed", true},
+		"Client.Retries": {"added", false},
+		"Client.Get":     {"changed", true},
+		"Client.Close":   {"removed", true},
+		"Client.Ping":    {"added", false},
+		"Store.Save":     {"added", true},
+		"NewWithContext": {"added", false},
+		// Values of Point no longer satisfy fmt.Stringer
+		"Point.String": {"changed", true},
+	}
+	for name, w := range want {
+		c := findChange(changes, ".", name)
+		if c == nil {
+			t.Errorf("missing change for %s in %+v", name, changes)
+			continue
+		}
+		if c.Change != w.change || c.Breaking != w.breaking {
+			t.Errorf("%s: got %s/breaking=%v, want %s/breaking=%v", name, c.Change, c.Breaking, w.change, w.breaking)
+		}
+	}
+	if c := findChange(changes, "util", ""); c == nil || c.Change != "removed" || !c.Breaking {
+		t.Errorf("util package removal not reported: %+v", c)
+	}
+	// Moving a method to a value receiver only adds it to values
+	for _, name := range []string{"New", "Options", "Client.Name", "Point.Reset"} {
+		if c := findChange(changes, ".", name); c != nil {
+			t.Errorf("unchanged %s reported as %+v", name, c)
+		}
+	}
+}
+
+func TestSemverViolations(t *testing.T) {
+	breaking := []APIChange{{Change: "removed", Breaking: true}}
+	additive := []APIChange{{Change: "added"}}
+
+	testCases := []struct {
+		oldRef, newRef string
+		changes        []APIChange
+		violations     int
+	}{
+		{"v1.2.0", "v1.3.0", breaking, 1},
+		{"v1.2.0", "v1.2.1", breaking, 1},
+		{"v1.2.0", "v2.0.0", breaking, 0},
+		{"v0.4.0", "v0.5.0", breaking, 0},
+		{"v1.2.0", "v1.2.1", additive, 1},
+		{"v1.2.0", "v1.3.0", additive, 0},
+		{"main", "v1.3.0", breaking, 0},
+	}
+	for _, tc := range testCases {
+		if got := SemverViolations(tc.oldRef, tc.newRef, tc.changes); len(got) != tc.violations {
+			t.Errorf("%s → %s: got %v, want %d violations", tc.oldRef, tc.newRef, got, tc.violations)
+		}
+	}
+}
+
+func TestGuessPackageName(t *testing.T) {
+	for path, want := range map[string]string{
+		"github.com/spf13/cobra":            "cobra",
+		"github.com/go-chi/chi/v5":          "chi",
+		"gopkg.in/yaml.v3":                  "yaml",
+		"github.com/charmbracelet/lipgloss": "lipgloss",
+	} {
+		if got := guessPackageName(path); got != want {
+			t.Errorf("guessPackageName(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
@@ -0,0 +1,343 @@
+// Package analyzer provides functions for analyzing GitHub repository data.
+// This file implements repository size, committed binary and Git LFS checks.
+package analyzer
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/agnivo988/Repo-lyzer/internal/github"
+)
+
+// LFSPointerMaxSize is the largest blob that can still be a Git LFS pointer.
+// Pointer files are about 130 bytes; anything well above that matching an
+// LFS pattern was committed as a regular blob.
+const LFSPointerMaxSize = 1024
+
+// BloatFile is a single blob reported by the bloat analysis
+type BloatFile struct {
+	Path     string `json:"path"`
+	Size     int    `json:"size"`
+	Category string `json:"category,omitempty"` // archive, executable, media, model, database
+}
+
+// DirectoryWeight is the total size of the blobs under a directory
+type DirectoryWeight struct {
+	Path  string `json:"path"` // "." for files in the repository root
+	Files int    `json:"files"`
+	Size  int    `json:"size"`
+}
+
+// LFSPattern is a path pattern routed through the LFS filter by .gitattributes
+type LFSPattern struct {
+	Pattern string `json:"pattern"`
+	Source  string `json:"source"` // .gitattributes file declaring it
+	Matched int    `json:"matched"`
+}
+
+// BloatAnalysis summarises what makes a repository heavy
+type BloatAnalysis struct {
+	TotalFiles     int               `json:"total_files"`
+	TotalSize      int               `json:"total_size"`
+	LargestFiles   []BloatFile       `json:"largest_files"`
+	Directories    []DirectoryWeight `json:"directories"` // top-level, heaviest first
+	Binaries       []BloatFile       `json:"binaries"`    // largest first
+	BinarySize     int               `json:"binary_size"`
+	BinariesByType map[string]int    `json:"binaries_by_type"`
+	LFSPatterns    []LFSPattern      `json:"lfs_patterns"`
+	LFSTracked     int               `json:"lfs_tracked"`      // pointer-sized files matching a pattern
+	LFSNotHonoured []BloatFile       `json:"lfs_not_honoured"` // full blobs matching a pattern
+	LFSCandidates  []BloatFile       `json:"lfs_candidates"`   // large files not covered by LFS
+	CandidateSize  int               `json:"candidate_size"`
+	Findings       []CodeSmell       `json:"findings"`
+}
+
+// BloatOptions sets the size thresholds used by AnalyzeBloat
+type BloatOptions struct {
+	TopN               int // number of largest files to keep
+	BinaryLFSThreshold int // binaries at least this large should use LFS
+	AnyLFSThreshold    int // any file at least this large should use LFS
+}
+
+// DefaultBloatOptions returns thresholds in line with GitHub's own guidance:
+// it warns about files over 50 MB and suggests LFS well before that.
+func DefaultBloatOptions() BloatOptions {
+	return BloatOptions{
+		TopN:               15,
+		BinaryLFSThreshold: 1 << 20,
+		AnyLFSThreshold:    10 << 20,
+	}
+}
+
+var binaryCategories = map[string]string{
+	// Archives and packaged artifacts
+	".zip": "archive", ".tar": "archive", ".gz": "archive", ".tgz": "archive", ".bz2": "archive",
+	".xz": "archive", ".7z": "archive", ".rar": "archive", ".jar": "archive", ".war": "archive",
+	".ear": "archive", ".whl": "archive", ".nupkg": "archive", ".gem": "archive", ".zst": "archive",
+	// Compiled code and installers
+	".exe": "executable", ".dll": "executable", ".so": "executable", ".dylib": "executable",
+	".o": "executable", ".a": "executable", ".lib": "executable", ".class": "executable",
+	".pyc": "executable", ".wasm": "executable", ".apk": "executable", ".ipa": "executable",
+	".msi": "executable", ".deb": "executable", ".rpm": "executable", ".dmg": "executable",
+	".iso": "executable", ".bin": "executable", ".elf": "executable",
+	// Images, audio, video, documents and fonts
+	".png": "media", ".jpg": "media", ".jpeg": "media", ".gif": "media", ".bmp": "media",
+	".tif": "media", ".tiff": "media", ".ico": "media", ".psd": "media", ".webp": "media",
+	".mp3": "media", ".wav": "media", ".flac": "media", ".ogg": "media", ".mp4": "media",
+	".mov": "media", ".avi": "media", ".mkv": "media", ".webm": "media", ".pdf": "media",
+	".ttf": "media", ".otf": "media", ".woff": "media", ".woff2": "media", ".blend": "media",
+	".fbx": "media",
+	// Machine learning model weights and tensors
+	".onnx": "model", ".pt": "model", ".pth": "model", ".ckpt": "model", ".safetensors": "model",
+	".h5": "model", ".hdf5": "model", ".tflite": "model", ".pkl": "model", ".pickle": "model",
+	".joblib": "model", ".npy": "model", ".npz": "model", ".gguf": "model", ".mlmodel": "model",
+	// Data stores
+	".sqlite": "database", ".sqlite3": "database", ".db": "database", ".mdb": "database",
+	".parquet": "database",
+}
+
+// BinaryCategory returns the kind of binary a path holds, or "" for files
+// that are usually text
+func BinaryCategory(p string) string {
+	name := strings.ToLower(baseName(p))
+	if strings.HasSuffix(name, ".tar.gz") {
+		return "archive"
+	}
+	return binaryCategories[path.Ext(name)]
+}
+
+// AnalyzeBloat reports the heaviest files and directories, committed
+// binaries and files that belong in Git LFS. Sizes come from the tree
+// listing; only .gitattributes files are read from source.
+func AnalyzeBloat(source FileSource, fileTree []github.TreeEntry, opts BloatOptions) (*BloatAnalysis, error) {
+	attributes := make(map[string][]byte)
+	for _, entry := range fileTree {
+		if entry.Type == "blob" && baseName(entry.Path) == ".gitattributes" {
+			if content, err := source.ReadFile(entry.Path); err == nil {
+				attributes[entry.Path] = content
+			}
+		}
+	}
+	return BuildBloatReport(fileTree, attributes, opts), nil
+}
+
+// BuildBloatReport computes the bloat analysis from a tree listing and the
+// contents of its .gitattributes files, keyed by path
+func BuildBloatReport(fileTree []github.TreeEntry, attributes map[string][]byte, opts BloatOptions) *BloatAnalysis {
+	b := &BloatAnalysis{
+		LargestFiles:   []BloatFile{},
+		Directories:    []DirectoryWeight{},
+		Binaries:       []BloatFile{},
+		BinariesByType: make(map[string]int),
+		LFSPatterns:    []LFSPattern{},
+		LFSNotHonoured: []BloatFile{},
+		LFSCandidates:  []BloatFile{},
+		Findings:       []CodeSmell{},
+	}
+
+	for _, attrPath := range sortedKeys(attributes) {
+		b.LFSPatterns = append(b.LFSPatterns, ParseLFSPatterns(attrPath, attributes[attrPath])...)
+	}
+
+	dirs := make(map[string]*DirectoryWeight)
+	var all []BloatFile
+	for _, entry := range fileTree {
+		if entry.Type != "blob" {
+			continue
+		}
+		b.TotalFiles++
+		b.TotalSize += entry.Size
+		file := BloatFile{Path: entry.Path, Size: entry.Size, Category: BinaryCategory(entry.Path)}
+		all = append(all, file)
+
+		top := "."
+		if i := strings.Index(entry.Path, "/"); i >= 0 {
+			top = entry.Path[:i]
+		}
+		if dirs[top] == nil {
+			dirs[top] = &DirectoryWeight{Path: top}
+		}
+		dirs[top].Files++
+		dirs[top].Size += entry.Size
+
+		lfsIndex := b.matchLFS(entry.Path)
+		if lfsIndex >= 0 {
+			b.LFSPatterns[lfsIndex].Matched++
+			if entry.Size <= LFSPointerMaxSize {
+				// A pointer's size says nothing about the real object
+				b.LFSTracked++
+				continue
+			}
+			b.LFSNotHonoured = append(b.LFSNotHonoured, file)
+		}
+
+		if file.Category != "" {
+			b.Binaries = append(b.Binaries, file)
+			b.BinarySize += file.Size
+			b.BinariesByType[file.Category]++
+		}
+		if lfsIndex < 0 && ((file.Category != "" && opts.BinaryLFSThreshold > 0 && file.Size >= opts.BinaryLFSThreshold) ||
+			(opts.AnyLFSThreshold > 0 && file.Size >= opts.AnyLFSThreshold)) {
+			b.LFSCandidates = append(b.LFSCandidates, file)
+			b.CandidateSize += file.Size
+		}
+	}
+
+	for _, d := range dirs {
+		b.Directories = append(b.Directories, *d)
+	}
+	sort.Slice(b.Directories, func(i, j int) bool {
+		if b.Directories[i].Size != b.Directories[j].Size {
+			return b.Directories[i].Size > b.Directories[j].Size
+		}
+		return b.Directories[i].Path < b.Directories[j].Path
+	})
+
+	sortBySize(all)
+	if opts.TopN > 0 && len(all) > opts.TopN {
+		all = all[:opts.TopN]
+	}
+	b.LargestFiles = append(b.LargestFiles, all...)
+	sortBySize(b.Binaries)
+	sortBySize(b.LFSNotHonoured)
+	sortBySize(b.LFSCandidates)
+
+	b.Findings = b.findings(opts)
+	return b
+}
+
+// matchLFS returns the index of the last LFS pattern matching p, or -1.
+// Later lines and deeper .gitattributes files take precedence in git.
+func (b *BloatAnalysis) matchLFS(p string) int {
+	for i := len(b.LFSPatterns) - 1; i >= 0; i-- {
+		if matchGitAttributes(b.LFSPatterns[i], p) {
+			return i
+		}
+	}
+	return -1
+}
+
+// findings lists the LFS problems and candidates as code smells
+func (b *BloatAnalysis) findings(opts BloatOptions) []CodeSmell {
+	findings := []CodeSmell{}
+	for _, f := range b.LFSNotHonoured {
+		findings = append(findings, CodeSmell{
+			Type:        "LFS Pattern Not Honoured",
+			Severity:    "Medium",
+			Description: "File matches an LFS pattern in .gitattributes but was committed as a regular blob (" + FormatSize(f.Size) + ")",
+			Location:    f.Path,
+		})
+	}
+	for _, f := range b.LFSCandidates {
+		severity := "Low"
+		if f.Size >= 50<<20 {
+			severity = "High" // GitHub warns at 50 MB and rejects pushes over 100 MB
+		} else if opts.AnyLFSThreshold > 0 && f.Size >= opts.AnyLFSThreshold {
+			severity = "Medium"
+		}
+		kind := "Large file"
+		if f.Category != "" {
+			kind = "Large " + f.Category + " file"
+		}
+		findings = append(findings, CodeSmell{
+			Type:        "Git LFS Candidate",
+			Severity:    severity,
+			Description: kind + " (" + FormatSize(f.Size) + ") is stored in git history; consider Git LFS",
+			Location:    f.Path,
+		})
+	}
+	return findings
+}
+
+// ParseLFSPatterns returns the patterns a .gitattributes file routes through
+// the LFS filter. Patterns later unset with -filter or !filter are dropped.
+func ParseLFSPatterns(attrPath string, content []byte) []LFSPattern {
+	var patterns []LFSPattern
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || strings.HasPrefix(fields[0], "#") || strings.HasPrefix(fields[0], "[attr]") {
+			continue
+		}
+		pattern := fields[0]
+		for _, attr := range fields[1:] {
+			switch attr {
+			case "filter=lfs":
+				patterns = append(patterns, LFSPattern{Pattern: pattern, Source: attrPath})
+			case "-filter", "!filter":
+				patterns = removeLFSPattern(patterns, pattern)
+			}
+		}
+	}
+	return patterns
+}
+
+func removeLFSPattern(patterns []LFSPattern, pattern string) []LFSPattern {
+	kept := patterns[:0]
+	for _, p := range patterns {
+		if p.Pattern != pattern {
+			kept = append(kept, p)
+		}
+	}
+	return kept
+}
+
+// matchGitAttributes applies gitattributes matching rules: a pattern without
+// a slash matches the file name at any depth below the .gitattributes file,
+// otherwise it is anchored to that file's directory.
+func matchGitAttributes(lfs LFSPattern, p string) bool {
+	base := path.Dir(lfs.Source)
+	if base != "." {
+		if !strings.HasPrefix(p, base+"/") {
+			return false
+		}
+		p = strings.TrimPrefix(p, base+"/")
+	}
+
+	pattern := strings.TrimPrefix(lfs.Pattern, "/")
+	if !strings.Contains(lfs.Pattern, "/") {
+		ok, _ := path.Match(pattern, baseName(p))
+		return ok
+	}
+	if strings.HasSuffix(pattern, "/**") {
+		return strings.HasPrefix(p, strings.TrimSuffix(pattern, "**"))
+	}
+	if strings.HasPrefix(pattern, "**/") {
+		return matchPathGlob(pattern, p)
+	}
+	if i := strings.Index(pattern, "/**/"); i >= 0 {
+		prefix, suffix := pattern[:i+1], pattern[i+4:]
+		if !strings.HasPrefix(p, prefix) {
+			return false
+		}
+		return matchPathGlob("**/"+suffix, strings.TrimPrefix(p, prefix))
+	}
+	ok, _ := path.Match(pattern, p)
+	return ok
+}
+
+// FormatSize renders a byte count with a binary unit suffix
+func FormatSize(n int) string {
+	switch {
+	case n >= 1<<30:
+		return fmt.Sprintf("%.1f GB", float64(n)/(1<<30))
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(n)/(1<<10))
+	}
+	return fmt.Sprintf("%d B", n)
+}
+
+func sortBySize(files []BloatFile) {
+	sort.SliceStable(files, func(i, j int) bool {
+		if files[i].Size != files[j].Size {
+			return files[i].Size > files[j].Size
+		}
+		return files[i].Path < files[j].Path
+	})
+}
@@ -0,0 +1,116 @@
+package analyzer
+
+import (
+	"testing"
+
+	"github.com/agnivo988/Repo-lyzer/internal/github"
+)
+
+func TestParseLFSPatterns(t *testing.T) {
+	content := []byte(`# Track large assets
+*.psd filter=lfs diff=lfs merge=lfs -text
+models/** filter=lfs diff=lfs merge=lfs -text
+*.md text
+*.zip filter=lfs diff=lfs merge=lfs -text
+*.zip -filter
+[attr]binary -diff -merge -text
+`)
+	patterns := ParseLFSPatterns(".gitattributes", content)
+	if len(patterns) != 2 || patterns[0].Pattern != "*.psd" || patterns[1].Pattern != "models/**" {
+		t.Errorf("patterns = %+v", patterns)
+	}
+}
+
+func TestMatchGitAttributes(t *testing.T) {
+	testCases := []struct {
+		pattern, source, path string
+		want                  bool
+	}{
+		{"*.psd", ".gitattributes", "art/deep/cover.psd", true},
+		{"*.psd", ".gitattributes", "cover.png", false},
+		{"models/**", ".gitattributes", "models/v1/weights.bin", true},
+		{"models/**", ".gitattributes", "src/models/weights.bin", false},
+		{"/data/*.csv", ".gitattributes", "data/a.csv", true},
+		{"/data/*.csv", ".gitattributes", "data/sub/a.csv", false},
+		{"assets/**/*.png", ".gitattributes", "assets/icons/x/logo.png", true},
+		{"*.bin", "game/.gitattributes", "game/levels/1.bin", true},
+		{"*.bin", "game/.gitattributes", "tools/1.bin", false},
+	}
+	for _, tc := range testCases {
+		lfs := LFSPattern{Pattern: tc.pattern, Source: tc.source}
+		if got := matchGitAttributes(lfs, tc.path); got != tc.want {
+			t.Errorf("%s (%s) vs %s = %v, want %v", tc.pattern, tc.source, tc.path, got, tc.want)
+		}
+	}
+}
+
+func TestBuildBloatReport(t *testing.T) {
+	tree := []github.TreeEntry{
+		{Path: "README.md", Type: "blob", Size: 2000},
+		{Path: "src", Type: "tree"},
+		{Path: "src/main.go", Type: "blob", Size: 5000},
+		{Path: "assets/cover.psd", Type: "blob", Size: 131},                // LFS pointer
+		{Path: "assets/banner.psd", Type: "blob", Size: 8 << 20},           // committed despite LFS
+		{Path: "assets/logo.png", Type: "blob", Size: 40 << 10},            // small media
+		{Path: "dist/app.tar.gz", Type: "blob", Size: 3 << 20},             // archive over threshold
+		{Path: "models/weights.safetensors", Type: "blob", Size: 60 << 20}, // huge model
+		{Path: "data/dump.sql", Type: "blob", Size: 12 << 20},              // large text file
+		{Path: ".gitattributes", Type: "blob", Size: 60},
+	}
+	attributes := map[string][]byte{
+		".gitattributes": []byte("*.psd filter=lfs diff=lfs merge=lfs -text\n"),
+	}
+
+	b := BuildBloatReport(tree, attributes, BloatOptions{TopN: 3, BinaryLFSThreshold: 1 << 20, AnyLFSThreshold: 10 << 20})
+
+	if b.TotalFiles != 9 {
+		t.Errorf("TotalFiles = %d, want 9", b.TotalFiles)
+	}
+	if len(b.LargestFiles) != 3 || b.LargestFiles[0].Path != "models/weights.safetensors" {
+		t.Errorf("LargestFiles = %+v", b.LargestFiles)
+	}
+	if b.Directories[0].Path != "models" || b.Directories[len(b.Directories)-1].Path != "." {
+		t.Errorf("Directories = %+v", b.Directories)
+	}
+	if b.LFSTracked != 1 || len(b.LFSNotHonoured) != 1 || b.LFSNotHonoured[0].Path != "assets/banner.psd" {
+		t.Errorf("LFS tracked=%d not honoured=%+v", b.LFSTracked, b.LFSNotHonoured)
+	}
+	if b.LFSPatterns[0].Matched != 2 {
+		t.Errorf("pattern matches = %d, want 2", b.LFSPatterns[0].Matched)
+	}
+	if b.BinariesByType["media"] != 2 || b.BinariesByType["archive"] != 1 || b.BinariesByType["model"] != 1 {
+		t.Errorf("BinariesByType = %v", b.BinariesByType)
+	}
+
+	candidates := map[string]bool{}
+	for _, c := range b.LFSCandidates {
+		candidates[c.Path] = true
+	}
+	if len(candidates) != 3 || !candidates["dist/app.tar.gz"] || !candidates["models/weights.safetensors"] || !candidates["data/dump.sql"] {
+		t.Errorf("LFSCandidates = %+v", b.LFSCandidates)
+	}
+
+	if !hasFinding(b.Findings, "LFS Pattern Not Honoured") {
+		t.Errorf("missing LFS finding: %+v", b.Findings)
+	}
+	for _, f := range b.Findings {
+		if f.Location == "models/weights.safetensors" && f.Severity != "High" {
+			t.Errorf("files over 50 MB should be High severity, got %s", f.Severity)
+		}
+	}
+}
+
+func TestBinaryCategory(t *testing.T) {
+	for p, want := range map[string]string{
+		"release/app.tar.gz":   "archive",
+		"bin/tool.EXE":         "executable",
+		"docs/img/Diagram.PNG": "media",
+		"model.onnx":           "model",
+		"app.db":               "database",
+		"main.go":              "",
+	} {
+		if got := BinaryCategory(p); got != want {
+			t.Errorf("BinaryCategory(%q) = %q, want %q", p, got, want)
+		}
+	}
+}
@@ -3,6 +3,7 @@
 package analyzer
 
 import (
+	"fmt"
 	"path/filepath"
 	"strings"
 
@@ -32,6 +33,7 @@ type CodeQualityMetrics struct {
 	FileStats         FileStatistics         `json:"file_stats"`
 	CodeSmells        []CodeSmell            `json:"code_smells"`
 	Recommendations   []string               `json:"recommendations"`
+	Go                *GoAnalysis            `json:"go,omitempty"` // set for Go repositories
 }
 
 // FileStatistics contains file-related metrics
@@ -45,6 +47,7 @@ type FileStatistics struct {
 	AvgPathDepth     float64        `json:"avg_path_depth"`    // average directory depth
 	FilesByExtension map[string]int `json:"files_by_extension"`
 	LargestFiles     []string       `json:"largest_files"`     // files with deep paths (potential complexity)
+	ExcludedFiles    int            `json:"excluded_files"`    // vendored and generated files left out above
 }
 
 // CodeSmell represents a potential code quality issue
@@ -57,6 +60,19 @@ type CodeSmell struct {
 
 // AnalyzeCodeQuality performs comprehensive code quality analysis
 func AnalyzeCodeQuality(repo *github.Repo, fileTree []github.TreeEntry, languages map[string]int) *CodeQualityMetrics {
+	return AnalyzeCodeQualityWith(repo, fileTree, languages, QualityInputs{})
+}
+
+// QualityInputs carries optional content-based analyses that refine the
+// path heuristics used by AnalyzeCodeQuality
+type QualityInputs struct {
+	Go    *GoAnalysis         // parsed Go source; refines the testing score and adds Go code smells
+	Files *FileClassification // vendored and generated files; classified from paths when nil
+}
+
+// AnalyzeCodeQualityWith performs code quality analysis using the given
+// inputs. Vendored and generated files are left out of every statistic.
+func AnalyzeCodeQualityWith(repo *github.Repo, fileTree []github.TreeEntry, languages map[string]int, in QualityInputs) *CodeQualityMetrics {
 	metrics := &CodeQualityMetrics{
 		FileStats: FileStatistics{
 			FilesByExtension: make(map[string]int),
@@ -64,7 +80,15 @@ func AnalyzeCodeQuality(repo *github.Repo, fileTree []github.TreeEntry, language
 		TestFrameworks: []string{},
 		CIProviders:    []string{},
 		CodeSmells:     []CodeSmell{},
+		Go:             in.Go,
+	}
+
+	files := in.Files
+	if files == nil {
+		files = ClassifyFiles(fileTree)
 	}
+	metrics.FileStats.ExcludedFiles = files.VendoredFiles + len(files.GeneratedFiles)
+	fileTree = files.FilterTree(fileTree)
 
 	if len(fileTree) == 0 {
 		metrics.Grade = "N/A"
@@ -86,6 +110,8 @@ func AnalyzeCodeQuality(repo *github.Repo, fileTree []github.TreeEntry, language
 
 	// Detect code smells
 	detectCodeSmells(metrics, fileTree, repo)
+	detectGoSmells(metrics)
+	detectDuplicateSmells(metrics, files)
 
 	// Calculate scores
 	calculateScores(metrics, repo)
@@ -438,6 +464,82 @@ func detectCodeSmells(metrics *CodeQualityMetrics, fileTree []github.TreeEntry,
 	}
 }
 
+// detectGoSmells adds code smells found by parsing Go source
+func detectGoSmells(metrics *CodeQualityMetrics) {
+	g := metrics.Go
+	if g == nil || g.FilesParsed == 0 {
+		return
+	}
+
+	if g.TestFuncs > 0 {
+		metrics.HasTests = true
+	}
+
+	for _, fn := range g.MostComplex {
+		if fn.Complexity <= 15 {
+			break
+		}
+		severity := "Medium"
+		if fn.Complexity > 30 {
+			severity = "High"
+		}
+		metrics.CodeSmells = append(metrics.CodeSmells, CodeSmell{
+			Type:        "High Cyclomatic Complexity",
+			Severity:    severity,
+			Description: fmt.Sprintf("%s has cyclomatic complexity %d", fn.Name, fn.Complexity),
+			Location:    fn.Location,
+		})
+	}
+
+	if len(g.PackagesWithoutDoc) > 0 {
+		metrics.CodeSmells = append(metrics.CodeSmells, CodeSmell{
+			Type:        "Undocumented Packages",
+			Severity:    "Low",
+			Description: fmt.Sprintf("%d of %d Go packages have no package doc comment", len(g.PackagesWithoutDoc), g.Packages),
+		})
+	}
+
+	if len(g.UnsafeFiles) > 0 {
+		metrics.CodeSmells = append(metrics.CodeSmells, CodeSmell{
+			Type:        "Unsafe Usage",
+			Severity:    "Medium",
+			Description: fmt.Sprintf("%d Go files import unsafe", len(g.UnsafeFiles)),
+			Location:    g.UnsafeFiles[0],
+		})
+	}
+
+	if len(g.CgoFiles) > 0 {
+		metrics.CodeSmells = append(metrics.CodeSmells, CodeSmell{
+			Type:        "Cgo Usage",
+			Severity:    "Low",
+			Description: fmt.Sprintf("%d Go files use cgo, which complicates cross-compilation", len(g.CgoFiles)),
+			Location:    g.CgoFiles[0],
+		})
+	}
+
+	if len(g.InitFuncs) > 3 {
+		metrics.CodeSmells = append(metrics.CodeSmells, CodeSmell{
+			Type:        "Heavy init() Usage",
+			Severity:    "Low",
+			Description: fmt.Sprintf("%d init() functions run implicit code at import time", len(g.InitFuncs)),
+			Location:    g.InitFuncs[0],
+		})
+	}
+}
+
+// detectDuplicateSmells reports files committed several times over
+func detectDuplicateSmells(metrics *CodeQualityMetrics, files *FileClassification) {
+	if len(files.DuplicateGroups) == 0 || files.WastedBytes < 100*1024 {
+		return
+	}
+	metrics.CodeSmells = append(metrics.CodeSmells, CodeSmell{
+		Type:        "Duplicate Files",
+		Severity:    "Low",
+		Description: fmt.Sprintf("%d files in %d groups are identical copies, wasting %d KB", files.DuplicateFiles, len(files.DuplicateGroups), files.WastedBytes/1024),
+		Location:    files.DuplicateGroups[0].Paths[0],
+	})
+}
+
 func calculateScores(metrics *CodeQualityMetrics, repo *github.Repo) {
 	// Documentation Score (0-100)
 	docScore := 0
@@ -466,11 +568,16 @@ func calculateScores(metrics *CodeQualityMetrics, repo *github.Repo) {
 	if len(metrics.TestFrameworks) > 0 {
 		testScore += 20
 	}
-	if metrics.FileStats.TestRatio >= 0.5 {
+	testRatio := metrics.FileStats.TestRatio
+	if metrics.Go != nil && metrics.Go.ExportedFuncs > 0 {
+		// Test functions per exported function is a better signal than file names
+		testRatio = metrics.Go.TestToExportedRatio()
+	}
+	if testRatio >= 0.5 {
 		testScore += 30
-	} else if metrics.FileStats.TestRatio >= 0.2 {
+	} else if testRatio >= 0.2 {
 		testScore += 20
-	} else if metrics.FileStats.TestRatio >= 0.1 {
+	} else if testRatio >= 0.1 {
 		testScore += 10
 	}
 	if metrics.HasCI {
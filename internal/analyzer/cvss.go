@@ -0,0 +1,457 @@
+// Package analyzer provides analysis functions for GitHub repositories.
+// This file computes CVSS base scores from vector strings.
+package analyzer
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// CVSSScore computes the score of a CVSS vector. It accepts version 2
+// vectors ("AV:N/AC:L/Au:N/C:P/I:P/A:P", optionally prefixed with
+// "CVSS:2.0/"), 3.0 and 3.1 vectors ("CVSS:3.1/AV:N/...") and 4.0 vectors
+// ("CVSS:4.0/AV:N/..."), and returns the score with the CVSS version.
+func CVSSScore(vector string) (float64, string, error) {
+	vector = strings.Trim(strings.TrimSpace(vector), "()")
+	prefix, rest, _ := strings.Cut(vector, "/")
+	switch prefix {
+	case "CVSS:4.0":
+		metrics, err := parseCVSSMetrics(rest)
+		if err != nil {
+			return 0, "", err
+		}
+		score, err := cvss4Score(metrics)
+		return score, "4.0", err
+	case "CVSS:3.0", "CVSS:3.1":
+		metrics, err := parseCVSSMetrics(rest)
+		if err != nil {
+			return 0, "", err
+		}
+		score, err := cvss3Score(metrics)
+		return score, strings.TrimPrefix(prefix, "CVSS:"), err
+	case "CVSS:2.0":
+		vector = rest
+	}
+	if strings.HasPrefix(vector, "CVSS:") {
+		return 0, "", fmt.Errorf("unsupported CVSS version in %q", vector)
+	}
+	metrics, err := parseCVSSMetrics(vector)
+	if err != nil {
+		return 0, "", err
+	}
+	score, err := cvss2Score(metrics)
+	return score, "2.0", err
+}
+
+// CVSSSeverity returns the qualitative rating of a CVSS v3/v4 score
+func CVSSSeverity(score float64) string {
+	switch {
+	case score >= 9.0:
+		return "CRITICAL"
+	case score >= 7.0:
+		return "HIGH"
+	case score >= 4.0:
+		return "MEDIUM"
+	case score > 0:
+		return "LOW"
+	}
+	return "NONE"
+}
+
+// cvss2Severity returns the rating of a CVSS v2 score, which has no
+// critical band
+func cvss2Severity(score float64) string {
+	switch {
+	case score >= 7.0:
+		return "HIGH"
+	case score >= 4.0:
+		return "MEDIUM"
+	}
+	return "LOW"
+}
+
+// parseCVSSMetrics splits "AV:N/AC:L/..." into a metric map
+func parseCVSSMetrics(s string) (map[string]string, error) {
+	metrics := make(map[string]string)
+	for _, part := range strings.Split(s, "/") {
+		key, value, ok := strings.Cut(part, ":")
+		if !ok || key == "" || value == "" {
+			return nil, fmt.Errorf("malformed CVSS metric %q", part)
+		}
+		if _, dup := metrics[key]; dup {
+			return nil, fmt.Errorf("duplicate CVSS metric %q", key)
+		}
+		metrics[key] = value
+	}
+	return metrics, nil
+}
+
+// cvssWeights looks up the weight of each metric, failing on a missing
+// metric or an unknown value
+func cvssWeights(metrics map[string]string, weights map[string]map[string]float64, names ...string) ([]float64, error) {
+	values := make([]float64, len(names))
+	for i, name := range names {
+		w, ok := weights[name][metrics[name]]
+		if !ok {
+			return nil, fmt.Errorf("invalid or missing CVSS metric %s:%s", name, metrics[name])
+		}
+		values[i] = w
+	}
+	return values, nil
+}
+
+var cvss2Weights = map[string]map[string]float64{
+	"AV": {"L": 0.395, "A": 0.646, "N": 1.0},
+	"AC": {"H": 0.35, "M": 0.61, "L": 0.71},
+	"Au": {"M": 0.45, "S": 0.56, "N": 0.704},
+	"C":  {"N": 0, "P": 0.275, "C": 0.660},
+	"I":  {"N": 0, "P": 0.275, "C": 0.660},
+	"A":  {"N": 0, "P": 0.275, "C": 0.660},
+}
+
+// cvss2Score computes a CVSS v2 base score
+func cvss2Score(metrics map[string]string) (float64, error) {
+	w, err := cvssWeights(metrics, cvss2Weights, "AV", "AC", "Au", "C", "I", "A")
+	if err != nil {
+		return 0, err
+	}
+	impact := 10.41 * (1 - (1-w[3])*(1-w[4])*(1-w[5]))
+	exploitability := 20 * w[0] * w[1] * w[2]
+	if impact == 0 {
+		return 0, nil
+	}
+	return math.Round(((0.6*impact)+(0.4*exploitability)-1.5)*1.176*10) / 10, nil
+}
+
+var cvss3Weights = map[string]map[string]float64{
+	"AV": {"N": 0.85, "A": 0.62, "L": 0.55, "P": 0.2},
+	"AC": {"L": 0.77, "H": 0.44},
+	"PR": {"N": 0.85, "L": 0.62, "H": 0.27},
+	"UI": {"N": 0.85, "R": 0.62},
+	"S":  {"U": 0, "C": 1},
+	"C":  {"H": 0.56, "L": 0.22, "N": 0},
+	"I":  {"H": 0.56, "L": 0.22, "N": 0},
+	"A":  {"H": 0.56, "L": 0.22, "N": 0},
+}
+
+// cvss3Score computes a CVSS v3.x base score
+func cvss3Score(metrics map[string]string) (float64, error) {
+	w, err := cvssWeights(metrics, cvss3Weights, "AV", "AC", "PR", "UI", "S", "C", "I", "A")
+	if err != nil {
+		return 0, err
+	}
+	changed := metrics["S"] == "C"
+	pr := w[2]
+	if changed {
+		// Privileges count for more when the scope changes
+		switch metrics["PR"] {
+		case "L":
+			pr = 0.68
+		case "H":
+			pr = 0.5
+		}
+	}
+
+	iss := 1 - (1-w[5])*(1-w[6])*(1-w[7])
+	impact := 6.42 * iss
+	if changed {
+		impact = 7.52*(iss-0.029) - 3.25*math.Pow(iss-0.02, 15)
+	}
+	if impact <= 0 {
+		return 0, nil
+	}
+	exploitability := 8.22 * w[0] * w[1] * pr * w[3]
+	if changed {
+		return cvssRoundUp(math.Min(1.08*(impact+exploitability), 10)), nil
+	}
+	return cvssRoundUp(math.Min(impact+exploitability, 10)), nil
+}
+
+// cvssRoundUp rounds up to one decimal as specified in CVSS v3.1, avoiding
+// floating point artifacts such as 4.000000000000001 rounding to 4.1
+func cvssRoundUp(x float64) float64 {
+	n := math.Round(x * 100000)
+	if math.Mod(n, 10000) == 0 {
+		return n / 100000
+	}
+	return (math.Floor(n/10000) + 1) / 10
+}
+
+// cvss4Values lists the allowed values of the CVSS v4 base metrics
+var cvss4Values = map[string]string{
+	"AV": "NALP", "AC": "LH", "AT": "NP", "PR": "NLH", "UI": "NPA",
+	"VC": "HLN", "VI": "HLN", "VA": "HLN", "SC": "HLN", "SI": "HLN", "SA": "HLN",
+}
+
+// cvss4Levels are the severity distances of each metric value used to
+// interpolate between macro vectors
+var cvss4Levels = map[string]map[string]float64{
+	"AV": {"N": 0.0, "A": 0.1, "L": 0.2, "P": 0.3},
+	"PR": {"N": 0.0, "L": 0.1, "H": 0.2},
+	"UI": {"N": 0.0, "P": 0.1, "A": 0.2},
+	"AC": {"L": 0.0, "H": 0.1},
+	"AT": {"N": 0.0, "P": 0.1},
+	"VC": {"H": 0.0, "L": 0.1, "N": 0.2},
+	"VI": {"H": 0.0, "L": 0.1, "N": 0.2},
+	"VA": {"H": 0.0, "L": 0.1, "N": 0.2},
+	"SC": {"H": 0.1, "L": 0.2, "N": 0.3},
+	"SI": {"S": 0.0, "H": 0.1, "L": 0.2, "N": 0.3},
+	"SA": {"S": 0.0, "H": 0.1, "L": 0.2, "N": 0.3},
+	"CR": {"H": 0.0, "M": 0.1, "L": 0.2},
+	"IR": {"H": 0.0, "M": 0.1, "L": 0.2},
+	"AR": {"H": 0.0, "M": 0.1, "L": 0.2},
+}
+
+// cvss4MaxVectors are the highest-severity vectors of each equivalence
+// class, per EQ level; EQ3 and EQ6 are combined
+var cvss4MaxVectors = struct {
+	eq1, eq2, eq4 map[int][]string
+	eq3eq6        map[[2]int][]string
+}{
+	eq1: map[int][]string{
+		0: {"AV:N/PR:N/UI:N"},
+		1: {"AV:A/PR:N/UI:N", "AV:N/PR:L/UI:N", "AV:N/PR:N/UI:P"},
+		2: {"AV:P/PR:N/UI:N", "AV:A/PR:L/UI:P"},
+	},
+	eq2: map[int][]string{
+		0: {"AC:L/AT:N"},
+		1: {"AC:H/AT:N", "AC:L/AT:P"},
+	},
+	eq4: map[int][]string{
+		0: {"SC:H/SI:S/SA:S"},
+		1: {"SC:H/SI:H/SA:H"},
+		2: {"SC:L/SI:L/SA:L"},
+	},
+	eq3eq6: map[[2]int][]string{
+		{0, 0}: {"VC:H/VI:H/VA:H/CR:H/IR:H/AR:H"},
+		{0, 1}: {"VC:H/VI:H/VA:L/CR:M/IR:M/AR:H", "VC:H/VI:H/VA:H/CR:M/IR:M/AR:M"},
+		{1, 0}: {"VC:L/VI:H/VA:H/CR:H/IR:H/AR:H", "VC:H/VI:L/VA:H/CR:H/IR:H/AR:H"},
+		{1, 1}: {"VC:L/VI:H/VA:L/CR:H/IR:M/AR:H", "VC:L/VI:H/VA:H/CR:H/IR:M/AR:M", "VC:H/VI:L/VA:H/CR:M/IR:H/AR:M", "VC:H/VI:L/VA:L/CR:M/IR:H/AR:H", "VC:L/VI:L/VA:H/CR:H/IR:H/AR:M"},
+		{2, 1}: {"VC:L/VI:L/VA:L/CR:H/IR:H/AR:H"},
+	},
+}
+
+// cvss4MaxSeverity is the depth of each equivalence class, in 0.1 steps
+var cvss4MaxSeverity = struct {
+	eq1, eq2, eq4 map[int]float64
+	eq3eq6        map[[2]int]float64
+}{
+	eq1:    map[int]float64{0: 1, 1: 4, 2: 5},
+	eq2:    map[int]float64{0: 1, 1: 2},
+	eq4:    map[int]float64{0: 6, 1: 5, 2: 4},
+	eq3eq6: map[[2]int]float64{{0, 0}: 7, {0, 1}: 6, {1, 0}: 8, {1, 1}: 8, {2, 1}: 10},
+}
+
+// cvss4Score computes a CVSS v4.0 score using the macro vector lookup and
+// interpolation of the specification. Threat and environmental metrics are
+// honoured when present; supplemental metrics do not affect the score.
+func cvss4Score(metrics map[string]string) (float64, error) {
+	for name, values := range cvss4Values {
+		v := metrics[name]
+		if len(v) != 1 || !strings.Contains(values, v) {
+			return 0, fmt.Errorf("invalid or missing CVSS metric %s:%s", name, v)
+		}
+	}
+
+	// Effective values: modified metrics override base ones, and unset
+	// threat and requirement metrics assume the worst case
+	m := func(name string) string {
+		if v := metrics["M"+name]; v != "" && v != "X" {
+			return v
+		}
+		if v := metrics[name]; v != "" && v != "X" {
+			return v
+		}
+		switch name {
+		case "E":
+			return "A"
+		case "CR", "IR", "AR":
+			return "H"
+		}
+		return ""
+	}
+
+	if m("VC") == "N" && m("VI") == "N" && m("VA") == "N" && m("SC") == "N" && m("SI") == "N" && m("SA") == "N" {
+		return 0, nil
+	}
+
+	var eq [6]int
+	switch {
+	case m("AV") == "N" && m("PR") == "N" && m("UI") == "N":
+		eq[0] = 0
+	case (m("AV") == "N" || m("PR") == "N" || m("UI") == "N") && m("AV") != "P":
+		eq[0] = 1
+	default:
+		eq[0] = 2
+	}
+	if !(m("AC") == "L" && m("AT") == "N") {
+		eq[1] = 1
+	}
+	switch {
+	case m("VC") == "H" && m("VI") == "H":
+		eq[2] = 0
+	case m("VC") == "H" || m("VI") == "H" || m("VA") == "H":
+		eq[2] = 1
+	default:
+		eq[2] = 2
+	}
+	switch {
+	case m("SI") == "S" || m("SA") == "S":
+		eq[3] = 0
+	case m("SC") == "H" || m("SI") == "H" || m("SA") == "H":
+		eq[3] = 1
+	default:
+		eq[3] = 2
+	}
+	switch m("E") {
+	case "P":
+		eq[4] = 1
+	case "U":
+		eq[4] = 2
+	}
+	if !((m("CR") == "H" && m("VC") == "H") || (m("IR") == "H" && m("VI") == "H") || (m("AR") == "H" && m("VA") == "H")) {
+		eq[5] = 1
+	}
+
+	lookup := func(e [6]int) (float64, bool) {
+		key := fmt.Sprintf("%d%d%d%d%d%d", e[0], e[1], e[2], e[3], e[4], e[5])
+		v, ok := cvss4Lookup[key]
+		return v, ok
+	}
+	value, ok := lookup(eq)
+	if !ok {
+		return 0, fmt.Errorf("no CVSS v4 score for macro vector %v", eq)
+	}
+
+	// Scores of the next lower macro vector in each equivalence class
+	lower := func(i int) (float64, bool) {
+		e := eq
+		e[i]++
+		return lookup(e)
+	}
+	var lowerEQ3EQ6 float64
+	var hasLowerEQ3EQ6 bool
+	if (eq[2] == 0 || eq[2] == 1) && eq[5] == 0 {
+		// Two neighbours; the higher one is the next step down
+		left, okLeft := lower(5)
+		right, okRight := lower(2)
+		switch {
+		case okLeft && okRight:
+			lowerEQ3EQ6, hasLowerEQ3EQ6 = math.Max(left, right), true
+		case okLeft:
+			lowerEQ3EQ6, hasLowerEQ3EQ6 = left, true
+		case okRight:
+			lowerEQ3EQ6, hasLowerEQ3EQ6 = right, true
+		}
+	} else {
+		lowerEQ3EQ6, hasLowerEQ3EQ6 = lower(2)
+	}
+
+	// Distance of the vector from the most severe vector of its class
+	distance := func(maxVectors []string, names ...string) (float64, bool) {
+		for _, maxVector := range maxVectors {
+			maxMetrics, _ := parseCVSSMetrics(maxVector)
+			total, valid := 0.0, true
+			for _, name := range names {
+				d := cvss4Levels[name][m(name)] - cvss4Levels[name][maxMetrics[name]]
+				if d < 0 {
+					valid = false
+					break
+				}
+				total += d
+			}
+			if valid {
+				return total, true
+			}
+		}
+		return 0, false
+	}
+	dist1, ok1 := distance(cvss4MaxVectors.eq1[eq[0]], "AV", "PR", "UI")
+	dist2, ok2 := distance(cvss4MaxVectors.eq2[eq[1]], "AC", "AT")
+	dist36, ok36 := distance(cvss4MaxVectors.eq3eq6[[2]int{eq[2], eq[5]}], "VC", "VI", "VA", "CR", "IR", "AR")
+	dist4, ok4 := distance(cvss4MaxVectors.eq4[eq[3]], "SC", "SI", "SA")
+	if !ok1 || !ok2 || !ok36 || !ok4 {
+		return 0, fmt.Errorf("no CVSS v4 max vector for macro vector %v", eq)
+	}
+
+	const step = 0.1
+	var total float64
+	var classes int
+	add := func(lowerScore float64, hasLower bool, dist, maxSeverity float64) {
+		if !hasLower {
+			return
+		}
+		classes++
+		total += (value - lowerScore) * dist / (maxSeverity * step)
+	}
+	l1, has1 := lower(0)
+	add(l1, has1, dist1, cvss4MaxSeverity.eq1[eq[0]])
+	l2, has2 := lower(1)
+	add(l2, has2, dist2, cvss4MaxSeverity.eq2[eq[1]])
+	add(lowerEQ3EQ6, hasLowerEQ3EQ6, dist36, cvss4MaxSeverity.eq3eq6[[2]int{eq[2], eq[5]}])
+	l4, has4 := lower(3)
+	add(l4, has4, dist4, cvss4MaxSeverity.eq4[eq[3]])
+	// EQ5 has a single vector per level, so it only counts as a class
+	l5, has5 := lower(4)
+	add(l5, has5, 0, 1)
+
+	if classes > 0 {
+		value -= total / float64(classes)
+	}
+	value = math.Max(0, math.Min(10, value))
+	return math.Round(value*10) / 10, nil
+}
+
+// cvss4Lookup maps macro vectors (EQ1..EQ6) to scores, from the CVSS v4.0
+// specification
+var cvss4Lookup = map[string]float64{
+	"000000": 10, "000001": 9.9, "000010": 9.8, "000011": 9.5, "000020": 9.5, "000021": 9.2,
+	"000100": 10, "000101": 9.6, "000110": 9.3, "000111": 8.7, "000120": 9.1, "000121": 8.1,
+	"000200": 9.3, "000201": 9, "000210": 8.9, "000211": 8, "000220": 8.1, "000221": 6.8,
+	"001000": 9.8, "001001": 9.5, "001010": 9.5, "001011": 9.2, "001020": 9, "001021": 8.4,
+	"001100": 9.3, "001101": 9.2, "001110": 8.9, "001111": 8.1, "001120": 8.1, "001121": 6.5,
+	"001200": 8.8, "001201": 8, "001210": 7.8, "001211": 7, "001220": 6.9, "001221": 4.8,
+	"002001": 9.2, "002011": 8.2, "002021": 7.2, "002101": 7.9, "002111": 6.9, "002121": 5,
+	"002201": 6.9, "002211": 5.5, "002221": 2.7,
+	"010000": 9.9, "010001": 9.7, "010010": 9.5, "010011": 9.2, "010020": 9.2, "010021": 8.5,
+	"010100": 9.5, "010101": 9.1, "010110": 9, "010111": 8.3, "010120": 8.4, "010121": 7.1,
+	"010200": 9.2, "010201": 8.1, "010210": 8.2, "010211": 7.1, "010220": 7.2, "010221": 5.3,
+	"011000": 9.5, "011001": 9.3, "011010": 9.2, "011011": 8.5, "011020": 8.5, "011021": 7.3,
+	"011100": 9.2, "011101": 8.2, "011110": 8, "011111": 7.2, "011120": 7, "011121": 5.9,
+	"011200": 8.4, "011201": 7, "011210": 7.1, "011211": 5.2, "011220": 5, "011221": 3,
+	"012001": 8.6, "012011": 7.5, "012021": 5.2, "012101": 7.1, "012111": 5.2, "012121": 2.9,
+	"012201": 6.3, "012211": 2.9, "012221": 1.7,
+	"100000": 9.8, "100001": 9.5, "100010": 9.4, "100011": 8.7, "100020": 9.1, "100021": 8.1,
+	"100100": 9.4, "100101": 8.9, "100110": 8.6, "100111": 7.4, "100120": 7.7, "100121": 6.4,
+	"100200": 8.7, "100201": 7.5, "100210": 7.4, "100211": 6.3, "100220": 6.3, "100221": 4.9,
+	"101000": 9.4, "101001": 8.9, "101010": 8.8, "101011": 7.7, "101020": 7.6, "101021": 6.7,
+	"101100": 8.6, "101101": 7.6, "101110": 7.4, "101111": 5.8, "101120": 5.9, "101121": 5,
+	"101200": 7.2, "101201": 5.7, "101210": 5.7, "101211": 5.2, "101220": 5.2, "101221": 2.5,
+	"102001": 8.3, "102011": 7, "102021": 5.4, "102101": 6.5, "102111": 5.8, "102121": 2.6,
+	"102201": 5.3, "102211": 2.1, "102221": 1.3,
+	"110000": 9.5, "110001": 9, "110010": 8.8, "110011": 7.6, "110020": 7.6, "110021": 7,
+	"110100": 9, "110101": 7.7, "110110": 7.5, "110111": 6.2, "110120": 6.1, "110121": 5.3,
+	"110200": 7.7, "110201": 6.6, "110210": 6.8, "110211": 5.9, "110220": 5.2, "110221": 3,
+	"111000": 8.9, "111001": 7.8, "111010": 7.6, "111011": 6.7, "111020": 6.2, "111021": 5.8,
+	"111100": 7.4, "111101": 5.9, "111110": 5.7, "111111": 5.7, "111120": 4.7, "111121": 2.3,
+	"111200": 6.1, "111201": 5.2, "111210": 5.7, "111211": 2.9, "111220": 2.4, "111221": 1.6,
+	"112001": 7.1, "112011": 5.9, "112021": 3, "112101": 5.8, "112111": 2.6, "112121": 1.5,
+	"112201": 2.3, "112211": 1.3, "112221": 0.6,
+	"200000": 9.3, "200001": 8.7, "200010": 8.6, "200011": 7.2, "200020": 7.5, "200021": 5.8,
+	"200100": 8.6, "200101": 7.4, "200110": 7.4, "200111": 6.1, "200120": 5.6, "200121": 3.4,
+	"200200": 7, "200201": 5.4, "200210": 5.2, "200211": 4, "200220": 4, "200221": 2.2,
+	"201000": 8.5, "201001": 7.5, "201010": 7.4, "201011": 5.5, "201020": 6.2, "201021": 5.1,
+	"201100": 7.2, "201101": 5.7, "201110": 5.5, "201111": 4.1, "201120": 4.6, "201121": 1.9,
+	"201200": 5.3, "201201": 3.6, "201210": 3.4, "201211": 1.9, "201220": 1.9, "201221": 0.8,
+	"202001": 6.4, "202011": 5.1, "202021": 2, "202101": 4.7, "202111": 2.1, "202121": 1.1,
+	"202201": 2.4, "202211": 0.9, "202221": 0.4,
+	"210000": 8.8, "210001": 7.5, "210010": 7.3, "210011": 5.3, "210020": 6, "210021": 5,
+	"210100": 7.3, "210101": 5.5, "210110": 5.9, "210111": 4, "210120": 4.1, "210121": 2,
+	"210200": 5.4, "210201": 4.3, "210210": 4.5, "210211": 2.2, "210220": 2, "210221": 1.1,
+	"211000": 7.5, "211001": 5.5, "211010": 5.8, "211011": 4.5, "211020": 4, "211021": 2.1,
+	"211100": 6.1, "211101": 5.1, "211110": 4.8, "211111": 1.8, "211120": 2, "211121": 0.9,
+	"211200": 4.6, "211201": 1.8, "211210": 1.7, "211211": 0.7, "211220": 0.8, "211221": 0.2,
+	"212001": 5.3, "212011": 2.4, "212021": 1.4, "212101": 2.4, "212111": 1.2, "212121": 0.5,
+	"212201": 1, "212211": 0.3, "212221": 0.1,
+}
@@ -0,0 +1,103 @@
+package analyzer
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestCVSSScore(t *testing.T) {
+	tests := []struct {
+		vector  string
+		score   float64
+		version string
+	}{
+		{"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H", 9.8, "3.1"},
+		{"CVSS:3.1/AV:N/AC:L/PR:N/UI:R/S:C/C:L/I:L/A:N", 6.1, "3.1"},
+		{"CVSS:3.0/AV:N/AC:L/PR:L/UI:N/S:C/C:H/I:H/A:H", 9.9, "3.0"},
+		{"CVSS:3.1/AV:L/AC:H/PR:H/UI:R/S:U/C:L/I:N/A:N", 1.8, "3.1"},
+		{"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:N/I:N/A:H", 7.5, "3.1"},
+		{"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:N/I:N/A:N", 0, "3.1"},
+		{"AV:N/AC:L/Au:N/C:P/I:P/A:P", 7.5, "2.0"},
+		{"(AV:N/AC:M/Au:N/C:N/I:P/A:N)", 4.3, "2.0"},
+		{"AV:N/AC:L/Au:N/C:C/I:C/A:C", 10, "2.0"},
+		{"CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:N/VC:H/VI:H/VA:H/SC:N/SI:N/SA:N", 9.3, "4.0"},
+		{"CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:N/VC:H/VI:H/VA:H/SC:H/SI:H/SA:H", 10, "4.0"},
+		{"CVSS:4.0/AV:N/AC:L/AT:N/PR:L/UI:N/VC:H/VI:H/VA:H/SC:N/SI:N/SA:N", 8.7, "4.0"},
+		{"CVSS:4.0/AV:L/AC:L/AT:N/PR:L/UI:N/VC:H/VI:H/VA:H/SC:N/SI:N/SA:N", 8.5, "4.0"},
+		{"CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:N/VC:L/VI:N/VA:N/SC:N/SI:N/SA:N", 6.9, "4.0"},
+		{"CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:N/VC:N/VI:N/VA:N/SC:N/SI:N/SA:N", 0, "4.0"},
+	}
+	for _, tt := range tests {
+		score, version, err := CVSSScore(tt.vector)
+		if err != nil || score != tt.score || version != tt.version {
+			t.Errorf("CVSSScore(%q) = %v, %q, %v; want %v, %q", tt.vector, score, version, err, tt.score, tt.version)
+		}
+	}
+
+	for _, bad := range []string{
+		"",
+		"9.8",
+		"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H",          // missing A
+		"CVSS:3.1/AV:X/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H",      // unknown value
+		"CVSS:3.1/AV:N/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H", // duplicate
+		"CVSS:5.0/AV:N",
+	} {
+		if _, _, err := CVSSScore(bad); err == nil {
+			t.Errorf("CVSSScore(%q) should fail", bad)
+		}
+	}
+}
+
+func TestGetSeverity(t *testing.T) {
+	parse := func(s string) osvVuln {
+		var o osvVuln
+		if err := json.Unmarshal([]byte(s), &o); err != nil {
+			t.Fatal(err)
+		}
+		return o
+	}
+	tests := []struct {
+		advisory string
+		severity string
+		score    float64
+	}{
+		// The newest CVSS version wins
+		{`{"severity":[{"type":"CVSS_V3","score":"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H"},
+			{"type":"CVSS_V4","score":"CVSS:4.0/AV:N/AC:L/AT:N/PR:L/UI:N/VC:H/VI:H/VA:H/SC:N/SI:N/SA:N"}]}`, "HIGH", 8.7},
+		{`{"severity":[{"type":"CVSS_V2","score":"AV:N/AC:L/Au:N/C:C/I:C/A:C"}]}`, "HIGH", 10},
+		// Per-package severities count too
+		{`{"affected":[{"severity":[{"type":"CVSS_V3","score":"CVSS:3.1/AV:L/AC:H/PR:H/UI:R/S:U/C:L/I:N/A:N"}]}]}`, "LOW", 1.8},
+		// Unparseable vectors fall back to the GitHub rating
+		{`{"severity":[{"type":"CVSS_V3","score":"garbage"}],"database_specific":{"severity":"MODERATE"}}`, "MEDIUM", 0},
+		{`{"database_specific":{"severity":"CRITICAL","cwe_ids":["CWE-79"]}}`, "CRITICAL", 0},
+		{`{"database_specific":{"severity":3}}`, "MEDIUM", 0},
+		{`{}`, "MEDIUM", 0},
+	}
+	for _, tt := range tests {
+		severity, score, _ := getSeverity(parse(tt.advisory))
+		if severity != tt.severity || score != tt.score {
+			t.Errorf("getSeverity(%s) = %s %v; want %s %v", tt.advisory, severity, score, tt.severity, tt.score)
+		}
+	}
+}
+
+func TestSecurityScoreUsesCVSS(t *testing.T) {
+	result := &SecurityScanResult{Vulnerabilities: []Vulnerability{
+		{ID: "B", Severity: "HIGH", CVSSScore: 7.0},
+		{ID: "A", Severity: "CRITICAL", CVSSScore: 9.8},
+		{ID: "C", Severity: "MEDIUM"},
+		{ID: "D", Severity: "HIGH", CVSSScore: 8.8},
+	}}
+	sortVulnerabilities(result.Vulnerabilities)
+	var order string
+	for _, v := range result.Vulnerabilities {
+		order += v.ID
+	}
+	if order != "ADBC" {
+		t.Errorf("order = %s", order)
+	}
+	// 24.01 + 19.36 + 12.25 + 5
+	if got := calcSecurityScore(result); got != 39 {
+		t.Errorf("score = %d", got)
+	}
+}
@@ -6,6 +6,7 @@ package analyzer
 import (
 	"encoding/base64"
 	"encoding/json"
+	"encoding/xml"
 	"regexp"
 	"sort"
 	"strings"
@@ -17,9 +18,15 @@ import (
 // It captures the essential information about a package dependency
 // regardless of the package manager used.
 type Dependency struct {
-	Name    string `json:"name"`    // Package name (e.g., "react", "github.com/gin-gonic/gin")
-	Version string `json:"version"` // Version constraint (e.g., "^1.0.0", "v1.9.1")
-	Type    string `json:"type"`    // Dependency type: "production", "dev", "peer", "indirect"
+	Name    string   `json:"name"`              // Package name (e.g., "react", "github.com/gin-gonic/gin")
+	Version string   `json:"version"`           // Version constraint (e.g., "^1.0.0", "v1.9.1")
+	Type    string   `json:"type"`              // Dependency type: "production", "dev", "peer", "optional", "indirect"
+	Extras  []string `json:"extras,omitempty"`  // Optional features requested (e.g., Python extras "requests[socks]")
+	Markers string   `json:"markers,omitempty"` // Environment markers limiting where it is installed (e.g., `sys_platform == "win32"`)
+
+	Resolved   string `json:"resolved,omitempty"`   // Exact version pinned by a lockfile (e.g., "18.2.0")
+	Transitive bool   `json:"transitive,omitempty"` // Pulled in by another dependency rather than declared directly
+	License    string `json:"license,omitempty"`    // SPDX license expression, when the source records one (e.g. an SBOM)
 }
 
 // DependencyFile represents all dependencies extracted from a single file.
@@ -29,16 +36,22 @@ type DependencyFile struct {
 	Filename     string       `json:"filename"`  // Full path to the file (e.g., "packages/web/package.json")
 	FileType     string       `json:"file_type"` // Package manager type: "npm", "go", "python", "rust", "ruby"
 	Dependencies []Dependency `json:"dependencies"`
-	TotalCount   int          `json:"total_count"` // Total number of dependencies in this file
+	TotalCount   int          `json:"total_count"`         // Total number of dependencies in this file
+	LockFile     string       `json:"lock_file,omitempty"` // Lockfile that pinned the versions, if any
+	// InstallScripts are the npm lifecycle scripts that run when the package
+	// is installed (preinstall, install, postinstall), by name
+	InstallScripts map[string]string `json:"install_scripts,omitempty"`
 }
 
 // DependencyAnalysis holds the complete dependency analysis for a repository.
 // It aggregates information from all dependency files found in the repo.
 type DependencyAnalysis struct {
-	Files       []DependencyFile `json:"files"`         // All parsed dependency files
-	TotalDeps   int              `json:"total_deps"`    // Total dependencies across all files
-	Languages   []string         `json:"languages"`     // Detected package managers/languages
-	HasLockFile bool             `json:"has_lock_file"` // Whether a lock file exists
+	Files          []DependencyFile `json:"files"`           // All parsed dependency files
+	TotalDeps      int              `json:"total_deps"`      // Total dependencies across all files
+	TransitiveDeps int              `json:"transitive_deps"` // How many of them are transitive
+	Languages      []string         `json:"languages"`       // Detected package managers/languages
+	HasLockFile    bool             `json:"has_lock_file"`   // Whether a lock file exists
+	LockFiles      []*LockFile      `json:"-"`               // Parsed lockfiles, including which packages require which
 }
 
 // AnalyzeDependencies fetches and parses dependency files from a repository.
@@ -51,6 +64,10 @@ type DependencyAnalysis struct {
 //   - Python (requirements.txt, Pipfile, pyproject.toml)
 //   - Rust (Cargo.toml)
 //   - Ruby (Gemfile)
+//   - Maven (pom.xml) and Gradle (build.gradle, build.gradle.kts)
+//   - NuGet (*.csproj, *.fsproj, *.vbproj, packages.config)
+//   - Composer (composer.json)
+//   - Pub (pubspec.yaml)
 //
 // Parameters:
 //   - client: GitHub API client for fetching file contents
@@ -71,6 +88,10 @@ func AnalyzeDependencies(client *github.Client, owner, repo, branch string, file
 	// Find all dependency files in the repository tree
 	depFiles := findDependencyFiles(fileTree)
 
+	// POMs inherit versions from their parents, so they are parsed once
+	// every pom.xml in the repository has been fetched
+	poms := make(map[string][]byte)
+
 	for _, df := range depFiles {
 		// Fetch file content from GitHub API
 		content, err := client.GetFileContent(owner, repo, df.path)
@@ -86,36 +107,80 @@ func AnalyzeDependencies(client *github.Client, owner, repo, branch string, file
 
 		var deps []Dependency
 		var fileType string
+		var scripts map[string]string
 
 		// Parse based on file type
 		switch df.fileType {
 		case "npm":
 			deps, fileType = parsePackageJSON(decoded)
+			scripts = npmInstallScripts(decoded)
 		case "go":
 			deps, fileType = parseGoMod(decoded)
 		case "python":
-			deps, fileType = parseRequirementsTxt(decoded)
+			switch baseName(df.path) {
+			case "pyproject.toml":
+				deps, fileType = parsePyproject(decoded)
+			case "Pipfile":
+				deps, fileType = parsePipfile(decoded)
+			default:
+				deps, fileType = parseRequirementsTxt(decoded)
+			}
 		case "rust":
 			deps, fileType = parseCargoToml(decoded)
 		case "ruby":
 			deps, fileType = parseGemfile(decoded)
+		case "maven":
+			poms[df.path] = decoded
+			continue
+		case "gradle":
+			deps, fileType = parseGradle(decoded)
+		case "nuget":
+			if strings.HasSuffix(df.path, ".config") {
+				deps, fileType = parsePackagesConfig(decoded)
+			} else {
+				deps, fileType = parseCsproj(decoded)
+			}
+		case "composer":
+			deps, fileType = parseComposerJSON(decoded)
+		case "pub":
+			deps, fileType = parsePubspec(decoded)
+		}
+
+		if len(deps) > 0 || len(scripts) > 0 {
+			analysis.AddFiles(DependencyFile{
+				Filename:       df.path,
+				FileType:       fileType,
+				Dependencies:   deps,
+				TotalCount:     len(deps),
+				InstallScripts: scripts,
+			})
 		}
+	}
 
-		if len(deps) > 0 {
-			analysis.Files = append(analysis.Files, DependencyFile{
-				Filename:     df.path,
-				FileType:     fileType,
+	for _, pomPath := range sortedKeys(poms) {
+		if deps := resolvePOMDependencies(pomPath, poms); len(deps) > 0 {
+			analysis.AddFiles(DependencyFile{
+				Filename:     pomPath,
+				FileType:     "maven",
 				Dependencies: deps,
 				TotalCount:   len(deps),
 			})
-			analysis.TotalDeps += len(deps)
+		}
+	}
 
-			// Track unique languages/package managers
-			if !contains(analysis.Languages, fileType) {
-				analysis.Languages = append(analysis.Languages, fileType)
-			}
+	// Lockfiles pin exact versions and list transitive packages. They are
+	// often larger than the contents API serves, so they are read as blobs.
+	blobs := NewGitHubBlobSource(client, owner, repo, fileTree, nil)
+	var locks []*LockFile
+	for _, entry := range fileTree {
+		if entry.Type != "blob" || !IsLockFile(entry.Path) || isVendoredPath(strings.ToLower(entry.Path)) {
+			continue
+		}
+		if content, err := blobs.ReadFile(entry.Path); err == nil {
+			locks = append(locks, ParseLockFile(entry.Path, content))
 		}
 	}
+	analysis.ApplyLockFiles(locks...)
 
 	// Check for lock files (indicates reproducible builds)
 	analysis.HasLockFile = hasLockFile(fileTree)
@@ -123,6 +188,25 @@ func AnalyzeDependencies(client *github.Client, owner, repo, branch string, file
 	return analysis, nil
 }
 
+// AddFiles appends parsed dependency files to the analysis and updates the
+// totals and detected package managers accordingly.
+func (a *DependencyAnalysis) AddFiles(files ...DependencyFile) {
+	for _, file := range files {
+		a.Files = append(a.Files, file)
+		a.TotalDeps += len(file.Dependencies)
+		for _, dep := range file.Dependencies {
+			if dep.Transitive {
+				a.TransitiveDeps++
+			}
+		}
+
+		// Track unique languages/package managers
+		if !contains(a.Languages, file.FileType) {
+			a.Languages = append(a.Languages, file.FileType)
+		}
+	}
+}
+
 // depFileInfo holds metadata about a dependency file found in the repo.
 type depFileInfo struct {
 	path     string // Full path to the file
@@ -143,6 +227,12 @@ func findDependencyFiles(tree []github.TreeEntry) []depFileInfo {
 		"pyproject.toml":   "python",
 		"Cargo.toml":       "rust",
 		"Gemfile":          "ruby",
+		"pom.xml":          "maven",
+		"build.gradle":     "gradle",
+		"build.gradle.kts": "gradle",
+		"packages.config":  "nuget",
+		"composer.json":    "composer",
+		"pubspec.yaml":     "pub",
 	}
 
 	for _, entry := range tree {
@@ -155,7 +245,11 @@ func findDependencyFiles(tree []github.TreeEntry) []depFileInfo {
 		parts := strings.Split(entry.Path, "/")
 		filename := parts[len(parts)-1]
 
-		if fileType, ok := depFilePatterns[filename]; ok {
+		fileType, ok := depFilePatterns[filename]
+		if !ok && (strings.HasSuffix(filename, ".csproj") || strings.HasSuffix(filename, ".fsproj") || strings.HasSuffix(filename, ".vbproj")) {
+			fileType, ok = "nuget", true
+		}
+		if ok && !isVendoredPath(strings.ToLower(entry.Path)) {
 			files = append(files, depFileInfo{
 				path:     entry.Path,
 				fileType: fileType,
@@ -170,14 +264,18 @@ func findDependencyFiles(tree []github.TreeEntry) []depFileInfo {
 // Lock files indicate that the project uses reproducible dependency resolution.
 func hasLockFile(tree []github.TreeEntry) bool {
 	lockFiles := []string{
-		"package-lock.json", // npm
-		"yarn.lock",         // Yarn
-		"pnpm-lock.yaml",    // pnpm
-		"go.sum",            // Go modules
-		"Pipfile.lock",      // Pipenv
-		"poetry.lock",       // Poetry
-		"Cargo.lock",        // Cargo (Rust)
-		"Gemfile.lock",      // Bundler (Ruby)
+		"package-lock.json",  // npm
+		"yarn.lock",          // Yarn
+		"pnpm-lock.yaml",     // pnpm
+		"go.sum",             // Go modules
+		"Pipfile.lock",       // Pipenv
+		"poetry.lock",        // Poetry
+		"Cargo.lock",         // Cargo (Rust)
+		"Gemfile.lock",       // Bundler (Ruby)
+		"composer.lock",      // Composer (PHP)
+		"pubspec.lock",       // Pub (Dart)
+		"packages.lock.json", // NuGet
+		"gradle.lockfile",    // Gradle
 	}
 
 	for _, entry := range tree {
@@ -251,8 +349,30 @@ func parsePackageJSON(content []byte) ([]Dependency, string) {
 	return deps, "npm"
 }
 
+// npmInstallScripts returns the lifecycle scripts of a package.json that npm
+// runs when the package is installed
+func npmInstallScripts(content []byte) map[string]string {
+	var pkg struct {
+		Scripts map[string]string `json:"scripts"`
+	}
+	if err := json.Unmarshal(content, &pkg); err != nil {
+		return nil
+	}
+	var scripts map[string]string
+	for _, name := range []string{"preinstall", "install", "postinstall"} {
+		if script := strings.TrimSpace(pkg.Scripts[name]); script != "" {
+			if scripts == nil {
+				scripts = make(map[string]string)
+			}
+			scripts[name] = script
+		}
+	}
+	return scripts
+}
+
 // parseGoMod parses a Go go.mod file and extracts module dependencies.
-// It handles both single-line requires and require blocks.
+// Requirements marked "// indirect", in blocks or on single lines, are
+// transitive; see ParseGoMod for the other directives.
 //
 // Example go.mod structure:
 //
@@ -262,53 +382,19 @@ func parsePackageJSON(content []byte) ([]Dependency, string) {
 //	)
 func parseGoMod(content []byte) ([]Dependency, string) {
 	var deps []Dependency
-	lines := strings.Split(string(content), "\n")
-
-	inRequire := false
-
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-
-		// Start of require block
-		if strings.HasPrefix(line, "require (") {
-			inRequire = true
-			continue
-		}
-		// End of require block
-		if line == ")" {
-			inRequire = false
-			continue
-		}
-
-		// Single line require (e.g., "require github.com/pkg/errors v0.9.1")
-		if strings.HasPrefix(line, "require ") && !strings.Contains(line, "(") {
-			parts := strings.Fields(line)
-			if len(parts) >= 3 {
-				deps = append(deps, Dependency{
-					Name:    parts[1],
-					Version: parts[2],
-					Type:    "production",
-				})
-			}
-			continue
-		}
-
-		// Inside require block
-		if inRequire && line != "" && !strings.HasPrefix(line, "//") {
-			parts := strings.Fields(line)
-			if len(parts) >= 2 {
-				depType := "production"
-				// Indirect dependencies are transitive deps
-				if strings.Contains(line, "// indirect") {
-					depType = "indirect"
-				}
-				deps = append(deps, Dependency{
-					Name:    parts[0],
-					Version: parts[1],
-					Type:    depType,
-				})
-			}
+	// Malformed lines are skipped; InspectGoModules reports them
+	mod, _ := ParseGoMod(content)
+	for _, req := range mod.Requires {
+		depType := "production"
+		if req.Indirect {
+			depType = "indirect"
 		}
+		deps = append(deps, Dependency{
+			Name:       req.Path,
+			Version:    req.Version,
+			Type:       depType,
+			Transitive: req.Indirect,
+		})
 	}
 
 	return deps, "go"
@@ -463,6 +549,164 @@ func parseGemfile(content []byte) ([]Dependency, string) {
 	return deps, "ruby"
 }
 
+// parseCsproj parses PackageReference items from a .NET project file.
+// The version may be an attribute or a child element; references marked
+// PrivateAssets="all" (analyzers, build tools) are development-only.
+//
+// Example .csproj:
+//
+//	<ItemGroup>
+//	  <PackageReference Include="Newtonsoft.Json" Version="13.0.3" />
+//	  <PackageReference Include="StyleCop.Analyzers" Version="1.1.118" PrivateAssets="all" />
+//	</ItemGroup>
+func parseCsproj(content []byte) ([]Dependency, string) {
+	var project struct {
+		References []struct {
+			Include       string `xml:"Include,attr"`
+			Update        string `xml:"Update,attr"`
+			Version       string `xml:"Version,attr"`
+			VersionElem   string `xml:"Version"`
+			PrivateAssets string `xml:"PrivateAssets,attr"`
+			PrivateElem   string `xml:"PrivateAssets"`
+		} `xml:"ItemGroup>PackageReference"`
+	}
+	if err := xml.Unmarshal(content, &project); err != nil {
+		return nil, "nuget"
+	}
+
+	var deps []Dependency
+	for _, ref := range project.References {
+		name := ref.Include
+		if name == "" {
+			name = ref.Update
+		}
+		if name == "" {
+			continue
+		}
+		version := ref.Version
+		if version == "" {
+			version = strings.TrimSpace(ref.VersionElem)
+		}
+		depType := "production"
+		if strings.EqualFold(ref.PrivateAssets, "all") || strings.EqualFold(strings.TrimSpace(ref.PrivateElem), "all") {
+			depType = "dev"
+		}
+		deps = append(deps, Dependency{Name: name, Version: cleanVersion(version), Type: depType})
+	}
+
+	sort.Slice(deps, func(i, j int) bool { return deps[i].Name < deps[j].Name })
+	return deps, "nuget"
+}
+
+// parsePackagesConfig parses a legacy NuGet packages.config file.
+//
+// Example packages.config:
+//
+//	<packages>
+//	  <package id="log4net" version="2.0.15" targetFramework="net48" />
+//	</packages>
+func parsePackagesConfig(content []byte) ([]Dependency, string) {
+	var config struct {
+		Packages []struct {
+			ID          string `xml:"id,attr"`
+			Version     string `xml:"version,attr"`
+			Development string `xml:"developmentDependency,attr"`
+		} `xml:"package"`
+	}
+	if err := xml.Unmarshal(content, &config); err != nil {
+		return nil, "nuget"
+	}
+
+	var deps []Dependency
+	for _, p := range config.Packages {
+		if p.ID == "" {
+			continue
+		}
+		depType := "production"
+		if p.Development == "true" {
+			depType = "dev"
+		}
+		deps = append(deps, Dependency{Name: p.ID, Version: cleanVersion(p.Version), Type: depType})
+	}
+
+	sort.Slice(deps, func(i, j int) bool { return deps[i].Name < deps[j].Name })
+	return deps, "nuget"
+}
+
+// parseComposerJSON parses a PHP composer.json file. Platform requirements
+// such as "php" and "ext-json" are not packages and are skipped.
+//
+// Example composer.json:
+//
+//	{
+//	  "require": { "php": ">=8.1", "monolog/monolog": "^3.0" },
+//	  "require-dev": { "phpunit/phpunit": "^10.0" }
+//	}
+func parseComposerJSON(content []byte) ([]Dependency, string) {
+	var composer struct {
+		Require    map[string]string `json:"require"`
+		RequireDev map[string]string `json:"require-dev"`
+	}
+	if err := json.Unmarshal(content, &composer); err != nil {
+		return nil, "composer"
+	}
+
+	var deps []Dependency
+	add := func(reqs map[string]string, depType string) {
+		for name, version := range reqs {
+			if !strings.Contains(name, "/") {
+				continue // php, ext-*, lib-*, composer-plugin-api
+			}
+			deps = append(deps, Dependency{Name: name, Version: cleanVersion(version), Type: depType})
+		}
+	}
+	add(composer.Require, "production")
+	add(composer.RequireDev, "dev")
+
+	sort.Slice(deps, func(i, j int) bool { return deps[i].Name < deps[j].Name })
+	return deps, "composer"
+}
+
+// parsePubspec parses a Dart/Flutter pubspec.yaml file. SDK dependencies
+// (flutter, flutter_test) are skipped; git and path dependencies are kept
+// without a version.
+//
+// Example pubspec.yaml:
+//
+//	dependencies:
+//	  flutter:
+//	    sdk: flutter
+//	  http: ^1.1.0
+//	dev_dependencies:
+//	  mockito: ^5.4.0
+func parsePubspec(content []byte) ([]Dependency, string) {
+	var deps []Dependency
+	for _, doc := range parseYAMLDocuments(string(content)) {
+		for _, section := range []struct{ key, depType string }{
+			{"dependencies", "production"},
+			{"dev_dependencies", "dev"},
+		} {
+			entries, _ := yamlGet(doc.Value, section.key).(map[string]interface{})
+			for name, spec := range entries {
+				version := ""
+				switch v := spec.(type) {
+				case string:
+					version = v
+				case map[string]interface{}:
+					if v["sdk"] != nil {
+						continue
+					}
+					version = yamlString(v["version"])
+				}
+				deps = append(deps, Dependency{Name: name, Version: cleanVersion(version), Type: section.depType})
+			}
+		}
+	}
+
+	sort.Slice(deps, func(i, j int) bool { return deps[i].Name < deps[j].Name })
+	return deps, "pub"
+}
+
 // cleanVersion normalizes version strings for display.
 // It preserves the original format including prefixes like ^, ~, >=.
 func cleanVersion(v string) string {
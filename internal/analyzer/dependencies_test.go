@@ -0,0 +1,250 @@
+package analyzer
+
+import (
+	"testing"
+)
+
+func depsByName(deps []Dependency) map[string]Dependency {
+	m := make(map[string]Dependency)
+	for _, d := range deps {
+		m[d.Name] = d
+	}
+	return m
+}
+
+func TestResolvePOMDependencies(t *testing.T) {
+	poms := map[string][]byte{
+		"pom.xml": []byte(`<project>
+  <groupId>com.acme</groupId>
+  <artifactId>parent</artifactId>
+  <version>2.3.0</version>
+  <packaging>pom</packaging>
+  <properties>
+    <jackson.version>2.16.1</jackson.version>
+    <junit.version>5.10.1</junit.version>
+  </properties>
+  <dependencyManagement>
+    <dependencies>
+      <dependency>
+        <groupId>com.fasterxml.jackson.core</groupId>
+        <artifactId>jackson-databind</artifactId>
+        <version>${jackson.version}</version>
+      </dependency>
+    </dependencies>
+  </dependencyManagement>
+</project>`),
+		"service/pom.xml": []byte(`<project>
+  <parent>
+    <groupId>com.acme</groupId>
+    <artifactId>parent</artifactId>
+    <version>2.3.0</version>
+  </parent>
+  <artifactId>service</artifactId>
+  <properties>
+    <guava.version>33.0.0-jre</guava.version>
+  </properties>
+  <dependencies>
+    <dependency>
+      <groupId>com.fasterxml.jackson.core</groupId>
+      <artifactId>jackson-databind</artifactId>
+    </dependency>
+    <dependency>
+      <groupId>com.google.guava</groupId>
+      <artifactId>guava</artifactId>
+      <version>${guava.version}</version>
+    </dependency>
+    <dependency>
+      <groupId>com.acme</groupId>
+      <artifactId>common</artifactId>
+      <version>${project.version}</version>
+    </dependency>
+    <dependency>
+      <groupId>org.junit.jupiter</groupId>
+      <artifactId>junit-jupiter</artifactId>
+      <version>${junit.version}</version>
+      <scope>test</scope>
+    </dependency>
+    <dependency>
+      <groupId>jakarta.servlet</groupId>
+      <artifactId>jakarta.servlet-api</artifactId>
+      <version>${servlet.version}</version>
+      <scope>provided</scope>
+    </dependency>
+  </dependencies>
+</project>`),
+	}
+
+	deps := depsByName(resolvePOMDependencies("service/pom.xml", poms))
+
+	want := map[string]Dependency{
+		"com.fasterxml.jackson.core:jackson-databind": {Version: "2.16.1", Type: "production"},
+		"com.google.guava:guava":                      {Version: "33.0.0-jre", Type: "production"},
+		"com.acme:common":                             {Version: "2.3.0", Type: "production"},
+		"org.junit.jupiter:junit-jupiter":             {Version: "5.10.1", Type: "dev"},
+		"jakarta.servlet:jakarta.servlet-api":         {Version: "", Type: "peer"},
+	}
+	if len(deps) != len(want) {
+		t.Fatalf("got %d deps: %+v", len(deps), deps)
+	}
+	for name, w := range want {
+		if d := deps[name]; d.Version != w.Version || d.Type != w.Type {
+			t.Errorf("%s = %q/%s, want %q/%s", name, d.Version, d.Type, w.Version, w.Type)
+		}
+	}
+}
+
+func TestParseGradle(t *testing.T) {
+	groovy := []byte(`
+ext {
+    springVersion = '6.1.2'
+}
+def jacksonVersion = "2.16.1"
+
+dependencies {
+    implementation "org.springframework:spring-core:$springVersion"
+    implementation 'com.fasterxml.jackson.core:jackson-databind:${jacksonVersion}'
+    api platform('org.springframework.boot:spring-boot-dependencies:3.2.1')
+    compileOnly 'org.projectlombok:lombok:1.18.30'
+    testImplementation group: 'junit', name: 'junit', version: '4.13.2'
+    // implementation 'com.example:commented-out:1.0'
+    implementation project(':core')
+}
+
+repositories {
+    maven { url "https://repo.example.com/maven" }
+}
+`)
+	kotlin := []byte(`
+val ktorVersion = "2.3.7"
+
+dependencies {
+    implementation("io.ktor:ktor-server-core:$ktorVersion")
+    implementation(kotlin("stdlib"))
+    testImplementation("io.mockk:mockk:1.13.8")
+    androidTestImplementation(group = "androidx.test", name = "runner", version = "1.5.2")
+    implementation("com.squareup.okhttp3:okhttp")
+}
+`)
+
+	deps, fileType := parseGradle(groovy)
+	if fileType != "gradle" {
+		t.Errorf("fileType = %q", fileType)
+	}
+	got := depsByName(deps)
+	want := map[string]Dependency{
+		"org.springframework:spring-core":                   {Version: "6.1.2", Type: "production"},
+		"com.fasterxml.jackson.core:jackson-databind":       {Version: "2.16.1", Type: "production"},
+		"org.springframework.boot:spring-boot-dependencies": {Version: "3.2.1", Type: "production"},
+		"org.projectlombok:lombok":                          {Version: "1.18.30", Type: "peer"},
+		"junit:junit":                                       {Version: "4.13.2", Type: "dev"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("groovy deps = %+v", deps)
+	}
+	for name, w := range want {
+		if d := got[name]; d.Version != w.Version || d.Type != w.Type {
+			t.Errorf("%s = %q/%s, want %q/%s", name, d.Version, d.Type, w.Version, w.Type)
+		}
+	}
+
+	deps, _ = parseGradle(kotlin)
+	got = depsByName(deps)
+	if len(got) != 4 || got["io.ktor:ktor-server-core"].Version != "2.3.7" ||
+		got["androidx.test:runner"].Type != "dev" || got["com.squareup.okhttp3:okhttp"].Version != "" {
+		t.Errorf("kotlin deps = %+v", deps)
+	}
+}
+
+func TestParseNuGet(t *testing.T) {
+	csproj := []byte(`<Project Sdk="Microsoft.NET.Sdk">
+  <ItemGroup>
+    <PackageReference Include="Newtonsoft.Json" Version="13.0.3" />
+    <PackageReference Include="Serilog">
+      <Version>3.1.1</Version>
+    </PackageReference>
+    <PackageReference Include="StyleCop.Analyzers" Version="1.1.118" PrivateAssets="all" />
+  </ItemGroup>
+  <ItemGroup>
+    <ProjectReference Include="..\Core\Core.csproj" />
+  </ItemGroup>
+</Project>`)
+	deps, fileType := parseCsproj(csproj)
+	got := depsByName(deps)
+	if fileType != "nuget" || len(deps) != 3 || got["Serilog"].Version != "3.1.1" || got["StyleCop.Analyzers"].Type != "dev" {
+		t.Errorf("csproj deps = %+v", deps)
+	}
+
+	config := []byte(`<?xml version="1.0" encoding="utf-8"?>
+<packages>
+  <package id="log4net" version="2.0.15" targetFramework="net48" />
+  <package id="NUnit" version="3.14.0" targetFramework="net48" developmentDependency="true" />
+</packages>`)
+	deps, _ = parsePackagesConfig(config)
+	got = depsByName(deps)
+	if len(deps) != 2 || got["log4net"].Version != "2.0.15" || got["NUnit"].Type != "dev" {
+		t.Errorf("packages.config deps = %+v", deps)
+	}
+}
+
+func TestParseComposerJSON(t *testing.T) {
+	deps, fileType := parseComposerJSON([]byte(`{
+  "require": {"php": ">=8.1", "ext-json": "*", "monolog/monolog": "^3.0", "guzzlehttp/guzzle": "^7.8"},
+  "require-dev": {"phpunit/phpunit": "^10.5"}
+}`))
+	got := depsByName(deps)
+	if fileType != "composer" || len(deps) != 3 || got["phpunit/phpunit"].Type != "dev" || got["monolog/monolog"].Version != "^3.0" {
+		t.Errorf("composer deps = %+v", deps)
+	}
+}
+
+func TestParsePubspec(t *testing.T) {
+	deps, fileType := parsePubspec([]byte(`name: app
+environment:
+  sdk: '>=3.0.0 <4.0.0'
+
+dependencies:
+  flutter:
+    sdk: flutter
+  http: ^1.1.0
+  provider:
+    version: ^6.1.1
+  shared:
+    path: ../shared
+
+dev_dependencies:
+  flutter_test:
+    sdk: flutter
+  mockito: ^5.4.4
+`))
+	got := depsByName(deps)
+	if fileType != "pub" || len(deps) != 4 {
+		t.Fatalf("pubspec deps = %+v", deps)
+	}
+	if got["http"].Version != "^1.1.0" || got["provider"].Version != "^6.1.1" || got["shared"].Version != "" || got["mockito"].Type != "dev" {
+		t.Errorf("pubspec deps = %+v", deps)
+	}
+}
+
+func TestFindDependencyFiles_NewEcosystems(t *testing.T) {
+	files := findDependencyFiles(treeFor(mapFileSource{
+		"pom.xml":                  "",
+		"app/build.gradle.kts":     "",
+		"src/Api/Api.csproj":       "",
+		"legacy/packages.config":   "",
+		"composer.json":            "",
+		"mobile/pubspec.yaml":      "",
+		"vendor/x/y/composer.json": "",
+	}))
+	types := make(map[string]string)
+	for _, f := range files {
+		types[f.path] = f.fileType
+	}
+	if len(types) != 6 || types["src/Api/Api.csproj"] != "nuget" || types["app/build.gradle.kts"] != "gradle" {
+		t.Errorf("dependency files = %v", types)
+	}
+	for _, fileType := range []string{"maven", "gradle", "nuget", "composer", "pub"} {
+		if mapEcosystem(fileType) == "" {
+			t.Errorf("no OSV ecosystem for %s", fileType)
+		}
+	}
+}
@@ -0,0 +1,398 @@
+// Package analyzer provides analysis functions for GitHub repositories.
+// This file builds the dependency graph from lockfiles and manifests.
+package analyzer
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// GraphNode is a single package version in the dependency graph
+type GraphNode struct {
+	ID        string `json:"id"` // "ecosystem:name@version"
+	Name      string `json:"name"`
+	Version   string `json:"version"`
+	Ecosystem string `json:"ecosystem"` // the dependency file type, e.g. "npm"
+	Direct    bool   `json:"direct"`
+	Dev       bool   `json:"dev,omitempty"`
+	Depth     int    `json:"depth"`  // 1 for direct dependencies, 0 when unreachable from them
+	FanIn     int    `json:"fan_in"` // number of packages depending on this one
+}
+
+// GraphEdge records that From depends on To
+type GraphEdge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// DuplicatePackage is a package installed in more than one version
+type DuplicatePackage struct {
+	Ecosystem string   `json:"ecosystem"`
+	Name      string   `json:"name"`
+	Versions  []string `json:"versions"`
+}
+
+// DependencyGraph links direct dependencies to the transitive packages they
+// pull in
+type DependencyGraph struct {
+	Nodes        []*GraphNode       `json:"nodes"`
+	Edges        []GraphEdge        `json:"edges"`
+	MaxDepth     int                `json:"max_depth"`
+	MostDepended []string           `json:"most_depended"` // node IDs with the highest fan-in
+	Duplicates   []DuplicatePackage `json:"duplicates"`
+
+	// Lookup tables, rebuilt from Nodes and Edges after the graph is loaded
+	// from JSON (e.g. from the analysis cache)
+	index    map[string]*GraphNode
+	children map[string][]string
+	parents  map[string][]string
+}
+
+// mostDependedLimit caps the MostDepended list
+const mostDependedLimit = 10
+
+// graphNodeID returns the node ID for a package version
+func graphNodeID(ecosystem, name, version string) string {
+	return ecosystem + ":" + name + "@" + version
+}
+
+// BuildDependencyGraph builds the dependency graph for an analysis. Edges
+// come from the lockfiles; direct dependencies are taken from the manifests
+// as well, so a project without lockfiles still gets its first level. When
+// a lockfile does not record which version of a requirement is used, the
+// top-level install is assumed.
+func BuildDependencyGraph(deps *DependencyAnalysis) *DependencyGraph {
+	g := &DependencyGraph{
+		index:    make(map[string]*GraphNode),
+		children: make(map[string][]string),
+		parents:  make(map[string][]string),
+	}
+	if deps == nil {
+		return g
+	}
+
+	addNode := func(ecosystem, name, version string) *GraphNode {
+		id := graphNodeID(ecosystem, name, version)
+		if node, ok := g.index[id]; ok {
+			return node
+		}
+		node := &GraphNode{ID: id, Name: name, Version: version, Ecosystem: ecosystem}
+		g.index[id] = node
+		g.Nodes = append(g.Nodes, node)
+		return node
+	}
+	addEdge := func(from, to string) {
+		if from == to || contains(g.children[from], to) {
+			return
+		}
+		g.children[from] = append(g.children[from], to)
+		g.parents[to] = append(g.parents[to], from)
+		g.Edges = append(g.Edges, GraphEdge{From: from, To: to})
+	}
+
+	// byName finds packages by ecosystem and normalized name, top-level
+	// installs first as the lockfile parsers list them
+	byName := make(map[string][]*GraphNode)
+	for _, lock := range deps.LockFiles {
+		// Versions of each package within this lockfile
+		local := make(map[string][]*GraphNode)
+		for _, p := range lock.Packages {
+			node := addNode(lock.FileType, p.Name, p.Version)
+			node.Direct = node.Direct || p.Direct
+			node.Dev = node.Dev || p.Dev
+			key := lockKey(lock.FileType, p.Name)
+			if !containsNode(local[key], node) {
+				local[key] = append(local[key], node)
+			}
+			if !containsNode(byName[lock.FileType+":"+key], node) {
+				byName[lock.FileType+":"+key] = append(byName[lock.FileType+":"+key], node)
+			}
+		}
+		for _, p := range lock.Packages {
+			from := graphNodeID(lock.FileType, p.Name, p.Version)
+			for _, req := range p.Requires {
+				candidates := local[lockKey(lock.FileType, req)]
+				if len(candidates) == 0 {
+					continue // optional or platform-specific package that was not installed
+				}
+				to := candidates[0]
+				for _, c := range candidates {
+					if c.Version == p.Pinned[req] {
+						to = c
+						break
+					}
+				}
+				addEdge(from, to.ID)
+			}
+		}
+	}
+
+	for _, file := range deps.Files {
+		for _, dep := range file.Dependencies {
+			if dep.Transitive {
+				continue
+			}
+			version := dep.Resolved
+			if version == "" {
+				version = dep.Version
+			}
+			node := g.index[graphNodeID(file.FileType, dep.Name, version)]
+			if node == nil {
+				// Lockfile names may differ in case or separators (PyPI)
+				for _, c := range byName[file.FileType+":"+lockKey(file.FileType, dep.Name)] {
+					if c.Version == version {
+						node = c
+						break
+					}
+				}
+			}
+			if node == nil {
+				node = addNode(file.FileType, dep.Name, version)
+				node.Dev = dep.Type == "dev"
+			}
+			node.Direct = true
+		}
+	}
+
+	g.computeDepths()
+	g.computeStats(byName)
+	sort.Slice(g.Nodes, func(i, j int) bool { return g.Nodes[i].ID < g.Nodes[j].ID })
+	return g
+}
+
+// containsNode reports whether nodes includes node
+func containsNode(nodes []*GraphNode, node *GraphNode) bool {
+	for _, n := range nodes {
+		if n == node {
+			return true
+		}
+	}
+	return false
+}
+
+// computeDepths sets each node's distance from the nearest direct dependency
+func (g *DependencyGraph) computeDepths() {
+	var queue []string
+	for _, node := range g.Nodes {
+		if node.Direct {
+			node.Depth = 1
+			queue = append(queue, node.ID)
+		}
+	}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		depth := g.index[id].Depth
+		if depth > g.MaxDepth {
+			g.MaxDepth = depth
+		}
+		for _, child := range g.children[id] {
+			if node := g.index[child]; node.Depth == 0 {
+				node.Depth = depth + 1
+				queue = append(queue, child)
+			}
+		}
+	}
+}
+
+// computeStats fills in fan-in, the most depended-on packages and the
+// packages installed in several versions
+func (g *DependencyGraph) computeStats(byName map[string][]*GraphNode) {
+	var ranked []*GraphNode
+	for _, node := range g.Nodes {
+		node.FanIn = len(g.parents[node.ID])
+		if node.FanIn > 0 {
+			ranked = append(ranked, node)
+		}
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].FanIn != ranked[j].FanIn {
+			return ranked[i].FanIn > ranked[j].FanIn
+		}
+		return ranked[i].ID < ranked[j].ID
+	})
+	for i := 0; i < len(ranked) && i < mostDependedLimit; i++ {
+		g.MostDepended = append(g.MostDepended, ranked[i].ID)
+	}
+
+	for _, key := range sortedKeys(byName) {
+		nodes := byName[key]
+		if len(nodes) < 2 {
+			continue
+		}
+		dup := DuplicatePackage{Ecosystem: nodes[0].Ecosystem, Name: nodes[0].Name}
+		for _, n := range nodes {
+			dup.Versions = append(dup.Versions, n.Version)
+		}
+		sort.Slice(dup.Versions, func(i, j int) bool {
+			return compareVersions(dup.Versions[i], dup.Versions[j]) < 0
+		})
+		g.Duplicates = append(g.Duplicates, dup)
+	}
+}
+
+// reindex rebuilds the lookup tables when they are missing
+func (g *DependencyGraph) reindex() {
+	if g.index != nil {
+		return
+	}
+	g.index = make(map[string]*GraphNode, len(g.Nodes))
+	g.children = make(map[string][]string)
+	g.parents = make(map[string][]string)
+	for _, node := range g.Nodes {
+		g.index[node.ID] = node
+	}
+	for _, e := range g.Edges {
+		g.children[e.From] = append(g.children[e.From], e.To)
+		g.parents[e.To] = append(g.parents[e.To], e.From)
+	}
+}
+
+// Node returns the node with the given ID, or nil
+func (g *DependencyGraph) Node(id string) *GraphNode {
+	g.reindex()
+	return g.index[id]
+}
+
+// Roots returns the direct dependencies, sorted by ID
+func (g *DependencyGraph) Roots() []*GraphNode {
+	var roots []*GraphNode
+	for _, node := range g.Nodes {
+		if node.Direct {
+			roots = append(roots, node)
+		}
+	}
+	return roots
+}
+
+// Children returns the packages a node depends on, sorted by ID
+func (g *DependencyGraph) Children(id string) []*GraphNode {
+	g.reindex()
+	var children []*GraphNode
+	for _, child := range g.children[id] {
+		children = append(children, g.index[child])
+	}
+	sort.Slice(children, func(i, j int) bool { return children[i].ID < children[j].ID })
+	return children
+}
+
+// FindNodes returns the nodes for a package name, optionally restricted to
+// one version. Names are compared case-insensitively, and PyPI names after
+// normalization.
+func (g *DependencyGraph) FindNodes(name, version string) []*GraphNode {
+	var found []*GraphNode
+	for _, node := range g.Nodes {
+		if version != "" && node.Version != version {
+			continue
+		}
+		if strings.EqualFold(lockKey(node.Ecosystem, node.Name), lockKey(node.Ecosystem, name)) {
+			found = append(found, node)
+		}
+	}
+	return found
+}
+
+// IntroducedBy returns the IDs of the direct dependencies that pull in the
+// given node, including the node itself when it is direct
+func (g *DependencyGraph) IntroducedBy(id string) []string {
+	g.reindex()
+	seen := map[string]bool{id: true}
+	queue := []string{id}
+	var roots []string
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		if node := g.index[current]; node != nil && node.Direct {
+			roots = append(roots, current)
+		}
+		for _, parent := range g.parents[current] {
+			if !seen[parent] {
+				seen[parent] = true
+				queue = append(queue, parent)
+			}
+		}
+	}
+	sort.Strings(roots)
+	return roots
+}
+
+// PathTo returns the shortest chain of node IDs from a direct dependency to
+// the given node, or nil when no direct dependency reaches it
+func (g *DependencyGraph) PathTo(id string) []string {
+	g.reindex()
+	next := map[string]string{id: ""} // node -> the node after it on the path
+	queue := []string{id}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		if node := g.index[current]; node != nil && node.Direct {
+			path := []string{current}
+			for step := next[current]; step != ""; step = next[step] {
+				path = append(path, step)
+			}
+			return path
+		}
+		parents := append([]string(nil), g.parents[current]...)
+		sort.Strings(parents)
+		for _, parent := range parents {
+			if _, ok := next[parent]; !ok {
+				next[parent] = current
+				queue = append(queue, parent)
+			}
+		}
+	}
+	return nil
+}
+
+// ToDOT renders the graph in Graphviz DOT format. Direct dependencies are
+// drawn as boxes.
+func (g *DependencyGraph) ToDOT() string {
+	var b strings.Builder
+	b.WriteString("digraph dependencies {\n")
+	b.WriteString("  rankdir=LR;\n")
+	b.WriteString("  node [shape=ellipse, fontsize=10];\n")
+	for _, node := range g.Nodes {
+		attrs := fmt.Sprintf("label=%q", node.Name+"\n"+node.Version)
+		if node.Direct {
+			attrs += ", shape=box"
+		}
+		if node.Dev {
+			attrs += ", style=dashed"
+		}
+		fmt.Fprintf(&b, "  %q [%s];\n", node.ID, attrs)
+	}
+	for _, e := range g.Edges {
+		fmt.Fprintf(&b, "  %q -> %q;\n", e.From, e.To)
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// ToMermaid renders the graph as a Mermaid flowchart. Node IDs are
+// replaced by short identifiers because Mermaid does not accept "@" or "/"
+// in them.
+func (g *DependencyGraph) ToMermaid() string {
+	var b strings.Builder
+	b.WriteString("graph LR\n")
+	ids := make(map[string]string, len(g.Nodes))
+	for i, node := range g.Nodes {
+		ids[node.ID] = fmt.Sprintf("n%d", i)
+		label := strings.ReplaceAll(node.Name+"@"+node.Version, `"`, "#quot;")
+		if node.Direct {
+			fmt.Fprintf(&b, "  %s[\"%s\"]\n", ids[node.ID], label)
+		} else {
+			fmt.Fprintf(&b, "  %s(\"%s\")\n", ids[node.ID], label)
+		}
+	}
+	for _, e := range g.Edges {
+		fmt.Fprintf(&b, "  %s --> %s\n", ids[e.From], ids[e.To])
+	}
+	return b.String()
+}
+
+// ToJSON renders the graph, its edges and statistics as indented JSON
+func (g *DependencyGraph) ToJSON() ([]byte, error) {
+	return json.MarshalIndent(g, "", "  ")
+}
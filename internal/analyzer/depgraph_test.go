@@ -0,0 +1,167 @@
+package analyzer
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func testGraph(t *testing.T) *DependencyGraph {
+	t.Helper()
+	analysis := &DependencyAnalysis{}
+	analysis.AddFiles(
+		DependencyFile{Filename: "package.json", FileType: "npm", Dependencies: []Dependency{
+			{Name: "express", Version: "^4.18.0", Type: "production"},
+			{Name: "jest", Version: "^29.0.0", Type: "dev"},
+		}},
+		DependencyFile{Filename: "requirements.txt", FileType: "python", Dependencies: []Dependency{
+			{Name: "flask", Version: ">=2.0", Type: "production"},
+		}},
+	)
+	analysis.ApplyLockFiles(
+		ParseLockFile("package-lock.json", loadFixture(t, "lockfiles/package-lock.json")),
+		ParseLockFile("crates/Cargo.lock", loadFixture(t, "lockfiles/Cargo.lock")),
+	)
+	return BuildDependencyGraph(analysis)
+}
+
+func TestBuildDependencyGraph(t *testing.T) {
+	g := testGraph(t)
+
+	var roots []string
+	for _, n := range g.Roots() {
+		roots = append(roots, n.ID)
+	}
+	want := "npm:express@4.18.2 npm:jest@29.7.0 python:flask@>=2.0 rust:clap@4.4.11 rust:serde@1.0.193"
+	if got := strings.Join(roots, " "); got != want {
+		t.Errorf("roots = %s\nwant %s", got, want)
+	}
+
+	// jest uses its nested copy of debug, express the top-level one
+	if c := g.Children("npm:jest@29.7.0"); len(c) != 1 || c[0].Version != "4.3.4" {
+		t.Errorf("jest children = %+v", c)
+	}
+	if c := g.Children("npm:express@4.18.2"); len(c) != 2 || c[1].ID != "npm:debug@2.6.9" {
+		t.Errorf("express children = %+v", c)
+	}
+
+	depths := map[string]int{
+		"npm:express@4.18.2":       1,
+		"npm:accepts@1.3.8":        2,
+		"npm:mime-types@2.1.35":    3,
+		"npm:ms@2.0.0":             3,
+		"npm:debug@4.3.4":          2,
+		"rust:clap_builder@4.4.11": 2,
+	}
+	for id, want := range depths {
+		if n := g.Node(id); n == nil || n.Depth != want {
+			t.Errorf("%s = %+v, want depth %d", id, n, want)
+		}
+	}
+	if g.MaxDepth != 3 {
+		t.Errorf("MaxDepth = %d", g.MaxDepth)
+	}
+
+	if len(g.Duplicates) != 1 || g.Duplicates[0].Name != "debug" || strings.Join(g.Duplicates[0].Versions, ",") != "2.6.9,4.3.4" {
+		t.Errorf("duplicates = %+v", g.Duplicates)
+	}
+	if len(g.MostDepended) == 0 || g.Node(g.MostDepended[0]).FanIn != 1 {
+		t.Errorf("most depended = %v", g.MostDepended)
+	}
+}
+
+func TestDependencyGraphPaths(t *testing.T) {
+	g := testGraph(t)
+
+	if got := strings.Join(g.PathTo("npm:ms@2.0.0"), " > "); got != "npm:express@4.18.2 > npm:debug@2.6.9 > npm:ms@2.0.0" {
+		t.Errorf("PathTo(ms) = %s", got)
+	}
+	if got := g.IntroducedBy("npm:mime-types@2.1.35"); len(got) != 1 || got[0] != "npm:express@4.18.2" {
+		t.Errorf("IntroducedBy(mime-types) = %v", got)
+	}
+	if got := g.IntroducedBy("npm:express@4.18.2"); len(got) != 1 {
+		t.Errorf("a direct dependency introduces itself, got %v", got)
+	}
+	if got := g.FindNodes("debug", ""); len(got) != 2 {
+		t.Errorf("FindNodes(debug) = %+v", got)
+	}
+	if got := g.FindNodes("Flask", ">=2.0"); len(got) != 1 {
+		t.Errorf("FindNodes(Flask) = %+v", got)
+	}
+}
+
+func TestDependencyGraphExport(t *testing.T) {
+	g := testGraph(t)
+
+	dot := g.ToDOT()
+	for _, want := range []string{"digraph dependencies {", `"npm:express@4.18.2" -> "npm:accepts@1.3.8";`, "shape=box"} {
+		if !strings.Contains(dot, want) {
+			t.Errorf("DOT output missing %q", want)
+		}
+	}
+
+	mermaid := g.ToMermaid()
+	if !strings.HasPrefix(mermaid, "graph LR\n") || strings.Count(mermaid, "-->") != len(g.Edges) {
+		t.Errorf("unexpected Mermaid output:\n%s", mermaid)
+	}
+	if strings.Contains(mermaid, " npm:") {
+		t.Error("Mermaid node IDs must not contain package names")
+	}
+
+	data, err := g.ToJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded struct {
+		Nodes []GraphNode `json:"nodes"`
+		Edges []GraphEdge `json:"edges"`
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if len(decoded.Nodes) != len(g.Nodes) || len(decoded.Edges) != len(g.Edges) {
+		t.Errorf("JSON has %d nodes and %d edges", len(decoded.Nodes), len(decoded.Edges))
+	}
+}
+
+func TestLockFilePinnedVersions(t *testing.T) {
+	testCases := []struct {
+		fixture string
+		parse   func([]byte) []LockedPackage
+		name    string
+		want    string
+	}{
+		{"package-lock.json", parsePackageLock, "jest", "debug=4.3.4"},
+		{"yarn.lock", parseYarnLock, "@babel/code-frame", "@babel/highlight=7.22.20 chalk=2.4.2"},
+		{"yarn-berry.lock", parseYarnLock, "loose-envify", "js-tokens=4.0.0"},
+		{"pnpm-lock.yaml", parsePnpmLock, "react-dom", "loose-envify=1.4.0 react=18.2.0 scheduler=0.23.0"},
+	}
+	for _, tc := range testCases {
+		for _, p := range tc.parse(loadFixture(t, "lockfiles/"+tc.fixture)) {
+			if p.Name != tc.name {
+				continue
+			}
+			var pins []string
+			for _, name := range sortedKeys(p.Pinned) {
+				pins = append(pins, name+"="+p.Pinned[name])
+			}
+			if got := strings.Join(pins, " "); got != tc.want {
+				t.Errorf("%s: %s pinned %s, want %s", tc.fixture, tc.name, got, tc.want)
+			}
+		}
+	}
+}
+
+func TestDependencyGraphFromJSON(t *testing.T) {
+	data, err := testGraph(t).ToJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var g DependencyGraph
+	if err := json.Unmarshal(data, &g); err != nil {
+		t.Fatal(err)
+	}
+	if len(g.Children("npm:express@4.18.2")) != 2 || len(g.PathTo("npm:ms@2.0.0")) != 3 {
+		t.Error("lookups should work on a graph loaded from JSON")
+	}
+}
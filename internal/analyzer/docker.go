@@ -0,0 +1,539 @@
+// Package analyzer provides container configuration analysis.
+// This file parses Dockerfiles and Compose files for common security and
+// reproducibility issues and extracts the base images they build on.
+package analyzer
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/agnivo988/Repo-lyzer/internal/github"
+)
+
+// BaseImage represents a container image referenced by FROM or image:
+type BaseImage struct {
+	Name   string `json:"name"`             // Image name including registry (e.g., "node", "ghcr.io/org/app")
+	Tag    string `json:"tag,omitempty"`    // Tag (e.g., "18-alpine"), empty if omitted
+	Digest string `json:"digest,omitempty"` // Digest (e.g., "sha256:..."), empty if not pinned
+	Source string `json:"source"`           // File the image was found in
+	Line   int    `json:"line"`             // Line number within the file
+}
+
+// Reference returns the image reference as written (name[:tag][@digest])
+func (b BaseImage) Reference() string {
+	ref := b.Name
+	if b.Tag != "" {
+		ref += ":" + b.Tag
+	}
+	if b.Digest != "" {
+		ref += "@" + b.Digest
+	}
+	return ref
+}
+
+// IsPinned reports whether the image is pinned to a specific version
+func (b BaseImage) IsPinned() bool {
+	return b.Digest != "" || (b.Tag != "" && b.Tag != "latest")
+}
+
+// DockerfileReport holds the analysis of a single Dockerfile or Compose file
+type DockerfileReport struct {
+	Path           string      `json:"path"`
+	Kind           string      `json:"kind"` // "dockerfile" or "compose"
+	BaseImages     []BaseImage `json:"base_images"`
+	Stages         int         `json:"stages"`
+	MultiStage     bool        `json:"multi_stage"`
+	HasHealthcheck bool        `json:"has_healthcheck"`
+	User           string      `json:"user,omitempty"` // Final USER of the last stage
+	Findings       []CodeSmell `json:"findings"`
+}
+
+// DockerAnalysis holds the container analysis for a repository
+type DockerAnalysis struct {
+	Files          []DockerfileReport `json:"files"`
+	BaseImages     []BaseImage        `json:"base_images"`
+	Findings       []CodeSmell        `json:"findings"`
+	UsesMultiStage bool               `json:"uses_multi_stage"`
+}
+
+// AnalyzeDocker fetches and analyzes every Dockerfile and Compose file in
+// the repository tree.
+func AnalyzeDocker(client *github.Client, owner, repo string, fileTree []github.TreeEntry) (*DockerAnalysis, error) {
+	analysis := &DockerAnalysis{
+		Files:      []DockerfileReport{},
+		BaseImages: []BaseImage{},
+		Findings:   []CodeSmell{},
+	}
+
+	for _, f := range findDockerFiles(fileTree) {
+		content, err := fetchFile(client, owner, repo, f.path)
+		if err != nil {
+			continue
+		}
+
+		var report DockerfileReport
+		if f.fileType == "compose" {
+			report = ParseComposeFile(f.path, content)
+		} else {
+			report = ParseDockerfile(f.path, content)
+		}
+		analysis.addReport(report)
+	}
+
+	return analysis, nil
+}
+
+// addReport merges a single file report into the analysis
+func (a *DockerAnalysis) addReport(report DockerfileReport) {
+	a.Files = append(a.Files, report)
+	a.BaseImages = append(a.BaseImages, report.BaseImages...)
+	a.Findings = append(a.Findings, report.Findings...)
+	if report.MultiStage {
+		a.UsesMultiStage = true
+	}
+}
+
+// Dependencies converts the extracted base images into dependency files so
+// they can be listed and scanned alongside package dependencies.
+//
+// OSV has no container image ecosystem, so images are reported with the
+// "docker" file type and skipped by the vulnerability scan. Official golang
+// images with an exact version are additionally mapped to the Go "stdlib"
+// package, which OSV does track.
+func (a *DockerAnalysis) Dependencies() []DependencyFile {
+	if a == nil {
+		return nil
+	}
+
+	var files []DependencyFile
+	for _, report := range a.Files {
+		images := DependencyFile{Filename: report.Path, FileType: "docker"}
+		runtimes := DependencyFile{Filename: report.Path, FileType: "go"}
+		seen := make(map[string]bool)
+
+		for _, img := range report.BaseImages {
+			if seen[img.Reference()] {
+				continue
+			}
+			seen[img.Reference()] = true
+
+			version := img.Tag
+			if version == "" {
+				version = "latest"
+			}
+			images.Dependencies = append(images.Dependencies, Dependency{
+				Name:    img.Name,
+				Version: version,
+				Type:    "base-image",
+			})
+
+			if goVersion := golangImageVersion(img); goVersion != "" {
+				runtimes.Dependencies = append(runtimes.Dependencies, Dependency{
+					Name:    "stdlib",
+					Version: goVersion,
+					Type:    "production",
+				})
+			}
+		}
+
+		if len(images.Dependencies) > 0 {
+			images.TotalCount = len(images.Dependencies)
+			files = append(files, images)
+		}
+		if len(runtimes.Dependencies) > 0 {
+			runtimes.TotalCount = len(runtimes.Dependencies)
+			files = append(files, runtimes)
+		}
+	}
+	return files
+}
+
+var goImageVersionPattern = regexp.MustCompile(`^(\d+\.\d+\.\d+)`)
+
+// golangImageVersion returns the full Go version of an official golang
+// image tag such as "1.21.5-alpine", or "" if the tag is not exact.
+func golangImageVersion(img BaseImage) string {
+	if img.Name != "golang" && img.Name != "docker.io/library/golang" {
+		return ""
+	}
+	m := goImageVersionPattern.FindStringSubmatch(img.Tag)
+	if m == nil {
+		return ""
+	}
+	return m[1]
+}
+
+// findDockerFiles scans the file tree for Dockerfiles and Compose files
+func findDockerFiles(tree []github.TreeEntry) []depFileInfo {
+	var files []depFileInfo
+	for _, entry := range tree {
+		if entry.Type != "blob" {
+			continue
+		}
+		name := strings.ToLower(baseName(entry.Path))
+		switch {
+		case name == "dockerfile" || name == "containerfile" ||
+			strings.HasPrefix(name, "dockerfile.") || strings.HasSuffix(name, ".dockerfile"):
+			files = append(files, depFileInfo{path: entry.Path, fileType: "dockerfile"})
+		case (strings.HasPrefix(name, "docker-compose") || strings.HasPrefix(name, "compose.") || strings.HasPrefix(name, "compose-")) &&
+			(strings.HasSuffix(name, ".yml") || strings.HasSuffix(name, ".yaml")):
+			files = append(files, depFileInfo{path: entry.Path, fileType: "compose"})
+		}
+	}
+	return files
+}
+
+// dockerInstruction is a single logical Dockerfile instruction with
+// continuation lines joined.
+type dockerInstruction struct {
+	cmd  string // Upper-case instruction (FROM, RUN, ...)
+	args string
+	line int // Line where the instruction starts
+}
+
+// splitDockerInstructions splits Dockerfile content into instructions,
+// joining backslash continuations and dropping comments.
+func splitDockerInstructions(content string) []dockerInstruction {
+	var instructions []dockerInstruction
+	var current strings.Builder
+	start := 0
+
+	for i, raw := range strings.Split(content, "\n") {
+		line := strings.TrimSpace(strings.TrimRight(raw, "\r"))
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		if current.Len() == 0 {
+			if line == "" {
+				continue
+			}
+			start = i + 1
+		}
+
+		if strings.HasSuffix(line, "\\") {
+			current.WriteString(strings.TrimSuffix(line, "\\"))
+			current.WriteString(" ")
+			continue
+		}
+		current.WriteString(line)
+
+		text := strings.TrimSpace(current.String())
+		current.Reset()
+		cmd, args, _ := strings.Cut(text, " ")
+		instructions = append(instructions, dockerInstruction{
+			cmd:  strings.ToUpper(cmd),
+			args: strings.TrimSpace(args),
+			line: start,
+		})
+	}
+	return instructions
+}
+
+// secretEnvPattern matches variable names that usually hold credentials
+var secretEnvPattern = regexp.MustCompile(`(?i)(password|passwd|secret|token|api_?key|private_?key|access_?key|credentials?)`)
+
+// dockerVarPattern matches ${VAR} and $VAR references
+var dockerVarPattern = regexp.MustCompile(`\$\{?([A-Za-z_][A-Za-z0-9_]*)(?::-[^}]*)?\}?`)
+
+// ParseDockerfile analyzes a Dockerfile's content
+func ParseDockerfile(path string, content []byte) DockerfileReport {
+	report := DockerfileReport{
+		Path:       path,
+		Kind:       "dockerfile",
+		BaseImages: []BaseImage{},
+		Findings:   []CodeSmell{},
+	}
+
+	location := func(line int) string { return fmt.Sprintf("%s:%d", path, line) }
+	finding := func(typ, severity, desc string, line int) {
+		report.Findings = append(report.Findings, CodeSmell{
+			Type:        typ,
+			Severity:    severity,
+			Description: desc,
+			Location:    location(line),
+		})
+	}
+
+	args := make(map[string]string) // ARG defaults declared before the first FROM
+	stageNames := make(map[string]bool)
+	stageUser := ""
+	lastFromLine := 0
+
+	for _, ins := range splitDockerInstructions(string(content)) {
+		switch ins.cmd {
+		case "ARG":
+			name, value, hasValue := strings.Cut(ins.args, "=")
+			name = strings.TrimSpace(name)
+			value = strings.Trim(strings.TrimSpace(value), `"'`)
+			if report.Stages == 0 && hasValue {
+				args[name] = value
+			}
+			if secretEnvPattern.MatchString(name) {
+				if hasValue && value != "" {
+					finding("Secret In Build Arg", "High", "ARG "+name+" has a hard-coded default value", ins.line)
+				} else {
+					finding("Secret In Build Arg", "Low", "ARG "+name+" is visible in the image history; use build secrets instead", ins.line)
+				}
+			}
+
+		case "FROM":
+			report.Stages++
+			lastFromLine = ins.line
+			stageUser = ""
+			report.HasHealthcheck = false
+
+			fields := strings.Fields(ins.args)
+			var ref string
+			for i := 0; i < len(fields); i++ {
+				if strings.HasPrefix(fields[i], "--") {
+					continue
+				}
+				if ref == "" {
+					ref = fields[i]
+					continue
+				}
+				if strings.EqualFold(fields[i], "as") && i+1 < len(fields) {
+					stageNames[strings.ToLower(fields[i+1])] = true
+				}
+				break
+			}
+			if ref == "" || strings.EqualFold(ref, "scratch") || stageNames[strings.ToLower(ref)] {
+				continue
+			}
+
+			ref = dockerVarPattern.ReplaceAllStringFunc(ref, func(v string) string {
+				name := dockerVarPattern.FindStringSubmatch(v)[1]
+				if val, ok := args[name]; ok {
+					return val
+				}
+				return v
+			})
+			img := parseImageRef(ref)
+			img.Source = path
+			img.Line = ins.line
+			report.BaseImages = append(report.BaseImages, img)
+
+			switch {
+			case strings.Contains(ref, "$"):
+				finding("Unpinned Base Image", "Low", "Base image "+ref+" is set by an unresolved build argument", ins.line)
+			case img.Tag == "latest":
+				finding("Unpinned Base Image", "Medium", "Base image "+img.Reference()+" uses the mutable 'latest' tag", ins.line)
+			case !img.IsPinned():
+				finding("Unpinned Base Image", "Medium", "Base image "+img.Name+" has no tag and defaults to 'latest'", ins.line)
+			}
+
+		case "USER":
+			stageUser = strings.TrimSpace(ins.args)
+
+		case "ADD":
+			for _, field := range strings.Fields(ins.args) {
+				if strings.HasPrefix(field, "http://") || strings.HasPrefix(field, "https://") {
+					finding("Remote ADD", "High", "ADD downloads "+field+" without checksum verification; use curl with a checksum or ADD --checksum", ins.line)
+					break
+				}
+			}
+
+		case "ENV":
+			for _, kv := range parseDockerEnv(ins.args) {
+				if secretEnvPattern.MatchString(kv[0]) && kv[1] != "" && !strings.HasPrefix(kv[1], "$") {
+					finding("Secret In ENV", "High", "ENV "+kv[0]+" bakes a credential into the image", ins.line)
+				}
+			}
+
+		case "HEALTHCHECK":
+			report.HasHealthcheck = !strings.EqualFold(strings.TrimSpace(ins.args), "NONE")
+
+		case "RUN":
+			if pkgs := unpinnedOSPackages(ins.args); len(pkgs) > 0 {
+				finding("Unpinned OS Packages", "Low", "Packages installed without version pins: "+strings.Join(pkgs, ", "), ins.line)
+			}
+		}
+	}
+
+	report.MultiStage = report.Stages > 1
+	report.User = stageUser
+
+	if report.Stages > 0 {
+		if stageUser == "" || stageUser == "root" || stageUser == "0" || strings.HasPrefix(stageUser, "root:") || strings.HasPrefix(stageUser, "0:") {
+			finding("Runs As Root", "Medium", "Final stage has no non-root USER instruction", lastFromLine)
+		}
+		if !report.HasHealthcheck {
+			finding("Missing HEALTHCHECK", "Low", "Final stage does not define a HEALTHCHECK", lastFromLine)
+		}
+	}
+
+	return report
+}
+
+// parseDockerEnv parses both "ENV KEY=value KEY2=value" and "ENV KEY value"
+// into name/value pairs in declaration order
+func parseDockerEnv(args string) [][2]string {
+	var env [][2]string
+	fields := strings.Fields(args)
+	if len(fields) == 0 {
+		return env
+	}
+	if !strings.Contains(fields[0], "=") {
+		value := strings.Trim(strings.TrimSpace(strings.TrimPrefix(args, fields[0])), `"'`)
+		return append(env, [2]string{fields[0], value})
+	}
+	for _, field := range fields {
+		if name, value, ok := strings.Cut(field, "="); ok {
+			env = append(env, [2]string{name, strings.Trim(value, `"'`)})
+		}
+	}
+	return env
+}
+
+var (
+	shellSeparatorPattern = regexp.MustCompile(`&&|;|\|\|?`)
+	rpmVersionPattern     = regexp.MustCompile(`-\d`)
+)
+
+// unpinnedOSPackages returns packages installed by apt, apk, yum or dnf in
+// a RUN command without a version pin
+func unpinnedOSPackages(run string) []string {
+	var unpinned []string
+
+	// Split compound shell commands
+	for _, segment := range shellSeparatorPattern.Split(run, -1) {
+		fields := strings.Fields(segment)
+		if len(fields) < 2 {
+			continue
+		}
+
+		pinSep := ""
+		idx := -1
+		for i := 0; i+1 < len(fields); i++ {
+			tool, action := fields[i], fields[i+1]
+			switch {
+			case (tool == "apt-get" || tool == "apt") && action == "install":
+				pinSep, idx = "=", i+2
+			case tool == "apk" && action == "add":
+				pinSep, idx = "=~", i+2
+			case (tool == "yum" || tool == "dnf" || tool == "microdnf") && action == "install":
+				pinSep, idx = "-", i+2
+			}
+			if idx >= 0 {
+				break
+			}
+		}
+		if idx < 0 {
+			continue
+		}
+
+		for _, pkg := range fields[idx:] {
+			if strings.HasPrefix(pkg, "-") || strings.HasPrefix(pkg, "$") || strings.Contains(pkg, "/") {
+				continue
+			}
+			pinned := false
+			if pinSep == "-" {
+				// yum/dnf pins look like name-1.2.3
+				pinned = rpmVersionPattern.MatchString(pkg)
+			} else {
+				pinned = strings.ContainsAny(pkg, pinSep)
+			}
+			if !pinned {
+				unpinned = append(unpinned, pkg)
+			}
+		}
+	}
+	return unpinned
+}
+
+// composeImagePattern matches "image: name:tag" lines in Compose files
+var composeImagePattern = regexp.MustCompile(`^\s*image:\s*["']?([^"'\s#]+)`)
+
+// composeEnvPattern matches environment entries in list or map form
+var composeEnvPattern = regexp.MustCompile(`^\s*-?\s*["']?([A-Za-z_][A-Za-z0-9_]*)["']?\s*[:=]\s*["']?([^"'#]*)`)
+
+// ParseComposeFile analyzes a docker-compose file's images and environment
+func ParseComposeFile(path string, content []byte) DockerfileReport {
+	report := DockerfileReport{
+		Path:       path,
+		Kind:       "compose",
+		BaseImages: []BaseImage{},
+		Findings:   []CodeSmell{},
+	}
+
+	inEnv := false
+	envIndent := 0
+	for i, line := range strings.Split(string(content), "\n") {
+		lineNum := i + 1
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		indent := len(line) - len(strings.TrimLeft(line, " "))
+
+		if inEnv && indent <= envIndent {
+			inEnv = false
+		}
+		if strings.HasPrefix(trimmed, "environment:") {
+			inEnv = true
+			envIndent = indent
+			continue
+		}
+
+		if m := composeImagePattern.FindStringSubmatch(line); m != nil {
+			img := parseImageRef(m[1])
+			img.Source = path
+			img.Line = lineNum
+			report.BaseImages = append(report.BaseImages, img)
+			if !img.IsPinned() && !strings.Contains(m[1], "$") {
+				report.Findings = append(report.Findings, CodeSmell{
+					Type:        "Unpinned Base Image",
+					Severity:    "Medium",
+					Description: "Service image " + img.Reference() + " is not pinned to a version",
+					Location:    fmt.Sprintf("%s:%d", path, lineNum),
+				})
+			}
+			continue
+		}
+
+		if trimmed == "privileged: true" {
+			report.Findings = append(report.Findings, CodeSmell{
+				Type:        "Privileged Container",
+				Severity:    "High",
+				Description: "Service runs in privileged mode",
+				Location:    fmt.Sprintf("%s:%d", path, lineNum),
+			})
+			continue
+		}
+
+		if inEnv {
+			if m := composeEnvPattern.FindStringSubmatch(line); m != nil {
+				value := strings.TrimSpace(m[2])
+				if secretEnvPattern.MatchString(m[1]) && value != "" && !strings.HasPrefix(value, "$") {
+					report.Findings = append(report.Findings, CodeSmell{
+						Type:        "Secret In ENV",
+						Severity:    "High",
+						Description: "Environment variable " + m[1] + " has a hard-coded value",
+						Location:    fmt.Sprintf("%s:%d", path, lineNum),
+					})
+				}
+			}
+		}
+	}
+
+	report.Stages = len(report.BaseImages)
+	return report
+}
+
+// parseImageRef splits an image reference into name, tag and digest
+func parseImageRef(ref string) BaseImage {
+	img := BaseImage{}
+	if name, digest, ok := strings.Cut(ref, "@"); ok {
+		ref = name
+		img.Digest = digest
+	}
+	// A tag separator must come after the last slash (registry ports use ':')
+	lastSlash := strings.LastIndex(ref, "/")
+	if colon := strings.LastIndex(ref, ":"); colon > lastSlash {
+		img.Tag = ref[colon+1:]
+		ref = ref[:colon]
+	}
+	img.Name = ref
+	return img
+}
@@ -0,0 +1,150 @@
+package analyzer
+
+import (
+	"testing"
+
+	"github.com/agnivo988/Repo-lyzer/internal/github"
+)
+
+func hasFinding(findings []CodeSmell, typ string) bool {
+	for _, f := range findings {
+		if f.Type == typ {
+			return true
+		}
+	}
+	return false
+}
+
+func TestParseDockerfile_Findings(t *testing.T) {
+	dockerfile := `# syntax=docker/dockerfile:1
+FROM node:latest
+ENV DB_PASSWORD=hunter2 NODE_ENV=production
+ARG NPM_TOKEN
+ADD https://example.com/install.sh /tmp/install.sh
+RUN apt-get update && apt-get install -y \
+    curl \
+    git=1:2.39.2-1
+CMD ["node", "server.js"]
+`
+	report := ParseDockerfile("Dockerfile", []byte(dockerfile))
+
+	for _, typ := range []string{"Unpinned Base Image", "Secret In ENV", "Secret In Build Arg", "Remote ADD", "Unpinned OS Packages", "Runs As Root", "Missing HEALTHCHECK"} {
+		if !hasFinding(report.Findings, typ) {
+			t.Errorf("expected %q finding, got %+v", typ, report.Findings)
+		}
+	}
+	if report.MultiStage {
+		t.Error("single FROM should not be multi-stage")
+	}
+	if len(report.BaseImages) != 1 || report.BaseImages[0].Name != "node" || report.BaseImages[0].Tag != "latest" {
+		t.Errorf("BaseImages = %+v, want node:latest", report.BaseImages)
+	}
+}
+
+func TestParseDockerfile_MultiStageClean(t *testing.T) {
+	dockerfile := `ARG GO_VERSION=1.21.5
+FROM golang:${GO_VERSION}-alpine AS build
+RUN apk add --no-cache git=2.40.1-r0
+RUN go build -o /app ./...
+
+FROM gcr.io/distroless/static@sha256:abc123 AS final
+COPY --from=build /app /app
+USER nonroot:nonroot
+HEALTHCHECK CMD ["/app", "health"]
+ENTRYPOINT ["/app"]
+`
+	report := ParseDockerfile("build/Dockerfile", []byte(dockerfile))
+
+	if len(report.Findings) != 0 {
+		t.Errorf("expected no findings, got %+v", report.Findings)
+	}
+	if !report.MultiStage || report.Stages != 2 {
+		t.Errorf("Stages = %d, want multi-stage with 2", report.Stages)
+	}
+	if len(report.BaseImages) != 2 {
+		t.Fatalf("expected 2 base images, got %+v", report.BaseImages)
+	}
+	if report.BaseImages[0].Tag != "1.21.5-alpine" {
+		t.Errorf("ARG substitution failed, tag = %s", report.BaseImages[0].Tag)
+	}
+	if report.BaseImages[1].Digest != "sha256:abc123" {
+		t.Errorf("Digest = %s, want sha256:abc123", report.BaseImages[1].Digest)
+	}
+}
+
+func TestParseComposeFile(t *testing.T) {
+	compose := `services:
+  db:
+    image: postgres
+    environment:
+      POSTGRES_PASSWORD: supersecret
+      POSTGRES_USER: app
+  app:
+    image: "ghcr.io/org/app:1.4.2"
+    privileged: true
+    environment:
+      - API_TOKEN=${API_TOKEN}
+`
+	report := ParseComposeFile("docker-compose.yml", []byte(compose))
+
+	if len(report.BaseImages) != 2 {
+		t.Fatalf("expected 2 images, got %+v", report.BaseImages)
+	}
+	if report.BaseImages[1].Name != "ghcr.io/org/app" || report.BaseImages[1].Tag != "1.4.2" {
+		t.Errorf("image = %+v, want ghcr.io/org/app:1.4.2", report.BaseImages[1])
+	}
+
+	counts := map[string]int{}
+	for _, f := range report.Findings {
+		counts[f.Type]++
+	}
+	if counts["Unpinned Base Image"] != 1 || counts["Secret In ENV"] != 1 || counts["Privileged Container"] != 1 {
+		t.Errorf("unexpected findings: %+v", report.Findings)
+	}
+}
+
+func TestParseImageRef(t *testing.T) {
+	testCases := []struct {
+		ref, name, tag, digest string
+	}{
+		{"ubuntu", "ubuntu", "", ""},
+		{"node:18-alpine", "node", "18-alpine", ""},
+		{"localhost:5000/app", "localhost:5000/app", "", ""},
+		{"localhost:5000/app:v2", "localhost:5000/app", "v2", ""},
+		{"alpine@sha256:deadbeef", "alpine", "", "sha256:deadbeef"},
+	}
+	for _, tc := range testCases {
+		img := parseImageRef(tc.ref)
+		if img.Name != tc.name || img.Tag != tc.tag || img.Digest != tc.digest {
+			t.Errorf("parseImageRef(%q) = %+v", tc.ref, img)
+		}
+	}
+}
+
+func TestDockerAnalysis_Dependencies(t *testing.T) {
+	a := &DockerAnalysis{}
+	a.addReport(ParseDockerfile("Dockerfile", []byte("FROM golang:1.22.1 AS build\nFROM alpine:3.19\n")))
+
+	files := a.Dependencies()
+	if len(files) != 2 {
+		t.Fatalf("expected docker and go dependency files, got %+v", files)
+	}
+	if files[0].FileType != "docker" || files[0].TotalCount != 2 {
+		t.Errorf("docker file = %+v", files[0])
+	}
+	if files[1].FileType != "go" || files[1].Dependencies[0].Name != "stdlib" || files[1].Dependencies[0].Version != "1.22.1" {
+		t.Errorf("go runtime file = %+v", files[1])
+	}
+}
+
+func TestFindDockerFiles(t *testing.T) {
+	tree := []github.TreeEntry{
+		{Path: "Dockerfile", Type: "blob"},
+		{Path: "deploy/api.Dockerfile", Type: "blob"},
+		{Path: "docker-compose.prod.yaml", Type: "blob"},
+		{Path: "docs/docker.md", Type: "blob"},
+	}
+	if files := findDockerFiles(tree); len(files) != 3 {
+		t.Errorf("expected 3 docker files, got %+v", files)
+	}
+}
@@ -0,0 +1,259 @@
+// Package analyzer provides functions for analyzing GitHub repository data.
+// This file implements duplicate, vendored and generated file detection.
+package analyzer
+
+import (
+	"bytes"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/agnivo988/Repo-lyzer/internal/github"
+)
+
+// DuplicateGroup is a set of files with identical content
+type DuplicateGroup struct {
+	Sha         string   `json:"sha"`
+	Size        int      `json:"size"`
+	Paths       []string `json:"paths"`
+	WastedBytes int      `json:"wasted_bytes"` // size of every copy but one
+}
+
+// FileClassification groups files that should not count as the project's
+// own code (vendored or generated) and files that are exact duplicates.
+type FileClassification struct {
+	DuplicateGroups []DuplicateGroup `json:"duplicate_groups"` // largest waste first
+	DuplicateFiles  int              `json:"duplicate_files"`
+	WastedBytes     int              `json:"wasted_bytes"`
+	VendoredDirs    []string         `json:"vendored_dirs"`
+	VendoredFiles   int              `json:"vendored_files"`
+	VendoredBytes   int              `json:"vendored_bytes"`
+	GeneratedFiles  []string         `json:"generated_files"`
+	GeneratedBytes  int              `json:"generated_bytes"`
+	HeadersChecked  int              `json:"headers_checked"`
+
+	sizes     map[string]int
+	excluded  map[string]bool
+	generated map[string]bool
+}
+
+var (
+	// generatedHeaderPattern matches the Go convention and common markers
+	// left by other code generators near the top of a file
+	generatedHeaderPattern = regexp.MustCompile(`(?i)(^// code generated .* do not edit\.$|@generated\b|auto-generated|autogenerated|generated by .* do not edit)`)
+
+	generatedSuffixes = []string{
+		".pb.go", ".pb.gw.go", "_gen.go", ".gen.go", "_generated.go", "_pb2.py", "_pb2_grpc.py",
+		".pb.h", ".pb.cc", ".designer.cs", ".g.dart", ".freezed.dart", ".min.js", ".min.css",
+		".js.map", ".css.map", "_pb.js", "_pb.d.ts",
+	}
+	generatedNames = map[string]bool{
+		"package-lock.json": true, "yarn.lock": true, "pnpm-lock.yaml": true, "go.sum": true,
+		"cargo.lock": true, "poetry.lock": true, "pipfile.lock": true, "gemfile.lock": true,
+		"composer.lock": true,
+	}
+)
+
+// ClassifyFiles finds duplicate files by blob SHA and classifies vendored and
+// generated files by path conventions. It needs no API requests.
+func ClassifyFiles(fileTree []github.TreeEntry) *FileClassification {
+	c := &FileClassification{
+		DuplicateGroups: []DuplicateGroup{},
+		VendoredDirs:    []string{},
+		GeneratedFiles:  []string{},
+		sizes:           make(map[string]int),
+		excluded:        make(map[string]bool),
+		generated:       make(map[string]bool),
+	}
+
+	bySha := make(map[string][]github.TreeEntry)
+	vendoredDirs := make(map[string]bool)
+	for _, entry := range fileTree {
+		if entry.Type != "blob" {
+			continue
+		}
+		c.sizes[entry.Path] = entry.Size
+		lowerPath := strings.ToLower(entry.Path)
+
+		switch {
+		case isVendoredPath(lowerPath):
+			vendoredDirs[vendoredRoot(entry.Path)] = true
+			c.VendoredFiles++
+			c.VendoredBytes += entry.Size
+			c.excluded[entry.Path] = true
+		case IsGeneratedPath(entry.Path):
+			c.markGenerated(entry.Path)
+		}
+
+		// Empty files all share one SHA and are not worth reporting
+		if entry.Sha != "" && entry.Size > 0 {
+			bySha[entry.Sha] = append(bySha[entry.Sha], entry)
+		}
+	}
+
+	for sha, entries := range bySha {
+		if len(entries) < 2 {
+			continue
+		}
+		group := DuplicateGroup{Sha: sha, Size: entries[0].Size, WastedBytes: entries[0].Size * (len(entries) - 1)}
+		for _, e := range entries {
+			group.Paths = append(group.Paths, e.Path)
+		}
+		sort.Strings(group.Paths)
+		c.DuplicateGroups = append(c.DuplicateGroups, group)
+		c.DuplicateFiles += len(entries)
+		c.WastedBytes += group.WastedBytes
+	}
+	sort.Slice(c.DuplicateGroups, func(i, j int) bool {
+		if c.DuplicateGroups[i].WastedBytes != c.DuplicateGroups[j].WastedBytes {
+			return c.DuplicateGroups[i].WastedBytes > c.DuplicateGroups[j].WastedBytes
+		}
+		return c.DuplicateGroups[i].Paths[0] < c.DuplicateGroups[j].Paths[0]
+	})
+
+	c.VendoredDirs = sortedKeys(vendoredDirs)
+	return c
+}
+
+// CheckGeneratedHeaders reads source files and marks those whose first lines
+// carry a "Code generated ... DO NOT EDIT" style marker. Generated files tend
+// to be large, so with a budget the largest candidates are checked first.
+func (c *FileClassification) CheckGeneratedHeaders(source FileSource, maxFiles int) {
+	var candidates []string
+	for p := range c.sizes {
+		if !c.excluded[p] && isSourceFile(strings.ToLower(p)) {
+			candidates = append(candidates, p)
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if c.sizes[candidates[i]] != c.sizes[candidates[j]] {
+			return c.sizes[candidates[i]] > c.sizes[candidates[j]]
+		}
+		return candidates[i] < candidates[j]
+	})
+	if maxFiles > 0 && len(candidates) > maxFiles {
+		candidates = candidates[:maxFiles]
+	}
+
+	for _, p := range candidates {
+		content, err := source.ReadFile(p)
+		if err != nil {
+			continue
+		}
+		c.HeadersChecked++
+		if HasGeneratedHeader(content) {
+			c.markGenerated(p)
+		}
+	}
+	sort.Strings(c.GeneratedFiles)
+}
+
+// Excluded reports whether a file is vendored or generated
+func (c *FileClassification) Excluded(path string) bool {
+	return c != nil && c.excluded[path]
+}
+
+// IsGenerated reports whether a file was classified as generated
+func (c *FileClassification) IsGenerated(path string) bool {
+	return c != nil && c.generated[path]
+}
+
+// OwnLanguages returns a language breakdown in bytes, as GitHub reports it,
+// without the vendored and generated files. Excluded files are matched to a
+// language by extension, so only the languages line counts know are
+// adjusted.
+func (c *FileClassification) OwnLanguages(languages map[string]int) map[string]int {
+	if c == nil || len(c.excluded) == 0 {
+		return languages
+	}
+	own := make(map[string]int, len(languages))
+	for lang, bytes := range languages {
+		own[lang] = bytes
+	}
+	for p := range c.excluded {
+		if lang := LOCLanguage(p); lang != "" {
+			if _, ok := own[lang]; ok {
+				own[lang] -= c.sizes[p]
+			}
+		}
+	}
+	for lang, bytes := range own {
+		if bytes <= 0 {
+			delete(own, lang)
+		}
+	}
+	return own
+}
+
+// FilterTree returns the tree without vendored and generated files
+func (c *FileClassification) FilterTree(fileTree []github.TreeEntry) []github.TreeEntry {
+	if c == nil {
+		return fileTree
+	}
+	filtered := make([]github.TreeEntry, 0, len(fileTree))
+	for _, entry := range fileTree {
+		if !c.excluded[entry.Path] {
+			filtered = append(filtered, entry)
+		}
+	}
+	return filtered
+}
+
+// markGenerated records a generated file once
+func (c *FileClassification) markGenerated(p string) {
+	if c.generated[p] {
+		return
+	}
+	c.generated[p] = true
+	c.excluded[p] = true
+	c.GeneratedFiles = append(c.GeneratedFiles, p)
+	c.GeneratedBytes += c.sizes[p]
+}
+
+// IsGeneratedPath reports whether a path follows a code generator's naming
+// convention, or is a lockfile written by a package manager
+func IsGeneratedPath(p string) bool {
+	lowerPath := strings.ToLower(p)
+	name := baseName(lowerPath)
+	if generatedNames[name] || strings.HasPrefix(name, "zz_generated") ||
+		strings.Contains(name, "_generated.") || strings.Contains(name, ".generated.") {
+		return true
+	}
+	for _, suffix := range generatedSuffixes {
+		if strings.HasSuffix(lowerPath, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// HasGeneratedHeader reports whether a generator marker appears in the first
+// lines of content, before the code proper starts
+func HasGeneratedHeader(content []byte) bool {
+	if len(content) > 2048 {
+		content = content[:2048]
+	}
+	lines := bytes.Split(content, []byte("\n"))
+	if len(lines) > 10 {
+		lines = lines[:10]
+	}
+	for _, line := range lines {
+		if generatedHeaderPattern.Match(bytes.TrimSpace(line)) {
+			return true
+		}
+	}
+	return false
+}
+
+// vendoredRoot returns the path up to and including the vendored directory
+func vendoredRoot(p string) string {
+	parts := strings.Split(p, "/")
+	for i, part := range parts {
+		switch strings.ToLower(part) {
+		case "node_modules", "vendor", "third_party", "bower_components":
+			return path.Join(parts[:i+1]...) + "/"
+		}
+	}
+	return path.Dir(p) + "/"
+}
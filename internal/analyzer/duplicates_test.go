@@ -0,0 +1,106 @@
+package analyzer
+
+import (
+	"testing"
+
+	"github.com/agnivo988/Repo-lyzer/internal/github"
+)
+
+func TestClassifyFiles(t *testing.T) {
+	tree := []github.TreeEntry{
+		{Path: "assets/logo.png", Type: "blob", Size: 5000, Sha: "aaa"},
+		{Path: "docs/logo.png", Type: "blob", Size: 5000, Sha: "aaa"},
+		{Path: "web/logo.png", Type: "blob", Size: 5000, Sha: "aaa"},
+		{Path: "LICENSE", Type: "blob", Size: 1000, Sha: "bbb"},
+		{Path: "sub/LICENSE", Type: "blob", Size: 1000, Sha: "bbb"},
+		{Path: "a/.gitkeep", Type: "blob", Size: 0, Sha: "e69de"},
+		{Path: "b/.gitkeep", Type: "blob", Size: 0, Sha: "e69de"},
+		{Path: "vendor/github.com/x/y/y.go", Type: "blob", Size: 300, Sha: "ccc"},
+		{Path: "web/node_modules/left-pad/index.js", Type: "blob", Size: 200, Sha: "ddd"},
+		{Path: "api/service.pb.go", Type: "blob", Size: 9000, Sha: "eee"},
+		{Path: "package-lock.json", Type: "blob", Size: 4000, Sha: "fff"},
+		{Path: "main.go", Type: "blob", Size: 100, Sha: "ggg"},
+		{Path: "vendor", Type: "tree"},
+	}
+
+	c := ClassifyFiles(tree)
+
+	if len(c.DuplicateGroups) != 2 || c.DuplicateFiles != 5 {
+		t.Fatalf("groups = %+v", c.DuplicateGroups)
+	}
+	if c.DuplicateGroups[0].Sha != "aaa" || c.DuplicateGroups[0].WastedBytes != 10000 {
+		t.Errorf("largest group = %+v, want aaa wasting 10000", c.DuplicateGroups[0])
+	}
+	if c.WastedBytes != 11000 {
+		t.Errorf("WastedBytes = %d, want 11000", c.WastedBytes)
+	}
+
+	if c.VendoredFiles != 2 || len(c.VendoredDirs) != 2 || c.VendoredDirs[1] != "web/node_modules/" {
+		t.Errorf("vendored = %d files in %v", c.VendoredFiles, c.VendoredDirs)
+	}
+	if len(c.GeneratedFiles) != 2 || c.GeneratedBytes != 13000 {
+		t.Errorf("generated = %v (%d bytes)", c.GeneratedFiles, c.GeneratedBytes)
+	}
+
+	filtered := c.FilterTree(tree)
+	if len(filtered) != len(tree)-4 {
+		t.Errorf("FilterTree kept %d of %d entries", len(filtered), len(tree))
+	}
+	if c.Excluded("main.go") || !c.Excluded("api/service.pb.go") {
+		t.Error("Excluded misclassifies files")
+	}
+
+	own := c.OwnLanguages(map[string]int{"Go": 9400, "JavaScript": 200, "Shell": 50})
+	if len(own) != 2 || own["Go"] != 100 || own["Shell"] != 50 {
+		t.Errorf("OwnLanguages = %v, want Go 100 and Shell 50", own)
+	}
+}
+
+func TestCheckGeneratedHeaders(t *testing.T) {
+	source := mapFileSource{
+		"big.go":    "// Code generated by protoc-gen-go. DO NOT EDIT.\n\npackage api\n",
+		"small.go":  "// Code generated by stringer. DO NOT EDIT.\npackage api\n",
+		"schema.ts": "/* eslint-disable */\n// @generated by graphql-codegen\nexport type A = {};\n",
+		"main.go":   "package main\n\n// This comment mentions code generated elsewhere.\nfunc main() {}\n",
+	}
+	tree := []github.TreeEntry{
+		{Path: "big.go", Type: "blob", Size: 900},
+		{Path: "small.go", Type: "blob", Size: 10},
+		{Path: "schema.ts", Type: "blob", Size: 500},
+		{Path: "main.go", Type: "blob", Size: 800},
+	}
+
+	c := ClassifyFiles(tree)
+	c.CheckGeneratedHeaders(source, 3)
+
+	if c.HeadersChecked != 3 {
+		t.Errorf("HeadersChecked = %d, want the 3 largest files", c.HeadersChecked)
+	}
+	if !c.IsGenerated("big.go") || !c.IsGenerated("schema.ts") {
+		t.Errorf("generated = %v", c.GeneratedFiles)
+	}
+	if c.IsGenerated("main.go") || c.IsGenerated("small.go") {
+		t.Errorf("unexpected generated files: %v", c.GeneratedFiles)
+	}
+}
+
+func TestAnalyzeCodeQuality_ExcludesVendoredAndGenerated(t *testing.T) {
+	tree := []github.TreeEntry{
+		{Path: "main.go", Type: "blob"},
+		{Path: "api/service.pb.go", Type: "blob"},
+		{Path: "vendor/github.com/x/y/y.go", Type: "blob"},
+		{Path: "vendor/github.com/x/y/y_test.go", Type: "blob"},
+	}
+
+	metrics := AnalyzeCodeQuality(nil, tree, nil)
+
+	if metrics.FileStats.TotalFiles != 1 || metrics.FileStats.SourceFiles != 1 {
+		t.Errorf("FileStats = %+v, want only main.go counted", metrics.FileStats)
+	}
+	if metrics.FileStats.ExcludedFiles != 3 {
+		t.Errorf("ExcludedFiles = %d, want 3", metrics.FileStats.ExcludedFiles)
+	}
+	if metrics.HasTests || metrics.FileStats.TestRatio != 0 {
+		t.Error("vendored tests should not count as project tests")
+	}
+}
@@ -1 +1,140 @@
 package analyzer
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/agnivo988/Repo-lyzer/internal/github"
+)
+
+// FileSource reads file contents by repository-relative path. It lets
+// content-based analyzers run against the GitHub API or a local checkout.
+type FileSource interface {
+	ReadFile(path string) ([]byte, error)
+}
+
+// GitHubFileSource reads files through the GitHub contents API
+type GitHubFileSource struct {
+	Client *github.Client
+	Owner  string
+	Repo   string
+}
+
+// ReadFile fetches and decodes a file from GitHub
+func (s GitHubFileSource) ReadFile(path string) ([]byte, error) {
+	return fetchFile(s.Client, s.Owner, s.Repo, path)
+}
+
+// GitHubBlobSource reads files of a specific tree (for example a tag) by
+// blob SHA. Blobs are cached by SHA, so sources sharing a cache fetch files
+// that are identical between two trees only once.
+type GitHubBlobSource struct {
+	client *github.Client
+	owner  string
+	repo   string
+	shas   map[string]string
+	cache  map[string][]byte
+}
+
+// NewGitHubBlobSource creates a source for the files of tree. cache may be
+// shared between sources and may be nil.
+func NewGitHubBlobSource(client *github.Client, owner, repo string, tree []github.TreeEntry, cache map[string][]byte) *GitHubBlobSource {
+	if cache == nil {
+		cache = make(map[string][]byte)
+	}
+	shas := make(map[string]string, len(tree))
+	for _, entry := range tree {
+		if entry.Type == "blob" {
+			shas[entry.Path] = entry.Sha
+		}
+	}
+	return &GitHubBlobSource{client: client, owner: owner, repo: repo, shas: shas, cache: cache}
+}
+
+// ReadFile fetches and decodes the blob for path
+func (s *GitHubBlobSource) ReadFile(path string) ([]byte, error) {
+	sha, ok := s.shas[path]
+	if !ok {
+		return nil, fmt.Errorf("%s: not in tree", path)
+	}
+	if content, ok := s.cache[sha]; ok {
+		return content, nil
+	}
+	encoded, err := s.client.GetBlob(s.owner, s.repo, sha)
+	if err != nil {
+		return nil, err
+	}
+	content, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, err
+	}
+	s.cache[sha] = content
+	return content, nil
+}
+
+// LocalFileSource reads files from a checkout on disk
+type LocalFileSource struct {
+	Root string
+}
+
+// ReadFile reads a file relative to the checkout root
+func (s LocalFileSource) ReadFile(path string) ([]byte, error) {
+	return os.ReadFile(filepath.Join(s.Root, filepath.FromSlash(path)))
+}
+
+// LocalFileTree walks a checkout and returns its files as tree entries, so
+// that the same analyzers can be used on local and remote repositories.
+// The .git directory is skipped.
+func LocalFileTree(root string) ([]github.TreeEntry, error) {
+	var entries []github.TreeEntry
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil || rel == "." {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if info.IsDir() {
+			if info.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			entries = append(entries, github.TreeEntry{Path: rel, Type: "tree"})
+			return nil
+		}
+		if info.Mode().IsRegular() {
+			entries = append(entries, github.TreeEntry{Path: rel, Type: "blob", Size: int(info.Size())})
+		}
+		return nil
+	})
+	return entries, err
+}
+
+// fetchFile fetches a file through the GitHub contents API and decodes it.
+func fetchFile(client *github.Client, owner, repo, path string) ([]byte, error) {
+	content, err := client.GetFileContent(owner, repo, path)
+	if err != nil {
+		return nil, err
+	}
+	return base64.StdEncoding.DecodeString(content)
+}
+
+// baseName returns the last element of a slash-separated tree path.
+func baseName(path string) string {
+	return path[strings.LastIndex(path, "/")+1:]
+}
+
+// isVendoredPath reports whether a lower-cased path lives in a directory of
+// third-party code that should not count towards the project's own metrics.
+func isVendoredPath(lowerPath string) bool {
+	for _, dir := range []string{"node_modules/", "vendor/", "third_party/", "bower_components/"} {
+		if strings.HasPrefix(lowerPath, dir) || strings.Contains(lowerPath, "/"+dir) {
+			return true
+		}
+	}
+	return false
+}
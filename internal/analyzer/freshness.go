@@ -0,0 +1,271 @@
+// Package analyzer provides analysis functions for GitHub repositories.
+// This file measures how far a project's dependencies have drifted behind
+// their latest releases.
+package analyzer
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Drift levels of a dependency, from none to most
+const (
+	DriftCurrent = "current"
+	DriftPatch   = "patch"
+	DriftMinor   = "minor"
+	DriftMajor   = "major"
+)
+
+// FreshnessOptions configures AnalyzeFreshness
+type FreshnessOptions struct {
+	Registry    RegistryOptions
+	Concurrency int // maximum number of registry lookups in flight
+}
+
+// DefaultFreshnessOptions returns options for the public registries
+func DefaultFreshnessOptions() FreshnessOptions {
+	return FreshnessOptions{Registry: DefaultRegistryOptions(), Concurrency: 8}
+}
+
+// DependencyFreshness is how far one direct dependency is behind its
+// latest stable release
+type DependencyFreshness struct {
+	FileType        string    `json:"file_type"`
+	Name            string    `json:"name"`
+	Current         string    `json:"current"`
+	Locked          bool      `json:"locked"` // Current comes from a lockfile, not a manifest range
+	Latest          string    `json:"latest"`
+	Drift           string    `json:"drift"`
+	MajorsBehind    int       `json:"majors_behind"`
+	Libyear         float64   `json:"libyear"` // years between the releases of Current and Latest
+	CurrentReleased time.Time `json:"current_released,omitempty"`
+	LatestReleased  time.Time `json:"latest_released,omitempty"`
+	Dev             bool      `json:"dev"`
+}
+
+// FreshnessReport sums up the drift of a project's direct dependencies
+type FreshnessReport struct {
+	Packages      []DependencyFreshness `json:"packages"` // most drift first
+	Checked       int                   `json:"checked"`
+	OutdatedCount int                   `json:"outdated_count"`
+	MajorCount    int                   `json:"major_count"` // at least one major version behind
+	TotalLibyear  float64               `json:"total_libyear"`
+	// Score is 100 when every dependency is on its latest release. Each
+	// year of drift costs a dependency a quarter of its share, each major
+	// version behind 15%.
+	Score         int      `json:"score"`
+	FailedLookups int      `json:"failed_lookups"`
+	Errors        []string `json:"errors,omitempty"`
+}
+
+// Outdated returns the dependencies behind their latest release, most
+// drift first
+func (r *FreshnessReport) Outdated() []DependencyFreshness {
+	var outdated []DependencyFreshness
+	for _, p := range r.Packages {
+		if p.Drift != DriftCurrent {
+			outdated = append(outdated, p)
+		}
+	}
+	return outdated
+}
+
+// AnalyzeFreshness compares the version of every direct dependency, as
+// locked or else as declared, with the latest stable release in its
+// registry. Dependencies declared with an open range or a wildcard, and
+// those of ecosystems without a supported registry, are not checked.
+func AnalyzeFreshness(deps *DependencyAnalysis, opts FreshnessOptions) *FreshnessReport {
+	report := &FreshnessReport{Packages: []DependencyFreshness{}, Score: 100}
+	if deps == nil {
+		return report
+	}
+
+	index := make(map[string]int)
+	for _, file := range deps.Files {
+		if file.FileType == "docker" || mapEcosystem(file.FileType) == "" {
+			continue
+		}
+		for _, dep := range file.Dependencies {
+			if dep.Transitive {
+				continue
+			}
+			p := DependencyFreshness{FileType: file.FileType, Name: dep.Name, Current: dep.Resolved, Locked: dep.Resolved != "", Dev: dep.Type == "dev"}
+			if p.Current == "" {
+				p.Current = declaredVersion(dep.Version)
+			}
+			if p.Current == "" {
+				continue
+			}
+			key := graphNodeID(file.FileType, lockKey(file.FileType, dep.Name), p.Current)
+			if i, ok := index[key]; ok {
+				report.Packages[i].Dev = report.Packages[i].Dev && p.Dev
+				continue
+			}
+			index[key] = len(report.Packages)
+			report.Packages = append(report.Packages, p)
+		}
+	}
+
+	registry := newRegistryClient(opts.Registry)
+	failed := make([]bool, len(report.Packages))
+	var mu sync.Mutex
+	forEachConcurrently(len(report.Packages), opts.Concurrency, func(i int) {
+		// Each call writes only its own package
+		p := &report.Packages[i]
+		rel, err := registry.releases(p.FileType, p.Name, p.Current)
+		if err == nil && (rel == nil || rel.latest == "") {
+			err = errNotInRegistry
+		}
+		if err != nil {
+			failed[i] = true
+			if errors.Is(err, errNotInRegistry) {
+				return
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			report.FailedLookups++
+			if len(report.Errors) < maxComplianceErrors {
+				report.Errors = append(report.Errors, fmt.Sprintf("%s: %v", p.Name, err))
+			}
+			return
+		}
+		measureDrift(p, rel)
+	})
+
+	// Packages the registries don't know, like private ones, are left out
+	checked := report.Packages[:0]
+	for i, p := range report.Packages {
+		if !failed[i] {
+			checked = append(checked, p)
+		}
+	}
+	report.Packages = checked
+	report.Checked = len(checked)
+
+	freshness := 0.0
+	for _, p := range report.Packages {
+		if p.Drift != DriftCurrent {
+			report.OutdatedCount++
+		}
+		if p.Drift == DriftMajor {
+			report.MajorCount++
+		}
+		report.TotalLibyear += p.Libyear
+		freshness += math.Max(0, 1-0.25*p.Libyear-0.15*float64(p.MajorsBehind))
+	}
+	if report.Checked > 0 {
+		report.Score = int(math.Round(100 * freshness / float64(report.Checked)))
+	}
+	report.TotalLibyear = math.Round(report.TotalLibyear*100) / 100
+
+	sort.SliceStable(report.Packages, func(i, j int) bool {
+		a, b := report.Packages[i], report.Packages[j]
+		if a.Libyear != b.Libyear {
+			return a.Libyear > b.Libyear
+		}
+		if a.MajorsBehind != b.MajorsBehind {
+			return a.MajorsBehind > b.MajorsBehind
+		}
+		return a.Name < b.Name
+	})
+	return report
+}
+
+// measureDrift compares a dependency's version with the latest release
+func measureDrift(p *DependencyFreshness, rel *packageReleases) {
+	ecosystem := mapEcosystem(p.FileType)
+	p.Latest = rel.latest
+	p.CurrentReleased, _ = rel.publishedAt(ecosystem, p.Current)
+	p.LatestReleased, _ = rel.publishedAt(ecosystem, p.Latest)
+	if CompareEcosystemVersions(ecosystem, p.Current, p.Latest) >= 0 {
+		p.Drift = DriftCurrent
+		return
+	}
+
+	curMajor, curMinor, okCur := majorMinor(ecosystem, p.Current)
+	latMajor, latMinor, okLat := majorMinor(ecosystem, p.Latest)
+	switch {
+	case !okCur || !okLat:
+		p.Drift = DriftMajor
+	case curMajor != latMajor:
+		p.Drift, p.MajorsBehind = DriftMajor, max(latMajor-curMajor, 0)
+	case curMajor == 0 && curMinor != latMinor:
+		// Below 1.0, minor releases may break compatibility
+		p.Drift, p.MajorsBehind = DriftMajor, max(latMinor-curMinor, 0)
+	case curMinor != latMinor:
+		p.Drift = DriftMinor
+	default:
+		p.Drift = DriftPatch
+	}
+	if !p.CurrentReleased.IsZero() && p.LatestReleased.After(p.CurrentReleased) {
+		years := p.LatestReleased.Sub(p.CurrentReleased).Hours() / (24 * 365.25)
+		p.Libyear = math.Round(years*100) / 100
+	}
+}
+
+// majorMinor returns the first two numbers of a version's release
+func majorMinor(ecosystem, version string) (int, int, bool) {
+	if ecosystem == "PyPI" {
+		v, ok := parsePEP440(version)
+		if !ok {
+			return 0, 0, false
+		}
+		minor := 0
+		if len(v.release) > 1 {
+			minor = v.release[1]
+		}
+		return v.release[0], minor, true
+	}
+	core := strings.TrimPrefix(strings.TrimSpace(version), "v")
+	core, _, _ = strings.Cut(core, "+")
+	core, _, _ = strings.Cut(core, "-")
+	parts := strings.Split(core, ".")
+	major, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, 0, false
+	}
+	minor := 0
+	if len(parts) > 1 {
+		if minor, err = strconv.Atoi(parts[1]); err != nil {
+			return 0, 0, false
+		}
+	}
+	return major, minor, true
+}
+
+// declaredVersion returns the version a manifest entry pins or starts its
+// range at: "4.18.0" for "^4.18.0", "2.31" for ">=2.31,<3". Ranges with
+// only an upper bound and wildcards give "".
+func declaredVersion(spec string) string {
+	v := strings.TrimSpace(spec)
+	if i := strings.Index(v, "||"); i >= 0 {
+		v = v[:i]
+	}
+	v, _, _ = strings.Cut(v, ",")
+	v = strings.TrimSpace(v)
+	if strings.HasPrefix(v, "<") || strings.HasPrefix(v, "!=") {
+		return ""
+	}
+	v = strings.TrimSpace(strings.TrimLeft(v, "^~=>! "))
+	v, _, _ = strings.Cut(v, " ")
+	if !versionStart.MatchString(v) {
+		return ""
+	}
+	core, _, _ := strings.Cut(v, "-")
+	for _, part := range strings.Split(core, ".") {
+		if part == "*" || part == "x" || part == "X" {
+			return ""
+		}
+	}
+	return exactVersion(v)
+}
+
+// versionStart matches strings that begin like a version number
+var versionStart = regexp.MustCompile(`^v?[0-9]`)
@@ -0,0 +1,196 @@
+package analyzer
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// fakeReleaseRegistries serves release histories in the format of each
+// registry, all from one server
+func fakeReleaseRegistries(t *testing.T) *httptest.Server {
+	responses := map[string]string{
+		"/express": `{"dist-tags":{"latest":"5.1.0","next":"6.0.0-beta.1"},"time":{
+			"created":"2010-12-29T19:38:25.450Z","modified":"2025-03-31T00:00:00.000Z",
+			"4.17.1":"2019-05-26T04:25:34.606Z","4.18.2":"2022-10-08T20:20:04.000Z","5.1.0":"2025-03-31T14:00:00.000Z"}}`,
+		"/lodash": `{"dist-tags":{"latest":"4.17.21"},"time":{"4.17.21":"2021-02-20T15:42:16.891Z"}}`,
+		"/pypi/requests/json": `{"info":{"version":"2.32.3"},"releases":{
+			"2.28.0":[{"upload_time_iso_8601":"2022-06-09T14:44:36.935Z"},{"upload_time_iso_8601":"2022-06-09T14:44:30.000Z"}],
+			"2.32.3":[{"upload_time_iso_8601":"2024-05-29T15:37:47.027Z"}],
+			"3.0.0a1":[{"upload_time_iso_8601":"2024-06-01T00:00:00Z"}]}}`,
+		"/api/v1/crates/serde": `{"crate":{"max_stable_version":"1.0.219","max_version":"1.0.219"},"versions":[
+			{"num":"1.0.219","created_at":"2025-03-09T00:00:00Z"},{"num":"0.9.15","created_at":"2017-04-01T00:00:00Z"}]}`,
+		"/api/v1/versions/rails.json": `[{"number":"8.0.0.rc1","created_at":"2024-10-01T00:00:00Z","prerelease":true},
+			{"number":"7.2.1","created_at":"2024-08-22T00:00:00Z","prerelease":false},
+			{"number":"6.1.7","created_at":"2022-09-09T00:00:00Z","prerelease":false}]`,
+		"/github.com/!burnt!sushi/toml/@v/list":        "v1.2.0\nv1.4.0\nv1.5.0-rc.1\n",
+		"/github.com/!burnt!sushi/toml/@v/v1.4.0.info": `{"Version":"v1.4.0","Time":"2024-06-01T00:00:00Z"}`,
+		"/github.com/!burnt!sushi/toml/@v/v1.2.0.info": `{"Version":"v1.2.0","Time":"2022-06-01T00:00:00Z"}`,
+		"/example.com/pseudo/@v/list":                  "",
+		"/example.com/pseudo/@latest":                  `{"Version":"v0.0.0-20240101000000-abcdef123456","Time":"2024-01-01T00:00:00Z"}`,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := responses[r.URL.Path]
+		switch {
+		case r.URL.Path == "/broken":
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+		case !ok && strings.HasPrefix(r.URL.Path, "/example.com/"):
+			http.Error(w, "gone", http.StatusGone)
+		case !ok:
+			http.NotFound(w, r)
+		default:
+			w.Write([]byte(body))
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func fakeReleaseOptions(server *httptest.Server) FreshnessOptions {
+	return FreshnessOptions{
+		Registry: RegistryOptions{NPMURL: server.URL, PyPIURL: server.URL, CratesURL: server.URL,
+			RubyGemsURL: server.URL, GoProxyURL: server.URL},
+		Concurrency: 2,
+	}
+}
+
+func TestRegistryReleases(t *testing.T) {
+	server := fakeReleaseRegistries(t)
+	client := newRegistryClient(fakeReleaseOptions(server).Registry)
+
+	tests := []struct {
+		fileType, name, current, latest string
+		dates                           int
+	}{
+		{"npm", "express", "4.18.2", "5.1.0", 3},
+		{"python", "requests", "2.28.0", "2.32.3", 3},
+		{"rust", "serde", "0.9.15", "1.0.219", 2},
+		{"ruby", "rails", "6.1.7", "7.2.1", 3},
+		// Prereleases are skipped; only the needed dates are fetched
+		{"go", "github.com/BurntSushi/toml", "v1.2.0", "v1.4.0", 2},
+		{"go", "example.com/pseudo", "", "v0.0.0-20240101000000-abcdef123456", 1},
+	}
+	for _, tt := range tests {
+		rel, err := client.releases(tt.fileType, tt.name, tt.current)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if rel.latest != tt.latest || len(rel.published) != tt.dates {
+			t.Errorf("%s = %s with %d dates, want %s with %d", tt.name, rel.latest, len(rel.published), tt.latest, tt.dates)
+		}
+	}
+
+	rel, _ := client.releases("python", "requests", "")
+	if got, _ := rel.publishedAt("PyPI", "2.28"); got.Format("15:04:05") != "14:44:30" {
+		t.Errorf("a release is dated by its first upload and matched by PEP 440 equality: %v", got)
+	}
+	if rel, err := client.releases("composer", "monolog/monolog", ""); rel != nil || err != nil {
+		t.Errorf("unsupported registry = %v, %v", rel, err)
+	}
+}
+
+func TestEscapeModulePath(t *testing.T) {
+	if got := escapeModulePath("github.com/BurntSushi/toml"); got != "github.com/!burnt!sushi/toml" {
+		t.Errorf("escapeModulePath = %s", got)
+	}
+}
+
+func TestDeclaredVersion(t *testing.T) {
+	tests := map[string]string{
+		"^4.18.0":       "4.18.0",
+		"~1.2.3":        "1.2.3",
+		">=2.31,<3":     "2.31",
+		"==2.0.1":       "2.0.1",
+		"~> 7.1":        "7.1",
+		"1.x":           "",
+		"*":             "",
+		"<2.0":          "",
+		"latest":        "",
+		"^1.0.0 || ^2":  "1.0.0",
+		"v1.9.1":        "v1.9.1",
+		"":              "",
+		"git+https://x": "",
+	}
+	for spec, want := range tests {
+		if got := declaredVersion(spec); got != want {
+			t.Errorf("declaredVersion(%q) = %q, want %q", spec, got, want)
+		}
+	}
+}
+
+func TestAnalyzeFreshness(t *testing.T) {
+	server := fakeReleaseRegistries(t)
+	deps := &DependencyAnalysis{}
+	deps.AddFiles(
+		DependencyFile{Filename: "package.json", FileType: "npm", Dependencies: []Dependency{
+			{Name: "express", Version: "^4.18.0", Resolved: "4.18.2", Type: "production"},
+			{Name: "lodash", Version: "^4.17.21", Type: "production"},
+			{Name: "private-pkg", Version: "1.0.0", Type: "production"},
+			{Name: "ms", Version: "2.1.3", Resolved: "2.1.3", Type: "production", Transitive: true},
+			{Name: "anything", Version: "*", Type: "dev"},
+		}},
+		DependencyFile{Filename: "requirements.txt", FileType: "python", Dependencies: []Dependency{
+			{Name: "requests", Version: "==2.28.0", Type: "production"},
+		}},
+		DependencyFile{Filename: "Cargo.toml", FileType: "rust", Dependencies: []Dependency{
+			{Name: "serde", Version: "0.9.15", Type: "production"},
+		}},
+		DependencyFile{Filename: "go.mod", FileType: "go", Dependencies: []Dependency{
+			{Name: "github.com/BurntSushi/toml", Version: "v1.2.0", Type: "production"},
+		}},
+	)
+
+	report := AnalyzeFreshness(deps, fakeReleaseOptions(server))
+	byName := make(map[string]DependencyFreshness)
+	for _, p := range report.Packages {
+		byName[p.Name] = p
+	}
+	if report.Checked != 5 || report.OutdatedCount != 4 || report.MajorCount != 2 || report.FailedLookups != 0 {
+		t.Fatalf("report = %+v", report)
+	}
+
+	if p := byName["express"]; p.Drift != DriftMajor || p.MajorsBehind != 1 || !p.Locked || p.Libyear != 2.48 {
+		t.Errorf("express = %+v", p)
+	}
+	// Below 1.0 a minor release counts as a major one
+	if p := byName["serde"]; p.Drift != DriftMajor || p.MajorsBehind != 1 || p.Libyear < 7.9 {
+		t.Errorf("serde = %+v", p)
+	}
+	if p := byName["requests"]; p.Drift != DriftMinor || p.Locked || p.Libyear != 1.97 {
+		t.Errorf("requests = %+v", p)
+	}
+	if p := byName["github.com/BurntSushi/toml"]; p.Drift != DriftMinor || p.Libyear != 2 {
+		t.Errorf("toml = %+v", p)
+	}
+	if p := byName["lodash"]; p.Drift != DriftCurrent || p.Libyear != 0 {
+		t.Errorf("lodash = %+v", p)
+	}
+
+	// Sorted by libyear, most drift first
+	if report.Packages[0].Name != "serde" || report.Packages[len(report.Packages)-1].Name != "lodash" || len(report.Outdated()) != 4 {
+		t.Errorf("order: %s ... %s", report.Packages[0].Name, report.Packages[len(report.Packages)-1].Name)
+	}
+	if report.Score != 45 || report.TotalLibyear < 14 {
+		t.Errorf("score %d, libyear %.2f", report.Score, report.TotalLibyear)
+	}
+}
+
+func TestAnalyzeFreshnessLookupErrors(t *testing.T) {
+	server := fakeReleaseRegistries(t)
+	deps := &DependencyAnalysis{}
+	deps.AddFiles(DependencyFile{Filename: "package.json", FileType: "npm", Dependencies: []Dependency{
+		{Name: "lodash", Version: "4.17.21", Type: "production"},
+	}})
+	opts := fakeReleaseOptions(server)
+	opts.Registry.NPMURL = server.URL + "/broken?"
+
+	report := AnalyzeFreshness(deps, opts)
+	if report.FailedLookups != 1 || len(report.Errors) != 1 || report.Checked != 0 || report.Score != 100 {
+		t.Errorf("report = %+v", report)
+	}
+	if empty := AnalyzeFreshness(nil, opts); empty.Checked != 0 {
+		t.Errorf("nil analysis = %+v", empty)
+	}
+}
@@ -0,0 +1,376 @@
+// Package analyzer provides functions for analyzing GitHub repository data.
+// This file implements Go-specific static analysis using go/parser.
+package analyzer
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/agnivo988/Repo-lyzer/internal/github"
+)
+
+// GoFunctionComplexity is the cyclomatic complexity of a single function
+type GoFunctionComplexity struct {
+	Name       string `json:"name"` // Recv.Method or Func
+	Package    string `json:"package"`
+	Location   string `json:"location"`
+	Complexity int    `json:"complexity"`
+}
+
+// GoAnalysis contains metrics computed from parsed Go source files
+type GoAnalysis struct {
+	FilesParsed        int                    `json:"files_parsed"`
+	FilesEligible      int                    `json:"files_eligible"`
+	ParseErrors        int                    `json:"parse_errors"`
+	GeneratedFiles     int                    `json:"generated_files"` // parsed but skipped
+	BudgetExhausted    bool                   `json:"budget_exhausted"`
+	Packages           int                    `json:"packages"`
+	PackagesWithoutDoc []string               `json:"packages_without_doc"`
+	Functions          int                    `json:"functions"`
+	AvgComplexity      float64                `json:"avg_complexity"`
+	MostComplex        []GoFunctionComplexity `json:"most_complex"`
+	ExportedFuncs      int                    `json:"exported_funcs"` // including methods on exported types
+	ExportedTypes      int                    `json:"exported_types"`
+	ExportedValues     int                    `json:"exported_values"` // consts and vars
+	TestFuncs          int                    `json:"test_funcs"`
+	BenchmarkFuncs     int                    `json:"benchmark_funcs"`
+	FuzzFuncs          int                    `json:"fuzz_funcs"`
+	ExampleFuncs       int                    `json:"example_funcs"`
+	UnsafeFiles        []string               `json:"unsafe_files"`
+	CgoFiles           []string               `json:"cgo_files"`
+	InitFuncs          []string               `json:"init_funcs"` // locations of init() functions
+
+	topN            int
+	totalComplexity int
+	complexities    []GoFunctionComplexity
+	packageDocs     map[string]bool // package dir -> has a doc comment
+	unsampled       map[string]bool // package dirs with non-test files left unparsed
+}
+
+// GoAnalysisOptions controls which Go files are parsed
+type GoAnalysisOptions struct {
+	MaxFiles    int // 0 means no limit
+	MaxFileSize int // bytes; larger files (usually generated) are skipped
+	TopN        int // number of most complex functions to keep
+}
+
+// DefaultGoAnalysisOptions returns the budget used over the GitHub API
+func DefaultGoAnalysisOptions() GoAnalysisOptions {
+	return GoAnalysisOptions{
+		MaxFiles:    80,
+		MaxFileSize: 256 * 1024,
+		TopN:        10,
+	}
+}
+
+// ExportedAPI returns the number of exported identifiers in importable packages
+func (g *GoAnalysis) ExportedAPI() int {
+	return g.ExportedFuncs + g.ExportedTypes + g.ExportedValues
+}
+
+// TestToExportedRatio returns test functions per exported function
+func (g *GoAnalysis) TestToExportedRatio() float64 {
+	if g.ExportedFuncs == 0 {
+		return 0
+	}
+	return float64(g.TestFuncs) / float64(g.ExportedFuncs)
+}
+
+// AnalyzeGo parses the repository's Go files and computes complexity, API
+// surface and test metrics. Files are spread across package directories so
+// that a limited budget still samples every package.
+func AnalyzeGo(source FileSource, fileTree []github.TreeEntry, opts GoAnalysisOptions) (*GoAnalysis, error) {
+	g := NewGoAnalysis(opts.TopN)
+
+	byDir := make(map[string][]string)
+	var dirs []string
+	for _, entry := range fileTree {
+		if entry.Type != "blob" || !strings.HasSuffix(entry.Path, ".go") {
+			continue
+		}
+		if opts.MaxFileSize > 0 && entry.Size > opts.MaxFileSize {
+			continue
+		}
+		if isVendoredPath(strings.ToLower(entry.Path)) || strings.Contains(entry.Path, "testdata/") {
+			continue
+		}
+		dir := path.Dir(entry.Path)
+		if _, ok := byDir[dir]; !ok {
+			dirs = append(dirs, dir)
+		}
+		byDir[dir] = append(byDir[dir], entry.Path)
+		g.FilesEligible++
+	}
+
+	// The package clause, and usually the package doc, is in doc.go or
+	// another non-test file, so those are sampled before tests
+	for _, files := range byDir {
+		sort.SliceStable(files, func(i, j int) bool { return goSampleRank(files[i]) < goSampleRank(files[j]) })
+	}
+	parsed := make(map[string]bool)
+	for _, p := range pickRoundRobin(byDir, dirs, opts.MaxFiles) {
+		content, err := source.ReadFile(p)
+		if err != nil {
+			continue
+		}
+		parsed[p] = g.AddFile(p, content) == nil
+	}
+	// A package can only be called undocumented if every file was read
+	for dir, files := range byDir {
+		for _, p := range files {
+			if !parsed[p] && !strings.HasSuffix(p, "_test.go") {
+				g.unsampled[dir] = true
+			}
+		}
+	}
+	g.BudgetExhausted = g.FilesParsed+g.ParseErrors < g.FilesEligible
+	g.Finish()
+
+	return g, nil
+}
+
+// goSampleRank orders a package's files for sampling: doc.go, other
+// source files, then tests
+func goSampleRank(p string) int {
+	switch {
+	case path.Base(p) == "doc.go":
+		return 0
+	case strings.HasSuffix(p, "_test.go"):
+		return 2
+	}
+	return 1
+}
+
+// NewGoAnalysis creates an empty analysis keeping the topN most complex functions
+func NewGoAnalysis(topN int) *GoAnalysis {
+	return &GoAnalysis{
+		PackagesWithoutDoc: []string{},
+		MostComplex:        []GoFunctionComplexity{},
+		UnsafeFiles:        []string{},
+		CgoFiles:           []string{},
+		InitFuncs:          []string{},
+		topN:               topN,
+		packageDocs:        make(map[string]bool),
+		unsampled:          make(map[string]bool),
+	}
+}
+
+// AddFile parses one Go source file and adds its metrics
+func (g *GoAnalysis) AddFile(filePath string, content []byte) error {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filePath, content, parser.ParseComments)
+	if err != nil {
+		g.ParseErrors++
+		return err
+	}
+	g.FilesParsed++
+
+	// Generated code would inflate complexity and API size
+	if ast.IsGenerated(file) {
+		g.GeneratedFiles++
+		return nil
+	}
+
+	isTest := strings.HasSuffix(filePath, "_test.go")
+	dir := path.Dir(filePath)
+	pkgName := file.Name.Name
+	public := isPublicGoPackage(dir, pkgName) && !isTest
+
+	if !isTest {
+		if _, ok := g.packageDocs[dir]; !ok {
+			g.packageDocs[dir] = false
+		}
+		if file.Doc != nil {
+			g.packageDocs[dir] = true
+		}
+	}
+
+	for _, imp := range file.Imports {
+		importPath, _ := strconv.Unquote(imp.Path.Value)
+		switch importPath {
+		case "unsafe":
+			g.UnsafeFiles = append(g.UnsafeFiles, filePath)
+		case "C":
+			g.CgoFiles = append(g.CgoFiles, filePath)
+		}
+	}
+
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			g.addFunc(fset, filePath, pkgName, d, isTest, public)
+		case *ast.GenDecl:
+			if public {
+				g.addGenDecl(d)
+			}
+		}
+	}
+
+	return nil
+}
+
+// addFunc records complexity, exported API and test counts for a function
+func (g *GoAnalysis) addFunc(fset *token.FileSet, filePath, pkgName string, fn *ast.FuncDecl, isTest, public bool) {
+	name := fn.Name.Name
+	recv := receiverTypeName(fn)
+	location := fmt.Sprintf("%s:%d", filePath, fset.Position(fn.Pos()).Line)
+
+	if recv == "" && name == "init" && !isTest {
+		g.InitFuncs = append(g.InitFuncs, location)
+	}
+
+	if isTest && recv == "" {
+		switch {
+		case isGoTestFunc(name, "Test"):
+			g.TestFuncs++
+		case isGoTestFunc(name, "Benchmark"):
+			g.BenchmarkFuncs++
+		case isGoTestFunc(name, "Fuzz"):
+			g.FuzzFuncs++
+		case isGoTestFunc(name, "Example") || name == "Example":
+			g.ExampleFuncs++
+		}
+	}
+
+	if public && ast.IsExported(name) && (recv == "" || ast.IsExported(recv)) {
+		g.ExportedFuncs++
+	}
+
+	if fn.Body == nil {
+		return
+	}
+	if recv != "" {
+		name = recv + "." + name
+	}
+	complexity := CyclomaticComplexity(fn)
+	g.Functions++
+	g.totalComplexity += complexity
+	g.complexities = append(g.complexities, GoFunctionComplexity{
+		Name:       name,
+		Package:    pkgName,
+		Location:   location,
+		Complexity: complexity,
+	})
+}
+
+// addGenDecl counts exported types, constants and variables
+func (g *GoAnalysis) addGenDecl(d *ast.GenDecl) {
+	for _, spec := range d.Specs {
+		switch s := spec.(type) {
+		case *ast.TypeSpec:
+			if s.Name.IsExported() {
+				g.ExportedTypes++
+			}
+		case *ast.ValueSpec:
+			for _, n := range s.Names {
+				if n.IsExported() {
+					g.ExportedValues++
+				}
+			}
+		}
+	}
+}
+
+// Finish computes the summary fields once all files have been added
+func (g *GoAnalysis) Finish() {
+	g.Packages = len(g.packageDocs)
+	g.PackagesWithoutDoc = g.PackagesWithoutDoc[:0]
+	for dir, documented := range g.packageDocs {
+		if !documented && !g.unsampled[dir] {
+			g.PackagesWithoutDoc = append(g.PackagesWithoutDoc, dir)
+		}
+	}
+	sort.Strings(g.PackagesWithoutDoc)
+
+	if g.Functions > 0 {
+		g.AvgComplexity = float64(g.totalComplexity) / float64(g.Functions)
+	}
+
+	sorted := make([]GoFunctionComplexity, len(g.complexities))
+	copy(sorted, g.complexities)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Complexity > sorted[j].Complexity
+	})
+	if g.topN > 0 && len(sorted) > g.topN {
+		sorted = sorted[:g.topN]
+	}
+	g.MostComplex = sorted
+}
+
+// CyclomaticComplexity returns 1 plus the number of decision points in a
+// function: if, for, range, non-default case and select clauses, && and ||.
+func CyclomaticComplexity(fn *ast.FuncDecl) int {
+	complexity := 1
+	ast.Inspect(fn, func(n ast.Node) bool {
+		switch x := n.(type) {
+		case *ast.IfStmt, *ast.ForStmt, *ast.RangeStmt:
+			complexity++
+		case *ast.CaseClause:
+			if x.List != nil {
+				complexity++
+			}
+		case *ast.CommClause:
+			if x.Comm != nil {
+				complexity++
+			}
+		case *ast.BinaryExpr:
+			if x.Op == token.LAND || x.Op == token.LOR {
+				complexity++
+			}
+		}
+		return true
+	})
+	return complexity
+}
+
+// receiverTypeName returns the receiver's type name, or "" for plain functions
+func receiverTypeName(fn *ast.FuncDecl) string {
+	if fn.Recv == nil || len(fn.Recv.List) == 0 {
+		return ""
+	}
+	expr := fn.Recv.List[0].Type
+	for {
+		switch t := expr.(type) {
+		case *ast.StarExpr:
+			expr = t.X
+		case *ast.IndexExpr:
+			expr = t.X
+		case *ast.IndexListExpr:
+			expr = t.X
+		case *ast.Ident:
+			return t.Name
+		default:
+			return ""
+		}
+	}
+}
+
+// isGoTestFunc reports whether name is prefix followed by an upper-case
+// letter, digit or underscore, as required by go test
+func isGoTestFunc(name, prefix string) bool {
+	if !strings.HasPrefix(name, prefix) || len(name) == len(prefix) {
+		return false
+	}
+	c := name[len(prefix)]
+	return c == '_' || (c >= '0' && c <= '9') || (c >= 'A' && c <= 'Z')
+}
+
+// isPublicGoPackage reports whether other modules can import a package:
+// main packages and anything under an internal directory are excluded.
+func isPublicGoPackage(dir, pkgName string) bool {
+	if pkgName == "main" {
+		return false
+	}
+	for _, part := range strings.Split(dir, "/") {
+		if part == "internal" {
+			return false
+		}
+	}
+	return true
+}
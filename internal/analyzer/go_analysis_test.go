@@ -0,0 +1,167 @@
+package analyzer
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"testing"
+
+	"github.com/agnivo988/Repo-lyzer/internal/github"
+)
+
+type mapFileSource map[string]string
+
+func (m mapFileSource) ReadFile(path string) ([]byte, error) {
+	return []byte(m[path]), nil
+}
+
+func TestCyclomaticComplexity(t *testing.T) {
+	src := `package p
+
+func f(xs []int, ok bool) int {
+	n := 0
+	for _, x := range xs {
+		if x > 0 && ok {
+			n++
+		}
+	}
+	switch n {
+	case 0:
+		return 0
+	case 1, 2:
+		return 1
+	default:
+	}
+	return n
+}
+`
+	file, err := parser.ParseFile(token.NewFileSet(), "p.go", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 1 + range + if + && + two non-default cases
+	if got := CyclomaticComplexity(file.Decls[0].(*ast.FuncDecl)); got != 6 {
+		t.Errorf("complexity = %d, want 6", got)
+	}
+}
+
+func TestAnalyzeGo(t *testing.T) {
+	files := mapFileSource{
+		"lib/lib.go": `// Package lib does things.
+package lib
+
+import "unsafe"
+
+const Version = "1.0"
+
+type Client struct{}
+
+func (c *Client) Do() {}
+func (c *Client) helper() {}
+func New() *Client { return &Client{} }
+
+var _ = unsafe.Sizeof(0)
+`,
+		"lib/lib_test.go": `package lib
+
+import "testing"
+
+func TestNew(t *testing.T) {}
+func TestDo(t *testing.T) {}
+func Testhelper(t *testing.T) {}
+func BenchmarkDo(b *testing.B) {}
+func ExampleNew() {}
+`,
+		"internal/util/util.go": `package util
+
+func init() {}
+func Exported() {}
+`,
+		"cmd/tool/main.go": `package main
+
+// #include <stdio.h>
+import "C"
+
+func main() {}
+`,
+		"broken/broken.go": "package broken\nfunc {",
+	}
+	var tree []github.TreeEntry
+	for path := range files {
+		tree = append(tree, github.TreeEntry{Path: path, Type: "blob"})
+	}
+
+	g, err := AnalyzeGo(files, tree, GoAnalysisOptions{TopN: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if g.FilesParsed != 4 || g.ParseErrors != 1 {
+		t.Errorf("parsed %d files with %d errors, want 4 and 1", g.FilesParsed, g.ParseErrors)
+	}
+	if g.ExportedFuncs != 2 || g.ExportedTypes != 1 || g.ExportedValues != 1 {
+		t.Errorf("exported funcs/types/values = %d/%d/%d, want 2/1/1", g.ExportedFuncs, g.ExportedTypes, g.ExportedValues)
+	}
+	if g.TestFuncs != 2 || g.BenchmarkFuncs != 1 || g.ExampleFuncs != 1 {
+		t.Errorf("tests/benchmarks/examples = %d/%d/%d, want 2/1/1", g.TestFuncs, g.BenchmarkFuncs, g.ExampleFuncs)
+	}
+	if g.TestToExportedRatio() != 1 {
+		t.Errorf("TestToExportedRatio = %.2f, want 1", g.TestToExportedRatio())
+	}
+	if g.Packages != 3 || len(g.PackagesWithoutDoc) != 2 {
+		t.Errorf("packages = %d, without doc = %v", g.Packages, g.PackagesWithoutDoc)
+	}
+	if len(g.UnsafeFiles) != 1 || len(g.CgoFiles) != 1 || len(g.InitFuncs) != 1 {
+		t.Errorf("unsafe=%v cgo=%v init=%v", g.UnsafeFiles, g.CgoFiles, g.InitFuncs)
+	}
+	if len(g.MostComplex) != 3 {
+		t.Errorf("MostComplex should be trimmed to TopN, got %d", len(g.MostComplex))
+	}
+}
+
+func TestAnalyzeGoPackageDocSampling(t *testing.T) {
+	files := mapFileSource{
+		"a/a.go":   "package a\n",
+		"a/b.go":   "package a\n",
+		"a/doc.go": "// Package a is documented.\npackage a\n",
+		"c/c1.go":  "package c\n",
+		"c/c2.go":  "// Package c is documented in a file left out of the sample.\npackage c\n",
+		"d/d.go":   "package d\n",
+	}
+	var tree []github.TreeEntry
+	for _, path := range []string{"a/a.go", "a/b.go", "a/doc.go", "c/c1.go", "c/c2.go", "d/d.go"} {
+		tree = append(tree, github.TreeEntry{Path: path, Type: "blob"})
+	}
+
+	g, err := AnalyzeGo(files, tree, GoAnalysisOptions{MaxFiles: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// doc.go is sampled first; c wasn't read completely, so it can't be
+	// called undocumented
+	if !g.BudgetExhausted || g.Packages != 3 || len(g.PackagesWithoutDoc) != 1 || g.PackagesWithoutDoc[0] != "d" {
+		t.Errorf("packages = %d, without doc = %v", g.Packages, g.PackagesWithoutDoc)
+	}
+}
+
+func TestAnalyzeCodeQualityWith_Go(t *testing.T) {
+	g := NewGoAnalysis(5)
+	g.FilesParsed = 1
+	g.TestFuncs = 3
+	g.ExportedFuncs = 4
+	g.UnsafeFiles = []string{"a.go"}
+	g.MostComplex = []GoFunctionComplexity{{Name: "Big", Location: "a.go:10", Complexity: 40}}
+
+	tree := []github.TreeEntry{{Path: "a.go", Type: "blob"}, {Path: "README.md", Type: "blob"}}
+	metrics := AnalyzeCodeQualityWith(nil, tree, map[string]int{"Go": 100}, QualityInputs{Go: g})
+
+	if !metrics.HasTests {
+		t.Error("Go test functions should mark the repo as tested")
+	}
+	if !hasFinding(metrics.CodeSmells, "High Cyclomatic Complexity") || !hasFinding(metrics.CodeSmells, "Unsafe Usage") {
+		t.Errorf("missing Go code smells: %+v", metrics.CodeSmells)
+	}
+	if metrics.Go != g {
+		t.Error("Go analysis should be attached to the metrics")
+	}
+}
@@ -0,0 +1,499 @@
+// Package analyzer provides analysis functions for GitHub repositories.
+// This file parses go.mod, go.sum and go.work files and checks Go modules
+// for problems that break builds or the module's consumers.
+package analyzer
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/agnivo988/Repo-lyzer/internal/github"
+)
+
+// GoRequire is a require directive of a go.mod file
+type GoRequire struct {
+	Path     string `json:"path"`
+	Version  string `json:"version"`
+	Indirect bool   `json:"indirect,omitempty"`
+}
+
+// GoReplace is a replace directive. OldVersion is empty when every version
+// is replaced, NewVersion when New is a local directory.
+type GoReplace struct {
+	Old        string `json:"old"`
+	OldVersion string `json:"old_version,omitempty"`
+	New        string `json:"new"`
+	NewVersion string `json:"new_version,omitempty"`
+}
+
+// Local reports whether the replacement is a directory rather than a module
+func (r GoReplace) Local() bool {
+	return isLocalModulePath(r.New)
+}
+
+// GoModuleVersion is a module version named by an exclude directive
+type GoModuleVersion struct {
+	Path    string `json:"path"`
+	Version string `json:"version"`
+}
+
+// GoRetract is a retract directive: a single version when Low equals High,
+// otherwise the closed interval [Low, High]
+type GoRetract struct {
+	Low       string `json:"low"`
+	High      string `json:"high"`
+	Rationale string `json:"rationale,omitempty"`
+}
+
+// GoModFile is the content of a go.mod file
+type GoModFile struct {
+	Module     string            `json:"module"`
+	Deprecated string            `json:"deprecated,omitempty"` // from a "// Deprecated:" comment on the module directive
+	GoVersion  string            `json:"go_version,omitempty"`
+	Toolchain  string            `json:"toolchain,omitempty"`
+	Requires   []GoRequire       `json:"requires,omitempty"`
+	Replaces   []GoReplace       `json:"replaces,omitempty"`
+	Excludes   []GoModuleVersion `json:"excludes,omitempty"`
+	Retracts   []GoRetract       `json:"retracts,omitempty"`
+}
+
+// goModLine is one directive of a go.mod or go.work file
+type goModLine struct {
+	verb    string
+	args    []string
+	comment string // the comment at the end of the line
+	before  string // comment lines directly above it
+	number  int
+}
+
+// splitGoModLines splits go.mod syntax into directives, expanding blocks
+// such as "require ( ... )" into one directive per line
+func splitGoModLines(content string) []goModLine {
+	var lines []goModLine
+	block := ""
+	var before []string
+	for n, raw := range strings.Split(content, "\n") {
+		code, comment := splitGoModComment(raw)
+		tokens := goModTokens(code)
+		if len(tokens) == 0 {
+			if comment != "" {
+				before = append(before, comment)
+			} else {
+				before = nil
+			}
+			continue
+		}
+		switch {
+		case block != "" && len(tokens) == 1 && tokens[0] == ")":
+			block = ""
+		case block == "" && len(tokens) == 2 && tokens[1] == "(":
+			block = tokens[0]
+		case block != "":
+			lines = append(lines, goModLine{block, tokens, comment, strings.Join(before, "\n"), n + 1})
+		default:
+			lines = append(lines, goModLine{tokens[0], tokens[1:], comment, strings.Join(before, "\n"), n + 1})
+		}
+		before = nil
+	}
+	return lines
+}
+
+// splitGoModComment splits a line into code and the text of its "//"
+// comment, ignoring "//" inside quoted strings
+func splitGoModComment(line string) (string, string) {
+	quote := rune(0)
+	for i, c := range line {
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '`':
+			quote = c
+		case c == '/' && strings.HasPrefix(line[i:], "//"):
+			return line[:i], strings.TrimSpace(line[i+2:])
+		}
+	}
+	return line, ""
+}
+
+// goModTokens splits code into fields, unquoting quoted ones and keeping
+// "(", ")", "[", "]", "," and "=>" as tokens of their own
+func goModTokens(code string) []string {
+	code = strings.NewReplacer("(", " ( ", ")", " ) ", "[", " [ ", "]", " ] ", ",", " , ", "=>", " => ").Replace(code)
+	var tokens []string
+	for _, f := range strings.Fields(code) {
+		if unquoted, err := strconv.Unquote(f); err == nil {
+			f = unquoted
+		}
+		tokens = append(tokens, f)
+	}
+	return tokens
+}
+
+// ParseGoMod parses a go.mod file. Malformed directives are skipped; the
+// first one is reported in the error, together with everything that could
+// be parsed.
+func ParseGoMod(content []byte) (*GoModFile, error) {
+	mod := &GoModFile{}
+	var firstErr error
+	fail := func(l goModLine) {
+		if firstErr == nil {
+			firstErr = fmt.Errorf("line %d: malformed %s directive", l.number, l.verb)
+		}
+	}
+	for _, l := range splitGoModLines(string(content)) {
+		switch l.verb {
+		case "module":
+			if len(l.args) != 1 {
+				fail(l)
+				continue
+			}
+			mod.Module = l.args[0]
+			for _, c := range []string{l.before, l.comment} {
+				if i := strings.Index(c, "Deprecated:"); i >= 0 {
+					mod.Deprecated = strings.TrimSpace(c[i+len("Deprecated:"):])
+				}
+			}
+		case "go":
+			if len(l.args) != 1 {
+				fail(l)
+				continue
+			}
+			mod.GoVersion = l.args[0]
+		case "toolchain":
+			if len(l.args) != 1 {
+				fail(l)
+				continue
+			}
+			mod.Toolchain = l.args[0]
+		case "require":
+			if len(l.args) != 2 {
+				fail(l)
+				continue
+			}
+			indirect := l.comment == "indirect" || strings.HasPrefix(l.comment, "indirect;")
+			mod.Requires = append(mod.Requires, GoRequire{Path: l.args[0], Version: l.args[1], Indirect: indirect})
+		case "exclude":
+			if len(l.args) != 2 {
+				fail(l)
+				continue
+			}
+			mod.Excludes = append(mod.Excludes, GoModuleVersion{Path: l.args[0], Version: l.args[1]})
+		case "replace":
+			r, ok := parseGoReplace(l.args)
+			if !ok {
+				fail(l)
+				continue
+			}
+			mod.Replaces = append(mod.Replaces, r)
+		case "retract":
+			r := GoRetract{Rationale: l.comment}
+			if r.Rationale == "" {
+				r.Rationale = l.before
+			}
+			switch {
+			case len(l.args) == 1:
+				r.Low, r.High = l.args[0], l.args[0]
+			case len(l.args) == 5 && l.args[0] == "[" && l.args[2] == "," && l.args[4] == "]":
+				r.Low, r.High = l.args[1], l.args[3]
+			default:
+				fail(l)
+				continue
+			}
+			mod.Retracts = append(mod.Retracts, r)
+		}
+	}
+	return mod, firstErr
+}
+
+// parseGoReplace parses the arguments of a replace directive:
+// "old [version] => new [version]"
+func parseGoReplace(args []string) (GoReplace, bool) {
+	arrow := -1
+	for i, a := range args {
+		if a == "=>" {
+			arrow = i
+		}
+	}
+	if arrow < 1 || arrow > 2 || len(args)-arrow-1 < 1 || len(args)-arrow-1 > 2 {
+		return GoReplace{}, false
+	}
+	r := GoReplace{Old: args[0], New: args[arrow+1]}
+	if arrow == 2 {
+		r.OldVersion = args[1]
+	}
+	if len(args) == arrow+3 {
+		r.NewVersion = args[arrow+2]
+	}
+	if r.Local() != (r.NewVersion == "") {
+		return GoReplace{}, false
+	}
+	return r, true
+}
+
+// isLocalModulePath reports whether a replacement is a directory: Go
+// treats paths starting with "./", "../" or "/" (or a drive letter) as such
+func isLocalModulePath(p string) bool {
+	return strings.HasPrefix(p, "./") || strings.HasPrefix(p, "../") || strings.HasPrefix(p, "/") ||
+		p == "." || p == ".." || strings.HasPrefix(p, `.\`) || strings.HasPrefix(p, `..\`) ||
+		len(p) > 2 && p[1] == ':' && (p[2] == '\\' || p[2] == '/')
+}
+
+// goSum holds which hashes a go.sum file records for each module version
+type goSum struct {
+	modHash  map[string]bool // "path@version" has a hash of its go.mod
+	treeHash map[string]bool // "path@version" has a hash of its content
+}
+
+func parseGoSumHashes(content []byte) goSum {
+	sum := goSum{modHash: make(map[string]bool), treeHash: make(map[string]bool)}
+	for _, line := range strings.Split(string(content), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 3 {
+			continue
+		}
+		if version, ok := strings.CutSuffix(fields[1], "/go.mod"); ok {
+			sum.modHash[fields[0]+"@"+version] = true
+		} else {
+			sum.treeHash[fields[0]+"@"+fields[1]] = true
+		}
+	}
+	return sum
+}
+
+// GoModWarning is a problem found in a Go module
+type GoModWarning struct {
+	File     string `json:"file"`
+	Severity string `json:"severity"` // HIGH, MEDIUM or LOW
+	Message  string `json:"message"`
+}
+
+// GoModule summarizes one module of a repository
+type GoModule struct {
+	File      string     `json:"file"` // path of the go.mod file
+	Module    *GoModFile `json:"module"`
+	Direct    int        `json:"direct"`
+	Indirect  int        `json:"indirect"`
+	HasGoSum  bool       `json:"has_go_sum"`
+	Workspace bool       `json:"workspace,omitempty"` // used by the repository's go.work
+}
+
+// GoModuleAnalysis is the inspection of a repository's Go modules
+type GoModuleAnalysis struct {
+	Modules   []GoModule     `json:"modules"`
+	Workspace string         `json:"workspace,omitempty"` // path of go.work, if any
+	Warnings  []GoModWarning `json:"warnings"`            // most severe first
+	// Truncated is set when the repository has more modules than were read
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// InspectGoModules reads every go.mod in the repository, with the go.sum
+// next to it and a go.work at the root, and checks them: local replace
+// directives, module paths that don't match the repository, requirements
+// missing from go.sum, stale go.sum entries and toolchain settings.
+// repoFullName is "owner/repo". At most maxModules modules are read; 0
+// reads all.
+func InspectGoModules(source FileSource, fileTree []github.TreeEntry, repoFullName string, maxModules int) (*GoModuleAnalysis, error) {
+	result := &GoModuleAnalysis{Modules: []GoModule{}, Warnings: []GoModWarning{}}
+	inTree := make(map[string]bool)
+	var modFiles []string
+	for _, entry := range fileTree {
+		if entry.Type != "blob" {
+			continue
+		}
+		inTree[entry.Path] = true
+		lower := strings.ToLower(entry.Path)
+		if baseName(entry.Path) == "go.mod" && !isVendoredPath(lower) && !strings.Contains(lower, "testdata/") {
+			modFiles = append(modFiles, entry.Path)
+		}
+	}
+	sort.Strings(modFiles)
+	if maxModules > 0 && len(modFiles) > maxModules {
+		modFiles, result.Truncated = modFiles[:maxModules], true
+	}
+
+	var workUses []string
+	if inTree["go.work"] {
+		result.Workspace = "go.work"
+		if content, err := source.ReadFile("go.work"); err == nil {
+			workUses = parseGoWorkUses(content)
+		}
+	}
+
+	warn := func(file, severity, format string, args ...interface{}) {
+		result.Warnings = append(result.Warnings, GoModWarning{File: file, Severity: severity, Message: fmt.Sprintf(format, args...)})
+	}
+	for _, file := range modFiles {
+		content, err := source.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		mod, err := ParseGoMod(content)
+		if err != nil {
+			warn(file, "HIGH", "go.mod does not parse: %v", err)
+		}
+		m := GoModule{File: file, Module: mod}
+		for _, r := range mod.Requires {
+			if r.Indirect {
+				m.Indirect++
+			} else {
+				m.Direct++
+			}
+		}
+		dir := path.Dir(file)
+		m.Workspace = contains(workUses, dir)
+		if result.Workspace != "" && !m.Workspace {
+			warn(file, "LOW", "module %s is not used by go.work, so workspace builds ignore local changes to it", mod.Module)
+		}
+
+		checkGoModFile(mod, file, dir, repoFullName, warn)
+
+		sumFile := strings.TrimPrefix(path.Join(dir, "go.sum"), "./")
+		if inTree[sumFile] {
+			if sumContent, err := source.ReadFile(sumFile); err == nil {
+				m.HasGoSum = true
+				checkGoSum(mod, parseGoSumHashes(sumContent), sumFile, warn)
+			}
+		} else if len(mod.Requires) > 0 {
+			warn(file, "HIGH", "go.sum is missing, so builds fail with \"missing go.sum entry\"; run `go mod tidy`")
+		}
+		result.Modules = append(result.Modules, m)
+	}
+
+	rank := map[string]int{"HIGH": 0, "MEDIUM": 1, "LOW": 2}
+	sort.SliceStable(result.Warnings, func(i, j int) bool {
+		return rank[result.Warnings[i].Severity] < rank[result.Warnings[j].Severity]
+	})
+	return result, nil
+}
+
+// goMajorVersionSuffix matches the major version suffix of a module path
+var goMajorVersionSuffix = regexp.MustCompile(`/v([2-9]|[1-9][0-9]+)$`)
+
+// checkGoModFile checks a go.mod on its own and against the repository
+func checkGoModFile(mod *GoModFile, file, dir, repoFullName string, warn func(file, severity, format string, args ...interface{})) {
+	switch {
+	case mod.Module == "":
+		warn(file, "HIGH", "go.mod has no module directive")
+	case strings.HasPrefix(strings.ToLower(mod.Module), "github.com/") && repoFullName != "":
+		// Modules hosted on GitHub must be at the repository path plus their
+		// directory, or go get can't find them
+		want := "github.com/" + repoFullName
+		if dir != "." {
+			want += "/" + dir
+		}
+		// A major version lives either in a vN subdirectory, where the path
+		// matches as is, or in a /vN suffix on top of the directory
+		got := mod.Module
+		if !strings.EqualFold(got, want) {
+			got = goMajorVersionSuffix.ReplaceAllString(got, "")
+		}
+		if !strings.EqualFold(got, want) {
+			warn(file, "MEDIUM", "module path %s does not match its location %s, so `go get` can't fetch it from this repository", mod.Module, want)
+		} else if got != want {
+			warn(file, "LOW", "module path %s differs from the repository path %s in letter case", mod.Module, want)
+		}
+	}
+
+	if mod.GoVersion == "" {
+		warn(file, "LOW", "no go directive; the go command assumes Go 1.16 semantics")
+	}
+	if mod.Toolchain != "" && mod.GoVersion != "" &&
+		compareGoVersions("v"+strings.TrimPrefix(mod.Toolchain, "go"), "v"+mod.GoVersion) < 0 {
+		warn(file, "LOW", "toolchain %s is older than the go %s directive and is ignored", mod.Toolchain, mod.GoVersion)
+	}
+
+	required := make(map[string]string)
+	for _, r := range mod.Requires {
+		if v, dup := required[r.Path]; dup {
+			warn(file, "MEDIUM", "%s is required twice (%s and %s)", r.Path, v, r.Version)
+		}
+		required[r.Path] = r.Version
+	}
+	for _, r := range mod.Replaces {
+		switch {
+		case r.Local() && isPlaceholderVersion(required[r.Old]):
+			warn(file, "HIGH", "%s is only available through the local replace => %s; consumers of %s can't build it",
+				r.Old, r.New, mod.Module)
+		case r.Local():
+			warn(file, "MEDIUM", "replace %s => %s points at a local directory; consumers ignore it and build %s %s instead",
+				r.Old, r.New, r.Old, required[r.Old])
+		case r.New != r.Old:
+			warn(file, "LOW", "%s is replaced by %s %s, which consumers of this module don't get", r.Old, r.New, r.NewVersion)
+		}
+	}
+}
+
+// isPlaceholderVersion reports whether a required version only exists to
+// satisfy a replace directive, like v0.0.0-00010101000000-000000000000
+func isPlaceholderVersion(v string) bool {
+	return v == "v0.0.0" || strings.HasPrefix(v, "v0.0.0-00010101000000-")
+}
+
+// checkGoSum compares go.sum with the requirements. Since Go 1.17 go.sum
+// must hold the go.mod hash of every requirement and the content hash of
+// each module that provides packages; content hashes of other versions of
+// required modules are left over from upgrades.
+func checkGoSum(mod *GoModFile, sum goSum, sumFile string, warn func(file, severity, format string, args ...interface{})) {
+	replaced := make(map[string]GoReplace)
+	for _, r := range mod.Replaces {
+		replaced[r.Old+"@"+r.OldVersion] = r
+	}
+	var missing, missingContent []string
+	selected := make(map[string]string)
+	for _, req := range mod.Requires {
+		p, v := req.Path, req.Version
+		r, ok := replaced[p+"@"+v]
+		if !ok {
+			r, ok = replaced[p+"@"]
+		}
+		if ok && r.Local() {
+			continue
+		}
+		if ok {
+			p, v = r.New, r.NewVersion
+		}
+		selected[p] = v
+		if !sum.modHash[p+"@"+v] {
+			missing = append(missing, p+"@"+v)
+		}
+		if !req.Indirect && !sum.treeHash[p+"@"+v] {
+			missingContent = append(missingContent, p+"@"+v)
+		}
+	}
+	if len(missing) > 0 {
+		warn(sumFile, "HIGH", "go.sum has no entry for %s; run `go mod tidy`", summarizeList(missing, 3))
+	}
+	if len(missingContent) > 0 {
+		warn(sumFile, "MEDIUM", "go.sum has no content hash for %s, so builds importing it fail", summarizeList(missingContent, 3))
+	}
+
+	pruned := mod.GoVersion != "" && compareGoVersions("v"+mod.GoVersion, "v1.17") >= 0
+	if !pruned {
+		return
+	}
+	var stale []string
+	for key := range sum.treeHash {
+		p, v, _ := strings.Cut(key, "@")
+		if want, ok := selected[p]; ok && want != v {
+			stale = append(stale, key)
+		}
+	}
+	if len(stale) > 0 {
+		sort.Strings(stale)
+		warn(sumFile, "LOW", "go.sum has stale hashes for %s, versions go.mod no longer requires; run `go mod tidy`", summarizeList(stale, 3))
+	}
+}
+
+// summarizeList joins up to n items and counts the rest
+func summarizeList(items []string, n int) string {
+	if len(items) <= n {
+		return strings.Join(items, ", ")
+	}
+	return fmt.Sprintf("%s and %d more", strings.Join(items[:n], ", "), len(items)-n)
+}
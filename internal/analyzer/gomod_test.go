@@ -0,0 +1,200 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+)
+
+const testGoMod = `// Deprecated: use example.com/app/v2 instead.
+module github.com/acme/app
+
+go 1.22.0
+
+toolchain go1.22.4
+
+require github.com/pkg/errors v0.9.1 // indirect
+
+require (
+	github.com/gin-gonic/gin v1.9.1
+	golang.org/x/text v0.14.0 // indirect; needed by gin
+	github.com/acme/shared v0.0.0-00010101000000-000000000000
+	"github.com/quoted/mod" v1.0.0
+)
+
+replace github.com/acme/shared => ../shared
+
+replace (
+	golang.org/x/text v0.14.0 => github.com/fork/text v0.14.1
+)
+
+exclude github.com/gin-gonic/gin v1.9.0
+
+retract (
+	v1.0.1 // published by mistake
+	// broken builds
+	[v1.1.0, v1.1.5]
+)
+`
+
+func TestParseGoMod(t *testing.T) {
+	mod, err := ParseGoMod([]byte(testGoMod))
+	if err != nil {
+		t.Fatalf("ParseGoMod: %v", err)
+	}
+	if mod.Module != "github.com/acme/app" || mod.Deprecated != "use example.com/app/v2 instead." ||
+		mod.GoVersion != "1.22.0" || mod.Toolchain != "go1.22.4" {
+		t.Errorf("header = %+v", mod)
+	}
+	want := []GoRequire{
+		{"github.com/pkg/errors", "v0.9.1", true},
+		{"github.com/gin-gonic/gin", "v1.9.1", false},
+		{"golang.org/x/text", "v0.14.0", true},
+		{"github.com/acme/shared", "v0.0.0-00010101000000-000000000000", false},
+		{"github.com/quoted/mod", "v1.0.0", false},
+	}
+	if len(mod.Requires) != len(want) {
+		t.Fatalf("requires = %+v", mod.Requires)
+	}
+	for i, r := range want {
+		if mod.Requires[i] != r {
+			t.Errorf("require %d = %+v, want %+v", i, mod.Requires[i], r)
+		}
+	}
+	if len(mod.Replaces) != 2 || !mod.Replaces[0].Local() || mod.Replaces[0].New != "../shared" ||
+		mod.Replaces[1].OldVersion != "v0.14.0" || mod.Replaces[1].NewVersion != "v0.14.1" || mod.Replaces[1].Local() {
+		t.Errorf("replaces = %+v", mod.Replaces)
+	}
+	if len(mod.Excludes) != 1 || mod.Excludes[0].Version != "v1.9.0" {
+		t.Errorf("excludes = %+v", mod.Excludes)
+	}
+	if len(mod.Retracts) != 2 || mod.Retracts[0] != (GoRetract{"v1.0.1", "v1.0.1", "published by mistake"}) ||
+		mod.Retracts[1] != (GoRetract{"v1.1.0", "v1.1.5", "broken builds"}) {
+		t.Errorf("retracts = %+v", mod.Retracts)
+	}
+
+	deps, fileType := parseGoMod([]byte(testGoMod))
+	if fileType != "go" || len(deps) != 5 || !deps[0].Transitive || deps[0].Type != "indirect" || deps[1].Transitive {
+		t.Errorf("parseGoMod = %+v", deps)
+	}
+}
+
+func TestParseGoModErrors(t *testing.T) {
+	mod, err := ParseGoMod([]byte("module example.com/m\nrequire broken\nreplace a => b\nrequire ok.com/x v1.0.0\n"))
+	if err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("err = %v", err)
+	}
+	// The rest of the file is still parsed
+	if mod.Module != "example.com/m" || len(mod.Requires) != 1 || len(mod.Replaces) != 0 {
+		t.Errorf("mod = %+v", mod)
+	}
+}
+
+func TestIsLocalModulePath(t *testing.T) {
+	for p, want := range map[string]bool{
+		"./x": true, "../x": true, "/abs": true, `C:\mods\x`: true, "..": true,
+		"github.com/x/y": false, "example.com/./x": false,
+	} {
+		if got := isLocalModulePath(p); got != want {
+			t.Errorf("isLocalModulePath(%q) = %v", p, got)
+		}
+	}
+}
+
+func goModWarnings(a *GoModuleAnalysis, file string) []string {
+	var msgs []string
+	for _, w := range a.Warnings {
+		if w.File == file {
+			msgs = append(msgs, w.Severity+" "+w.Message)
+		}
+	}
+	return msgs
+}
+
+func hasWarning(msgs []string, severity, fragment string) bool {
+	for _, m := range msgs {
+		if strings.HasPrefix(m, severity+" ") && strings.Contains(m, fragment) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestInspectGoModules(t *testing.T) {
+	files := mapFileSource{
+		"go.work": "go 1.22\n\nuse (\n\t.\n\t./tools\n)\n",
+		"go.mod":  testGoMod,
+		"go.sum": strings.Join([]string{
+			"github.com/pkg/errors v0.9.1 h1:abc=",
+			"github.com/pkg/errors v0.9.1/go.mod h1:abc=",
+			"github.com/gin-gonic/gin v1.9.1/go.mod h1:abc=",
+			"github.com/gin-gonic/gin v1.8.0 h1:old=",
+			"github.com/fork/text v0.14.1/go.mod h1:abc=",
+			"github.com/quoted/mod v1.0.0 h1:abc=",
+			"github.com/quoted/mod v1.0.0/go.mod h1:abc=",
+		}, "\n"),
+		"tools/go.mod":      "module github.com/acme/app/tools/v2\n\ngo 1.21\ntoolchain go1.20.1\n",
+		"api/go.mod":        "module github.com/other/api\n\ngo 1.22\n\nrequire github.com/pkg/errors v0.9.1\n",
+		"cli/go.mod":        "module example.com/cli\n",
+		"v2/go.mod":         "module github.com/acme/app/v2\n\ngo 1.22\n",
+		"vendor/go.mod":     "module vendored\n",
+		"x/testdata/go.mod": "module broken\n",
+	}
+	a, err := InspectGoModules(files, treeFor(files), "acme/app", 0)
+	if err != nil {
+		t.Fatalf("InspectGoModules: %v", err)
+	}
+	if len(a.Modules) != 5 || a.Workspace != "go.work" || a.Truncated {
+		t.Fatalf("modules = %+v", a.Modules)
+	}
+	byFile := make(map[string]GoModule)
+	for _, m := range a.Modules {
+		byFile[m.File] = m
+	}
+	if root := byFile["go.mod"]; root.Direct != 3 || root.Indirect != 2 || !root.HasGoSum || !root.Workspace {
+		t.Errorf("root module = %+v", root)
+	}
+	if !byFile["tools/go.mod"].Workspace || byFile["api/go.mod"].Workspace {
+		t.Errorf("workspace membership: %+v", a.Modules)
+	}
+
+	root := goModWarnings(a, "go.mod")
+	if !hasWarning(root, "HIGH", "github.com/acme/shared is only available through the local replace") ||
+		!hasWarning(root, "LOW", "golang.org/x/text is replaced by github.com/fork/text v0.14.1") || len(root) != 2 {
+		t.Errorf("go.mod warnings = %q", root)
+	}
+	sum := goModWarnings(a, "go.sum")
+	// The replaced module is checked under its replacement; the local one
+	// isn't checked at all
+	if !hasWarning(sum, "MEDIUM", "no content hash for github.com/gin-gonic/gin@v1.9.1") ||
+		!hasWarning(sum, "LOW", "stale hashes for github.com/gin-gonic/gin@v1.8.0") || len(sum) != 2 {
+		t.Errorf("go.sum warnings = %q", sum)
+	}
+
+	// A major version suffix is allowed; an old toolchain line isn't
+	tools := goModWarnings(a, "tools/go.mod")
+	if !hasWarning(tools, "LOW", "toolchain go1.20.1 is older than the go 1.21") || len(tools) != 1 {
+		t.Errorf("tools warnings = %q", tools)
+	}
+	api := goModWarnings(a, "api/go.mod")
+	if !hasWarning(api, "MEDIUM", "does not match its location github.com/acme/app/api") ||
+		!hasWarning(api, "HIGH", "go.sum is missing") || !hasWarning(api, "LOW", "not used by go.work") {
+		t.Errorf("api warnings = %q", api)
+	}
+	// A major version subdirectory carries the suffix in its own path
+	if v2 := goModWarnings(a, "v2/go.mod"); hasWarning(v2, "MEDIUM", "does not match") {
+		t.Errorf("v2 warnings = %q", v2)
+	}
+	// Paths off GitHub can't be checked against the repository
+	cli := goModWarnings(a, "cli/go.mod")
+	if !hasWarning(cli, "LOW", "no go directive") || len(cli) != 2 {
+		t.Errorf("cli warnings = %q", cli)
+	}
+	if a.Warnings[0].Severity != "HIGH" || a.Warnings[len(a.Warnings)-1].Severity != "LOW" {
+		t.Errorf("warnings are not sorted by severity: %+v", a.Warnings)
+	}
+
+	limited, _ := InspectGoModules(files, treeFor(files), "acme/app", 2)
+	if len(limited.Modules) != 2 || !limited.Truncated {
+		t.Errorf("limited = %+v", limited.Modules)
+	}
+}
@@ -0,0 +1,428 @@
+// Package analyzer provides infrastructure-as-code analysis.
+// This file detects Terraform, Kubernetes manifests and Helm charts in the
+// repository and runs a starter set of misconfiguration rules over them.
+package analyzer
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/agnivo988/Repo-lyzer/internal/github"
+)
+
+// IaCFile describes a single infrastructure-as-code file that was analyzed
+type IaCFile struct {
+	Path      string `json:"path"`
+	Framework string `json:"framework"` // "terraform", "kubernetes", "helm"
+	Resources int    `json:"resources"` // Resources/objects declared in the file
+}
+
+// IaCAnalysis holds infrastructure-as-code detection and findings.
+// Findings use the same severity/location format as CodeSmell.
+type IaCAnalysis struct {
+	Frameworks   []string    `json:"frameworks"`
+	Files        []IaCFile   `json:"files"`
+	Findings     []CodeSmell `json:"findings"`
+	FilesSkipped int         `json:"files_skipped"` // Candidates not fetched due to the file budget
+}
+
+// AnalyzeIaC detects IaC files in the tree, fetches up to maxFiles of them
+// (0 = unlimited) and checks them for common misconfigurations.
+func AnalyzeIaC(client *github.Client, owner, repo string, fileTree []github.TreeEntry, maxFiles int) (*IaCAnalysis, error) {
+	analysis := &IaCAnalysis{
+		Frameworks: []string{},
+		Files:      []IaCFile{},
+		Findings:   []CodeSmell{},
+	}
+
+	candidates := findIaCFiles(fileTree)
+	if maxFiles > 0 && len(candidates) > maxFiles {
+		analysis.FilesSkipped = len(candidates) - maxFiles
+		candidates = candidates[:maxFiles]
+	}
+
+	for _, f := range candidates {
+		content, err := fetchFile(client, owner, repo, f.path)
+		if err != nil {
+			continue
+		}
+		analysis.AddFile(f.path, f.fileType, content)
+	}
+
+	return analysis, nil
+}
+
+// AddFile analyzes one IaC file of the given kind ("terraform",
+// "kubernetes", "helm-template" or "helm-values") and records the result.
+func (a *IaCAnalysis) AddFile(path, kind string, content []byte) {
+	var resources int
+	var findings []CodeSmell
+	framework := kind
+
+	switch kind {
+	case "terraform":
+		resources, findings = ParseTerraform(path, content)
+	case "kubernetes":
+		resources, findings = ParseKubernetesManifest(path, content)
+	case "helm-template":
+		framework = "helm"
+		resources, findings = ParseKubernetesManifest(path, content)
+	case "helm-values":
+		framework = "helm"
+		findings = checkHelmValues(path, content)
+	}
+
+	// Generic YAML files that turned out not to be manifests are ignored
+	if kind == "kubernetes" && resources == 0 {
+		return
+	}
+
+	a.Files = append(a.Files, IaCFile{Path: path, Framework: framework, Resources: resources})
+	a.Findings = append(a.Findings, findings...)
+	if !contains(a.Frameworks, framework) {
+		a.Frameworks = append(a.Frameworks, framework)
+	}
+}
+
+// k8sDirHints are directory names that usually hold Kubernetes manifests
+var k8sDirHints = []string{"k8s", "kube", "kubernetes", "manifests", "deploy", "deployment", "deployments", "overlays", "base", "kustomize", "openshift"}
+
+// findIaCFiles scans the file tree for Terraform, Helm and Kubernetes files.
+// Terraform and Helm are recognized by name; YAML files are only considered
+// Kubernetes candidates when they live in a deployment-like directory.
+func findIaCFiles(tree []github.TreeEntry) []depFileInfo {
+	var files []depFileInfo
+	chartRoots := []string{}
+
+	for _, entry := range tree {
+		if entry.Type == "blob" && baseName(entry.Path) == "Chart.yaml" {
+			chartRoots = append(chartRoots, strings.TrimSuffix(entry.Path, "Chart.yaml"))
+		}
+	}
+
+	inChart := func(path string) string {
+		for _, root := range chartRoots {
+			if strings.HasPrefix(path, root) {
+				return root
+			}
+		}
+		return ""
+	}
+
+	for _, entry := range tree {
+		if entry.Type != "blob" {
+			continue
+		}
+		lowerPath := strings.ToLower(entry.Path)
+		name := baseName(lowerPath)
+		isYAML := strings.HasSuffix(name, ".yaml") || strings.HasSuffix(name, ".yml")
+
+		switch {
+		case strings.HasSuffix(name, ".tf"):
+			files = append(files, depFileInfo{path: entry.Path, fileType: "terraform"})
+
+		case isYAML && inChart(entry.Path) != "":
+			rel := strings.TrimPrefix(entry.Path, inChart(entry.Path))
+			if rel == "values.yaml" {
+				files = append(files, depFileInfo{path: entry.Path, fileType: "helm-values"})
+			} else if strings.HasPrefix(rel, "templates/") {
+				files = append(files, depFileInfo{path: entry.Path, fileType: "helm-template"})
+			}
+
+		case isYAML && !strings.HasPrefix(lowerPath, ".github/") && !strings.Contains(name, "compose"):
+			dirs := strings.Split(lowerPath, "/")
+			for _, dir := range dirs[:len(dirs)-1] {
+				if contains(k8sDirHints, dir) {
+					files = append(files, depFileInfo{path: entry.Path, fileType: "kubernetes"})
+					break
+				}
+			}
+		}
+	}
+
+	return files
+}
+
+// tfResourcePattern matches the opening line of a Terraform resource block
+var tfResourcePattern = regexp.MustCompile(`^\s*resource\s+"([^"]+)"\s+"([^"]+)"\s*\{`)
+
+// tfAttrPattern matches a simple "name = value" attribute
+var tfAttrPattern = regexp.MustCompile(`^\s*([A-Za-z0-9_]+)\s*=\s*(.+?)\s*$`)
+
+// tfBlockPattern matches the opening of a nested block such as "ingress {"
+var tfBlockPattern = regexp.MustCompile(`^\s*([A-Za-z0-9_]+)\s*\{\s*$`)
+
+// ParseTerraform counts resources in a Terraform file and checks them for
+// public S3 buckets and security groups open to the internet.
+func ParseTerraform(path string, content []byte) (int, []CodeSmell) {
+	var findings []CodeSmell
+	resources := 0
+
+	resourceType, resourceName := "", ""
+	var blocks []string // open blocks inside the current resource, "" for maps
+	ruleType := ""      // "ingress"/"egress" for aws_security_group_rule
+
+	for i, line := range strings.Split(string(content), "\n") {
+		lineNum := i + 1
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, "//") {
+			continue
+		}
+
+		if resourceType == "" {
+			if m := tfResourcePattern.FindStringSubmatch(line); m != nil {
+				resources++
+				resourceType, resourceName = m[1], m[2]
+				blocks = nil
+				ruleType = ""
+			}
+			continue
+		}
+
+		opens, closes := strings.Count(trimmed, "{"), strings.Count(trimmed, "}")
+		if m := tfBlockPattern.FindStringSubmatch(line); m != nil {
+			blocks = append(blocks, m[1])
+			opens--
+		}
+		for ; opens > 0; opens-- {
+			blocks = append(blocks, "")
+		}
+		for ; closes > 0; closes-- {
+			if len(blocks) == 0 {
+				resourceType = "" // closing brace of the resource itself
+				break
+			}
+			blocks = blocks[:len(blocks)-1]
+		}
+		if resourceType == "" {
+			continue
+		}
+
+		m := tfAttrPattern.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		attr, value := m[1], strings.Trim(m[2], `"`)
+		location := fmt.Sprintf("%s:%d", path, lineNum)
+		ref := resourceType + "." + resourceName
+		currentBlock := ""
+		if len(blocks) > 0 {
+			currentBlock = blocks[len(blocks)-1]
+		}
+
+		switch resourceType {
+		case "aws_s3_bucket", "aws_s3_bucket_acl":
+			if attr == "acl" && (value == "public-read" || value == "public-read-write") {
+				findings = append(findings, CodeSmell{
+					Type:        "Public S3 Bucket",
+					Severity:    "High",
+					Description: ref + " grants a public ACL (" + value + ")",
+					Location:    location,
+				})
+			}
+		case "aws_s3_bucket_public_access_block":
+			if (attr == "block_public_acls" || attr == "block_public_policy" || attr == "restrict_public_buckets" || attr == "ignore_public_acls") && value == "false" {
+				findings = append(findings, CodeSmell{
+					Type:        "Public S3 Bucket",
+					Severity:    "Medium",
+					Description: ref + " disables " + attr,
+					Location:    location,
+				})
+			}
+		case "aws_security_group", "aws_security_group_rule", "aws_vpc_security_group_ingress_rule":
+			if attr == "type" {
+				ruleType = value
+			}
+			ingress := currentBlock == "ingress" || ruleType == "ingress" || resourceType == "aws_vpc_security_group_ingress_rule"
+			if ingress && (attr == "cidr_blocks" || attr == "ipv6_cidr_blocks" || attr == "cidr_ipv4" || attr == "cidr_ipv6") && isOpenCIDR(value) {
+				findings = append(findings, CodeSmell{
+					Type:        "Open Security Group",
+					Severity:    "High",
+					Description: ref + " allows ingress from the entire internet",
+					Location:    location,
+				})
+			}
+		case "google_compute_firewall":
+			if attr == "source_ranges" && isOpenCIDR(value) {
+				findings = append(findings, CodeSmell{
+					Type:        "Open Security Group",
+					Severity:    "High",
+					Description: ref + " allows traffic from the entire internet",
+					Location:    location,
+				})
+			}
+		case "azurerm_network_security_rule":
+			if attr == "source_address_prefix" && (value == "*" || isOpenCIDR(value) || value == "Internet") {
+				findings = append(findings, CodeSmell{
+					Type:        "Open Security Group",
+					Severity:    "High",
+					Description: ref + " allows traffic from any source",
+					Location:    location,
+				})
+			}
+		}
+	}
+
+	return resources, findings
+}
+
+// isOpenCIDR reports whether a Terraform value contains a world-open range
+func isOpenCIDR(value string) bool {
+	return strings.Contains(value, "0.0.0.0/0") || strings.Contains(value, "::/0")
+}
+
+// ParseKubernetesManifest counts Kubernetes objects in a (possibly
+// multi-document) manifest and checks their pod specs.
+func ParseKubernetesManifest(path string, content []byte) (int, []CodeSmell) {
+	var findings []CodeSmell
+	resources := 0
+
+	for _, doc := range parseYAMLDocuments(string(content)) {
+		kind := yamlString(yamlGet(doc.Value, "kind"))
+		if kind == "" || yamlGet(doc.Value, "apiVersion") == nil {
+			continue
+		}
+		resources++
+
+		// Lists (kind: List) wrap other objects
+		objects := []interface{}{doc.Value}
+		if kind == "List" {
+			objects = yamlList(yamlGet(doc.Value, "items"))
+		}
+
+		for _, obj := range objects {
+			objKind := yamlString(yamlGet(obj, "kind"))
+			name := yamlString(yamlGet(obj, "metadata", "name"))
+			ref := objKind + "/" + name
+			location := fmt.Sprintf("%s:%d", path, doc.Line)
+
+			if spec := podSpec(obj); spec != nil {
+				findings = append(findings, checkPodSpec(spec, ref, location)...)
+			}
+		}
+	}
+
+	return resources, findings
+}
+
+// podSpec returns the pod template spec of a workload object, if any
+func podSpec(obj interface{}) interface{} {
+	switch yamlString(yamlGet(obj, "kind")) {
+	case "Pod":
+		return yamlGet(obj, "spec")
+	case "Deployment", "StatefulSet", "DaemonSet", "ReplicaSet", "ReplicationController", "Job":
+		return yamlGet(obj, "spec", "template", "spec")
+	case "CronJob":
+		return yamlGet(obj, "spec", "jobTemplate", "spec", "template", "spec")
+	}
+	return nil
+}
+
+// checkPodSpec runs the container and volume rules over a pod spec
+func checkPodSpec(spec interface{}, ref, location string) []CodeSmell {
+	var findings []CodeSmell
+	add := func(typ, severity, desc string) {
+		findings = append(findings, CodeSmell{Type: typ, Severity: severity, Description: desc, Location: location})
+	}
+
+	for _, key := range []string{"containers", "initContainers"} {
+		for _, c := range yamlList(yamlGet(spec, key)) {
+			name := yamlString(yamlGet(c, "name"))
+
+			if yamlString(yamlGet(c, "securityContext", "privileged")) == "true" {
+				add("Privileged Container", "High", fmt.Sprintf("%s container %q runs privileged", ref, name))
+			}
+
+			if key == "containers" {
+				// An empty "resources:" is usually filled in by a Helm template line
+				m, _ := c.(map[string]interface{})
+				templated := hasKey(m, "resources") && m["resources"] == nil
+				if limits, _ := yamlGet(c, "resources", "limits").(map[string]interface{}); len(limits) == 0 && !templated {
+					add("Missing Resource Limits", "Medium", fmt.Sprintf("%s container %q has no CPU/memory limits", ref, name))
+				}
+			}
+
+			image := yamlString(yamlGet(c, "image"))
+			if image != "" && !strings.Contains(image, "{{") && !strings.Contains(image, "$") {
+				if img := parseImageRef(image); !img.IsPinned() {
+					add("Latest Image Tag", "Medium", fmt.Sprintf("%s container %q uses unpinned image %s", ref, name, image))
+				}
+			}
+		}
+	}
+
+	for _, v := range yamlList(yamlGet(spec, "volumes")) {
+		if hostPath := yamlGet(v, "hostPath"); hostPath != nil {
+			add("hostPath Mount", "High", fmt.Sprintf("%s mounts host path %s", ref, yamlString(yamlGet(hostPath, "path"))))
+		}
+	}
+
+	return findings
+}
+
+// hasKey reports whether a mapping contains a key, even with a nil value
+func hasKey(m map[string]interface{}, key string) bool {
+	_, ok := m[key]
+	return ok
+}
+
+// checkHelmValues checks a chart's values.yaml for latest image tags,
+// privileged security contexts and empty resource settings.
+func checkHelmValues(path string, content []byte) []CodeSmell {
+	var findings []CodeSmell
+	docs := parseYAMLDocuments(string(content))
+	if len(docs) == 0 {
+		return findings
+	}
+
+	var walk func(node interface{}, keyPath string)
+	walk = func(node interface{}, keyPath string) {
+		m, ok := node.(map[string]interface{})
+		if !ok {
+			return
+		}
+		keys := make([]string, 0, len(m))
+		for k := range m {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			v := m[k]
+			p := strings.TrimPrefix(keyPath+"."+k, ".")
+			switch {
+			// An empty tag is the chart convention for falling back to the
+			// chart's appVersion, so only an explicit latest is reported
+			case k == "tag" && yamlString(v) == "latest" && hasKey(m, "repository"):
+				findings = append(findings, CodeSmell{
+					Type:        "Latest Image Tag",
+					Severity:    "Medium",
+					Description: "Helm value " + p + " sets the image tag to latest",
+					Location:    path,
+				})
+			case k == "privileged" && yamlString(v) == "true":
+				findings = append(findings, CodeSmell{
+					Type:        "Privileged Container",
+					Severity:    "High",
+					Description: "Helm value " + p + " enables privileged mode",
+					Location:    path,
+				})
+			case k == "resources":
+				if r, ok := v.(map[string]interface{}); v == nil || (ok && len(r) == 0) {
+					findings = append(findings, CodeSmell{
+						Type:        "Missing Resource Limits",
+						Severity:    "Medium",
+						Description: "Helm value " + p + " sets no resource requests or limits",
+						Location:    path,
+					})
+				}
+			}
+			walk(v, p)
+		}
+	}
+	walk(docs[0].Value, "")
+
+	return findings
+}
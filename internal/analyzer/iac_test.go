@@ -0,0 +1,193 @@
+package analyzer
+
+import (
+	"testing"
+
+	"github.com/agnivo988/Repo-lyzer/internal/github"
+)
+
+func countFindings(findings []CodeSmell) map[string]int {
+	counts := map[string]int{}
+	for _, f := range findings {
+		counts[f.Type]++
+	}
+	return counts
+}
+
+func TestParseTerraform(t *testing.T) {
+	tf := `resource "aws_s3_bucket" "assets" {
+  bucket = "my-assets"
+  acl    = "public-read"
+  tags = {
+    Name = "assets"
+  }
+}
+
+resource "aws_security_group" "web" {
+  name = "web"
+
+  ingress {
+    from_port   = 22
+    to_port     = 22
+    cidr_blocks = ["0.0.0.0/0"]
+  }
+
+  egress {
+    from_port   = 0
+    to_port     = 0
+    cidr_blocks = ["0.0.0.0/0"]
+  }
+}
+
+resource "aws_security_group_rule" "internal" {
+  type        = "ingress"
+  cidr_blocks = ["10.0.0.0/8"]
+}
+`
+	resources, findings := ParseTerraform("main.tf", []byte(tf))
+	if resources != 3 {
+		t.Errorf("resources = %d, want 3", resources)
+	}
+
+	counts := countFindings(findings)
+	if counts["Public S3 Bucket"] != 1 {
+		t.Errorf("expected 1 public bucket finding, got %+v", findings)
+	}
+	if counts["Open Security Group"] != 1 {
+		t.Errorf("expected only the ingress rule to be flagged, got %+v", findings)
+	}
+	for _, f := range findings {
+		if f.Type == "Open Security Group" && f.Location != "main.tf:15" {
+			t.Errorf("Location = %s, want main.tf:15", f.Location)
+		}
+	}
+}
+
+func TestParseKubernetesManifest(t *testing.T) {
+	manifest := `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      containers:
+        - name: app
+          image: nginx:latest
+          securityContext:
+            privileged: true
+        - name: sidecar
+          image: "busybox:1.36"
+          resources:
+            limits:
+              cpu: 100m
+      volumes:
+        - name: docker-sock
+          hostPath:
+            path: /var/run/docker.sock
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+spec:
+  ports: [{port: 80, targetPort: 8080}]
+`
+	resources, findings := ParseKubernetesManifest("deploy/web.yaml", []byte(manifest))
+	if resources != 2 {
+		t.Errorf("resources = %d, want 2", resources)
+	}
+
+	counts := countFindings(findings)
+	want := map[string]int{"Privileged Container": 1, "Missing Resource Limits": 1, "Latest Image Tag": 1, "hostPath Mount": 1}
+	for typ, n := range want {
+		if counts[typ] != n {
+			t.Errorf("%s findings = %d, want %d (all: %+v)", typ, counts[typ], n, findings)
+		}
+	}
+}
+
+func TestCheckHelmValues(t *testing.T) {
+	values := `image:
+  repository: ghcr.io/org/app
+  tag: latest
+resources: {}
+securityContext:
+  privileged: false
+sidecar:
+  image:
+    repository: ghcr.io/org/sidecar
+    tag: ""
+`
+	counts := countFindings(checkHelmValues("chart/values.yaml", []byte(values)))
+	if counts["Latest Image Tag"] != 1 || counts["Missing Resource Limits"] != 1 || counts["Privileged Container"] != 0 {
+		t.Errorf("unexpected helm findings: %+v", counts)
+	}
+}
+
+func TestFindIaCFiles(t *testing.T) {
+	tree := []github.TreeEntry{
+		{Path: "infra/main.tf", Type: "blob"},
+		{Path: "charts/app/Chart.yaml", Type: "blob"},
+		{Path: "charts/app/values.yaml", Type: "blob"},
+		{Path: "charts/app/templates/deployment.yaml", Type: "blob"},
+		{Path: "k8s/service.yml", Type: "blob"},
+		{Path: ".github/workflows/ci.yml", Type: "blob"},
+		{Path: "config/app.yaml", Type: "blob"},
+	}
+
+	kinds := map[string]string{}
+	for _, f := range findIaCFiles(tree) {
+		kinds[f.path] = f.fileType
+	}
+	want := map[string]string{
+		"infra/main.tf":                        "terraform",
+		"charts/app/values.yaml":               "helm-values",
+		"charts/app/templates/deployment.yaml": "helm-template",
+		"k8s/service.yml":                      "kubernetes",
+	}
+	if len(kinds) != len(want) {
+		t.Errorf("found %v, want %v", kinds, want)
+	}
+	for path, kind := range want {
+		if kinds[path] != kind {
+			t.Errorf("%s detected as %q, want %q", path, kinds[path], kind)
+		}
+	}
+}
+
+func TestParseYAMLDocuments(t *testing.T) {
+	content := `# comment
+name: demo   # trailing comment
+list:
+- a
+- "b: c"
+nested:
+  script: |
+    echo one
+    echo two
+  empty:
+flow: [x, y]
+---
+second: true
+`
+	docs := parseYAMLDocuments(content)
+	if len(docs) != 2 {
+		t.Fatalf("expected 2 documents, got %d", len(docs))
+	}
+	if got := yamlString(yamlGet(docs[0].Value, "name")); got != "demo" {
+		t.Errorf("name = %q, want demo", got)
+	}
+	if l := yamlList(yamlGet(docs[0].Value, "list")); len(l) != 2 || l[1] != "b: c" {
+		t.Errorf("list = %#v", l)
+	}
+	if got := yamlString(yamlGet(docs[0].Value, "nested", "script")); got != "echo one\necho two" {
+		t.Errorf("block scalar = %q", got)
+	}
+	if l := yamlList(yamlGet(docs[0].Value, "flow")); len(l) != 2 {
+		t.Errorf("flow = %#v", l)
+	}
+	if docs[1].Line != 13 || yamlString(yamlGet(docs[1].Value, "second")) != "true" {
+		t.Errorf("second doc = %+v", docs[1])
+	}
+}
@@ -4,6 +4,10 @@ package analyzer
 
 import (
 	"encoding/base64"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/agnivo988/Repo-lyzer/internal/github"
@@ -11,14 +15,15 @@ import (
 
 // LicenseInfo represents detected license information
 type LicenseInfo struct {
-	Name       string `json:"name"`        // License name (e.g., "MIT", "Apache-2.0")
-	SPDX       string `json:"spdx"`        // SPDX identifier
-	Category   string `json:"category"`    // "permissive", "copyleft", "proprietary"
-	Commercial bool   `json:"commercial"`  // Allows commercial use
-	Modify     bool   `json:"modify"`      // Allows modification
-	Distribute bool   `json:"distribute"`  // Allows distribution
-	Patent     bool   `json:"patent"`      // Includes patent grant
-	SourceFile string `json:"source_file"` // File where license was found
+	Name       string  `json:"name"`                 // License name (e.g., "MIT", "Apache-2.0")
+	SPDX       string  `json:"spdx"`                 // SPDX identifier
+	Category   string  `json:"category"`             // "permissive", "copyleft", "proprietary"
+	Commercial bool    `json:"commercial"`           // Allows commercial use
+	Modify     bool    `json:"modify"`               // Allows modification
+	Distribute bool    `json:"distribute"`           // Allows distribution
+	Patent     bool    `json:"patent"`               // Includes patent grant
+	SourceFile string  `json:"source_file"`          // File where license was found
+	Confidence float64 `json:"confidence,omitempty"` // Similarity of the license text, 0-1
 }
 
 // LicenseAnalysis holds complete license analysis results
@@ -30,9 +35,10 @@ type LicenseAnalysis struct {
 	LicenseScore   int            `json:"license_score"`   // 0-100 score
 }
 
-// Known license patterns for detection
-var licensePatterns = map[string]LicenseInfo{
-	"mit": {
+// knownLicenses describes the licenses by SPDX ID. Each has a license text
+// in licenses/ for detection, except variants listed in licenseVariants.
+var knownLicenses = map[string]LicenseInfo{
+	"MIT": {
 		Name:       "MIT License",
 		SPDX:       "MIT",
 		Category:   "permissive",
@@ -41,43 +47,43 @@ var licensePatterns = map[string]LicenseInfo{
 		Distribute: true,
 		Patent:     false,
 	},
-	"apache": {
-		Name:       "Apache License 2.0",
-		SPDX:       "Apache-2.0",
+	"MIT-0": {
+		Name:       "MIT No Attribution",
+		SPDX:       "MIT-0",
 		Category:   "permissive",
 		Commercial: true,
 		Modify:     true,
 		Distribute: true,
-		Patent:     true,
+		Patent:     false,
 	},
-	"gpl-3": {
-		Name:       "GNU GPL v3",
-		SPDX:       "GPL-3.0",
-		Category:   "copyleft",
+	"ISC": {
+		Name:       "ISC License",
+		SPDX:       "ISC",
+		Category:   "permissive",
 		Commercial: true,
 		Modify:     true,
 		Distribute: true,
-		Patent:     true,
+		Patent:     false,
 	},
-	"gpl-2": {
-		Name:       "GNU GPL v2",
-		SPDX:       "GPL-2.0",
-		Category:   "copyleft",
+	"0BSD": {
+		Name:       "BSD Zero Clause License",
+		SPDX:       "0BSD",
+		Category:   "permissive",
 		Commercial: true,
 		Modify:     true,
 		Distribute: true,
 		Patent:     false,
 	},
-	"lgpl": {
-		Name:       "GNU LGPL",
-		SPDX:       "LGPL-3.0",
-		Category:   "copyleft",
+	"BSD-2-Clause": {
+		Name:       "BSD 2-Clause",
+		SPDX:       "BSD-2-Clause",
+		Category:   "permissive",
 		Commercial: true,
 		Modify:     true,
 		Distribute: true,
 		Patent:     false,
 	},
-	"bsd-3": {
+	"BSD-3-Clause": {
 		Name:       "BSD 3-Clause",
 		SPDX:       "BSD-3-Clause",
 		Category:   "permissive",
@@ -86,34 +92,79 @@ var licensePatterns = map[string]LicenseInfo{
 		Distribute: true,
 		Patent:     false,
 	},
-	"bsd-2": {
-		Name:       "BSD 2-Clause",
-		SPDX:       "BSD-2-Clause",
+	"Apache-2.0": {
+		Name:       "Apache License 2.0",
+		SPDX:       "Apache-2.0",
+		Category:   "permissive",
+		Commercial: true,
+		Modify:     true,
+		Distribute: true,
+		Patent:     true,
+	},
+	"Zlib": {
+		Name:       "zlib License",
+		SPDX:       "Zlib",
 		Category:   "permissive",
 		Commercial: true,
 		Modify:     true,
 		Distribute: true,
 		Patent:     false,
 	},
-	"isc": {
-		Name:       "ISC License",
-		SPDX:       "ISC",
+	"BSL-1.0": {
+		Name:       "Boost Software License 1.0",
+		SPDX:       "BSL-1.0",
 		Category:   "permissive",
 		Commercial: true,
 		Modify:     true,
 		Distribute: true,
 		Patent:     false,
 	},
-	"mpl": {
-		Name:       "Mozilla Public License 2.0",
-		SPDX:       "MPL-2.0",
-		Category:   "copyleft",
+	"BlueOak-1.0.0": {
+		Name:       "Blue Oak Model License 1.0.0",
+		SPDX:       "BlueOak-1.0.0",
+		Category:   "permissive",
+		Commercial: true,
+		Modify:     true,
+		Distribute: true,
+		Patent:     true,
+	},
+	"PSF-2.0": {
+		Name:       "Python Software Foundation License 2.0",
+		SPDX:       "PSF-2.0",
+		Category:   "permissive",
+		Commercial: true,
+		Modify:     true,
+		Distribute: true,
+		Patent:     false,
+	},
+	"Unicode-3.0": {
+		Name:       "Unicode License v3",
+		SPDX:       "Unicode-3.0",
+		Category:   "permissive",
+		Commercial: true,
+		Modify:     true,
+		Distribute: true,
+		Patent:     false,
+	},
+	"Artistic-1.0-Perl": {
+		Name:       "Artistic License 1.0 (Perl)",
+		SPDX:       "Artistic-1.0-Perl",
+		Category:   "permissive",
+		Commercial: true,
+		Modify:     true,
+		Distribute: true,
+		Patent:     false,
+	},
+	"Artistic-2.0": {
+		Name:       "Artistic License 2.0",
+		SPDX:       "Artistic-2.0",
+		Category:   "permissive",
 		Commercial: true,
 		Modify:     true,
 		Distribute: true,
 		Patent:     true,
 	},
-	"unlicense": {
+	"Unlicense": {
 		Name:       "The Unlicense",
 		SPDX:       "Unlicense",
 		Category:   "permissive",
@@ -122,7 +173,7 @@ var licensePatterns = map[string]LicenseInfo{
 		Distribute: true,
 		Patent:     false,
 	},
-	"cc0": {
+	"CC0-1.0": {
 		Name:       "CC0 1.0 Universal",
 		SPDX:       "CC0-1.0",
 		Category:   "permissive",
@@ -131,7 +182,43 @@ var licensePatterns = map[string]LicenseInfo{
 		Distribute: true,
 		Patent:     false,
 	},
-	"agpl": {
+	"WTFPL": {
+		Name:       "Do What The F*ck You Want To Public License",
+		SPDX:       "WTFPL",
+		Category:   "permissive",
+		Commercial: true,
+		Modify:     true,
+		Distribute: true,
+		Patent:     false,
+	},
+	"GPL-1.0": {
+		Name:       "GNU GPL v1",
+		SPDX:       "GPL-1.0",
+		Category:   "copyleft",
+		Commercial: true,
+		Modify:     true,
+		Distribute: true,
+		Patent:     false,
+	},
+	"GPL-2.0": {
+		Name:       "GNU GPL v2",
+		SPDX:       "GPL-2.0",
+		Category:   "copyleft",
+		Commercial: true,
+		Modify:     true,
+		Distribute: true,
+		Patent:     false,
+	},
+	"GPL-3.0": {
+		Name:       "GNU GPL v3",
+		SPDX:       "GPL-3.0",
+		Category:   "copyleft",
+		Commercial: true,
+		Modify:     true,
+		Distribute: true,
+		Patent:     true,
+	},
+	"AGPL-3.0": {
 		Name:       "GNU AGPL v3",
 		SPDX:       "AGPL-3.0",
 		Category:   "copyleft",
@@ -140,6 +227,69 @@ var licensePatterns = map[string]LicenseInfo{
 		Distribute: true,
 		Patent:     true,
 	},
+	"LGPL-2.0": {
+		Name:       "GNU LGPL v2",
+		SPDX:       "LGPL-2.0",
+		Category:   "copyleft",
+		Commercial: true,
+		Modify:     true,
+		Distribute: true,
+		Patent:     false,
+	},
+	"LGPL-2.1": {
+		Name:       "GNU LGPL v2.1",
+		SPDX:       "LGPL-2.1",
+		Category:   "copyleft",
+		Commercial: true,
+		Modify:     true,
+		Distribute: true,
+		Patent:     false,
+	},
+	"LGPL-3.0": {
+		Name:       "GNU LGPL v3",
+		SPDX:       "LGPL-3.0",
+		Category:   "copyleft",
+		Commercial: true,
+		Modify:     true,
+		Distribute: true,
+		Patent:     true,
+	},
+	"MPL-1.1": {
+		Name:       "Mozilla Public License 1.1",
+		SPDX:       "MPL-1.1",
+		Category:   "copyleft",
+		Commercial: true,
+		Modify:     true,
+		Distribute: true,
+		Patent:     true,
+	},
+	"MPL-2.0": {
+		Name:       "Mozilla Public License 2.0",
+		SPDX:       "MPL-2.0",
+		Category:   "copyleft",
+		Commercial: true,
+		Modify:     true,
+		Distribute: true,
+		Patent:     true,
+	},
+	"GFDL-1.2": {
+		Name:       "GNU Free Documentation License v1.2",
+		SPDX:       "GFDL-1.2",
+		Category:   "copyleft",
+		Commercial: true,
+		Modify:     true,
+		Distribute: true,
+		Patent:     false,
+	},
+	"GFDL-1.3": {
+		Name:       "GNU Free Documentation License v1.3",
+		SPDX:       "GFDL-1.3",
+		Category:   "copyleft",
+		Commercial: true,
+		Modify:     true,
+		Distribute: true,
+		Patent:     false,
+	},
 }
 
 // AnalyzeLicense detects and analyzes licenses in a repository
@@ -160,8 +310,8 @@ func AnalyzeLicense(client *github.Client, owner, repo string, fileTree []github
 		return analysis, nil
 	}
 
-	// Analyze each license file
-	for i, path := range licenseFiles {
+	// Analyze each license file; the first license found is the main one
+	for _, path := range licenseFiles {
 		content, err := client.GetFileContent(owner, repo, path)
 		if err != nil {
 			continue
@@ -172,12 +322,11 @@ func AnalyzeLicense(client *github.Client, owner, repo string, fileTree []github
 			continue
 		}
 
-		license := detectLicense(string(decoded), path)
-		if license != nil {
-			if i == 0 {
-				analysis.MainLicense = license
-			} else {
-				analysis.OtherLicenses = append(analysis.OtherLicenses, *license)
+		for _, license := range detectLicenses(string(decoded), path) {
+			if analysis.MainLicense == nil {
+				analysis.MainLicense = &license
+			} else if license.SPDX != analysis.MainLicense.SPDX {
+				analysis.OtherLicenses = append(analysis.OtherLicenses, license)
 			}
 		}
 	}
@@ -193,130 +342,40 @@ func AnalyzeLicense(client *github.Client, owner, repo string, fileTree []github
 	return analysis, nil
 }
 
-// findLicenseFiles finds license files in the file tree
+// licenseFileName matches license file names, including the per-license
+// files of dual-licensed projects such as LICENSE-MIT and COPYING.LESSER
+var licenseFileName = regexp.MustCompile(`^(LICEN[CS]E|COPYING|UNLICENSE)([-._](MIT|APACHE(-?2(\.0)?)?|BSD|ISC|ZLIB|BOOST|MPL|L?GPL|AGPL|LESSER|CC0|0BSD))?(\.MD|\.TXT|\.RST)?$`)
+
+// findLicenseFiles finds license files in the file tree, those closest to
+// the repository root first
 func findLicenseFiles(tree []github.TreeEntry) []string {
 	var files []string
-	licenseNames := []string{
-		"LICENSE",
-		"LICENSE.md",
-		"LICENSE.txt",
-		"LICENCE",
-		"LICENCE.md",
-		"LICENCE.txt",
-		"COPYING",
-		"COPYING.md",
-		"COPYING.txt",
-	}
-
 	for _, entry := range tree {
 		if entry.Type != "blob" {
 			continue
 		}
-
-		// Get filename from path
-		parts := strings.Split(entry.Path, "/")
-		filename := strings.ToUpper(parts[len(parts)-1])
-
-		for _, licenseName := range licenseNames {
-			if filename == licenseName || strings.ToUpper(filename) == licenseName {
-				files = append(files, entry.Path)
-				break
-			}
+		if licenseFileName.MatchString(strings.ToUpper(path.Base(entry.Path))) {
+			files = append(files, entry.Path)
 		}
 	}
 
+	sort.SliceStable(files, func(i, j int) bool {
+		return strings.Count(files[i], "/") < strings.Count(files[j], "/")
+	})
 	return files
 }
 
-// detectLicense detects license type from content
-func detectLicense(content, sourcePath string) *LicenseInfo {
-	contentLower := strings.ToLower(content)
-
-	// Check for specific license patterns
-	if strings.Contains(contentLower, "mit license") ||
-		strings.Contains(contentLower, "permission is hereby granted, free of charge") {
-		license := licensePatterns["mit"]
-		license.SourceFile = sourcePath
-		return &license
-	}
-
-	if strings.Contains(contentLower, "apache license") &&
-		strings.Contains(contentLower, "version 2.0") {
-		license := licensePatterns["apache"]
-		license.SourceFile = sourcePath
-		return &license
-	}
-
-	if strings.Contains(contentLower, "gnu general public license") {
-		if strings.Contains(contentLower, "version 3") {
-			license := licensePatterns["gpl-3"]
-			license.SourceFile = sourcePath
-			return &license
-		}
-		if strings.Contains(contentLower, "version 2") {
-			license := licensePatterns["gpl-2"]
-			license.SourceFile = sourcePath
-			return &license
-		}
-	}
-
-	if strings.Contains(contentLower, "gnu lesser general public license") ||
-		strings.Contains(contentLower, "gnu library general public license") {
-		license := licensePatterns["lgpl"]
-		license.SourceFile = sourcePath
-		return &license
-	}
-
-	if strings.Contains(contentLower, "gnu affero general public license") {
-		license := licensePatterns["agpl"]
-		license.SourceFile = sourcePath
-		return &license
-	}
-
-	if strings.Contains(contentLower, "bsd 3-clause") ||
-		(strings.Contains(contentLower, "redistribution") &&
-			strings.Contains(contentLower, "neither the name")) {
-		license := licensePatterns["bsd-3"]
-		license.SourceFile = sourcePath
-		return &license
-	}
-
-	if strings.Contains(contentLower, "bsd 2-clause") ||
-		(strings.Contains(contentLower, "redistribution") &&
-			!strings.Contains(contentLower, "neither the name") &&
-			strings.Contains(contentLower, "binary form")) {
-		license := licensePatterns["bsd-2"]
-		license.SourceFile = sourcePath
-		return &license
-	}
-
-	if strings.Contains(contentLower, "isc license") {
-		license := licensePatterns["isc"]
-		license.SourceFile = sourcePath
-		return &license
-	}
-
-	if strings.Contains(contentLower, "mozilla public license") {
-		license := licensePatterns["mpl"]
-		license.SourceFile = sourcePath
-		return &license
-	}
-
-	if strings.Contains(contentLower, "unlicense") ||
-		strings.Contains(contentLower, "this is free and unencumbered software") {
-		license := licensePatterns["unlicense"]
-		license.SourceFile = sourcePath
-		return &license
-	}
-
-	if strings.Contains(contentLower, "cc0") ||
-		strings.Contains(contentLower, "creative commons zero") {
-		license := licensePatterns["cc0"]
+// detectLicenses identifies the licenses in a license file's content,
+// with the confidence of each match
+func detectLicenses(content, sourcePath string) []LicenseInfo {
+	var licenses []LicenseInfo
+	for _, m := range matchLicenseText(content) {
+		license := licenseInfo(m.id)
+		license.Confidence = m.confidence
 		license.SourceFile = sourcePath
-		return &license
+		licenses = append(licenses, *license)
 	}
-
-	return nil
+	return licenses
 }
 
 // checkCompatibility checks for license compatibility issues
@@ -393,3 +452,145 @@ func GetLicenseGrade(score int) string {
 		return "F"
 	}
 }
+
+// SPDXExpression combines the licenses of the repository's top-level
+// license files into an SPDX license expression. Several of them are a
+// choice, e.g. "MIT OR Apache-2.0" for LICENSE-MIT and LICENSE-APACHE.
+// Licenses of files in subdirectories only cover part of the tree and are
+// left out. It is empty when no license was detected at the top level, as
+// the project's license can't be told then.
+func (a *LicenseAnalysis) SPDXExpression() string {
+	if a == nil || a.MainLicense == nil || strings.Contains(a.MainLicense.SourceFile, "/") {
+		return ""
+	}
+	ids := []string{a.MainLicense.SPDX}
+	for _, other := range a.OtherLicenses {
+		if other.SPDX != "" && !strings.Contains(other.SourceFile, "/") && !contains(ids, other.SPDX) {
+			ids = append(ids, other.SPDX)
+		}
+	}
+	return strings.Join(ids, " OR ")
+}
+
+// AnalyzeDeclaredLicenses builds a license analysis from declared licenses
+// rather than LICENSE files: the project's SPDX expression and the licenses
+// recorded on its dependencies, as an SBOM provides them. Dependency
+// licenses are reported as other licenses, so a permissive project pulling
+// in copyleft code is flagged as a conflict.
+func AnalyzeDeclaredLicenses(projectLicense string, deps *DependencyAnalysis) *LicenseAnalysis {
+	analysis := &LicenseAnalysis{
+		OtherLicenses: []LicenseInfo{},
+		Warnings:      []string{},
+		Compatibility: "compatible",
+		LicenseScore:  100,
+	}
+
+	if ids := licenseChoice(projectLicense); len(ids) > 0 {
+		analysis.MainLicense = licenseInfo(ids[0])
+		analysis.MainLicense.SourceFile = "SBOM"
+	}
+
+	undeclared := 0
+	seen := make(map[string]bool)
+	if deps != nil {
+		for _, file := range deps.Files {
+			for _, dep := range file.Dependencies {
+				ids := licenseChoice(dep.License)
+				if len(ids) == 0 {
+					undeclared++
+				}
+				for _, id := range ids {
+					info := licenseBySPDX(id)
+					if info == nil || seen[info.SPDX] {
+						continue
+					}
+					seen[info.SPDX] = true
+					info.SourceFile = file.Filename
+					analysis.OtherLicenses = append(analysis.OtherLicenses, *info)
+				}
+			}
+		}
+	}
+
+	if analysis.MainLicense != nil {
+		checkCompatibility(analysis)
+	} else {
+		analysis.Warnings = append(analysis.Warnings, "No project license declared")
+		analysis.LicenseScore = 60
+	}
+	if undeclared > 0 {
+		analysis.Warnings = append(analysis.Warnings, fmt.Sprintf("%d dependencies have no declared license", undeclared))
+	}
+	return analysis
+}
+
+// licenseBySPDX returns a copy of the known license with an SPDX ID,
+// ignoring the "-only", "-or-later" and "+" variants
+func licenseBySPDX(id string) *LicenseInfo {
+	id = strings.TrimSuffix(strings.TrimSuffix(strings.TrimSuffix(id, "+"), "-only"), "-or-later")
+	for spdx, info := range knownLicenses {
+		if strings.EqualFold(spdx, id) {
+			found := info
+			return &found
+		}
+	}
+	return nil
+}
+
+// licenseInfo returns the known license with an SPDX ID, or a license of
+// unknown category named after the ID
+func licenseInfo(id string) *LicenseInfo {
+	if info := licenseBySPDX(id); info != nil {
+		return info
+	}
+	return &LicenseInfo{Name: id, SPDX: id, Category: "unknown"}
+}
+
+// licenseChoice returns the license IDs that apply under an SPDX expression.
+// For a choice ("MIT OR GPL-2.0") the first alternative made only of
+// permissive licenses is taken, as a licensee would.
+func licenseChoice(expression string) []string {
+	alternatives := licenseAlternatives(expression)
+	if len(alternatives) == 1 {
+		return alternatives[0]
+	}
+
+	var all []string
+	for _, alt := range alternatives {
+		permissive := len(alt) > 0
+		for _, id := range alt {
+			if info := licenseBySPDX(id); info == nil || info.Category != "permissive" {
+				permissive = false
+			}
+		}
+		if permissive {
+			return alt
+		}
+		all = append(all, alt...)
+	}
+	return all
+}
+
+// licenseAlternatives splits an SPDX expression into the license IDs of
+// each choice, dropping exceptions after WITH
+func licenseAlternatives(expression string) [][]string {
+	expression = strings.NewReplacer("(", " ", ")", " ").Replace(expression)
+	var alternatives [][]string
+	var current []string
+	skip := false
+	for _, token := range strings.Fields(expression) {
+		switch {
+		case skip:
+			skip = false
+		case strings.EqualFold(token, "WITH"):
+			skip = true
+		case strings.EqualFold(token, "OR"):
+			alternatives = append(alternatives, current)
+			current = nil
+		case strings.EqualFold(token, "AND"):
+		default:
+			current = append(current, token)
+		}
+	}
+	return append(alternatives, current)
+}
@@ -0,0 +1,510 @@
+// Package analyzer provides analysis functions for GitHub repositories.
+// This file checks the licenses of a project's dependencies against a
+// team's license policy.
+package analyzer
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/agnivo988/Repo-lyzer/internal/github"
+)
+
+// Policy statuses of a dependency license
+const (
+	LicenseAllowed = "allowed"
+	LicenseReview  = "review"  // needs a human decision
+	LicenseDenied  = "denied"  // must not be used
+	LicenseUnknown = "unknown" // no license could be found
+)
+
+// licenseStatusRank orders statuses from best to worst
+var licenseStatusRank = map[string]int{LicenseAllowed: 0, LicenseReview: 1, LicenseUnknown: 2, LicenseDenied: 3}
+
+// LicensePolicy is a team's rules for dependency licenses.
+//
+// Each non-empty, non-comment line of a policy file lists SPDX IDs after a
+// prefix. An ID ending in "*" matches a family of licenses:
+//
+//	allow: MIT, Apache-2.0, BSD-*
+//	deny: AGPL-*, UNLICENSED
+//	review: LGPL-*, MPL-2.0
+//
+// With an allow list, licenses on none of the lists need review; without
+// one, everything not denied or marked for review is allowed.
+type LicensePolicy struct {
+	Allow  []string `json:"allow,omitempty"`
+	Deny   []string `json:"deny,omitempty"`
+	Review []string `json:"review,omitempty"`
+	Source string   `json:"source,omitempty"` // file the policy was read from
+}
+
+// LoadLicensePolicy reads a policy file from disk
+func LoadLicensePolicy(path string) (*LicensePolicy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	policy, err := ParseLicensePolicy(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	policy.Source = path
+	return policy, nil
+}
+
+// ParseLicensePolicy parses policy content. Unlike the secret allowlist, an
+// unknown prefix is an error, as a typo would silently let licenses through.
+func ParseLicensePolicy(content string) (*LicensePolicy, error) {
+	p := &LicensePolicy{}
+	for n, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		prefix, list, ok := strings.Cut(line, ":")
+		var target *[]string
+		switch strings.ToLower(strings.TrimSpace(prefix)) {
+		case "allow":
+			target = &p.Allow
+		case "deny":
+			target = &p.Deny
+		case "review":
+			target = &p.Review
+		}
+		if !ok || target == nil {
+			return nil, fmt.Errorf("line %d: expected allow:, deny: or review:, got %q", n+1, line)
+		}
+		*target = append(*target, strings.FieldsFunc(list, func(r rune) bool { return r == ',' || r == ' ' || r == '\t' })...)
+	}
+	return p, nil
+}
+
+// Status returns the status of a single license ID under the policy. A nil
+// policy allows everything.
+func (p *LicensePolicy) Status(id string) string {
+	switch {
+	case p == nil:
+		return LicenseAllowed
+	case matchLicenseIDs(p.Deny, id):
+		return LicenseDenied
+	case matchLicenseIDs(p.Review, id):
+		return LicenseReview
+	case len(p.Allow) == 0, matchLicenseIDs(p.Allow, id):
+		return LicenseAllowed
+	}
+	return LicenseReview
+}
+
+// matchLicenseIDs reports whether id is on a policy list. Case and the
+// "-only", "-or-later" and "+" suffixes don't matter.
+func matchLicenseIDs(list []string, id string) bool {
+	id = baseLicenseID(id)
+	for _, pattern := range list {
+		if ok, _ := path.Match(baseLicenseID(pattern), id); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func baseLicenseID(id string) string {
+	id = strings.ToUpper(strings.TrimSpace(id))
+	return strings.TrimSuffix(strings.TrimSuffix(strings.TrimSuffix(id, "+"), "-ONLY"), "-OR-LATER")
+}
+
+// isStrongCopyleft reports whether a license requires works that include
+// the licensed code to be released under it as a whole
+func isStrongCopyleft(id string) bool {
+	id = baseLicenseID(id)
+	return strings.HasPrefix(id, "GPL-") || strings.HasPrefix(id, "AGPL-")
+}
+
+// LicenseResolver finds the license of a package release. It returns an
+// empty license when none can be found. Resolvers are called concurrently.
+type LicenseResolver interface {
+	ResolveLicense(fileType, name, version string) (license, source string, err error)
+}
+
+// LicenseResolverFunc adapts a function to a LicenseResolver
+type LicenseResolverFunc func(fileType, name, version string) (string, string, error)
+
+// ResolveLicense calls f
+func (f LicenseResolverFunc) ResolveLicense(fileType, name, version string) (string, string, error) {
+	return f(fileType, name, version)
+}
+
+// RegistryLicenseResolver reads licenses from package registry metadata.
+// For packages whose registry records none, and for Go modules, which have
+// no registry metadata, it falls back to the LICENSE file of the package's
+// GitHub repository, if a GitHub client is given.
+type RegistryLicenseResolver struct {
+	registry *registryClient
+	github   *github.Client
+	budget   atomic.Int32 // GitHub lookups left
+}
+
+// NewRegistryLicenseResolver returns a resolver for the given registries.
+// client may be nil; otherwise at most githubLookups LICENSE files are read
+// from GitHub, to stay within the API rate limit.
+func NewRegistryLicenseResolver(opts RegistryOptions, client *github.Client, githubLookups int) *RegistryLicenseResolver {
+	r := &RegistryLicenseResolver{registry: newRegistryClient(opts), github: client}
+	r.budget.Store(int32(githubLookups))
+	return r
+}
+
+// ResolveLicense implements LicenseResolver
+func (r *RegistryLicenseResolver) ResolveLicense(fileType, name, version string) (string, string, error) {
+	meta, err := r.registry.metadata(fileType, name, version)
+	if err != nil && !errors.Is(err, errNotInRegistry) {
+		return "", "", err
+	}
+	repository := ""
+	if meta != nil {
+		if license := spdxFromDeclared(meta.license); license != "" {
+			return license, "registry", nil
+		}
+		repository = meta.repository
+	}
+	if fileType == "go" {
+		repository = goModuleRepository(name)
+	}
+
+	owner, repo, ok := githubRepoFromURL(repository)
+	if !ok || r.github == nil || r.budget.Add(-1) < 0 {
+		return "", "", nil
+	}
+	filePath, content, err := r.github.GetLicenseFile(owner, repo)
+	if err != nil {
+		return "", "", fmt.Errorf("license of %s/%s: %w", owner, repo, err)
+	}
+	decoded, err := base64.StdEncoding.DecodeString(content)
+	if err != nil {
+		return "", "", fmt.Errorf("license of %s/%s: %w", owner, repo, err)
+	}
+	return licenseFileExpression(string(decoded), filePath), "LICENSE file", nil
+}
+
+// licenseFileExpression turns the licenses found in one LICENSE file into an
+// SPDX expression. Several license texts in one file are almost always a
+// choice (e.g. MIT or Apache-2.0), so they're joined with OR
+func licenseFileExpression(content, filePath string) string {
+	var ids []string
+	for _, l := range detectLicenses(content, filePath) {
+		if !contains(ids, l.SPDX) {
+			ids = append(ids, l.SPDX)
+		}
+	}
+	return strings.Join(ids, " OR ")
+}
+
+// goVanityOrgs maps module path prefixes whose repositories live in one
+// GitHub organization to that organization
+var goVanityOrgs = map[string]string{
+	"golang.org/x/": "golang",
+	"go.uber.org/":  "uber-go",
+	"k8s.io/":       "kubernetes",
+	"sigs.k8s.io/":  "kubernetes-sigs",
+}
+
+// goVanityRepos maps module paths to the GitHub repository they are
+// developed in, where the name differs
+var goVanityRepos = map[string]string{
+	"google.golang.org/grpc":     "grpc/grpc-go",
+	"google.golang.org/protobuf": "protocolbuffers/protobuf-go",
+	"google.golang.org/api":      "googleapis/google-api-go-client",
+	"gorm.io/gorm":               "go-gorm/gorm",
+}
+
+// gopkgInPath matches gopkg.in paths: "gopkg.in/yaml.v3" is developed in
+// github.com/go-yaml/yaml, "gopkg.in/user/pkg.v1" in github.com/user/pkg
+var gopkgInPath = regexp.MustCompile(`^gopkg\.in/(?:([\w.-]+)/)?([\w.-]+?)\.v[0-9]+(?:/|$)`)
+
+// goModuleRepository returns the repository URL of a Go module path, for
+// modules hosted on GitHub or developed there under a vanity path
+func goModuleRepository(module string) string {
+	if strings.HasPrefix(module, "github.com/") {
+		return "https://" + module
+	}
+	for prefix, org := range goVanityOrgs {
+		if rest, ok := strings.CutPrefix(module, prefix); ok {
+			return "https://github.com/" + org + "/" + strings.SplitN(rest, "/", 2)[0]
+		}
+	}
+	for path, repo := range goVanityRepos {
+		if module == path || strings.HasPrefix(module, path+"/") {
+			return "https://github.com/" + repo
+		}
+	}
+	if m := gopkgInPath.FindStringSubmatch(module); m != nil {
+		if m[1] == "" {
+			return "https://github.com/go-" + m[2] + "/" + m[2]
+		}
+		return "https://github.com/" + m[1] + "/" + m[2]
+	}
+	return ""
+}
+
+// LicenseComplianceOptions configures CheckLicenseCompliance
+type LicenseComplianceOptions struct {
+	// Resolver looks up licenses that neither lockfiles nor SBOMs record.
+	// Without one those packages are reported as unknown.
+	Resolver    LicenseResolver
+	Policy      *LicensePolicy // optional; without one every license is allowed
+	Concurrency int            // maximum number of lookups in flight
+}
+
+// DefaultLicenseComplianceOptions returns options without a resolver or
+// policy, which only checks for copyleft conflicts
+func DefaultLicenseComplianceOptions() LicenseComplianceOptions {
+	return LicenseComplianceOptions{Concurrency: 8}
+}
+
+// PackageLicense is the license of one dependency and how the policy rates it
+type PackageLicense struct {
+	FileType string `json:"file_type"` // package manager, e.g. "npm"
+	Name     string `json:"name"`
+	Version  string `json:"version,omitempty"`
+	License  string `json:"license,omitempty"` // SPDX expression
+	Source   string `json:"source,omitempty"`  // "lockfile", "registry" or "LICENSE file"
+	Status   string `json:"status"`
+	Reason   string `json:"reason,omitempty"`
+	// Conflict explains why the license is incompatible with the project's
+	// own license, if it is
+	Conflict string `json:"conflict,omitempty"`
+	Dev      bool   `json:"dev,omitempty"`
+	Direct   bool   `json:"direct,omitempty"`
+}
+
+// LicenseCompliance is the result of checking dependency licenses
+type LicenseCompliance struct {
+	ProjectLicense string `json:"project_license,omitempty"`
+	PolicySource   string `json:"policy_source,omitempty"` // "" without a policy
+	// PolicyError is set when the policy file exists but can't be used, so
+	// the check ran without it
+	PolicyError   string           `json:"policy_error,omitempty"`
+	Packages      []PackageLicense `json:"packages"` // worst first
+	AllowedCount  int              `json:"allowed_count"`
+	ReviewCount   int              `json:"review_count"`
+	DeniedCount   int              `json:"denied_count"`
+	UnknownCount  int              `json:"unknown_count"`
+	ConflictCount int              `json:"conflict_count"`
+	// Lookups that failed; those packages are reported as unknown
+	Errors        []string `json:"errors,omitempty"`
+	FailedLookups int      `json:"failed_lookups,omitempty"`
+}
+
+// Violations returns the packages with a denied license or one that
+// conflicts with the project's license
+func (c *LicenseCompliance) Violations() []PackageLicense {
+	var violations []PackageLicense
+	if c == nil {
+		return nil
+	}
+	for _, p := range c.Packages {
+		if p.Status == LicenseDenied || p.Conflict != "" {
+			violations = append(violations, p)
+		}
+	}
+	return violations
+}
+
+// Passed reports whether the dependencies comply with the policy. In strict
+// mode, licenses needing review and packages without a known license fail
+// the check too.
+func (c *LicenseCompliance) Passed(strict bool) bool {
+	if c == nil {
+		return true
+	}
+	if strict && c.ReviewCount+c.UnknownCount > 0 {
+		return false
+	}
+	return len(c.Violations()) == 0
+}
+
+// maxComplianceErrors caps the lookup errors kept, as an unreachable
+// registry fails every package the same way
+const maxComplianceErrors = 5
+
+// CheckLicenseCompliance checks the license of every dependency against the
+// policy, and flags strong copyleft licenses (GPL, AGPL) on the production
+// dependencies of a project that is not itself under one of them; a project
+// without a license is treated as proprietary. Weak copyleft licenses such
+// as the LGPL and MPL don't extend to the project and are not conflicts.
+func CheckLicenseCompliance(project *LicenseAnalysis, deps *DependencyAnalysis, opts LicenseComplianceOptions) *LicenseCompliance {
+	result := &LicenseCompliance{Packages: []PackageLicense{}}
+	if opts.Policy != nil {
+		result.PolicySource = opts.Policy.Source
+		if result.PolicySource == "" {
+			result.PolicySource = "custom policy"
+		}
+	}
+	if project != nil && project.MainLicense != nil {
+		result.ProjectLicense = project.MainLicense.SPDX
+	}
+	if deps == nil {
+		return result
+	}
+
+	// One entry per package release; a package used in production anywhere
+	// counts as a production dependency
+	index := make(map[string]int)
+	for _, file := range deps.Files {
+		if file.FileType == "docker" {
+			continue
+		}
+		for _, dep := range file.Dependencies {
+			version := dep.Resolved
+			if version == "" {
+				version = exactVersion(dep.Version)
+			}
+			key := graphNodeID(file.FileType, lockKey(file.FileType, dep.Name), version)
+			dev := dep.Type == "dev"
+			if i, ok := index[key]; ok {
+				p := &result.Packages[i]
+				p.Dev = p.Dev && dev
+				p.Direct = p.Direct || !dep.Transitive
+				if p.License == "" && dep.License != "" {
+					p.License, p.Source = dep.License, "lockfile"
+				}
+				continue
+			}
+			p := PackageLicense{FileType: file.FileType, Name: dep.Name, Version: version, Dev: dev, Direct: !dep.Transitive}
+			if dep.License != "" {
+				p.License, p.Source = dep.License, "lockfile"
+			}
+			index[key] = len(result.Packages)
+			result.Packages = append(result.Packages, p)
+		}
+	}
+
+	if opts.Resolver != nil {
+		resolveLicenses(result, opts)
+	}
+
+	projectCopyleft := false
+	for _, id := range licenseChoice(result.ProjectLicense) {
+		projectCopyleft = projectCopyleft || isStrongCopyleft(id)
+	}
+	for i := range result.Packages {
+		evaluatePackageLicense(&result.Packages[i], opts.Policy, result.ProjectLicense, projectCopyleft)
+		p := result.Packages[i]
+		switch p.Status {
+		case LicenseAllowed:
+			result.AllowedCount++
+		case LicenseReview:
+			result.ReviewCount++
+		case LicenseDenied:
+			result.DeniedCount++
+		case LicenseUnknown:
+			result.UnknownCount++
+		}
+		if p.Conflict != "" {
+			result.ConflictCount++
+		}
+	}
+
+	sort.SliceStable(result.Packages, func(i, j int) bool {
+		a, b := result.Packages[i], result.Packages[j]
+		if (a.Conflict != "") != (b.Conflict != "") {
+			return a.Conflict != ""
+		}
+		if licenseStatusRank[a.Status] != licenseStatusRank[b.Status] {
+			return licenseStatusRank[a.Status] > licenseStatusRank[b.Status]
+		}
+		return a.Name < b.Name
+	})
+	return result
+}
+
+// resolveLicenses looks up the packages without a license
+func resolveLicenses(result *LicenseCompliance, opts LicenseComplianceOptions) {
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+	var (
+		mu  sync.Mutex
+		wg  sync.WaitGroup
+		sem = make(chan struct{}, concurrency)
+	)
+	for i := range result.Packages {
+		if result.Packages[i].License != "" {
+			continue
+		}
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(p *PackageLicense) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			// Each goroutine writes only its own package
+			license, source, err := opts.Resolver.ResolveLicense(p.FileType, p.Name, p.Version)
+			if err != nil {
+				mu.Lock()
+				defer mu.Unlock()
+				result.FailedLookups++
+				if len(result.Errors) < maxComplianceErrors {
+					result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", p.Name, err))
+				}
+				return
+			}
+			if license != "" {
+				p.License, p.Source = license, source
+			}
+		}(&result.Packages[i])
+	}
+	wg.Wait()
+}
+
+// evaluatePackageLicense sets the status, reason and conflict of a package.
+// For a choice of licenses the alternative the policy rates best is taken,
+// preferring one that doesn't conflict with the project license.
+func evaluatePackageLicense(p *PackageLicense, policy *LicensePolicy, projectLicense string, projectCopyleft bool) {
+	alternatives := licenseAlternatives(p.License)
+	if strings.TrimSpace(p.License) == "" {
+		p.Status, p.Reason = LicenseUnknown, "no license found"
+		return
+	}
+
+	best := -1
+	var bestStatus, bestReason, bestConflict string
+	for i, alt := range alternatives {
+		status, reason, conflict := LicenseAllowed, "", ""
+		for _, id := range alt {
+			s := policy.Status(id)
+			if licenseStatusRank[s] > licenseStatusRank[status] {
+				status = s
+				switch {
+				case s == LicenseDenied:
+					reason = id + " is denied by the policy"
+				case matchLicenseIDs(policy.Review, id):
+					reason = id + " needs review under the policy"
+				default:
+					reason = id + " is not on the allow list"
+				}
+			}
+			if conflict == "" && !p.Dev && !projectCopyleft && isStrongCopyleft(id) {
+				if projectLicense == "" {
+					conflict = id + " code can't be used in a project without an open source license"
+				} else {
+					conflict = id + " requires derivative works to be " + id + " too, but the project is " + projectLicense
+				}
+			}
+		}
+		better := best < 0 ||
+			(conflict == "") != (bestConflict == "") && conflict == "" ||
+			(conflict == "") == (bestConflict == "") && licenseStatusRank[status] < licenseStatusRank[bestStatus]
+		if better {
+			best, bestStatus, bestReason, bestConflict = i, status, reason, conflict
+		}
+	}
+	p.Status, p.Reason, p.Conflict = bestStatus, bestReason, bestConflict
+}
@@ -0,0 +1,243 @@
+package analyzer
+
+import (
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestParseLicensePolicy(t *testing.T) {
+	policy, err := ParseLicensePolicy(`
+# Team policy
+allow: MIT, Apache-2.0 BSD-*
+Deny: AGPL-*
+review: LGPL-2.1, MPL-2.0
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(policy.Allow, ",") != "MIT,Apache-2.0,BSD-*" || strings.Join(policy.Deny, ",") != "AGPL-*" ||
+		len(policy.Review) != 2 {
+		t.Errorf("policy = %+v", policy)
+	}
+
+	tests := map[string]string{
+		"MIT":               LicenseAllowed,
+		"mit":               LicenseAllowed,
+		"BSD-3-Clause":      LicenseAllowed,
+		"AGPL-3.0-only":     LicenseDenied,
+		"LGPL-2.1-or-later": LicenseReview,
+		"LGPL-2.1+":         LicenseReview,
+		"ISC":               LicenseReview, // not on the allow list
+	}
+	for id, want := range tests {
+		if got := policy.Status(id); got != want {
+			t.Errorf("Status(%s) = %s, want %s", id, got, want)
+		}
+	}
+
+	denyOnly, _ := ParseLicensePolicy("deny: GPL-3.0")
+	if denyOnly.Status("ISC") != LicenseAllowed || denyOnly.Status("GPL-3.0-or-later") != LicenseDenied {
+		t.Error("without an allow list, licenses that are not denied are allowed")
+	}
+	var none *LicensePolicy
+	if none.Status("AGPL-3.0") != LicenseAllowed {
+		t.Error("a nil policy allows everything")
+	}
+
+	if _, err := ParseLicensePolicy("allow: MIT\ndenny: GPL-3.0"); err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("typo not reported: %v", err)
+	}
+}
+
+// complianceTestDeps has an npm project with a lockfile license on express
+// and a Go module, with dev and transitive dependencies
+func complianceTestDeps() *DependencyAnalysis {
+	deps := &DependencyAnalysis{}
+	deps.AddFiles(
+		DependencyFile{Filename: "package.json", FileType: "npm", Dependencies: []Dependency{
+			{Name: "express", Version: "^4.18.0", Resolved: "4.18.2", Type: "production", License: "MIT"},
+			{Name: "gpl-lib", Version: "1.0.0", Type: "production"},
+			{Name: "gpl-test-tool", Version: "2.0.0", Type: "dev"},
+			{Name: "dual", Version: "3.0.0", Type: "production"},
+			{Name: "mystery", Version: "0.1.0", Type: "production"},
+			{Name: "weak", Version: "1.2.3", Type: "production", Transitive: true},
+		}},
+		DependencyFile{Filename: "web/package.json", FileType: "npm", Dependencies: []Dependency{
+			{Name: "gpl-test-tool", Version: "2.0.0", Type: "production"},
+		}},
+		DependencyFile{Filename: "go.mod", FileType: "go", Dependencies: []Dependency{
+			{Name: "golang.org/x/net", Version: "v0.10.0", Type: "production"},
+		}},
+		DependencyFile{Filename: "Dockerfile", FileType: "docker", Dependencies: []Dependency{
+			{Name: "node", Version: "20", Type: "production"},
+		}},
+	)
+	return deps
+}
+
+func TestCheckLicenseCompliance(t *testing.T) {
+	var lookups []string
+	resolver := LicenseResolverFunc(func(fileType, name, version string) (string, string, error) {
+		lookups = append(lookups, name+"@"+version)
+		switch name {
+		case "gpl-lib":
+			return "GPL-3.0-or-later", "registry", nil
+		case "gpl-test-tool":
+			return "GPL-2.0-only", "registry", nil
+		case "dual":
+			return "(GPL-2.0 OR MIT)", "registry", nil
+		case "weak":
+			return "LGPL-2.1", "registry", nil
+		case "golang.org/x/net":
+			return "BSD-3-Clause", "LICENSE file", nil
+		}
+		return "", "", nil
+	})
+	project := &LicenseAnalysis{MainLicense: licenseInfo("MIT")}
+	policy, _ := ParseLicensePolicy("allow: MIT, BSD-*\nreview: LGPL-*")
+	opts := DefaultLicenseComplianceOptions()
+	opts.Resolver, opts.Policy, opts.Concurrency = resolver, policy, 1
+
+	result := CheckLicenseCompliance(project, complianceTestDeps(), opts)
+	byName := make(map[string]PackageLicense)
+	for _, p := range result.Packages {
+		byName[p.Name] = p
+	}
+	if len(result.Packages) != 7 || byName["node"].Name != "" {
+		t.Fatalf("packages = %+v", result.Packages)
+	}
+	// Licenses recorded in the lockfile are not looked up again
+	if len(lookups) != 6 || byName["express"].Source != "lockfile" || byName["express"].Status != LicenseAllowed {
+		t.Errorf("lookups = %v, express = %+v", lookups, byName["express"])
+	}
+	if p := byName["gpl-lib"]; p.Conflict == "" || !strings.Contains(p.Conflict, "project is MIT") || p.Status != LicenseReview {
+		t.Errorf("gpl-lib = %+v", p)
+	}
+	// A dev dependency in one manifest is a production one in another
+	if p := byName["gpl-test-tool"]; p.Dev || p.Conflict == "" {
+		t.Errorf("gpl-test-tool = %+v", p)
+	}
+	// The permissive alternative is taken
+	if p := byName["dual"]; p.Status != LicenseAllowed || p.Conflict != "" {
+		t.Errorf("dual = %+v", p)
+	}
+	if p := byName["weak"]; p.Status != LicenseReview || p.Conflict != "" || p.Direct || !strings.Contains(p.Reason, "needs review") {
+		t.Errorf("weak copyleft = %+v", p)
+	}
+	if p := byName["mystery"]; p.Status != LicenseUnknown {
+		t.Errorf("mystery = %+v", p)
+	}
+
+	if result.ConflictCount != 2 || result.AllowedCount != 3 || result.ReviewCount != 3 || result.UnknownCount != 1 ||
+		result.DeniedCount != 0 || result.PolicySource != "custom policy" {
+		t.Errorf("counts = %+v", result)
+	}
+	if result.Packages[0].Conflict == "" || len(result.Violations()) != 2 || result.Passed(false) {
+		t.Errorf("conflicts are violations, sorted first: %+v", result.Packages[0])
+	}
+}
+
+func TestCheckLicenseComplianceDevAndCopyleftProjects(t *testing.T) {
+	deps := &DependencyAnalysis{}
+	deps.AddFiles(DependencyFile{Filename: "package.json", FileType: "npm", Dependencies: []Dependency{
+		{Name: "gpl-test-tool", Version: "2.0.0", Type: "dev", License: "GPL-3.0"},
+		{Name: "agpl-lib", Version: "1.0.0", Type: "production", License: "AGPL-3.0"},
+	}})
+
+	// A GPL project may use GPL code; only the policy matters
+	policy, _ := ParseLicensePolicy("deny: AGPL-*")
+	opts := LicenseComplianceOptions{Policy: policy}
+	result := CheckLicenseCompliance(&LicenseAnalysis{MainLicense: licenseInfo("GPL-3.0")}, deps, opts)
+	if result.ConflictCount != 0 || result.DeniedCount != 1 || result.Passed(false) {
+		t.Errorf("GPL project = %+v", result)
+	}
+
+	// Without a license the project is proprietary, but dev tools are not shipped
+	result = CheckLicenseCompliance(&LicenseAnalysis{}, deps, LicenseComplianceOptions{})
+	if result.ConflictCount != 1 || !strings.Contains(result.Packages[0].Conflict, "without an open source license") ||
+		result.Packages[0].Name != "agpl-lib" {
+		t.Errorf("unlicensed project = %+v", result.Packages)
+	}
+
+	// Strict mode also fails on licenses needing review
+	policy, _ = ParseLicensePolicy("allow: GPL-*")
+	opts.Policy = policy
+	result = CheckLicenseCompliance(&LicenseAnalysis{MainLicense: licenseInfo("AGPL-3.0")}, deps, opts)
+	if !result.Passed(false) || result.Passed(true) || result.ReviewCount != 1 {
+		t.Errorf("strict = %+v", result)
+	}
+}
+
+func TestCheckLicenseComplianceLookupErrors(t *testing.T) {
+	var calls atomic.Int32
+	resolver := LicenseResolverFunc(func(fileType, name, version string) (string, string, error) {
+		calls.Add(1)
+		return "", "", errors.New("registry unreachable")
+	})
+	deps := &DependencyAnalysis{}
+	var many []Dependency
+	for _, name := range strings.Fields("a b c d e f g") {
+		many = append(many, Dependency{Name: name, Version: "1.0.0", Type: "production"})
+	}
+	deps.AddFiles(DependencyFile{Filename: "package.json", FileType: "npm", Dependencies: many})
+
+	result := CheckLicenseCompliance(nil, deps, LicenseComplianceOptions{Resolver: resolver, Concurrency: 3})
+	if calls.Load() != 7 || result.FailedLookups != 7 || len(result.Errors) != maxComplianceErrors || result.UnknownCount != 7 {
+		t.Errorf("result = %+v", result)
+	}
+	if !result.Passed(false) || result.Passed(true) {
+		t.Error("unknown licenses only fail strict checks")
+	}
+}
+
+func TestRegistryLicenseResolver(t *testing.T) {
+	server := fakeRegistries(t)
+	opts := RegistryOptions{NPMURL: server.URL, PyPIURL: server.URL, CratesURL: server.URL}
+	resolver := NewRegistryLicenseResolver(opts, nil, 10)
+
+	tests := []struct{ fileType, name, version, license, source string }{
+		{"npm", "express", "4.18.2", "MIT", "registry"},
+		{"python", "requests", "2.31.0", "Apache-2.0", "registry"},
+		{"rust", "serde", "1.0.200", "MIT OR Apache-2.0", "registry"},
+		// Without a GitHub client there is nothing more to try
+		{"npm", "no-license", "1.0.0", "", ""},
+		{"npm", "missing", "1.0.0", "", ""},
+		{"go", "golang.org/x/net", "v0.10.0", "", ""},
+	}
+	for _, tt := range tests {
+		license, source, err := resolver.ResolveLicense(tt.fileType, tt.name, tt.version)
+		if err != nil || license != tt.license || source != tt.source {
+			t.Errorf("%s = %q from %q, %v", tt.name, license, source, err)
+		}
+	}
+
+	broken := NewRegistryLicenseResolver(RegistryOptions{NPMURL: server.URL + "/broken/"}, nil, 0)
+	if _, _, err := broken.ResolveLicense("npm", "express", "4.18.2"); err != nil {
+		t.Errorf("a missing package is not an error: %v", err)
+	}
+
+	// Several licenses in one LICENSE file are offered as a choice
+	dual := bundledLicense(t, "Apache-2.0") + "\n---\n\n" + bundledLicense(t, "MIT")
+	if got := licenseFileExpression(dual, "LICENSE"); got != "Apache-2.0 OR MIT" {
+		t.Errorf("licenseFileExpression = %q", got)
+	}
+}
+
+func TestGoModuleRepository(t *testing.T) {
+	tests := map[string]string{
+		"golang.org/x/net/http2":   "https://github.com/golang/net",
+		"github.com/gin-gonic/gin": "https://github.com/gin-gonic/gin",
+		"gopkg.in/yaml.v3":         "https://github.com/go-yaml/yaml",
+		"gopkg.in/src-d/go-git.v4": "https://github.com/src-d/go-git",
+		"go.uber.org/zap":          "https://github.com/uber-go/zap",
+		"google.golang.org/grpc":   "https://github.com/grpc/grpc-go",
+		"example.com/private/mod":  "",
+	}
+	for module, want := range tests {
+		if got := goModuleRepository(module); got != want {
+			t.Errorf("goModuleRepository(%s) = %s, want %s", module, got, want)
+		}
+	}
+}
@@ -0,0 +1,188 @@
+// Package analyzer provides analysis functions for GitHub repositories.
+// This file identifies licenses by comparing license texts with the SPDX
+// license list texts bundled in licenses/.
+package analyzer
+
+import (
+	"embed"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+//go:embed licenses/*.txt
+var licenseTexts embed.FS
+
+// minLicenseCoverage is the share of a license text's word pairs a file
+// must contain before that license is considered a match at all
+const minLicenseCoverage = 0.8
+
+// licenseVariants are licenses recognised as a bundled text plus a phrase
+// only the variant has. The AGPL is the GPL v3 with a section on network
+// use, so its text matches the GPL template.
+var licenseVariants = []struct{ base, id, marker string }{
+	{"GPL-3.0", "AGPL-3.0", "remote network interaction"},
+}
+
+var (
+	copyrightLine   = regexp.MustCompile(`(?m)^[\s#*/>-]*(copyright\s*(\(c\)|©|\d{4}|<|\[)|\(c\)\s*(\d{4}|<)|©).*$`)
+	spdxLicenseTag  = regexp.MustCompile(`(?m)SPDX-License-Identifier:\s*([^\r\n]+?)\s*(\*/|-->)?\s*$`)
+	licenseWord     = regexp.MustCompile(`[a-z0-9]+`)
+	licenseSpelling = strings.NewReplacer("licence", "license", "https://", "http://", "&", " and ", "all rights reserved", "")
+)
+
+// licenseTemplate is a bundled license text as a set of word pairs
+type licenseTemplate struct {
+	id    string
+	pairs map[string]bool
+}
+
+// licenseMatch is a license found in a text, with the similarity of the
+// text to the license (0-1)
+type licenseMatch struct {
+	id         string
+	confidence float64
+}
+
+var (
+	licenseTemplatesOnce sync.Once
+	licenseTemplates     []licenseTemplate
+)
+
+// loadLicenseTemplates reads the bundled license texts, named after their
+// SPDX IDs
+func loadLicenseTemplates() []licenseTemplate {
+	licenseTemplatesOnce.Do(func() {
+		entries, _ := licenseTexts.ReadDir("licenses")
+		for _, e := range entries {
+			data, err := licenseTexts.ReadFile(path.Join("licenses", e.Name()))
+			if err != nil {
+				continue
+			}
+			licenseTemplates = append(licenseTemplates, licenseTemplate{
+				id:    strings.TrimSuffix(e.Name(), ".txt"),
+				pairs: wordPairs(normalizeLicenseText(string(data))),
+			})
+		}
+	})
+	return licenseTemplates
+}
+
+// normalizeLicenseText reduces a license text to its words, following the
+// SPDX matching guidelines: case, punctuation, whitespace, copyright lines
+// and spelling variants such as "licence" don't count
+func normalizeLicenseText(text string) []string {
+	text = copyrightLine.ReplaceAllString(strings.ToLower(text), "")
+	return licenseWord.FindAllString(licenseSpelling.Replace(text), -1)
+}
+
+// wordPairs returns the set of adjacent word pairs in a text, which keeps
+// some word order in the comparison
+func wordPairs(words []string) map[string]bool {
+	pairs := make(map[string]bool, len(words))
+	for i := 1; i < len(words); i++ {
+		pairs[words[i-1]+" "+words[i]] = true
+	}
+	if len(words) == 1 {
+		pairs[words[0]] = true
+	}
+	return pairs
+}
+
+// matchLicenseText identifies the licenses in a license file. An
+// SPDX-License-Identifier tag is taken as is; otherwise the text is
+// compared with each bundled license using the Sørensen–Dice coefficient of
+// their word pairs. A file may hold several licenses, as dual-licensed
+// projects often concatenate them, and each is reported with its own
+// confidence.
+func matchLicenseText(content string) []licenseMatch {
+	if tag := spdxLicenseTag.FindStringSubmatch(content); tag != nil {
+		var matches []licenseMatch
+		for _, alt := range licenseAlternatives(tag[1]) {
+			for _, id := range alt {
+				matches = append(matches, licenseMatch{id, 1})
+			}
+		}
+		return matches
+	}
+
+	words := normalizeLicenseText(content)
+	text := wordPairs(words)
+	if len(text) == 0 {
+		return nil
+	}
+
+	type candidate struct {
+		template *licenseTemplate
+		common   int
+		score    int
+	}
+	var candidates []candidate
+	templates := loadLicenseTemplates()
+	for i := range templates {
+		t := &templates[i]
+		common := 0
+		for p := range t.pairs {
+			if text[p] {
+				common++
+			}
+		}
+		if float64(common) < minLicenseCoverage*float64(len(t.pairs)) {
+			continue
+		}
+		// Matched pairs count for a license and missing ones against it, so
+		// that of two nested licenses (ISC and 0BSD, BSD-3-Clause and
+		// BSD-2-Clause) the longer one wins only when its extra clauses are
+		// in the text
+		candidates = append(candidates, candidate{t, common, 2*common - len(t.pairs)})
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].score > candidates[j].score })
+
+	// Take the best license, then any other whose text is mostly not
+	// part of the licenses already taken: a dual-licensed file holds both
+	// texts, while the BSD-2-Clause text inside a BSD-3-Clause one is not a
+	// second license
+	claimed := make(map[string]bool)
+	var found []candidate
+	for _, c := range candidates {
+		fresh := 0
+		for p := range c.template.pairs {
+			if text[p] && !claimed[p] {
+				fresh++
+			}
+		}
+		if len(found) > 0 && 2*fresh < c.common {
+			continue
+		}
+		for p := range c.template.pairs {
+			if text[p] {
+				claimed[p] = true
+			}
+		}
+		found = append(found, c)
+	}
+	unexplained := 0
+	for p := range text {
+		if !claimed[p] {
+			unexplained++
+		}
+	}
+
+	joined := strings.Join(words, " ")
+	var matches []licenseMatch
+	for _, c := range found {
+		id := c.template.id
+		for _, v := range licenseVariants {
+			if v.base == id && strings.Contains(joined, v.marker) {
+				id = v.id
+			}
+		}
+		// The Dice coefficient of the license and its part of the file:
+		// the pairs it matched, plus whatever no license accounts for
+		confidence := 2 * float64(c.common) / float64(len(c.template.pairs)+c.common+unexplained)
+		matches = append(matches, licenseMatch{id, confidence})
+	}
+	return matches
+}
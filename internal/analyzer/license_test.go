@@ -0,0 +1,185 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/agnivo988/Repo-lyzer/internal/github"
+)
+
+func bundledLicense(t *testing.T, id string) string {
+	t.Helper()
+	data, err := licenseTexts.ReadFile("licenses/" + id + ".txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestBundledLicenses(t *testing.T) {
+	templates := loadLicenseTemplates()
+	if len(templates) < 25 {
+		t.Fatalf("only %d license texts bundled", len(templates))
+	}
+	for _, tmpl := range templates {
+		if _, ok := knownLicenses[tmpl.id]; !ok {
+			t.Errorf("%s has a text but no license info", tmpl.id)
+		}
+		matches := matchLicenseText(bundledLicense(t, tmpl.id))
+		if len(matches) != 1 || matches[0].id != tmpl.id || matches[0].confidence < 0.999 {
+			t.Errorf("%s text matched %+v", tmpl.id, matches)
+		}
+	}
+	for id := range knownLicenses {
+		if _, err := licenseTexts.ReadFile("licenses/" + id + ".txt"); err != nil {
+			variant := false
+			for _, v := range licenseVariants {
+				variant = variant || v.id == id
+			}
+			if !variant {
+				t.Errorf("%s has no license text", id)
+			}
+		}
+	}
+}
+
+func TestMatchLicenseText(t *testing.T) {
+	mit := bundledLicense(t, "MIT")
+	bsd3 := bundledLicense(t, "BSD-3-Clause")
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{
+			name: "MIT reflowed with a copyright line and British spelling",
+			text: "The MIT Licence (MIT)\n\nCopyright © 2015-2024 Jane Doe <jane@example.com>\n" +
+				strings.Join(strings.Fields(strings.SplitN(mit, "\n", 4)[3]), " "),
+			want: []string{"MIT"},
+		},
+		{
+			name: "BSD-3-Clause with bullets and a named holder",
+			text: strings.NewReplacer("1. ", "  * ", "2. ", "  * ", "3. ", "  * ", "the copyright holder", "Google LLC").Replace(bsd3),
+			want: []string{"BSD-3-Clause"},
+		},
+		{name: "ISC is not 0BSD", text: bundledLicense(t, "ISC"), want: []string{"ISC"}},
+		{name: "MIT-0 is not MIT", text: bundledLicense(t, "MIT-0"), want: []string{"MIT-0"}},
+		{
+			name: "dual licensed",
+			text: "Licensed under either of the licenses below, at your option.\n\n" +
+				bundledLicense(t, "Apache-2.0") + "\n---\n\n" + mit,
+			want: []string{"Apache-2.0", "MIT"},
+		},
+		{
+			name: "AGPL",
+			text: strings.Replace(bundledLicense(t, "GPL-3.0"), "Use with the GNU Affero General Public License",
+				"Remote Network Interaction; Use with the GNU General Public License", 1),
+			want: []string{"AGPL-3.0"},
+		},
+		{
+			name: "passing mentions",
+			text: "This project is MIT licensed. Test fixtures are CC0, in the spirit of the Unlicense.\n" +
+				"See the GNU General Public License version 3 for why we avoided it.",
+		},
+		{
+			name: "SPDX tag",
+			text: "// SPDX-License-Identifier: (MIT OR Apache-2.0) AND GPL-2.0-only WITH Linux-syscall-note\n",
+			want: []string{"MIT", "Apache-2.0", "GPL-2.0-only"},
+		},
+		{name: "empty", text: "  \n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, m := range matchLicenseText(tt.text) {
+				if m.confidence < 0.9 {
+					t.Errorf("%s matched with confidence %.2f", m.id, m.confidence)
+				}
+				got = append(got, m.id)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLicenseConfidence(t *testing.T) {
+	// A license with an added restriction is still reported, but with less
+	// confidence than the plain text
+	mit := bundledLicense(t, "MIT")
+	modified := mit + "\nThe Software may not be used to train machine learning models, " +
+		"sold as a hosted service, or bundled with any product competing with the original author's products."
+	plain, changed := matchLicenseText(mit), matchLicenseText(modified)
+	if len(changed) != 1 || changed[0].id != "MIT" {
+		t.Fatalf("modified MIT matched %+v", changed)
+	}
+	if changed[0].confidence >= plain[0].confidence || changed[0].confidence < 0.8 {
+		t.Errorf("confidence = %.2f, plain text %.2f", changed[0].confidence, plain[0].confidence)
+	}
+
+	licenses := detectLicenses(modified, "LICENSE")
+	if len(licenses) != 1 || licenses[0].SourceFile != "LICENSE" || licenses[0].Confidence != changed[0].confidence ||
+		licenses[0].Category != "permissive" {
+		t.Errorf("detectLicenses = %+v", licenses)
+	}
+}
+
+func TestFindLicenseFiles(t *testing.T) {
+	var tree []github.TreeEntry
+	for _, path := range []string{
+		"docs/LICENSE", "LICENSE-APACHE", "LICENSE-MIT", "COPYING.LESSER", "licence.md", "UNLICENSE",
+		"internal/license.go", "LICENSES", "LICENSE-THIRD-PARTY", "src/license_test.go",
+	} {
+		tree = append(tree, github.TreeEntry{Path: path, Type: "blob"})
+	}
+	tree = append(tree, github.TreeEntry{Path: "LICENSE", Type: "tree"})
+
+	got := strings.Join(findLicenseFiles(tree), ",")
+	if want := "LICENSE-APACHE,LICENSE-MIT,COPYING.LESSER,licence.md,UNLICENSE,docs/LICENSE"; got != want {
+		t.Errorf("findLicenseFiles = %s, want %s", got, want)
+	}
+}
+
+func TestSPDXExpression(t *testing.T) {
+	info := func(id, file string) LicenseInfo {
+		l := licenseInfo(id)
+		l.SourceFile = file
+		return *l
+	}
+	mit := info("MIT", "LICENSE-MIT")
+	tests := []struct {
+		name     string
+		analysis *LicenseAnalysis
+		want     string
+	}{
+		{"none", &LicenseAnalysis{}, ""},
+		{"single", &LicenseAnalysis{MainLicense: &mit}, "MIT"},
+		{"dual licensed", &LicenseAnalysis{MainLicense: &mit, OtherLicenses: []LicenseInfo{
+			info("Apache-2.0", "LICENSE-APACHE"), info("BSD-3-Clause", "vendor/x/LICENSE"),
+		}}, "MIT OR Apache-2.0"},
+		{"only nested", &LicenseAnalysis{MainLicense: &LicenseInfo{SPDX: "MIT", SourceFile: "docs/LICENSE"}}, ""},
+	}
+	for _, tt := range tests {
+		if got := tt.analysis.SPDXExpression(); got != tt.want {
+			t.Errorf("%s: SPDXExpression = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestLicenseChoice(t *testing.T) {
+	tests := map[string]string{
+		"MIT":                              "MIT",
+		"(MIT OR GPL-3.0-or-later)":        "MIT",
+		"GPL-3.0-only OR LGPL-2.1+":        "GPL-3.0-only,LGPL-2.1+",
+		"Apache-2.0 AND (MIT OR CC0-1.0)":  "Apache-2.0,MIT",
+		"GPL-2.0 WITH Classpath-exception": "GPL-2.0",
+		"":                                 "",
+	}
+	for expr, want := range tests {
+		if got := strings.Join(licenseChoice(expr), ","); got != want {
+			t.Errorf("licenseChoice(%q) = %s, want %s", expr, got, want)
+		}
+	}
+}
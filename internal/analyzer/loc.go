@@ -0,0 +1,343 @@
+// Package analyzer provides functions for analyzing GitHub repository data.
+// This file implements cloc-style line counting per language.
+package analyzer
+
+import (
+	"bytes"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/agnivo988/Repo-lyzer/internal/github"
+)
+
+// LanguageLOC holds line counts for a single language
+type LanguageLOC struct {
+	Language string `json:"language"`
+	Files    int    `json:"files"`
+	Code     int    `json:"code"`
+	Comment  int    `json:"comment"`
+	Blank    int    `json:"blank"`
+}
+
+// CommentDensity returns comment lines as a percentage of code and comment lines
+func (l LanguageLOC) CommentDensity() float64 {
+	if l.Code+l.Comment == 0 {
+		return 0
+	}
+	return float64(l.Comment) / float64(l.Code+l.Comment) * 100
+}
+
+// LOCAnalysis contains line counts for every recognised language
+type LOCAnalysis struct {
+	Languages       []LanguageLOC `json:"languages"` // sorted by code lines, descending
+	Total           LanguageLOC   `json:"total"`
+	FilesEligible   int           `json:"files_eligible"`
+	FilesCounted    int           `json:"files_counted"`
+	BudgetExhausted bool          `json:"budget_exhausted"`
+	Local           bool          `json:"local"`
+}
+
+// Rows returns the per-language counts followed by the total
+func (a *LOCAnalysis) Rows() []LanguageLOC {
+	rows := make([]LanguageLOC, 0, len(a.Languages)+1)
+	rows = append(rows, a.Languages...)
+	return append(rows, a.Total)
+}
+
+// LOCOptions controls which files are counted
+type LOCOptions struct {
+	MaxFiles    int // 0 means no limit, used for local checkouts
+	MaxFileSize int // bytes; larger files are skipped
+}
+
+// DefaultLOCOptions returns the budget used when counting over the GitHub API
+func DefaultLOCOptions() LOCOptions {
+	return LOCOptions{
+		MaxFiles:    60,
+		MaxFileSize: 512 * 1024,
+	}
+}
+
+// commentSyntax describes how comments and strings are written in a language
+type commentSyntax struct {
+	line        []string    // line comment markers
+	block       [][2]string // block comment open/close pairs
+	nested      bool        // block comments nest (Rust)
+	columnBlock [2]string   // block comments that must start at column 0 (Ruby =begin/=end)
+	quotes      string      // string delimiters; backquoted strings may span lines
+}
+
+var (
+	cStyleSyntax  = commentSyntax{line: []string{"//"}, block: [][2]string{{"/*", "*/"}}, quotes: `"'`}
+	goSyntax      = commentSyntax{line: []string{"//"}, block: [][2]string{{"/*", "*/"}}, quotes: "\"'`"}
+	jsSyntax      = goSyntax
+	rustSyntax    = commentSyntax{line: []string{"//"}, block: [][2]string{{"/*", "*/"}}, nested: true, quotes: `"`}
+	pythonSyntax  = commentSyntax{line: []string{"#"}, block: [][2]string{{`"""`, `"""`}, {"'''", "'''"}}, quotes: `"'`}
+	rubySyntax    = commentSyntax{line: []string{"#"}, columnBlock: [2]string{"=begin", "=end"}, quotes: `"'`}
+	shellSyntax   = commentSyntax{line: []string{"#"}, quotes: `"'`}
+	locExtensions = map[string]string{
+		".go":   "Go",
+		".js":   "JavaScript",
+		".jsx":  "JavaScript",
+		".mjs":  "JavaScript",
+		".cjs":  "JavaScript",
+		".ts":   "TypeScript",
+		".tsx":  "TypeScript",
+		".mts":  "TypeScript",
+		".py":   "Python",
+		".pyi":  "Python",
+		".rs":   "Rust",
+		".java": "Java",
+		".c":    "C",
+		".h":    "C/C++ Header",
+		".hh":   "C/C++ Header",
+		".hpp":  "C/C++ Header",
+		".cc":   "C++",
+		".cpp":  "C++",
+		".cxx":  "C++",
+		".rb":   "Ruby",
+		".rake": "Ruby",
+		".sh":   "Shell",
+		".bash": "Shell",
+		".zsh":  "Shell",
+	}
+	locFileNames = map[string]string{
+		"rakefile": "Ruby",
+		"gemfile":  "Ruby",
+	}
+	locSyntax = map[string]commentSyntax{
+		"Go":           goSyntax,
+		"JavaScript":   jsSyntax,
+		"TypeScript":   jsSyntax,
+		"Python":       pythonSyntax,
+		"Rust":         rustSyntax,
+		"Java":         cStyleSyntax,
+		"C":            cStyleSyntax,
+		"C/C++ Header": cStyleSyntax,
+		"C++":          cStyleSyntax,
+		"Ruby":         rubySyntax,
+		"Shell":        shellSyntax,
+	}
+)
+
+// LOCLanguage returns the language a path is counted as, or "" if unsupported
+func LOCLanguage(path string) string {
+	lowerPath := strings.ToLower(path)
+	if lang, ok := locFileNames[baseName(lowerPath)]; ok {
+		return lang
+	}
+	return locExtensions[filepath.Ext(lowerPath)]
+}
+
+// CountLOC counts code, comment and blank lines for the supported languages.
+// Files are read through source; when opts.MaxFiles is set, files are picked
+// round-robin across languages so a small budget still covers all of them.
+func CountLOC(source FileSource, fileTree []github.TreeEntry, opts LOCOptions) (*LOCAnalysis, error) {
+	result := &LOCAnalysis{Languages: []LanguageLOC{}}
+	_, result.Local = source.(LocalFileSource)
+
+	byLang := make(map[string][]string)
+	var order []string
+	for _, entry := range fileTree {
+		if entry.Type != "blob" || (opts.MaxFileSize > 0 && entry.Size > opts.MaxFileSize) {
+			continue
+		}
+		lowerPath := strings.ToLower(entry.Path)
+		if isVendoredPath(lowerPath) || strings.HasSuffix(lowerPath, ".min.js") {
+			continue
+		}
+		lang := LOCLanguage(entry.Path)
+		if lang == "" {
+			continue
+		}
+		if _, ok := byLang[lang]; !ok {
+			order = append(order, lang)
+		}
+		byLang[lang] = append(byLang[lang], entry.Path)
+		result.FilesEligible++
+	}
+
+	stats := make(map[string]*LanguageLOC)
+	for _, path := range pickRoundRobin(byLang, order, opts.MaxFiles) {
+		content, err := source.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		lang := LOCLanguage(path)
+		code, comment, blank := CountLines(content, lang)
+
+		s, ok := stats[lang]
+		if !ok {
+			s = &LanguageLOC{Language: lang}
+			stats[lang] = s
+		}
+		s.Files++
+		s.Code += code
+		s.Comment += comment
+		s.Blank += blank
+		result.FilesCounted++
+	}
+	result.BudgetExhausted = result.FilesCounted < result.FilesEligible
+
+	result.Total.Language = "Total"
+	for _, s := range stats {
+		result.Languages = append(result.Languages, *s)
+		result.Total.Files += s.Files
+		result.Total.Code += s.Code
+		result.Total.Comment += s.Comment
+		result.Total.Blank += s.Blank
+	}
+	sort.Slice(result.Languages, func(i, j int) bool {
+		if result.Languages[i].Code != result.Languages[j].Code {
+			return result.Languages[i].Code > result.Languages[j].Code
+		}
+		return result.Languages[i].Language < result.Languages[j].Language
+	})
+
+	return result, nil
+}
+
+// pickRoundRobin takes one path per language in turn until limit is reached
+func pickRoundRobin(byLang map[string][]string, order []string, limit int) []string {
+	var picked []string
+	for round := 0; ; round++ {
+		added := false
+		for _, lang := range order {
+			if round >= len(byLang[lang]) {
+				continue
+			}
+			if limit > 0 && len(picked) >= limit {
+				return picked
+			}
+			picked = append(picked, byLang[lang][round])
+			added = true
+		}
+		if !added {
+			return picked
+		}
+	}
+}
+
+// CountLines classifies each line of content as code, comment or blank using
+// the comment syntax of language (as returned by LOCLanguage). A line with any
+// code on it counts as code and a line containing only comment text counts
+// as comment. Python docstrings are counted as comments, as cloc does.
+func CountLines(content []byte, language string) (code, comment, blank int) {
+	syntax, ok := locSyntax[language]
+	if !ok || len(content) == 0 {
+		return 0, 0, 0
+	}
+
+	var (
+		depth      int    // block comment nesting depth
+		closer     string // closing marker of the open block comment
+		inColumn   bool   // inside a column-0 block comment
+		quote      byte   // open string delimiter
+		openBlocks = syntax.block
+	)
+
+	lines := bytes.Split(bytes.TrimSuffix(content, []byte("\n")), []byte("\n"))
+	for _, raw := range lines {
+		line := strings.TrimRight(string(raw), "\r")
+		trimmed := strings.TrimSpace(line)
+
+		if inColumn {
+			comment++
+			if strings.HasPrefix(line, syntax.columnBlock[1]) {
+				inColumn = false
+			}
+			continue
+		}
+		if syntax.columnBlock[0] != "" && quote == 0 && depth == 0 && strings.HasPrefix(line, syntax.columnBlock[0]) {
+			inColumn = true
+			comment++
+			continue
+		}
+		if trimmed == "" {
+			blank++
+			continue
+		}
+
+		hasCode, hasComment := false, false
+	scan:
+		for i := 0; i < len(line); {
+			rest := line[i:]
+			switch {
+			case depth > 0:
+				hasComment = true
+				if syntax.nested && strings.HasPrefix(rest, openerFor(openBlocks, closer)) {
+					depth++
+					i += len(openerFor(openBlocks, closer))
+					continue
+				}
+				if strings.HasPrefix(rest, closer) {
+					depth--
+					i += len(closer)
+					continue
+				}
+				i++
+				continue
+			case quote != 0:
+				hasCode = true
+				if line[i] == '\\' && quote != '`' {
+					i += 2
+					continue
+				}
+				if line[i] == quote {
+					quote = 0
+				}
+				i++
+				continue
+			}
+
+			for _, b := range openBlocks {
+				if strings.HasPrefix(rest, b[0]) {
+					depth, closer = 1, b[1]
+					hasComment = true
+					i += len(b[0])
+					continue scan
+				}
+			}
+			for _, marker := range syntax.line {
+				if strings.HasPrefix(rest, marker) {
+					hasComment = true
+					break scan
+				}
+			}
+			if strings.IndexByte(syntax.quotes, line[i]) >= 0 {
+				quote = line[i]
+			}
+			if line[i] != ' ' && line[i] != '\t' {
+				hasCode = true
+			}
+			i++
+		}
+
+		// Only backquoted strings (Go raw strings, JS templates) span lines
+		if quote != '`' {
+			quote = 0
+		}
+
+		switch {
+		case hasCode:
+			code++
+		case hasComment:
+			comment++
+		default:
+			blank++
+		}
+	}
+
+	return code, comment, blank
+}
+
+// openerFor returns the opening marker that pairs with a closing marker
+func openerFor(blocks [][2]string, closer string) string {
+	for _, b := range blocks {
+		if b[1] == closer {
+			return b[0]
+		}
+	}
+	return closer
+}
@@ -0,0 +1,160 @@
+package analyzer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCountLines(t *testing.T) {
+	testCases := []struct {
+		name                 string
+		language             string
+		content              string
+		code, comment, blank int
+	}{
+		{
+			name:     "go comments and strings",
+			language: "Go",
+			content: `// Package main does things
+package main
+
+/*
+ block comment
+*/
+var url = "http://example.com" // trailing comment
+var raw = ` + "`" + `
+// not a comment
+` + "`" + `
+`,
+			code: 5, comment: 4, blank: 1,
+		},
+		{
+			name:     "python docstrings",
+			language: "Python",
+			content: `"""Module docstring.
+
+Spans lines.
+"""
+import os  # comment
+
+def f():
+    # comment
+    return "#not"
+`,
+			code: 3, comment: 4, blank: 2,
+		},
+		{
+			name:     "rust nested block",
+			language: "Rust",
+			content: `/* outer /* inner */ still comment */
+fn main() {}
+`,
+			code: 1, comment: 1, blank: 0,
+		},
+		{
+			name:     "ruby begin end",
+			language: "Ruby",
+			content: `=begin
+doc
+=end
+puts 'hi' # greet
+`,
+			code: 1, comment: 3, blank: 0,
+		},
+		{
+			name:     "shell",
+			language: "Shell",
+			content:  "#!/bin/sh\n\necho \"# not a comment\"\n",
+			code:     1, comment: 1, blank: 1,
+		},
+		{
+			name:     "unsupported language",
+			language: "COBOL",
+			content:  "IDENTIFICATION DIVISION.\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			code, comment, blank := CountLines([]byte(tc.content), tc.language)
+			if code != tc.code || comment != tc.comment || blank != tc.blank {
+				t.Errorf("got code=%d comment=%d blank=%d, want %d/%d/%d",
+					code, comment, blank, tc.code, tc.comment, tc.blank)
+			}
+		})
+	}
+}
+
+func TestCountLOC_Local(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"main.go":                "package main\n\n// entry\nfunc main() {}\n",
+		"web/app.ts":             "/** doc */\nexport const x = 1;\n",
+		"node_modules/lib/a.js":  "var skipped = true;\n",
+		"README.md":              "# Title\n",
+		".git/objects/ignore.go": "package ignored\n",
+	}
+	for path, content := range files {
+		full := filepath.Join(root, filepath.FromSlash(path))
+		if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tree, err := LocalFileTree(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	loc, err := CountLOC(LocalFileSource{Root: root}, tree, LOCOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !loc.Local || loc.FilesCounted != 2 || loc.BudgetExhausted {
+		t.Errorf("unexpected summary: %+v", loc)
+	}
+	if len(loc.Languages) != 2 || loc.Languages[0].Language != "Go" || loc.Languages[0].Code != 2 {
+		t.Errorf("Languages = %+v", loc.Languages)
+	}
+	if loc.Total.Code != 3 || loc.Total.Comment != 2 || loc.Total.Blank != 1 {
+		t.Errorf("Total = %+v", loc.Total)
+	}
+	if d := loc.Total.CommentDensity(); d < 39.9 || d > 40.1 {
+		t.Errorf("CommentDensity = %.2f, want 40", d)
+	}
+}
+
+func TestPickRoundRobin(t *testing.T) {
+	byLang := map[string][]string{
+		"Go":     {"a.go", "b.go", "c.go"},
+		"Python": {"x.py"},
+	}
+	picked := pickRoundRobin(byLang, []string{"Go", "Python"}, 3)
+	want := []string{"a.go", "x.py", "b.go"}
+	if len(picked) != len(want) {
+		t.Fatalf("picked %v, want %v", picked, want)
+	}
+	for i := range want {
+		if picked[i] != want[i] {
+			t.Errorf("picked %v, want %v", picked, want)
+		}
+	}
+}
+
+func TestLOCLanguage(t *testing.T) {
+	for path, want := range map[string]string{
+		"src/lib.rs":     "Rust",
+		"include/foo.h":  "C/C++ Header",
+		"Rakefile":       "Ruby",
+		"scripts/run.sh": "Shell",
+		"docs/index.md":  "",
+	} {
+		if got := LOCLanguage(path); got != want {
+			t.Errorf("LOCLanguage(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
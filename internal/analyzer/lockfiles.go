@@ -0,0 +1,787 @@
+// Package analyzer provides analysis functions for GitHub repositories.
+// This file parses package manager lockfiles for the exact versions that
+// were resolved, including transitive dependencies.
+package analyzer
+
+import (
+	"encoding/json"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// LockedPackage is one package version pinned by a lockfile
+type LockedPackage struct {
+	Name     string   `json:"name"`
+	Version  string   `json:"version"`
+	Dev      bool     `json:"dev,omitempty"`      // only needed for development
+	Direct   bool     `json:"direct,omitempty"`   // declared by the project itself, when the lockfile records it
+	Requires []string `json:"requires,omitempty"` // names of the packages it depends on
+	// Pinned holds the exact version of each required package, when the
+	// lockfile records which of several installed versions is used
+	Pinned map[string]string `json:"pinned,omitempty"`
+	// License is the SPDX expression the lockfile records (npm copies it
+	// from each package's manifest)
+	License string `json:"license,omitempty"`
+	// InstallScript is set when the package runs a script on install
+	// (npm's hasInstallScript, pnpm's requiresBuild)
+	InstallScript bool `json:"install_script,omitempty"`
+}
+
+// LockFile holds the packages pinned by a single lockfile
+type LockFile struct {
+	Filename string          `json:"filename"`
+	FileType string          `json:"file_type"` // package manager type, as in DependencyFile
+	Packages []LockedPackage `json:"packages"`
+}
+
+// lockFileFormat describes a supported lockfile and the manifest it locks
+type lockFileFormat struct {
+	fileType string
+	manifest string
+	parse    func([]byte) []LockedPackage
+}
+
+var lockFileFormats = map[string]lockFileFormat{
+	"package-lock.json": {"npm", "package.json", parsePackageLock},
+	"yarn.lock":         {"npm", "package.json", parseYarnLock},
+	"pnpm-lock.yaml":    {"npm", "package.json", parsePnpmLock},
+	"go.sum":            {"go", "go.mod", parseGoSum},
+	"Cargo.lock":        {"rust", "Cargo.toml", parseCargoLock},
+	"poetry.lock":       {"python", "pyproject.toml", parsePoetryLock},
+	"Pipfile.lock":      {"python", "Pipfile", parsePipfileLock},
+	"Gemfile.lock":      {"ruby", "Gemfile", parseGemfileLock},
+}
+
+// IsLockFile reports whether path is a lockfile that ParseLockFile understands
+func IsLockFile(path string) bool {
+	_, ok := lockFileFormats[baseName(path)]
+	return ok
+}
+
+// ParseLockFile parses a supported lockfile, chosen by file name. It returns
+// nil for other files and for lockfiles that pin nothing.
+func ParseLockFile(filename string, content []byte) *LockFile {
+	format, ok := lockFileFormats[baseName(filename)]
+	if !ok {
+		return nil
+	}
+	packages := format.parse(content)
+	if len(packages) == 0 {
+		return nil
+	}
+	return &LockFile{Filename: filename, FileType: format.fileType, Packages: packages}
+}
+
+// ApplyLockFiles records the versions pinned by lockfiles on the manifests
+// they lock. A lockfile applies to the matching manifests in its directory
+// and below (covering npm and Cargo workspaces) unless a deeper lockfile
+// claims them. Each manifest dependency gets its Resolved version, and the
+// lockfile's remaining packages are added as transitive dependencies of the
+// manifest next to it, or of a file named after the lockfile if there is no
+// such manifest.
+func (a *DependencyAnalysis) ApplyLockFiles(locks ...*LockFile) {
+	var valid []*LockFile
+	for _, lock := range locks {
+		if lock != nil {
+			valid = append(valid, lock)
+		}
+	}
+	locks = valid
+
+	// Deepest lockfiles first so that they claim their manifests
+	sort.SliceStable(locks, func(i, j int) bool {
+		return strings.Count(locks[i].Filename, "/") > strings.Count(locks[j].Filename, "/")
+	})
+
+	claimed := make(map[int]bool)
+	for _, lock := range locks {
+		a.LockFiles = append(a.LockFiles, lock)
+		dir := path.Dir(lock.Filename)
+		manifest := lockFileFormats[baseName(lock.Filename)].manifest
+
+		// Packages listed by a manifest, or marked direct by the lockfile,
+		// are not added again as transitive dependencies
+		byName := make(map[string][]LockedPackage)
+		listed := make(map[string]bool)
+		for _, p := range lock.Packages {
+			key := lockKey(lock.FileType, p.Name)
+			byName[key] = append(byName[key], p)
+			if p.Direct {
+				listed[key] = true
+			}
+		}
+
+		var own *DependencyFile
+		for i := range a.Files {
+			file := &a.Files[i]
+			if claimed[i] || file.FileType != lock.FileType || baseName(file.Filename) != manifest || !isWithinDir(path.Dir(file.Filename), dir) {
+				continue
+			}
+			claimed[i] = true
+			file.LockFile = lock.Filename
+			if path.Dir(file.Filename) == dir {
+				own = file
+			}
+			for j := range file.Dependencies {
+				dep := &file.Dependencies[j]
+				key := lockKey(lock.FileType, dep.Name)
+				if locked := pickLockedVersion(byName[key], dep.Version); locked != "" {
+					dep.Resolved = locked
+					for _, p := range byName[key] {
+						if p.Version == locked && dep.License == "" {
+							dep.License = p.License
+						}
+					}
+				}
+				listed[key] = true
+			}
+		}
+
+		var transitive []Dependency
+		seen := make(map[string]bool)
+		for _, p := range lock.Packages {
+			key := lockKey(lock.FileType, p.Name)
+			if listed[key] || seen[key+"@"+p.Version] {
+				continue
+			}
+			seen[key+"@"+p.Version] = true
+			depType := "production"
+			if p.Dev {
+				depType = "dev"
+			}
+			transitive = append(transitive, Dependency{
+				Name:       p.Name,
+				Version:    p.Version,
+				Resolved:   p.Version,
+				Type:       depType,
+				Transitive: true,
+				License:    p.License,
+			})
+		}
+		if len(transitive) == 0 {
+			continue
+		}
+		sort.SliceStable(transitive, func(i, j int) bool { return transitive[i].Name < transitive[j].Name })
+
+		if own == nil {
+			a.AddFiles(DependencyFile{Filename: lock.Filename, FileType: lock.FileType, LockFile: lock.Filename})
+			own = &a.Files[len(a.Files)-1]
+			claimed[len(a.Files)-1] = true
+		}
+		own.Dependencies = append(own.Dependencies, transitive...)
+		own.TotalCount = len(own.Dependencies)
+		a.TotalDeps += len(transitive)
+		a.TransitiveDeps += len(transitive)
+	}
+	a.HasLockFile = a.HasLockFile || len(locks) > 0
+}
+
+// lockKey normalizes a package name for matching manifests against lockfiles
+func lockKey(fileType, name string) string {
+	if fileType == "python" {
+		return normalizePythonName(name)
+	}
+	return name
+}
+
+// isWithinDir reports whether dir is root or below it
+func isWithinDir(dir, root string) bool {
+	return root == "." || dir == root || strings.HasPrefix(dir, root+"/")
+}
+
+// pickLockedVersion chooses the locked version for a manifest constraint.
+// With several versions locked (nested npm installs, go.sum history) the one
+// equal to the constraint wins, otherwise the first, which parsers list as
+// the top-level install.
+func pickLockedVersion(candidates []LockedPackage, constraint string) string {
+	if len(candidates) == 0 {
+		return ""
+	}
+	for _, c := range candidates {
+		if c.Version == strings.TrimLeft(constraint, "=v ") || c.Version == constraint {
+			return c.Version
+		}
+	}
+	return candidates[0].Version
+}
+
+// parsePackageLock parses npm's package-lock.json (and npm-shrinkwrap
+// format): the flat "packages" map of lockfile v2/v3, or the nested
+// "dependencies" tree of v1.
+func parsePackageLock(content []byte) []LockedPackage {
+	type lockEntry struct {
+		Name                 string            `json:"name"`
+		Version              string            `json:"version"`
+		Dev                  bool              `json:"dev"`
+		Link                 bool              `json:"link"`
+		License              string            `json:"license"`
+		HasInstallScript     bool              `json:"hasInstallScript"`
+		Dependencies         map[string]string `json:"dependencies"`
+		DevDependencies      map[string]string `json:"devDependencies"`
+		OptionalDependencies map[string]string `json:"optionalDependencies"`
+		PeerDependencies     map[string]string `json:"peerDependencies"`
+	}
+	var lock struct {
+		Packages     map[string]lockEntry `json:"packages"`
+		Dependencies json.RawMessage      `json:"dependencies"`
+	}
+	if err := json.Unmarshal(content, &lock); err != nil {
+		return nil
+	}
+
+	if len(lock.Packages) == 0 {
+		return parsePackageLockV1(lock.Dependencies)
+	}
+
+	// The root ("") and workspace entries declare the direct dependencies
+	direct := make(map[string]bool)
+	for key, entry := range lock.Packages {
+		if strings.Contains(key, "node_modules/") {
+			continue
+		}
+		for _, deps := range []map[string]string{entry.Dependencies, entry.DevDependencies, entry.OptionalDependencies, entry.PeerDependencies} {
+			for name := range deps {
+				direct[name] = true
+			}
+		}
+	}
+
+	installed := make(map[string]string, len(lock.Packages))
+	for key, entry := range lock.Packages {
+		if !entry.Link && entry.Version != "" {
+			installed[key] = entry.Version
+		}
+	}
+
+	// Top-level installs first, so they are picked over nested copies
+	keys := sortedKeys(lock.Packages)
+	sort.SliceStable(keys, func(i, j int) bool {
+		return strings.Count(keys[i], "node_modules/") < strings.Count(keys[j], "node_modules/")
+	})
+	var packages []LockedPackage
+	for _, key := range keys {
+		entry := lock.Packages[key]
+		i := strings.LastIndex(key, "node_modules/")
+		if i < 0 || entry.Link || entry.Version == "" {
+			continue
+		}
+		name := key[i+len("node_modules/"):]
+		if entry.Name != "" {
+			name = entry.Name // aliased install
+		}
+		var requires []string
+		pinned := make(map[string]string)
+		for _, deps := range []map[string]string{entry.Dependencies, entry.OptionalDependencies, entry.PeerDependencies} {
+			for _, dep := range sortedKeys(deps) {
+				requires = append(requires, dep)
+				if version := resolveNodeModule(installed, key, dep); version != "" {
+					pinned[dep] = version
+				}
+			}
+		}
+		packages = append(packages, LockedPackage{
+			Name:          name,
+			Version:       entry.Version,
+			Dev:           entry.Dev,
+			Direct:        direct[name] && strings.Count(key, "node_modules/") == 1, // nested copies are transitive
+			Requires:      requires,
+			Pinned:        pinned,
+			License:       entry.License,
+			InstallScript: entry.HasInstallScript,
+		})
+	}
+	sortLockedPackages(packages)
+	return packages
+}
+
+// resolveNodeModule finds the version of name that the package installed at
+// key loads, following Node's lookup through parent node_modules folders
+func resolveNodeModule(installed map[string]string, key, name string) string {
+	dir := key
+	for {
+		candidate := "node_modules/" + name
+		if dir != "" {
+			candidate = dir + "/" + candidate
+		}
+		if version, ok := installed[candidate]; ok {
+			return version
+		}
+		if dir == "" {
+			return ""
+		}
+		if i := strings.LastIndex(dir, "node_modules/"); i > 0 {
+			dir = strings.TrimSuffix(dir[:i], "/")
+		} else {
+			dir = ""
+		}
+	}
+}
+
+// parsePackageLockV1 walks the nested dependency tree of lockfile v1
+func parsePackageLockV1(raw json.RawMessage) []LockedPackage {
+	type v1Entry struct {
+		Version      string                     `json:"version"`
+		Dev          bool                       `json:"dev"`
+		Requires     map[string]string          `json:"requires"`
+		Dependencies map[string]json.RawMessage `json:"dependencies"`
+	}
+	var packages []LockedPackage
+	var walk func(raw json.RawMessage, depth int)
+	walk = func(raw json.RawMessage, depth int) {
+		var deps map[string]v1Entry
+		if json.Unmarshal(raw, &deps) != nil {
+			return
+		}
+		for _, name := range sortedKeys(deps) {
+			entry := deps[name]
+			packages = append(packages, LockedPackage{
+				Name:     name,
+				Version:  entry.Version,
+				Dev:      entry.Dev,
+				Requires: sortedKeys(entry.Requires),
+			})
+			if len(entry.Dependencies) > 0 && depth < 64 {
+				nested, _ := json.Marshal(entry.Dependencies)
+				walk(nested, depth+1)
+			}
+		}
+	}
+	walk(raw, 0)
+	sortLockedPackages(packages)
+	return packages
+}
+
+// yarnDescriptorName returns the package name of a descriptor such as
+// "@babel/core@^7.0.0" or "react@npm:^18.0.0"
+func yarnDescriptorName(descriptor string) string {
+	descriptor = strings.Trim(strings.TrimSpace(descriptor), `"`)
+	if i := strings.Index(descriptor[min(1, len(descriptor)):], "@"); i >= 0 {
+		return descriptor[:i+1]
+	}
+	return descriptor
+}
+
+// parseYarnLock parses yarn.lock in either the classic v1 format or the
+// YAML format used by Yarn 2+ (berry). Workspace entries are skipped, but
+// their dependencies are marked direct.
+func parseYarnLock(content []byte) []LockedPackage {
+	if strings.Contains(string(content), "__metadata:") {
+		return parseYarnBerryLock(content)
+	}
+
+	var packages []LockedPackage
+	var current *LockedPackage
+	inDeps := false
+	resolved := make(map[string]int)          // descriptor -> index into packages
+	ranges := make(map[int]map[string]string) // package index -> required ranges
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimRight(line, "\r")
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		indent := len(line) - len(strings.TrimLeft(line, " "))
+
+		switch {
+		case indent == 0 && strings.HasSuffix(trimmed, ":"):
+			descriptors := strings.Split(strings.TrimSuffix(trimmed, ":"), ",")
+			for _, d := range descriptors {
+				resolved[strings.Trim(strings.TrimSpace(d), `"`)] = len(packages)
+			}
+			packages = append(packages, LockedPackage{Name: yarnDescriptorName(descriptors[0])})
+			current = &packages[len(packages)-1]
+			inDeps = false
+		case current == nil:
+		case indent == 2:
+			key, value, _ := strings.Cut(trimmed, " ")
+			inDeps = key == "dependencies:" || key == "optionalDependencies:"
+			if key == "version" {
+				current.Version = strings.Trim(value, `"`)
+			}
+		case indent >= 4 && inDeps:
+			name, spec, _ := strings.Cut(trimmed, " ")
+			name = strings.Trim(name, `"`)
+			current.Requires = append(current.Requires, name)
+			i := len(packages) - 1
+			if ranges[i] == nil {
+				ranges[i] = make(map[string]string)
+			}
+			ranges[i][name] = strings.Trim(strings.TrimSpace(spec), `"`)
+		}
+	}
+	for i, deps := range ranges {
+		packages[i].Pinned = make(map[string]string)
+		for name, spec := range deps {
+			if j, ok := resolved[name+"@"+spec]; ok && packages[j].Version != "" {
+				packages[i].Pinned[name] = packages[j].Version
+			}
+		}
+	}
+	sortLockedPackages(packages)
+	return packages
+}
+
+// parseYarnBerryLock parses the YAML lockfile written by Yarn 2 and later
+func parseYarnBerryLock(content []byte) []LockedPackage {
+	docs := parseYAMLDocuments(string(content))
+	if len(docs) == 0 {
+		return nil
+	}
+	entries, _ := docs[0].Value.(map[string]interface{})
+
+	versions := make(map[string]string) // descriptor -> locked version
+	for key, entry := range entries {
+		for _, descriptor := range strings.Split(key, ",") {
+			versions[strings.TrimSpace(descriptor)] = yamlString(yamlGet(entry, "version"))
+		}
+	}
+
+	direct := make(map[string]bool)
+	var packages []LockedPackage
+	for _, key := range sortedKeys(entries) {
+		if key == "__metadata" {
+			continue
+		}
+		entry := entries[key]
+		deps, _ := yamlGet(entry, "dependencies").(map[string]interface{})
+		if strings.Contains(key, "@workspace:") {
+			for name := range deps {
+				direct[name] = true
+			}
+			continue
+		}
+		version := yamlString(yamlGet(entry, "version"))
+		if version == "" || strings.Contains(key, "@patch:") {
+			continue
+		}
+		pinned := make(map[string]string)
+		for name, spec := range deps {
+			spec := yamlString(spec)
+			for _, descriptor := range []string{name + "@" + spec, name + "@npm:" + spec} {
+				if v := versions[descriptor]; v != "" {
+					pinned[name] = v
+					break
+				}
+			}
+		}
+		packages = append(packages, LockedPackage{
+			Name:     yarnDescriptorName(strings.Split(key, ",")[0]),
+			Version:  version,
+			Requires: sortedKeys(deps),
+			Pinned:   pinned,
+		})
+	}
+	for i := range packages {
+		packages[i].Direct = direct[packages[i].Name]
+	}
+	sortLockedPackages(packages)
+	return packages
+}
+
+// parsePnpmLock parses pnpm-lock.yaml for lockfile versions 5 through 9.
+// Direct dependencies come from the importers (or the top-level
+// dependency maps of single-project lockfiles).
+func parsePnpmLock(content []byte) []LockedPackage {
+	docs := parseYAMLDocuments(string(content))
+	if len(docs) == 0 {
+		return nil
+	}
+	root := docs[0].Value
+
+	importers := []interface{}{root}
+	if m, ok := yamlGet(root, "importers").(map[string]interface{}); ok {
+		importers = nil
+		for _, key := range sortedKeys(m) {
+			importers = append(importers, m[key])
+		}
+	}
+	direct := make(map[string]bool)
+	production := make(map[string]bool)
+	for _, importer := range importers {
+		for _, section := range []string{"dependencies", "optionalDependencies", "devDependencies"} {
+			deps, _ := yamlGet(importer, section).(map[string]interface{})
+			for name := range deps {
+				direct[name] = true
+				production[name] = production[name] || section != "devDependencies"
+			}
+		}
+	}
+
+	// Lockfile v9 moves dependency lists from packages to snapshots
+	snapshots, _ := yamlGet(root, "snapshots").(map[string]interface{})
+	entries, _ := yamlGet(root, "packages").(map[string]interface{})
+	if len(snapshots) > 0 {
+		entries = snapshots
+	}
+
+	var packages []LockedPackage
+	seen := make(map[string]bool)
+	for _, key := range sortedKeys(entries) {
+		name, version := splitPnpmKey(key)
+		if name == "" || seen[name+"@"+version] {
+			continue
+		}
+		seen[name+"@"+version] = true
+		entry := entries[key]
+		var requires []string
+		pinned := make(map[string]string)
+		for _, section := range []string{"dependencies", "optionalDependencies"} {
+			deps, _ := yamlGet(entry, section).(map[string]interface{})
+			for _, dep := range sortedKeys(deps) {
+				requires = append(requires, dep)
+				if v := pnpmVersion(yamlString(deps[dep])); v != "" {
+					pinned[dep] = v
+				}
+			}
+		}
+		packages = append(packages, LockedPackage{
+			Name:          name,
+			Version:       version,
+			Dev:           yamlString(yamlGet(entry, "dev")) == "true" || (direct[name] && !production[name]),
+			Direct:        direct[name],
+			Requires:      requires,
+			Pinned:        pinned,
+			InstallScript: yamlString(yamlGet(entry, "requiresBuild")) == "true",
+		})
+	}
+	sortLockedPackages(packages)
+	return packages
+}
+
+// splitPnpmKey splits a pnpm package key such as "/@babel/core@7.23.0",
+// "react@18.2.0(react-dom@18.2.0)" or the v5 form "/react/18.2.0_peer@1.0.0"
+func splitPnpmKey(key string) (string, string) {
+	key = strings.TrimPrefix(key, "/")
+	if i := strings.Index(key, "("); i >= 0 {
+		key = key[:i] // peer dependency suffix
+	}
+	if i := strings.LastIndex(key, "@"); i > 0 {
+		return key[:i], key[i+1:]
+	}
+	i := strings.LastIndex(key, "/")
+	if i <= 0 {
+		return "", ""
+	}
+	version := key[i+1:]
+	if j := strings.Index(version, "_"); j >= 0 {
+		version = version[:j]
+	}
+	return key[:i], version
+}
+
+// pnpmVersion extracts the version from a pnpm dependency reference such as
+// "18.2.0(react@18.2.0)" or "1.0.0_peer@2.0.0". Links to local packages and
+// aliases have no registry version.
+func pnpmVersion(ref string) string {
+	if i := strings.IndexAny(ref, "(_"); i >= 0 {
+		ref = ref[:i]
+	}
+	if strings.HasPrefix(ref, "link:") || strings.HasPrefix(ref, "file:") || strings.Contains(ref, "@") {
+		return ""
+	}
+	return ref
+}
+
+// parseGoSum parses go.sum. Modules whose source was downloaded (lines
+// without the /go.mod suffix) are reported at their highest listed
+// version; modules needed only for their go.mod are left out.
+func parseGoSum(content []byte) []LockedPackage {
+	versions := make(map[string]string)
+	for _, line := range strings.Split(string(content), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 3 || strings.HasSuffix(fields[1], "/go.mod") {
+			continue
+		}
+		if current, ok := versions[fields[0]]; !ok || compareGoVersions(fields[1], current) > 0 {
+			versions[fields[0]] = fields[1]
+		}
+	}
+	var packages []LockedPackage
+	for _, name := range sortedKeys(versions) {
+		packages = append(packages, LockedPackage{Name: name, Version: versions[name]})
+	}
+	return packages
+}
+
+// parseCargoLock parses Cargo.lock. Packages without a source are the
+// workspace's own crates; what they depend on is direct.
+func parseCargoLock(content []byte) []LockedPackage {
+	doc := parseTOML(string(content))
+	direct := make(map[string]bool)
+	var packages []LockedPackage
+	for _, entry := range yamlList(doc["package"]) {
+		var requires []string
+		pinned := make(map[string]string)
+		for _, dep := range tomlStrings(yamlGet(entry, "dependencies")) {
+			// "name" or, when several versions are locked, "name version"
+			fields := strings.Fields(dep)
+			if len(fields) == 0 {
+				continue
+			}
+			requires = append(requires, fields[0])
+			if len(fields) > 1 {
+				pinned[fields[0]] = fields[1]
+			}
+		}
+		if yamlGet(entry, "source") == nil {
+			for _, name := range requires {
+				direct[name] = true
+			}
+			continue
+		}
+		packages = append(packages, LockedPackage{
+			Name:     yamlString(yamlGet(entry, "name")),
+			Version:  yamlString(yamlGet(entry, "version")),
+			Requires: requires,
+			Pinned:   pinned,
+		})
+	}
+	for i := range packages {
+		packages[i].Direct = direct[packages[i].Name]
+	}
+	sortLockedPackages(packages)
+	return packages
+}
+
+// parsePoetryLock parses poetry.lock. Development packages are marked
+// with category = "dev" (Poetry < 1.5) or belong only to non-main groups.
+func parsePoetryLock(content []byte) []LockedPackage {
+	doc := parseTOML(string(content))
+	var packages []LockedPackage
+	for _, entry := range yamlList(doc["package"]) {
+		dev := yamlString(yamlGet(entry, "category")) == "dev"
+		if groups := tomlStrings(yamlGet(entry, "groups")); len(groups) > 0 {
+			dev = !contains(groups, "main")
+		}
+		deps, _ := yamlGet(entry, "dependencies").(map[string]interface{})
+		packages = append(packages, LockedPackage{
+			Name:     yamlString(yamlGet(entry, "name")),
+			Version:  yamlString(yamlGet(entry, "version")),
+			Dev:      dev,
+			Requires: sortedKeys(deps),
+		})
+	}
+	sortLockedPackages(packages)
+	return packages
+}
+
+// parsePipfileLock parses Pipfile.lock, whose "default" and "develop"
+// sections pin production and development packages
+func parsePipfileLock(content []byte) []LockedPackage {
+	var lock map[string]json.RawMessage
+	if err := json.Unmarshal(content, &lock); err != nil {
+		return nil
+	}
+	var packages []LockedPackage
+	for _, section := range []string{"default", "develop"} {
+		var entries map[string]struct {
+			Version string `json:"version"`
+		}
+		if json.Unmarshal(lock[section], &entries) != nil {
+			continue
+		}
+		for _, name := range sortedKeys(entries) {
+			version := strings.TrimPrefix(entries[name].Version, "==")
+			if version == "" {
+				continue // VCS or path install
+			}
+			packages = append(packages, LockedPackage{Name: name, Version: version, Dev: section == "develop"})
+		}
+	}
+	sortLockedPackages(packages)
+	return packages
+}
+
+// gemfileLockSpec matches "    name (version)" spec lines in Gemfile.lock
+var gemfileLockSpec = regexp.MustCompile(`^( {4}| {6})(\S+)(?: \(([^)]*)\))?$`)
+
+// parseGemfileLock parses Gemfile.lock. Gems listed under DEPENDENCIES are
+// direct; platform suffixes such as "-x86_64-linux" are dropped.
+func parseGemfileLock(content []byte) []LockedPackage {
+	var packages []LockedPackage
+	direct := make(map[string]bool)
+	section := ""
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimRight(line, "\r")
+		if line != "" && line[0] != ' ' {
+			section = strings.TrimSpace(line)
+			continue
+		}
+		if section == "DEPENDENCIES" {
+			if name := strings.Fields(line); len(name) > 0 {
+				direct[strings.TrimSuffix(name[0], "!")] = true
+			}
+			continue
+		}
+		if section != "GEM" && section != "GIT" && section != "PATH" {
+			continue
+		}
+		m := gemfileLockSpec.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		if len(m[1]) == 4 {
+			version := m[3]
+			if i := strings.Index(version, "-"); i >= 0 {
+				version = version[:i]
+			}
+			packages = append(packages, LockedPackage{Name: m[2], Version: version})
+		} else if len(packages) > 0 {
+			last := &packages[len(packages)-1]
+			last.Requires = append(last.Requires, m[2])
+		}
+	}
+	for i := range packages {
+		packages[i].Direct = direct[packages[i].Name]
+	}
+	sortLockedPackages(packages)
+	return packages
+}
+
+// sortLockedPackages orders packages by name, keeping the parser's order
+// between versions of the same package
+func sortLockedPackages(packages []LockedPackage) {
+	sort.SliceStable(packages, func(i, j int) bool { return packages[i].Name < packages[j].Name })
+}
+
+// compareVersions compares dotted version strings numerically where both
+// segments are numbers, ignoring a leading "v". Pre-release versions sort
+// before the release they precede.
+func compareVersions(a, b string) int {
+	a, b = strings.TrimPrefix(a, "v"), strings.TrimPrefix(b, "v")
+	aCore, aPre, _ := strings.Cut(a, "-")
+	bCore, bPre, _ := strings.Cut(b, "-")
+	aParts, bParts := strings.Split(aCore, "."), strings.Split(bCore, ".")
+	for i := 0; i < len(aParts) || i < len(bParts); i++ {
+		x, y := "0", "0"
+		if i < len(aParts) {
+			x = aParts[i]
+		}
+		if i < len(bParts) {
+			y = bParts[i]
+		}
+		xn, xErr := strconv.Atoi(x)
+		yn, yErr := strconv.Atoi(y)
+		switch {
+		case xErr == nil && yErr == nil && xn != yn:
+			if xn < yn {
+				return -1
+			}
+			return 1
+		case (xErr != nil || yErr != nil) && x != y:
+			return strings.Compare(x, y)
+		}
+	}
+	switch {
+	case aPre == bPre:
+		return 0
+	case aPre == "":
+		return 1
+	case bPre == "":
+		return -1
+	}
+	return strings.Compare(aPre, bPre)
+}
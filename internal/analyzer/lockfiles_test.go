@@ -0,0 +1,175 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+)
+
+// lockedSummary renders packages as "name@version" with "!" for direct and
+// "~" for dev packages, in order
+func lockedSummary(packages []LockedPackage) string {
+	var parts []string
+	for _, p := range packages {
+		s := p.Name + "@" + p.Version
+		if p.Direct {
+			s += "!"
+		}
+		if p.Dev {
+			s += "~"
+		}
+		parts = append(parts, s)
+	}
+	return strings.Join(parts, " ")
+}
+
+func TestParseLockFiles(t *testing.T) {
+	testCases := []struct {
+		fixture string
+		parse   func([]byte) []LockedPackage
+		want    string
+	}{
+		{"package-lock.json", parsePackageLock,
+			"accepts@1.3.8 debug@2.6.9 debug@4.3.4~ express@4.18.2! jest@29.7.0!~ mime-types@2.1.35 ms@2.0.0"},
+		{"yarn.lock", parseYarnLock,
+			"@babel/code-frame@7.22.13 @babel/highlight@7.22.20 chalk@2.4.2"},
+		{"yarn-berry.lock", parseYarnLock,
+			"js-tokens@4.0.0 loose-envify@1.4.0 react@18.2.0!"},
+		{"pnpm-lock.yaml", parsePnpmLock,
+			"loose-envify@1.4.0 react@18.2.0 react-dom@18.2.0! scheduler@0.23.0 typescript@5.3.3!~"},
+		{"go.sum", parseGoSum,
+			"github.com/gin-gonic/gin@v1.9.1 github.com/go-playground/validator/v10@v10.14.0 golang.org/x/net@v0.10.0"},
+		{"Cargo.lock", parseCargoLock,
+			"clap@4.4.11! clap_builder@4.4.11 serde@1.0.193! serde_derive@1.0.193"},
+		{"poetry.lock", parsePoetryLock,
+			"certifi@2024.2.2 pytest@8.1.1 requests@2.31.0"},
+		{"Pipfile.lock", parsePipfileLock,
+			"django@4.2.11 pytest@8.1.1~ sqlparse@0.4.4"},
+		{"Gemfile.lock", parseGemfileLock,
+			"acme-auth@0.3.0! jwt@2.7.1 nokogiri@1.15.5 racc@1.7.3 rails@7.1.2!"},
+	}
+	for _, tc := range testCases {
+		packages := tc.parse(loadFixture(t, "lockfiles/"+tc.fixture))
+		if got := lockedSummary(packages); got != tc.want {
+			t.Errorf("%s:\n got %s\nwant %s", tc.fixture, got, tc.want)
+		}
+	}
+
+	// Dependency edges are kept for the dependency graph
+	packages := parsePackageLock(loadFixture(t, "lockfiles/package-lock.json"))
+	for _, p := range packages {
+		if p.Name == "express" && strings.Join(p.Requires, ",") != "accepts,debug" {
+			t.Errorf("express requires %v", p.Requires)
+		}
+	}
+	packages = parseGemfileLock(loadFixture(t, "lockfiles/Gemfile.lock"))
+	if packages[2].Name != "nokogiri" || strings.Join(packages[2].Requires, ",") != "racc" {
+		t.Errorf("nokogiri = %+v", packages[2])
+	}
+}
+
+func TestParseGoSumVersionOrder(t *testing.T) {
+	sum := strings.Join([]string{
+		"github.com/docker/docker v20.10.9+incompatible h1:a=",
+		"github.com/docker/docker v20.10.10+incompatible h1:b=",
+		"example.com/rc v1.0.0-rc.9 h1:c=",
+		"example.com/rc v1.0.0-rc.10 h1:d=",
+		"example.com/pseudo v0.0.0-20231231000000-aaaaaaaaaaaa h1:e=",
+		"example.com/pseudo v0.0.0-20240101000000-bbbbbbbbbbbb h1:f=",
+		"example.com/pseudo v0.0.0-20250101000000-cccccccccccc/go.mod h1:g=",
+	}, "\n")
+	want := "example.com/pseudo@v0.0.0-20240101000000-bbbbbbbbbbbb example.com/rc@v1.0.0-rc.10 " +
+		"github.com/docker/docker@v20.10.10+incompatible"
+	if got := lockedSummary(parseGoSum([]byte(sum))); got != want {
+		t.Errorf("parseGoSum:\n got %s\nwant %s", got, want)
+	}
+}
+
+func TestSplitPnpmKey(t *testing.T) {
+	testCases := map[string]string{
+		"/react/18.2.0":                      "react 18.2.0",
+		"/@babel/core/7.23.0_supports-color": "@babel/core 7.23.0",
+		"/@babel/core@7.23.0":                "@babel/core 7.23.0",
+		"react-dom@18.2.0(react@18.2.0)":     "react-dom 18.2.0",
+	}
+	for key, want := range testCases {
+		name, version := splitPnpmKey(key)
+		if got := name + " " + version; got != want {
+			t.Errorf("splitPnpmKey(%q) = %q, want %q", key, got, want)
+		}
+	}
+}
+
+func TestApplyLockFiles(t *testing.T) {
+	analysis := &DependencyAnalysis{}
+	analysis.AddFiles(
+		DependencyFile{Filename: "package.json", FileType: "npm", Dependencies: []Dependency{
+			{Name: "express", Version: "^4.18.0", Type: "production"},
+			{Name: "jest", Version: "^29.0.0", Type: "dev"},
+		}},
+		DependencyFile{Filename: "api/pyproject.toml", FileType: "python", Dependencies: []Dependency{
+			{Name: "Requests", Version: "^2.31", Type: "production"},
+		}},
+		DependencyFile{Filename: "api/requirements.txt", FileType: "python", Dependencies: []Dependency{
+			{Name: "flask", Version: ">=2.0", Type: "production"},
+		}},
+	)
+	analysis.ApplyLockFiles(
+		ParseLockFile("package-lock.json", loadFixture(t, "lockfiles/package-lock.json")),
+		ParseLockFile("api/poetry.lock", loadFixture(t, "lockfiles/poetry.lock")),
+		ParseLockFile("crates/Cargo.lock", loadFixture(t, "lockfiles/Cargo.lock")),
+		ParseLockFile("README.md", []byte("not a lockfile")),
+	)
+
+	npm := analysis.Files[0]
+	if npm.LockFile != "package-lock.json" || npm.Dependencies[0].Resolved != "4.18.2" || npm.Dependencies[1].Resolved != "29.7.0" {
+		t.Errorf("npm direct deps = %+v", npm.Dependencies[:2])
+	}
+	// accepts, debug (two versions), mime-types and ms are transitive
+	if len(npm.Dependencies) != 7 || npm.TotalCount != 7 {
+		t.Fatalf("npm deps = %+v", npm.Dependencies)
+	}
+	if d := npm.Dependencies[4]; d.Name != "debug" || d.Version != "4.3.4" || !d.Transitive || d.Type != "dev" {
+		t.Errorf("nested debug = %+v", d)
+	}
+	// Licenses recorded in the lockfile are kept on direct and transitive deps
+	if npm.Dependencies[0].License != "MIT" || npm.Dependencies[6].Name != "ms" || npm.Dependencies[6].License != "MIT" ||
+		npm.Dependencies[1].License != "" {
+		t.Errorf("npm licenses = %+v", npm.Dependencies)
+	}
+
+	py := analysis.Files[1]
+	if py.Dependencies[0].Resolved != "2.31.0" || len(py.Dependencies) != 3 {
+		t.Errorf("poetry deps = %+v", py.Dependencies)
+	}
+	if analysis.Files[2].LockFile != "" {
+		t.Error("poetry.lock does not lock requirements.txt")
+	}
+
+	// A lockfile without a manifest next to it gets its own entry
+	cargo := analysis.Files[3]
+	if cargo.Filename != "crates/Cargo.lock" || len(cargo.Dependencies) != 2 {
+		t.Errorf("cargo = %+v", cargo)
+	}
+
+	if analysis.TotalDeps != 13 || analysis.TransitiveDeps != 9 || !analysis.HasLockFile {
+		t.Errorf("totals = %d/%d", analysis.TotalDeps, analysis.TransitiveDeps)
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	testCases := []struct {
+		a, b string
+		want int
+	}{
+		{"v1.9.1", "v1.9.0", 1},
+		{"1.10.0", "1.9.9", 1},
+		{"2.0.0-rc.1", "2.0.0", -1},
+		{"v0.0.0-20230101-abc", "v0.0.0-20240101-def", -1},
+		{"1.2", "1.2.0", 0},
+	}
+	for _, tc := range testCases {
+		if got := compareVersions(tc.a, tc.b); got != tc.want {
+			t.Errorf("compareVersions(%q, %q) = %d, want %d", tc.a, tc.b, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,272 @@
+// Package analyzer provides analysis functions for GitHub repositories.
+// This file parses Maven pom.xml and Gradle build scripts.
+package analyzer
+
+import (
+	"encoding/xml"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// pomProject holds the parts of a pom.xml needed to resolve dependency versions
+type pomProject struct {
+	GroupID    string `xml:"groupId"`
+	ArtifactID string `xml:"artifactId"`
+	Version    string `xml:"version"`
+	Parent     struct {
+		GroupID      string  `xml:"groupId"`
+		ArtifactID   string  `xml:"artifactId"`
+		Version      string  `xml:"version"`
+		RelativePath *string `xml:"relativePath"`
+	} `xml:"parent"`
+	Properties           pomProperties   `xml:"properties"`
+	DependencyManagement []pomDependency `xml:"dependencyManagement>dependencies>dependency"`
+	Dependencies         []pomDependency `xml:"dependencies>dependency"`
+}
+
+type pomDependency struct {
+	GroupID    string `xml:"groupId"`
+	ArtifactID string `xml:"artifactId"`
+	Version    string `xml:"version"`
+	Scope      string `xml:"scope"`
+}
+
+// pomProperties collects the free-form <properties> element
+type pomProperties map[string]string
+
+func (p *pomProperties) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	*p = make(pomProperties)
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			var value string
+			if err := d.DecodeElement(&value, &t); err != nil {
+				return err
+			}
+			(*p)[t.Name.Local] = strings.TrimSpace(value)
+		case xml.EndElement:
+			return nil
+		}
+	}
+}
+
+var pomPropertyRef = regexp.MustCompile(`\$\{([^}]+)\}`)
+
+// resolvePOMDependencies returns the dependencies of the POM at pomPath.
+// Versions are resolved through ${property} references and
+// <dependencyManagement>, inheriting both from parent POMs found among poms
+// (keyed by path) through <relativePath> or their coordinates.
+func resolvePOMDependencies(pomPath string, poms map[string][]byte) []Dependency {
+	var chain []*pomProject // the project first, then its ancestors
+	current := pomPath
+	for depth := 0; depth < 10 && current != ""; depth++ {
+		var project pomProject
+		if err := xml.Unmarshal(poms[current], &project); err != nil {
+			break
+		}
+		chain = append(chain, &project)
+		current = findParentPOM(current, &project, poms)
+	}
+	if len(chain) == 0 {
+		return nil
+	}
+
+	// Ancestors first so that children override inherited values
+	props := make(map[string]string)
+	managed := make(map[string]string)
+	for i := len(chain) - 1; i >= 0; i-- {
+		project := chain[i]
+		for k, v := range project.Properties {
+			props[k] = v
+		}
+		version := project.Version
+		if version == "" {
+			version = project.Parent.Version
+		}
+		if version != "" {
+			props["project.version"], props["version"], props["pom.version"] = version, version, version
+		}
+		if project.Parent.Version != "" {
+			props["project.parent.version"] = project.Parent.Version
+		}
+		for _, d := range project.DependencyManagement {
+			managed[d.GroupID+":"+d.ArtifactID] = d.Version
+		}
+	}
+	resolve := func(value string) string {
+		for i := 0; i < 5 && strings.Contains(value, "${"); i++ {
+			value = pomPropertyRef.ReplaceAllStringFunc(value, func(ref string) string {
+				if v, ok := props[ref[2:len(ref)-1]]; ok {
+					return v
+				}
+				return ref
+			})
+		}
+		if strings.Contains(value, "${") {
+			return "" // defined outside the repository
+		}
+		return strings.TrimSpace(value)
+	}
+
+	var deps []Dependency
+	for _, d := range chain[0].Dependencies {
+		groupID, artifactID := resolve(d.GroupID), resolve(d.ArtifactID)
+		version := d.Version
+		if version == "" {
+			version = managed[d.GroupID+":"+d.ArtifactID]
+		}
+		depType := "production"
+		switch d.Scope {
+		case "test":
+			depType = "dev"
+		case "provided":
+			depType = "peer"
+		}
+		deps = append(deps, Dependency{
+			Name:    groupID + ":" + artifactID,
+			Version: resolve(version),
+			Type:    depType,
+		})
+	}
+	sort.Slice(deps, func(i, j int) bool { return deps[i].Name < deps[j].Name })
+	return deps
+}
+
+// findParentPOM locates the parent of a POM among poms: by <relativePath>,
+// which defaults to ../pom.xml, or else by matching coordinates
+func findParentPOM(pomPath string, project *pomProject, poms map[string][]byte) string {
+	if project.Parent.ArtifactID == "" {
+		return ""
+	}
+	rel := "../pom.xml"
+	if project.Parent.RelativePath != nil {
+		rel = strings.TrimSpace(*project.Parent.RelativePath)
+	}
+	if rel != "" {
+		candidate := path.Clean(path.Join(path.Dir(pomPath), rel))
+		if !strings.HasSuffix(candidate, ".xml") {
+			candidate = path.Join(candidate, "pom.xml")
+		}
+		if candidate != pomPath && poms[candidate] != nil && pomHasCoordinates(poms[candidate], project.Parent.GroupID, project.Parent.ArtifactID) {
+			return candidate
+		}
+	}
+	for _, p := range sortedKeys(poms) {
+		if p != pomPath && pomHasCoordinates(poms[p], project.Parent.GroupID, project.Parent.ArtifactID) {
+			return p
+		}
+	}
+	return ""
+}
+
+// pomHasCoordinates reports whether a POM declares the given artifact
+func pomHasCoordinates(content []byte, groupID, artifactID string) bool {
+	var project pomProject
+	if err := xml.Unmarshal(content, &project); err != nil {
+		return false
+	}
+	group := project.GroupID
+	if group == "" {
+		group = project.Parent.GroupID // inherited
+	}
+	return project.ArtifactID == artifactID && (groupID == "" || group == groupID)
+}
+
+var (
+	// implementation 'g:a:v', testImplementation("g:a:v"), api(platform("g:a:v"))
+	gradleStringNotation = regexp.MustCompile(`^\s*(\w+)\s*\(?\s*(?:(?:enforcedPlatform|platform|kotlin)\s*\(\s*)?["']([^"':\s]+):([^"':\s]+)(?::([^"'\s]+))?["']`)
+	// implementation group: 'g', name: 'a', version: 'v' and the Kotlin DSL form with =
+	gradleMapNotation = regexp.MustCompile(`^\s*(\w+)\s*\(?\s*group\s*[:=]\s*["']([^"']+)["']\s*,\s*name\s*[:=]\s*["']([^"']+)["'](?:\s*,\s*version\s*[:=]\s*["']([^"']+)["'])?`)
+	// def springVersion = '6.1.0', val ktor = "2.3.7", ext.junitVersion = '5.10.0'
+	gradleVariable = regexp.MustCompile(`^\s*(?:def\s+|val\s+|var\s+|ext\.|extra\[")?\s*(\w+)"?\]?\s*=\s*["']([^"'$]+)["']\s*$`)
+	gradleVarRef   = regexp.MustCompile(`\$\{?([\w.]+)\}?`)
+	gradleConfig   = regexp.MustCompile(`^(\w*(Implementation|Api|CompileOnly|RuntimeOnly|Compile|Runtime)|implementation|api|compile|compileOnly|runtime|runtimeOnly|kapt|ksp|annotationProcessor|classpath|developmentOnly)$`)
+)
+
+// parseGradle parses dependency declarations from build.gradle and
+// build.gradle.kts. String and map notations are recognised, and versions
+// given as variables defined in the same script are substituted.
+//
+// Example build.gradle:
+//
+//	def jacksonVersion = '2.16.0'
+//	dependencies {
+//	    implementation "com.fasterxml.jackson.core:jackson-databind:$jacksonVersion"
+//	    testImplementation group: 'junit', name: 'junit', version: '4.13.2'
+//	}
+func parseGradle(content []byte) ([]Dependency, string) {
+	lines := strings.Split(string(content), "\n")
+
+	vars := make(map[string]string)
+	for _, line := range lines {
+		if m := gradleVariable.FindStringSubmatch(line); m != nil {
+			vars[m[1]] = m[2]
+		}
+	}
+	resolve := func(version string) string {
+		return gradleVarRef.ReplaceAllStringFunc(version, func(ref string) string {
+			name := gradleVarRef.FindStringSubmatch(ref)[1]
+			if i := strings.LastIndex(name, "."); i >= 0 {
+				name = name[i+1:] // rootProject.ext.foo, libs.versions.foo
+			}
+			if v, ok := vars[name]; ok {
+				return v
+			}
+			return ref
+		})
+	}
+
+	var deps []Dependency
+	seen := make(map[string]bool)
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "//") {
+			continue
+		}
+		m := gradleStringNotation.FindStringSubmatch(line)
+		if m == nil {
+			m = gradleMapNotation.FindStringSubmatch(line)
+		}
+		if m == nil || !gradleConfig.MatchString(m[1]) {
+			continue
+		}
+
+		name := m[2] + ":" + m[3]
+		if seen[m[1]+" "+name] {
+			continue
+		}
+		seen[m[1]+" "+name] = true
+
+		version := resolve(m[4])
+		if strings.Contains(version, "$") {
+			version = ""
+		}
+		deps = append(deps, Dependency{
+			Name:    name,
+			Version: version,
+			Type:    gradleDependencyType(m[1]),
+		})
+	}
+
+	sort.Slice(deps, func(i, j int) bool { return deps[i].Name < deps[j].Name })
+	return deps, "gradle"
+}
+
+// gradleDependencyType maps a Gradle configuration to a dependency type
+func gradleDependencyType(config string) string {
+	lower := strings.ToLower(config)
+	switch {
+	case strings.Contains(lower, "test"), config == "kapt", config == "ksp",
+		config == "annotationProcessor", config == "classpath", config == "developmentOnly":
+		return "dev"
+	case strings.HasSuffix(lower, "compileonly"):
+		return "peer"
+	}
+	return "production"
+}
@@ -0,0 +1,421 @@
+// Package analyzer provides functions for analyzing GitHub repository data.
+// This file implements monorepo detection and per-package analysis.
+package analyzer
+
+import (
+	"encoding/json"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/agnivo988/Repo-lyzer/internal/github"
+)
+
+// WorkspacePackage is one package of a monorepo together with its own
+// quality, dependency, license and contributor analysis
+type WorkspacePackage struct {
+	Name            string              `json:"name"`
+	Dir             string              `json:"dir"`      // "." for a package at the repository root
+	Manifest        string              `json:"manifest"` // e.g. packages/web/package.json
+	Ecosystem       string              `json:"ecosystem"`
+	DeclaredLicense string              `json:"declared_license,omitempty"` // from the manifest
+	Files           int                 `json:"files"`
+	Size            int                 `json:"size"`
+	CodeQuality     *CodeQualityMetrics `json:"code_quality,omitempty"`
+	Dependencies    *DependencyAnalysis `json:"dependencies,omitempty"`
+	License         *LicenseAnalysis    `json:"license,omitempty"` // nil when the package has no LICENSE file of its own
+	Contributors    []ContributorShare  `json:"contributors,omitempty"`
+	Commits         int                 `json:"commits"`
+}
+
+// ContributorShare is one author's share of the commits touching a directory
+type ContributorShare struct {
+	Login      string  `json:"login"`
+	Commits    int     `json:"commits"`
+	Percentage float64 `json:"percentage"`
+}
+
+// MonorepoAnalysis describes the workspace tooling and packages of a repository
+type MonorepoAnalysis struct {
+	Tools     []string           `json:"tools"` // e.g. "npm workspaces", "pnpm", "go.work", "nx"
+	Packages  []WorkspacePackage `json:"packages"`
+	Truncated bool               `json:"truncated"` // more packages than MaxPackages; the rest were listed only
+}
+
+// MonorepoOptions bounds the API requests spent on per-package analysis
+type MonorepoOptions struct {
+	MaxPackages     int // packages whose manifests are read and commits fetched
+	ContributorDays int
+}
+
+// DefaultMonorepoOptions returns the budget used over the GitHub API
+func DefaultMonorepoOptions() MonorepoOptions {
+	return MonorepoOptions{
+		MaxPackages:     25,
+		ContributorDays: 365,
+	}
+}
+
+// IsMonorepo reports whether more than one workspace package was found
+func (m *MonorepoAnalysis) IsMonorepo() bool {
+	return m != nil && len(m.Packages) > 1
+}
+
+var (
+	goWorkUseLine   = regexp.MustCompile(`^use\s+(\S+)`)
+	goModModuleLine = regexp.MustCompile(`(?m)^module\s+(\S+)`)
+)
+
+// DetectMonorepo looks for workspace definitions (package.json workspaces,
+// pnpm-workspace.yaml, lerna.json, go.work, Cargo workspaces) and build
+// system markers (Nx, Turborepo, Bazel), and enumerates the packages they
+// declare. Only root-level workspace files and package manifests are read;
+// at most maxPackages manifests are read for package names.
+func DetectMonorepo(source FileSource, fileTree []github.TreeEntry, maxPackages int) *MonorepoAnalysis {
+	m := &MonorepoAnalysis{Tools: []string{}, Packages: []WorkspacePackage{}}
+
+	blobs := make(map[string]bool)
+	manifests := map[string][]string{} // manifest file name -> dirs containing it
+	for _, entry := range fileTree {
+		if entry.Type != "blob" || isVendoredPath(strings.ToLower(entry.Path)) {
+			continue
+		}
+		blobs[entry.Path] = true
+		name := baseName(entry.Path)
+		switch name {
+		case "package.json", "go.mod", "Cargo.toml", "project.json", "BUILD", "BUILD.bazel":
+			manifests[name] = append(manifests[name], path.Dir(entry.Path))
+		}
+	}
+
+	add := func(ecosystem, manifest string, dirs []string) {
+		for _, dir := range dirs {
+			if dir == "." && manifest != "go.mod" {
+				continue // the workspace root itself is not a package
+			}
+			m.addPackage(ecosystem, dir, path.Join(dir, manifest))
+		}
+	}
+	readRoot := func(name string) []byte {
+		if !blobs[name] {
+			return nil
+		}
+		content, err := source.ReadFile(name)
+		if err != nil {
+			return nil
+		}
+		return content
+	}
+
+	if patterns := packageJSONWorkspaces(readRoot("package.json")); len(patterns) > 0 {
+		m.addTool("npm workspaces")
+		add("npm", "package.json", matchWorkspaceDirs(patterns, manifests["package.json"]))
+	}
+	if content := readRoot("pnpm-workspace.yaml"); content != nil {
+		m.addTool("pnpm")
+		var patterns []string
+		for _, doc := range parseYAMLDocuments(string(content)) {
+			for _, item := range yamlList(yamlGet(doc.Value, "packages")) {
+				patterns = append(patterns, yamlString(item))
+			}
+		}
+		add("npm", "package.json", matchWorkspaceDirs(patterns, manifests["package.json"]))
+	}
+	if content := readRoot("lerna.json"); content != nil {
+		m.addTool("lerna")
+		var lerna struct {
+			Packages []string `json:"packages"`
+		}
+		_ = json.Unmarshal(content, &lerna)
+		if len(lerna.Packages) == 0 {
+			lerna.Packages = []string{"packages/*"}
+		}
+		add("npm", "package.json", matchWorkspaceDirs(lerna.Packages, manifests["package.json"]))
+	}
+	if content := readRoot("go.work"); content != nil {
+		m.addTool("go.work")
+		add("go", "go.mod", parseGoWorkUses(content))
+	}
+	if content := readRoot("Cargo.toml"); content != nil {
+		if workspace := yamlGet(parseTOML(string(content)), "workspace"); workspace != nil {
+			m.addTool("cargo workspace")
+			dirs := matchWorkspaceDirs(tomlStrings(yamlGet(workspace, "members")), manifests["Cargo.toml"])
+			excluded := matchWorkspaceDirs(tomlStrings(yamlGet(workspace, "exclude")), dirs)
+			add("rust", "Cargo.toml", subtractDirs(dirs, excluded))
+		}
+	}
+	if blobs["nx.json"] {
+		m.addTool("nx")
+		add("nx", "project.json", manifests["project.json"])
+	}
+	if blobs["turbo.json"] {
+		m.addTool("turborepo")
+	}
+	if blobs["WORKSPACE"] || blobs["WORKSPACE.bazel"] || blobs["MODULE.bazel"] {
+		m.addTool("bazel")
+		add("bazel", "BUILD", outermostDirs(append(manifests["BUILD"], manifests["BUILD.bazel"]...)))
+	}
+
+	sort.Slice(m.Packages, func(i, j int) bool { return m.Packages[i].Dir < m.Packages[j].Dir })
+	for i := range m.Packages {
+		pkg := &m.Packages[i]
+		if maxPackages > 0 && i >= maxPackages {
+			m.Truncated = true
+		} else if blobs[pkg.Manifest] && pkg.Ecosystem != "bazel" {
+			if content, err := source.ReadFile(pkg.Manifest); err == nil {
+				pkg.Name, pkg.DeclaredLicense = manifestIdentity(pkg.Ecosystem, content)
+			}
+		}
+		if pkg.Name == "" {
+			pkg.Name = pkg.Dir
+		}
+	}
+	return m
+}
+
+// AnalyzePackages runs code quality, dependency, license and contributor
+// analysis for each package. Dependencies are taken from the repository-wide
+// analysis rather than fetched again. The client may be nil, in which case
+// license files and commits are not fetched.
+func (m *MonorepoAnalysis) AnalyzePackages(client *github.Client, owner, repo string, repoInfo *github.Repo, fileTree []github.TreeEntry, deps *DependencyAnalysis, opts MonorepoOptions) {
+	for i := range m.Packages {
+		pkg := &m.Packages[i]
+		sub := SubTree(fileTree, pkg.Dir)
+
+		languages := make(map[string]int)
+		for _, entry := range sub {
+			if entry.Type != "blob" {
+				continue
+			}
+			pkg.Files++
+			pkg.Size += entry.Size
+			if lang := LOCLanguage(entry.Path); lang != "" {
+				languages[lang] += entry.Size
+			}
+		}
+		pkg.CodeQuality = AnalyzeCodeQuality(repoInfo, sub, languages)
+
+		if deps != nil {
+			pkg.Dependencies = deps.ForDirectory(pkg.Dir)
+			pkg.Dependencies.HasLockFile = deps.HasLockFile
+		}
+
+		withinBudget := opts.MaxPackages <= 0 || i < opts.MaxPackages
+		if client == nil || !withinBudget {
+			continue
+		}
+		if licenseFiles := findLicenseFiles(sub); len(licenseFiles) > 0 {
+			full := make([]github.TreeEntry, 0, len(licenseFiles))
+			for _, p := range licenseFiles {
+				full = append(full, github.TreeEntry{Path: path.Join(pkg.Dir, p), Type: "blob"})
+			}
+			pkg.License, _ = AnalyzeLicense(client, owner, repo, full)
+		}
+		if commits, err := client.GetCommitsForPath(owner, repo, pkg.Dir, opts.ContributorDays); err == nil {
+			pkg.Commits = len(commits)
+			pkg.Contributors = ContributorShares(commits)
+		}
+	}
+}
+
+// ContributorShares counts commits per author, largest share first
+func ContributorShares(commits []github.Commit) []ContributorShare {
+	counts := make(map[string]int)
+	for _, c := range commits {
+		login := c.Author.Login
+		if login == "" {
+			login = "(no GitHub account)"
+		}
+		counts[login]++
+	}
+
+	shares := make([]ContributorShare, 0, len(counts))
+	for login, n := range counts {
+		shares = append(shares, ContributorShare{
+			Login:      login,
+			Commits:    n,
+			Percentage: float64(n) / float64(len(commits)) * 100,
+		})
+	}
+	sort.Slice(shares, func(i, j int) bool {
+		if shares[i].Commits != shares[j].Commits {
+			return shares[i].Commits > shares[j].Commits
+		}
+		return shares[i].Login < shares[j].Login
+	})
+	return shares
+}
+
+// SubTree returns the entries under dir with paths made relative to it, so
+// that root-level checks (README, LICENSE, CI files) apply to the package
+func SubTree(fileTree []github.TreeEntry, dir string) []github.TreeEntry {
+	if dir == "." || dir == "" {
+		return fileTree
+	}
+	prefix := strings.TrimSuffix(dir, "/") + "/"
+	var sub []github.TreeEntry
+	for _, entry := range fileTree {
+		if strings.HasPrefix(entry.Path, prefix) {
+			entry.Path = strings.TrimPrefix(entry.Path, prefix)
+			sub = append(sub, entry)
+		}
+	}
+	return sub
+}
+
+// ForDirectory returns the dependency files located under dir
+func (a *DependencyAnalysis) ForDirectory(dir string) *DependencyAnalysis {
+	sub := &DependencyAnalysis{Files: []DependencyFile{}, Languages: []string{}}
+	prefix := strings.TrimSuffix(dir, "/") + "/"
+	for _, file := range a.Files {
+		if dir == "." || strings.HasPrefix(file.Filename, prefix) {
+			sub.AddFiles(file)
+		}
+	}
+	return sub
+}
+
+func (m *MonorepoAnalysis) addTool(tool string) {
+	if !contains(m.Tools, tool) {
+		m.Tools = append(m.Tools, tool)
+	}
+}
+
+func (m *MonorepoAnalysis) addPackage(ecosystem, dir, manifest string) {
+	for _, p := range m.Packages {
+		if p.Dir == dir {
+			return
+		}
+	}
+	m.Packages = append(m.Packages, WorkspacePackage{Dir: dir, Manifest: manifest, Ecosystem: ecosystem})
+}
+
+// packageJSONWorkspaces returns the "workspaces" globs of a package.json,
+// in either the array form or Yarn's {"packages": [...]} form
+func packageJSONWorkspaces(content []byte) []string {
+	if content == nil {
+		return nil
+	}
+	var pkg struct {
+		Workspaces json.RawMessage `json:"workspaces"`
+	}
+	if err := json.Unmarshal(content, &pkg); err != nil || len(pkg.Workspaces) == 0 {
+		return nil
+	}
+	var patterns []string
+	if err := json.Unmarshal(pkg.Workspaces, &patterns); err == nil {
+		return patterns
+	}
+	var yarn struct {
+		Packages []string `json:"packages"`
+	}
+	_ = json.Unmarshal(pkg.Workspaces, &yarn)
+	return yarn.Packages
+}
+
+// parseGoWorkUses returns the module directories listed in a go.work file
+func parseGoWorkUses(content []byte) []string {
+	var dirs []string
+	inBlock := false
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = strings.TrimSpace(line[:i])
+		}
+		switch {
+		case line == "use (":
+			inBlock = true
+		case inBlock && line == ")":
+			inBlock = false
+		case inBlock && line != "":
+			dirs = append(dirs, path.Clean(strings.Trim(line, `"`)))
+		default:
+			if match := goWorkUseLine.FindStringSubmatch(line); match != nil && match[1] != "(" {
+				dirs = append(dirs, path.Clean(strings.Trim(match[1], `"`)))
+			}
+		}
+	}
+	return dirs
+}
+
+// matchWorkspaceDirs returns the candidate directories matched by workspace
+// globs such as "packages/*" or "apps/**"; patterns starting with "!" exclude
+func matchWorkspaceDirs(patterns, candidates []string) []string {
+	var matched []string
+	for _, dir := range candidates {
+		include := false
+		for _, pattern := range patterns {
+			negate := strings.HasPrefix(pattern, "!")
+			pattern = strings.TrimPrefix(strings.TrimPrefix(pattern, "!"), "./")
+			pattern = strings.TrimSuffix(pattern, "/")
+			if pattern == "" {
+				continue
+			}
+			if matchPathGlob(pattern, dir) || (strings.HasSuffix(pattern, "/**") && dir == strings.TrimSuffix(pattern, "/**")) {
+				include = !negate
+			}
+		}
+		if include {
+			matched = append(matched, dir)
+		}
+	}
+	return matched
+}
+
+// subtractDirs returns dirs without the excluded ones
+func subtractDirs(dirs, excluded []string) []string {
+	var kept []string
+	for _, d := range dirs {
+		if !contains(excluded, d) {
+			kept = append(kept, d)
+		}
+	}
+	return kept
+}
+
+// outermostDirs keeps the directories that are not nested in another one,
+// so a Bazel package's subpackages are not reported separately
+func outermostDirs(dirs []string) []string {
+	sort.Strings(dirs)
+	var kept []string
+	for _, d := range dirs {
+		if d == "." {
+			continue
+		}
+		nested := false
+		for _, k := range kept {
+			if strings.HasPrefix(d, k+"/") {
+				nested = true
+				break
+			}
+		}
+		if !nested && !contains(kept, d) {
+			kept = append(kept, d)
+		}
+	}
+	return kept
+}
+
+// manifestIdentity extracts a package's name and declared license
+func manifestIdentity(ecosystem string, content []byte) (name, license string) {
+	switch ecosystem {
+	case "npm", "nx":
+		var pkg struct {
+			Name    string          `json:"name"`
+			License json.RawMessage `json:"license"`
+		}
+		if json.Unmarshal(content, &pkg) == nil {
+			_ = json.Unmarshal(pkg.License, &license)
+			return pkg.Name, license
+		}
+	case "go":
+		if match := goModModuleLine.FindSubmatch(content); match != nil {
+			return string(match[1]), ""
+		}
+	case "rust":
+		doc := parseTOML(string(content))
+		return yamlString(yamlGet(doc, "package", "name")), yamlString(yamlGet(doc, "package", "license"))
+	}
+	return "", ""
+}
@@ -0,0 +1,173 @@
+package analyzer
+
+import (
+	"testing"
+
+	"github.com/agnivo988/Repo-lyzer/internal/github"
+)
+
+func treeFor(files mapFileSource, extra ...string) []github.TreeEntry {
+	var tree []github.TreeEntry
+	for p := range files {
+		tree = append(tree, github.TreeEntry{Path: p, Type: "blob", Size: 10})
+	}
+	for _, p := range extra {
+		tree = append(tree, github.TreeEntry{Path: p, Type: "blob", Size: 10})
+	}
+	return tree
+}
+
+func packageDirs(m *MonorepoAnalysis) []string {
+	var dirs []string
+	for _, p := range m.Packages {
+		dirs = append(dirs, p.Dir)
+	}
+	return dirs
+}
+
+func TestDetectMonorepo_NpmWorkspaces(t *testing.T) {
+	files := mapFileSource{
+		"package.json":                             `{"private": true, "workspaces": ["packages/*", "!packages/legacy"]}`,
+		"packages/web/package.json":                `{"name": "@acme/web", "license": "MIT"}`,
+		"packages/api/package.json":                `{"name": "@acme/api"}`,
+		"packages/legacy/package.json":             `{"name": "legacy"}`,
+		"packages/web/node_modules/x/package.json": `{"name": "x"}`,
+		"turbo.json":                               `{}`,
+	}
+	m := DetectMonorepo(files, treeFor(files), 0)
+
+	if !m.IsMonorepo() || len(m.Packages) != 2 {
+		t.Fatalf("packages = %v", packageDirs(m))
+	}
+	if m.Packages[0].Dir != "packages/api" || m.Packages[1].Name != "@acme/web" || m.Packages[1].DeclaredLicense != "MIT" {
+		t.Errorf("packages = %+v", m.Packages)
+	}
+	if !contains(m.Tools, "npm workspaces") || !contains(m.Tools, "turborepo") {
+		t.Errorf("tools = %v", m.Tools)
+	}
+}
+
+func TestDetectMonorepo_OtherTools(t *testing.T) {
+	testCases := []struct {
+		name  string
+		files mapFileSource
+		extra []string
+		tool  string
+		dirs  []string
+	}{
+		{
+			name: "pnpm",
+			files: mapFileSource{
+				"pnpm-workspace.yaml":        "packages:\n  - 'apps/**'\n  - libs/*\n",
+				"apps/site/package.json":     `{"name": "site"}`,
+				"apps/admin/ui/package.json": `{"name": "admin-ui"}`,
+				"libs/core/package.json":     `{"name": "core"}`,
+				"tools/package.json":         `{"name": "tools"}`,
+			},
+			tool: "pnpm",
+			dirs: []string{"apps/admin/ui", "apps/site", "libs/core"},
+		},
+		{
+			name: "go.work",
+			files: mapFileSource{
+				"go.work":       "go 1.22\n\nuse (\n\t./api\n\t./worker // jobs\n)\nuse ./cli\n",
+				"api/go.mod":    "module example.com/api\n",
+				"worker/go.mod": "module example.com/worker\n",
+				"cli/go.mod":    "module example.com/cli\n",
+			},
+			tool: "go.work",
+			dirs: []string{"api", "cli", "worker"},
+		},
+		{
+			name: "cargo",
+			files: mapFileSource{
+				"Cargo.toml":                "[workspace]\nmembers = [\n  \"crates/*\", # all crates\n]\nexclude = [\"crates/scratch\"]\n",
+				"crates/core/Cargo.toml":    "[package]\nname = \"acme-core\"\nlicense = \"Apache-2.0\"\n",
+				"crates/cli/Cargo.toml":     "[package]\nname = \"acme-cli\"\n",
+				"crates/scratch/Cargo.toml": "[package]\nname = \"scratch\"\n",
+			},
+			tool: "cargo workspace",
+			dirs: []string{"crates/cli", "crates/core"},
+		},
+		{
+			name:  "bazel",
+			files: mapFileSource{"MODULE.bazel": "module(name = \"acme\")\n"},
+			extra: []string{"BUILD.bazel", "server/BUILD.bazel", "server/handlers/BUILD.bazel", "web/BUILD"},
+			tool:  "bazel",
+			dirs:  []string{"server", "web"},
+		},
+	}
+	for _, tc := range testCases {
+		m := DetectMonorepo(tc.files, treeFor(tc.files, tc.extra...), 0)
+		if !contains(m.Tools, tc.tool) {
+			t.Errorf("%s: tools = %v", tc.name, m.Tools)
+		}
+		got := packageDirs(m)
+		if len(got) != len(tc.dirs) {
+			t.Errorf("%s: packages = %v, want %v", tc.name, got, tc.dirs)
+			continue
+		}
+		for i := range got {
+			if got[i] != tc.dirs[i] {
+				t.Errorf("%s: packages = %v, want %v", tc.name, got, tc.dirs)
+				break
+			}
+		}
+	}
+}
+
+func TestDetectMonorepo_NotAMonorepo(t *testing.T) {
+	files := mapFileSource{
+		"package.json": `{"name": "app", "dependencies": {"react": "^18.0.0"}}`,
+		"go.mod":       "module example.com/app\n",
+	}
+	if m := DetectMonorepo(files, treeFor(files), 0); m.IsMonorepo() || len(m.Tools) != 0 {
+		t.Errorf("single-package repo detected as monorepo: %+v", m)
+	}
+}
+
+func TestAnalyzePackages(t *testing.T) {
+	tree := []github.TreeEntry{
+		{Path: "packages/web/package.json", Type: "blob", Size: 100},
+		{Path: "packages/web/README.md", Type: "blob", Size: 200},
+		{Path: "packages/web/src/app.ts", Type: "blob", Size: 3000},
+		{Path: "packages/web/src/app.test.ts", Type: "blob", Size: 1000},
+		{Path: "packages/api/package.json", Type: "blob", Size: 100},
+		{Path: "packages/api/index.js", Type: "blob", Size: 500},
+	}
+	deps := &DependencyAnalysis{HasLockFile: true}
+	deps.AddFiles(
+		DependencyFile{Filename: "packages/web/package.json", FileType: "npm", Dependencies: []Dependency{{Name: "react"}, {Name: "vite"}}},
+		DependencyFile{Filename: "packages/api/package.json", FileType: "npm", Dependencies: []Dependency{{Name: "express"}}},
+	)
+	m := &MonorepoAnalysis{Packages: []WorkspacePackage{{Dir: "packages/api"}, {Dir: "packages/web"}}}
+
+	m.AnalyzePackages(nil, "acme", "mono", nil, tree, deps, DefaultMonorepoOptions())
+
+	web := m.Packages[1]
+	if web.Files != 4 || web.Size != 4300 {
+		t.Errorf("web files/size = %d/%d", web.Files, web.Size)
+	}
+	if web.Dependencies.TotalDeps != 2 || !web.Dependencies.HasLockFile {
+		t.Errorf("web deps = %+v", web.Dependencies)
+	}
+	if !web.CodeQuality.HasTests || !web.CodeQuality.HasReadme {
+		t.Errorf("web quality should see its own tests and README: %+v", web.CodeQuality)
+	}
+	if m.Packages[0].CodeQuality.HasTests {
+		t.Error("api package has no tests")
+	}
+}
+
+func TestContributorShares(t *testing.T) {
+	var commits []github.Commit
+	for _, login := range []string{"alice", "bob", "alice", "", "alice"} {
+		var c github.Commit
+		c.Author.Login = login
+		commits = append(commits, c)
+	}
+	shares := ContributorShares(commits)
+	if len(shares) != 3 || shares[0].Login != "alice" || shares[0].Percentage != 60 {
+		t.Errorf("shares = %+v", shares)
+	}
+}
@@ -0,0 +1,351 @@
+// Package analyzer provides analysis functions for GitHub repositories.
+// This file parses Python pyproject.toml and Pipfile manifests.
+package analyzer
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// pep508Requirement matches "name[extra1,extra2] specifier ; markers"
+var pep508Requirement = regexp.MustCompile(`^\s*([A-Za-z0-9](?:[A-Za-z0-9._-]*[A-Za-z0-9])?)\s*(?:\[([^\]]*)\])?\s*(.*)$`)
+
+// pythonNameSeparators are the runs PEP 503 folds into a single "-"
+var pythonNameSeparators = regexp.MustCompile(`[-_.]+`)
+
+// poetryOperatorSpace matches the space Poetry allows after a version
+// operator, as in ">= 3.8"
+var poetryOperatorSpace = regexp.MustCompile(`([<>=!~^]+)\s+`)
+
+// pipfileMarkerKeys are the environment marker variables Pipfile accepts as
+// keys of a package table, e.g. sys_platform = "== 'linux'"
+var pipfileMarkerKeys = []string{
+	"os_name", "sys_platform", "platform_machine", "platform_python_implementation",
+	"platform_release", "platform_system", "platform_version", "python_version",
+	"python_full_version", "implementation_name", "implementation_version",
+}
+
+// parsePEP508 parses a PEP 508 requirement string such as
+// `requests[socks]>=2.31; python_version >= "3.8"`. Direct URL references
+// ("name @ https://...") have no version.
+func parsePEP508(requirement string) (Dependency, bool) {
+	spec, markers, _ := strings.Cut(requirement, ";")
+	m := pep508Requirement.FindStringSubmatch(spec)
+	if m == nil {
+		return Dependency{}, false
+	}
+
+	dep := Dependency{Name: m[1], Markers: strings.TrimSpace(markers)}
+	for _, extra := range strings.Split(m[2], ",") {
+		if extra = strings.TrimSpace(extra); extra != "" {
+			dep.Extras = append(dep.Extras, extra)
+		}
+	}
+
+	version := strings.TrimSpace(m[3])
+	switch {
+	case strings.HasPrefix(version, "@"):
+		version = ""
+	case strings.HasPrefix(version, "(") && strings.HasSuffix(version, ")"):
+		version = strings.TrimSpace(version[1 : len(version)-1])
+	case version == "":
+		version = "*"
+	}
+	dep.Version = version
+	return dep, true
+}
+
+// parsePyproject parses a pyproject.toml file. It reads PEP 621
+// [project] dependencies and optional-dependencies, PEP 735
+// [dependency-groups], and Poetry's [tool.poetry] dependencies,
+// dev-dependencies and dependency groups. A package declared both ways
+// (Poetry 2 allows this) is listed once.
+//
+// Example pyproject.toml:
+//
+//	[project]
+//	dependencies = ["httpx>=0.27", "pydantic[email]~=2.6"]
+//
+//	[project.optional-dependencies]
+//	docs = ["mkdocs"]
+//
+//	[tool.poetry.group.dev.dependencies]
+//	pytest = "^8.0"
+func parsePyproject(content []byte) ([]Dependency, string) {
+	doc := parseTOML(string(content))
+	var deps []Dependency
+	seen := make(map[string]bool)
+	add := func(dep Dependency, depType string) {
+		key := normalizePythonName(dep.Name)
+		if seen[key] {
+			return
+		}
+		seen[key] = true
+		dep.Type = depType
+		deps = append(deps, dep)
+	}
+	addRequirements := func(list interface{}, depType string) {
+		for _, item := range yamlList(list) {
+			if dep, ok := parsePEP508(yamlString(item)); ok {
+				add(dep, depType)
+			}
+		}
+	}
+
+	// PEP 621
+	addRequirements(yamlGet(doc, "project", "dependencies"), "production")
+	if optional, ok := yamlGet(doc, "project", "optional-dependencies").(map[string]interface{}); ok {
+		for _, group := range sortedKeys(optional) {
+			addRequirements(optional[group], "optional")
+		}
+	}
+
+	// PEP 735 dependency groups may also contain {include-group = "..."}
+	// tables, which add nothing new
+	if groups, ok := yamlGet(doc, "dependency-groups").(map[string]interface{}); ok {
+		for _, group := range sortedKeys(groups) {
+			addRequirements(groups[group], "dev")
+		}
+	}
+
+	// Poetry
+	poetry := yamlGet(doc, "tool", "poetry")
+	addPoetry := func(table interface{}, depType string) {
+		specs, ok := table.(map[string]interface{})
+		if !ok {
+			return
+		}
+		for _, name := range sortedKeys(specs) {
+			if strings.EqualFold(name, "python") {
+				continue // interpreter constraint, not a package
+			}
+			dep, optional := poetryDependency(name, specs[name])
+			if optional && depType == "production" {
+				add(dep, "optional")
+			} else {
+				add(dep, depType)
+			}
+		}
+	}
+	addPoetry(yamlGet(poetry, "dependencies"), "production")
+	addPoetry(yamlGet(poetry, "dev-dependencies"), "dev")
+	if groups, ok := yamlGet(poetry, "group").(map[string]interface{}); ok {
+		for _, group := range sortedKeys(groups) {
+			depType := "dev"
+			if group == "main" {
+				depType = "production"
+			}
+			addPoetry(yamlGet(groups[group], "dependencies"), depType)
+		}
+	}
+
+	return deps, "python"
+}
+
+// poetryDependency converts a Poetry dependency specification, which is a
+// version string, a table or a list of tables with alternative constraints.
+// It also reports whether the dependency is optional (installed via extras).
+func poetryDependency(name string, spec interface{}) (Dependency, bool) {
+	dep := Dependency{Name: name}
+	if list := yamlList(spec); len(list) > 0 {
+		spec = list[0] // alternatives for different environments
+	}
+
+	if version, ok := spec.(string); ok {
+		dep.Version = version
+		return dep, false
+	}
+	dep.Version = yamlString(yamlGet(spec, "version"))
+	for _, extra := range yamlList(yamlGet(spec, "extras")) {
+		dep.Extras = append(dep.Extras, yamlString(extra))
+	}
+
+	var markers []string
+	if python := poetryPythonMarker(yamlString(yamlGet(spec, "python"))); python != "" {
+		markers = append(markers, python)
+	}
+	if m := yamlString(yamlGet(spec, "markers")); m != "" {
+		markers = append(markers, m)
+	}
+	dep.Markers = strings.Join(markers, " and ")
+	return dep, yamlString(yamlGet(spec, "optional")) == "true"
+}
+
+// poetryPythonMarker translates a Poetry Python version constraint, such
+// as "^3.8" or ">=3.7,<3.11 || ^3.12", into a PEP 508 environment marker.
+// It returns "" for "*" and for constraints it can't read.
+func poetryPythonMarker(constraint string) string {
+	var alternatives []string
+	for _, alt := range strings.Split(strings.ReplaceAll(constraint, "||", "|"), "|") {
+		var clauses []string
+		alt = poetryOperatorSpace.ReplaceAllString(alt, "$1")
+		for _, c := range strings.FieldsFunc(alt, func(r rune) bool { return r == ',' || r == ' ' }) {
+			translated, ok := poetryPythonClause(c)
+			if !ok {
+				return ""
+			}
+			clauses = append(clauses, translated...)
+		}
+		if len(clauses) == 0 {
+			return "" // "*" or an empty alternative allows any version
+		}
+		alternatives = append(alternatives, strings.Join(clauses, " and "))
+	}
+	if len(alternatives) == 1 {
+		return alternatives[0]
+	}
+	for i, alt := range alternatives {
+		if strings.Contains(alt, " and ") {
+			alternatives[i] = "(" + alt + ")"
+		}
+	}
+	return "(" + strings.Join(alternatives, " or ") + ")"
+}
+
+// poetryPythonClause translates one clause of a Poetry Python constraint
+// into marker comparisons. Caret and tilde ranges become a pair of bounds.
+func poetryPythonClause(c string) ([]string, bool) {
+	if c == "*" {
+		return nil, true
+	}
+	op := ""
+	for _, candidate := range []string{"~=", ">=", "<=", "==", "!=", "^", "~", ">", "<", "="} {
+		if strings.HasPrefix(c, candidate) {
+			op, c = candidate, strings.TrimSpace(c[len(candidate):])
+			break
+		}
+	}
+	// "3.8.*" only matches 3.8, which python_version holds
+	c = strings.TrimSuffix(c, ".*")
+	parts := strings.Split(c, ".")
+	nums := make([]int, len(parts))
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			return nil, false
+		}
+		nums[i] = n
+	}
+	compare := func(op string, v string) string {
+		// python_version only has the major and minor version
+		key := "python_version"
+		if strings.Count(v, ".") > 1 {
+			key = "python_full_version"
+		}
+		return fmt.Sprintf("%s %s %q", key, op, v)
+	}
+	join := func(nums []int) string {
+		s := make([]string, len(nums))
+		for i, n := range nums {
+			s[i] = strconv.Itoa(n)
+		}
+		return strings.Join(s, ".")
+	}
+	// upper returns the version after nums with the component at i bumped
+	upper := func(i int) string {
+		bumped := append([]int(nil), nums[:i+1]...)
+		bumped[i]++
+		if i == 0 {
+			bumped = append(bumped, 0)
+		}
+		return join(bumped)
+	}
+
+	switch {
+	case op == "^":
+		// The first non-zero component may not change
+		i := 0
+		for i < len(nums)-1 && nums[i] == 0 {
+			i++
+		}
+		return []string{compare(">=", c), compare("<", upper(i))}, true
+	case op == "~" || op == "~=":
+		// ~3.8 allows 3.8.x; ~=3.8 and ~3 allow 3.x
+		i := len(nums) - 1
+		if op == "~" && len(nums) > 1 {
+			i = 1
+		}
+		if op == "~=" && len(nums) > 1 {
+			i = len(nums) - 2
+		}
+		return []string{compare(">=", c), compare("<", upper(i))}, true
+	case op == "" || op == "=" || op == "==":
+		return []string{compare("==", c)}, true
+	}
+	return []string{compare(op, c)}, true
+}
+
+// parsePipfile parses a Pipenv Pipfile. [packages] are production
+// dependencies, [dev-packages] and any custom package categories are dev
+// dependencies.
+//
+// Example Pipfile:
+//
+//	[packages]
+//	requests = {version = "*", extras = ["socks"]}
+//	django = ">=4.2"
+//
+//	[dev-packages]
+//	pytest = "*"
+func parsePipfile(content []byte) ([]Dependency, string) {
+	doc := parseTOML(string(content))
+	var deps []Dependency
+
+	for _, section := range sortedKeys(doc) {
+		switch section {
+		case "source", "requires", "pipenv", "scripts":
+			continue // not package categories
+		}
+		specs, ok := doc[section].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		depType := "dev"
+		if section == "packages" {
+			depType = "production"
+		}
+
+		for _, name := range sortedKeys(specs) {
+			dep := Dependency{Name: name, Type: depType}
+			spec := specs[name]
+			if version, ok := spec.(string); ok {
+				dep.Version = version
+				deps = append(deps, dep)
+				continue
+			}
+
+			dep.Version = yamlString(yamlGet(spec, "version"))
+			if dep.Version == "" && yamlGet(spec, "git") == nil && yamlGet(spec, "path") == nil && yamlGet(spec, "file") == nil {
+				dep.Version = "*"
+			}
+			for _, extra := range yamlList(yamlGet(spec, "extras")) {
+				dep.Extras = append(dep.Extras, yamlString(extra))
+			}
+			var markers []string
+			if m := yamlString(yamlGet(spec, "markers")); m != "" {
+				markers = append(markers, m)
+			}
+			for _, key := range pipfileMarkerKeys {
+				if m := yamlString(yamlGet(spec, key)); m != "" {
+					markers = append(markers, key+" "+m)
+				}
+			}
+			dep.Markers = strings.Join(markers, " and ")
+			deps = append(deps, dep)
+		}
+	}
+
+	// Keep [packages] before [dev-packages] and custom categories
+	sort.SliceStable(deps, func(i, j int) bool {
+		return deps[i].Type == "production" && deps[j].Type != "production"
+	})
+	return deps, "python"
+}
+
+// normalizePythonName applies PEP 503 name normalization
+func normalizePythonName(name string) string {
+	return strings.ToLower(pythonNameSeparators.ReplaceAllString(name, "-"))
+}
@@ -0,0 +1,195 @@
+package analyzer
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func loadFixture(t *testing.T, name string) []byte {
+	t.Helper()
+	content, err := os.ReadFile("testdata/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return content
+}
+
+// checkDeps compares deps against want, keyed by name, where each entry is
+// "version|type|extras|markers"
+func checkDeps(t *testing.T, deps []Dependency, want map[string]string) {
+	t.Helper()
+	if len(deps) != len(want) {
+		var names []string
+		for _, d := range deps {
+			names = append(names, d.Name)
+		}
+		t.Errorf("got %d deps %v, want %d", len(deps), names, len(want))
+	}
+	for _, d := range deps {
+		got := strings.Join([]string{d.Version, d.Type, strings.Join(d.Extras, ","), d.Markers}, "|")
+		if w, ok := want[d.Name]; !ok {
+			t.Errorf("unexpected dependency %s", d.Name)
+		} else if got != w {
+			t.Errorf("%s = %q, want %q", d.Name, got, w)
+		}
+	}
+}
+
+func TestParsePyproject_PEP621(t *testing.T) {
+	deps, fileType := parsePyproject(loadFixture(t, "python/pep621.pyproject.toml"))
+	if fileType != "python" {
+		t.Errorf("fileType = %q", fileType)
+	}
+	checkDeps(t, deps, map[string]string{
+		"certifi":            "*|production||",
+		"httpcore":           "==1.*|production||",
+		"anyio":              "*|production||",
+		"idna":               "*|production||",
+		"importlib-metadata": `>=4.6|production||python_version < "3.10"`,
+		"brotli":             "*|optional||platform_python_implementation == 'CPython'",
+		"brotlicffi":         "*|optional||platform_python_implementation != 'CPython'",
+		"click":              "==8.*|optional||",
+		"pygments":           "==2.*|optional||",
+		"rich":               ">=10,<14|optional||",
+		"h2":                 ">=3,<5|optional||",
+		"socksio":            "==1.*|optional||",
+		"zstandard":          ">=0.18.0|optional||",
+		"pytest":             ">=8.1|dev||",
+		"uvicorn":            "==0.29.0|dev|standard|",
+		"trustme":            "|dev||",
+		"ruff":               "==0.4.*|dev||",
+	})
+}
+
+func TestParsePyproject_Poetry(t *testing.T) {
+	deps, _ := parsePyproject(loadFixture(t, "python/poetry.pyproject.toml"))
+	checkDeps(t, deps, map[string]string{
+		"fastapi":             "^0.110.0|production||",
+		"uvicorn":             "^0.29.0|production|standard|",
+		"SQLAlchemy":          "^2.0.29|production|asyncio|",
+		"psycopg":             "^3.1|optional|binary|",
+		"pywin32":             ">=306|production||sys_platform == 'win32'",
+		"numpy":               `<1.25|production||python_version < "3.9"`,
+		"acme-shared":         "|production||",
+		"tomli":               `^2.0.1|production||python_version < "3.11"`,
+		"exceptiongroup":      `^1.2|production||python_version >= "3.10" and python_version < "3.11"`,
+		"importlib-resources": `^6.4|production||python_version >= "3.8" and python_version < "4.0"`,
+		"backports-zoneinfo":  `^0.2.1|production||((python_full_version >= "3.8.1" and python_version < "3.9") or python_version == "3.7")`,
+		"typing-extensions":   "^4.11|production||",
+		"pytest":              "^8.1.1|dev||",
+		"pytest-asyncio":      "^0.23.6|dev||",
+		"mypy":                "^1.9.0|dev||",
+		"mkdocs-material":     "^9.5.17|dev||",
+		"black":               "^24.3.0|dev||",
+	})
+}
+
+func TestPoetryPythonMarker(t *testing.T) {
+	tests := map[string]string{
+		"^0.2":        `python_version >= "0.2" and python_version < "0.3"`,
+		"~=3.8.1":     `python_full_version >= "3.8.1" and python_version < "3.9"`,
+		"~3":          `python_version >= "3" and python_version < "4.0"`,
+		"!=3.9.*":     `python_version != "3.9"`,
+		"3.11":        `python_version == "3.11"`,
+		"*":           "",
+		">=3.8,<4 ||": "",
+		"latest":      "",
+	}
+	for constraint, want := range tests {
+		if got := poetryPythonMarker(constraint); got != want {
+			t.Errorf("poetryPythonMarker(%q) = %s, want %s", constraint, got, want)
+		}
+	}
+}
+
+func TestParsePipfile(t *testing.T) {
+	deps, fileType := parsePipfile(loadFixture(t, "python/Pipfile"))
+	if fileType != "python" {
+		t.Errorf("fileType = %q", fileType)
+	}
+	checkDeps(t, deps, map[string]string{
+		"django":          ">=4.2,<5.0|production||",
+		"requests":        "==2.31.0|production|socks|",
+		"gunicorn":        "*|production||",
+		"psycopg2-binary": "*|production||",
+		"pywinpty":        "*|production||sys_platform == 'win32'",
+		"colorama":        ">=0.4|production||os_name == 'nt'",
+		"acme-utils":      "|production||",
+		"pytest":          "~=8.1|dev||",
+		"pytest-django":   "*|dev||",
+		"black":           "*|dev||python_version >= '3.8'",
+		"sphinx":          ">=7|dev||",
+	})
+	if deps[0].Type != "production" || deps[len(deps)-1].Type != "dev" {
+		t.Error("[packages] should be listed before other categories")
+	}
+}
+
+func TestParseTOML(t *testing.T) {
+	doc := parseTOML(`# comment
+title = "a \"quoted\" \u00e9 value" # trailing
+path = 'C:\Users\dev'
+"quoted.key" = 1
+site."google.com" = true
+lines = """
+one \
+  two"""
+raw = '''x
+y'''
+nested = { a = { b = "c" }, list = [1, 2,] }
+mixed = [
+  "a", # first
+  ['b'],
+]
+
+[server]
+host = "localhost"
+
+[[products]]
+name = "Hammer"
+
+[[products]]
+name = "Nail"
+dims.size = "small"
+
+[products.meta]
+color = "gray"
+`)
+
+	checks := map[string]interface{}{
+		"title":           `a "quoted" é value`,
+		"path":            `C:\Users\dev`,
+		"lines":           "one two",
+		"raw":             "x\ny",
+		"server.host":     "localhost",
+		"nested.a.b":      "c",
+		"site.google.com": "true",
+	}
+	for path, want := range checks {
+		keys := strings.Split(path, ".")
+		if path == "site.google.com" {
+			keys = []string{"site", "google.com"}
+		}
+		if got := yamlGet(doc, keys...); got != want {
+			t.Errorf("%s = %#v, want %#v", path, got, want)
+		}
+	}
+	if doc["quoted.key"] != "1" {
+		t.Errorf("quoted key = %#v", doc["quoted.key"])
+	}
+	if list := yamlList(yamlGet(doc, "nested", "list")); len(list) != 2 {
+		t.Errorf("nested.list = %#v", list)
+	}
+	if mixed := yamlList(doc["mixed"]); len(mixed) != 2 || len(yamlList(mixed[1])) != 1 {
+		t.Errorf("mixed = %#v", mixed)
+	}
+
+	products := yamlList(doc["products"])
+	if len(products) != 2 || yamlGet(products[0], "name") != "Hammer" {
+		t.Fatalf("products = %#v", products)
+	}
+	if yamlGet(products[1], "dims", "size") != "small" || yamlGet(products[1], "meta", "color") != "gray" {
+		t.Errorf("second product = %#v", products[1])
+	}
+}
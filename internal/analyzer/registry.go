@@ -0,0 +1,625 @@
+// Package analyzer provides analysis functions for GitHub repositories.
+// This file reads package metadata and release histories from the package
+// registries and the Go module proxy.
+package analyzer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RegistryOptions configures lookups in the package registries. Each URL
+// can point at a mirror or a local stub.
+type RegistryOptions struct {
+	NPMURL       string        // e.g. "https://registry.npmjs.org"
+	PyPIURL      string        // e.g. "https://pypi.org"
+	CratesURL    string        // e.g. "https://crates.io"
+	RubyGemsURL  string        // e.g. "https://rubygems.org"
+	PackagistURL string        // e.g. "https://repo.packagist.org"
+	GoProxyURL   string        // e.g. "https://proxy.golang.org"
+	Timeout      time.Duration // per request
+	HTTPClient   *http.Client  // optional; overrides Timeout
+}
+
+// DefaultRegistryOptions returns options for the public registries
+func DefaultRegistryOptions() RegistryOptions {
+	return RegistryOptions{
+		NPMURL:       "https://registry.npmjs.org",
+		PyPIURL:      "https://pypi.org",
+		CratesURL:    "https://crates.io",
+		RubyGemsURL:  "https://rubygems.org",
+		PackagistURL: "https://repo.packagist.org",
+		GoProxyURL:   "https://proxy.golang.org",
+		Timeout:      15 * time.Second,
+	}
+}
+
+// errNotInRegistry is returned for packages the registry doesn't know
+var errNotInRegistry = errors.New("not found in registry")
+
+// registryUserAgent identifies requests; crates.io rejects requests without one
+const registryUserAgent = "repo-lyzer (https://github.com/agnivo988/Repo-lyzer)"
+
+type registryClient struct {
+	opts   RegistryOptions
+	client *http.Client
+}
+
+func newRegistryClient(opts RegistryOptions) *registryClient {
+	defaults := DefaultRegistryOptions()
+	for _, u := range []struct {
+		value    *string
+		fallback string
+	}{
+		{&opts.NPMURL, defaults.NPMURL},
+		{&opts.PyPIURL, defaults.PyPIURL},
+		{&opts.CratesURL, defaults.CratesURL},
+		{&opts.RubyGemsURL, defaults.RubyGemsURL},
+		{&opts.PackagistURL, defaults.PackagistURL},
+		{&opts.GoProxyURL, defaults.GoProxyURL},
+	} {
+		if *u.value == "" {
+			*u.value = u.fallback
+		}
+		*u.value = strings.TrimSuffix(*u.value, "/")
+	}
+	client := opts.HTTPClient
+	if client == nil {
+		if opts.Timeout <= 0 {
+			opts.Timeout = defaults.Timeout
+		}
+		client = &http.Client{Timeout: opts.Timeout}
+	}
+	return &registryClient{opts: opts, client: client}
+}
+
+// get fetches base+path. The Go module proxy answers 410 Gone for modules
+// it can't find, which is treated like 404.
+func (r *registryClient) get(base, path string) ([]byte, *url.URL, error) {
+	req, err := http.NewRequest("GET", base+path, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", registryUserAgent)
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, req.URL, fmt.Errorf("network error: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone {
+		return nil, req.URL, fmt.Errorf("%s%s: %w", req.URL.Host, req.URL.Path, errNotInRegistry)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, req.URL, fmt.Errorf("%s%s returned %s", req.URL.Host, req.URL.Path, resp.Status)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, req.URL, fmt.Errorf("reading %s response: %w", req.URL.Host, err)
+	}
+	return body, req.URL, nil
+}
+
+// getJSON fetches base+path and decodes the JSON response into out
+func (r *registryClient) getJSON(base, path string, out interface{}) error {
+	body, u, err := r.get(base, path)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("decoding %s response: %w", u.Host, err)
+	}
+	return nil
+}
+
+// packageMetadata is what a registry reports about a package release
+type packageMetadata struct {
+	license    string // as declared; not necessarily an SPDX expression
+	repository string // source repository URL
+}
+
+// metadata fetches the metadata of a package release, or of its latest
+// release when version is empty. It returns nil for file types without a
+// supported registry.
+func (r *registryClient) metadata(fileType, name, version string) (*packageMetadata, error) {
+	switch fileType {
+	case "npm":
+		return r.npmMetadata(name, version)
+	case "python":
+		return r.pypiMetadata(name, version)
+	case "rust":
+		return r.cratesMetadata(name, version)
+	case "ruby":
+		return r.rubyGemsMetadata(name, version)
+	case "composer":
+		return r.packagistMetadata(name, version)
+	}
+	return nil, nil
+}
+
+func (r *registryClient) npmMetadata(name, version string) (*packageMetadata, error) {
+	if version == "" {
+		version = "latest"
+	}
+	var doc struct {
+		License  json.RawMessage `json:"license"`
+		Licenses []struct {
+			Type string `json:"type"`
+		} `json:"licenses"` // deprecated form
+		Repository json.RawMessage `json:"repository"`
+	}
+	if err := r.getJSON(r.opts.NPMURL, "/"+name+"/"+url.PathEscape(version), &doc); err != nil {
+		return nil, err
+	}
+	meta := &packageMetadata{license: stringOrField(doc.License, "type"), repository: stringOrField(doc.Repository, "url")}
+	if meta.license == "" {
+		var types []string
+		for _, l := range doc.Licenses {
+			types = append(types, l.Type)
+		}
+		meta.license = joinDeclaredLicenses(types)
+	}
+	return meta, nil
+}
+
+// stringOrField decodes a JSON value that is either a string or an object
+// holding the string in field, as npm allows for "license" and "repository"
+func stringOrField(raw json.RawMessage, field string) string {
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		return s
+	}
+	var obj map[string]interface{}
+	if json.Unmarshal(raw, &obj) == nil {
+		s, _ = obj[field].(string)
+	}
+	return s
+}
+
+func (r *registryClient) pypiMetadata(name, version string) (*packageMetadata, error) {
+	path := "/pypi/" + url.PathEscape(name) + "/json"
+	if version != "" {
+		path = "/pypi/" + url.PathEscape(name) + "/" + url.PathEscape(version) + "/json"
+	}
+	var doc struct {
+		Info struct {
+			License           string            `json:"license"`
+			LicenseExpression string            `json:"license_expression"` // PEP 639
+			Classifiers       []string          `json:"classifiers"`
+			HomePage          string            `json:"home_page"`
+			ProjectURLs       map[string]string `json:"project_urls"`
+		} `json:"info"`
+	}
+	if err := r.getJSON(r.opts.PyPIURL, path, &doc); err != nil {
+		return nil, err
+	}
+	info := doc.Info
+	meta := &packageMetadata{license: info.LicenseExpression}
+	if meta.license == "" && spdxFromDeclared(info.License) != "" {
+		meta.license = info.License
+	}
+	if meta.license == "" {
+		var names []string
+		for _, c := range info.Classifiers {
+			if strings.HasPrefix(c, "License :: ") {
+				names = append(names, c[strings.LastIndex(c, "::")+2:])
+			}
+		}
+		meta.license = joinDeclaredLicenses(names)
+	}
+	for _, u := range append([]string{info.HomePage}, sortedValues(info.ProjectURLs)...) {
+		if _, _, ok := githubRepoFromURL(u); ok {
+			meta.repository = u
+			break
+		}
+	}
+	return meta, nil
+}
+
+// sortedValues returns the values of a map ordered by key
+func sortedValues(m map[string]string) []string {
+	values := make([]string, 0, len(m))
+	for _, k := range sortedKeys(m) {
+		values = append(values, m[k])
+	}
+	return values
+}
+
+func (r *registryClient) cratesMetadata(name, version string) (*packageMetadata, error) {
+	var doc struct {
+		Crate struct {
+			Repository string `json:"repository"`
+		} `json:"crate"`
+		Versions []struct {
+			Num     string `json:"num"`
+			License string `json:"license"`
+		} `json:"versions"`
+	}
+	if err := r.getJSON(r.opts.CratesURL, "/api/v1/crates/"+url.PathEscape(name), &doc); err != nil {
+		return nil, err
+	}
+	meta := &packageMetadata{repository: doc.Crate.Repository}
+	// Versions are listed newest first
+	for i, v := range doc.Versions {
+		if v.Num == version || (i == 0 && meta.license == "") {
+			meta.license = v.License
+		}
+	}
+	return meta, nil
+}
+
+func (r *registryClient) rubyGemsMetadata(name, version string) (*packageMetadata, error) {
+	path := "/api/v1/gems/" + url.PathEscape(name) + ".json"
+	if version != "" {
+		path = "/api/v2/rubygems/" + url.PathEscape(name) + "/versions/" + url.PathEscape(version) + ".json"
+	}
+	var doc struct {
+		Licenses      []string `json:"licenses"`
+		SourceCodeURI string   `json:"source_code_uri"`
+		HomepageURI   string   `json:"homepage_uri"`
+	}
+	if err := r.getJSON(r.opts.RubyGemsURL, path, &doc); err != nil {
+		return nil, err
+	}
+	meta := &packageMetadata{license: joinDeclaredLicenses(doc.Licenses), repository: doc.SourceCodeURI}
+	if meta.repository == "" {
+		meta.repository = doc.HomepageURI
+	}
+	return meta, nil
+}
+
+func (r *registryClient) packagistMetadata(name, version string) (*packageMetadata, error) {
+	var doc struct {
+		Packages map[string][]struct {
+			Version string   `json:"version"`
+			License []string `json:"license"`
+			Source  struct {
+				URL string `json:"url"`
+			} `json:"source"`
+		} `json:"packages"`
+	}
+	if err := r.getJSON(r.opts.PackagistURL, "/p2/"+name+".json", &doc); err != nil {
+		return nil, err
+	}
+	releases := doc.Packages[name]
+	if len(releases) == 0 {
+		return nil, fmt.Errorf("%s/p2/%s.json: %w", r.opts.PackagistURL, name, errNotInRegistry)
+	}
+	// Releases are listed newest first
+	release := releases[0]
+	for _, rel := range releases {
+		if strings.TrimPrefix(rel.Version, "v") == strings.TrimPrefix(version, "v") {
+			release = rel
+			break
+		}
+	}
+	return &packageMetadata{license: joinDeclaredLicenses(release.License), repository: release.Source.URL}, nil
+}
+
+// packageReleases is the release history a registry reports for a package
+type packageReleases struct {
+	latest    string               // latest stable release
+	published map[string]time.Time // release dates by version
+}
+
+// publishedAt returns the release date of version, matching versions the
+// way the ecosystem compares them ("1.0" finds PyPI's "1.0.0")
+func (p *packageReleases) publishedAt(ecosystem, version string) (time.Time, bool) {
+	if t, ok := p.published[version]; ok {
+		return t, true
+	}
+	for v, t := range p.published {
+		if CompareEcosystemVersions(ecosystem, v, version) == 0 {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// releases fetches the latest stable release of a package and the release
+// dates of its versions. The Go module proxy has no document listing every
+// date, so for Go modules only the dates of the latest release and of
+// current are fetched. It returns nil for file types without a supported
+// registry.
+func (r *registryClient) releases(fileType, name, current string) (*packageReleases, error) {
+	switch fileType {
+	case "npm":
+		return r.npmReleases(name)
+	case "python":
+		return r.pypiReleases(name)
+	case "rust":
+		return r.cratesReleases(name)
+	case "ruby":
+		return r.rubyGemsReleases(name)
+	case "go":
+		return r.goProxyReleases(name, current)
+	}
+	return nil, nil
+}
+
+func (r *registryClient) npmReleases(name string) (*packageReleases, error) {
+	var doc struct {
+		DistTags map[string]string `json:"dist-tags"`
+		Time     map[string]string `json:"time"` // also has "created" and "modified"
+	}
+	if err := r.getJSON(r.opts.NPMURL, "/"+name, &doc); err != nil {
+		return nil, err
+	}
+	rel := &packageReleases{latest: doc.DistTags["latest"], published: make(map[string]time.Time)}
+	for v, date := range doc.Time {
+		if t, err := time.Parse(time.RFC3339, date); err == nil && v != "created" && v != "modified" {
+			rel.published[v] = t
+		}
+	}
+	return rel, nil
+}
+
+func (r *registryClient) pypiReleases(name string) (*packageReleases, error) {
+	var doc struct {
+		Info struct {
+			Version string `json:"version"` // the latest stable release
+		} `json:"info"`
+		Releases map[string][]struct {
+			UploadTime string `json:"upload_time_iso_8601"`
+			Yanked     bool   `json:"yanked"`
+		} `json:"releases"`
+	}
+	if err := r.getJSON(r.opts.PyPIURL, "/pypi/"+url.PathEscape(name)+"/json", &doc); err != nil {
+		return nil, err
+	}
+	rel := &packageReleases{latest: doc.Info.Version, published: make(map[string]time.Time)}
+	for v, files := range doc.Releases {
+		// A release is published when its first file is uploaded
+		for _, f := range files {
+			t, err := time.Parse(time.RFC3339, f.UploadTime)
+			if err == nil && (rel.published[v].IsZero() || t.Before(rel.published[v])) {
+				rel.published[v] = t
+			}
+		}
+	}
+	return rel, nil
+}
+
+func (r *registryClient) cratesReleases(name string) (*packageReleases, error) {
+	var doc struct {
+		Crate struct {
+			MaxStableVersion string `json:"max_stable_version"`
+			MaxVersion       string `json:"max_version"`
+		} `json:"crate"`
+		Versions []struct {
+			Num       string    `json:"num"`
+			CreatedAt time.Time `json:"created_at"`
+			Yanked    bool      `json:"yanked"`
+		} `json:"versions"`
+	}
+	if err := r.getJSON(r.opts.CratesURL, "/api/v1/crates/"+url.PathEscape(name), &doc); err != nil {
+		return nil, err
+	}
+	rel := &packageReleases{latest: doc.Crate.MaxStableVersion, published: make(map[string]time.Time)}
+	if rel.latest == "" {
+		rel.latest = doc.Crate.MaxVersion
+	}
+	for _, v := range doc.Versions {
+		rel.published[v.Num] = v.CreatedAt
+	}
+	return rel, nil
+}
+
+func (r *registryClient) rubyGemsReleases(name string) (*packageReleases, error) {
+	var versions []struct {
+		Number     string    `json:"number"`
+		CreatedAt  time.Time `json:"created_at"`
+		Prerelease bool      `json:"prerelease"`
+	}
+	if err := r.getJSON(r.opts.RubyGemsURL, "/api/v1/versions/"+url.PathEscape(name)+".json", &versions); err != nil {
+		return nil, err
+	}
+	rel := &packageReleases{published: make(map[string]time.Time)}
+	for _, v := range versions {
+		rel.published[v.Number] = v.CreatedAt
+		if !v.Prerelease && (rel.latest == "" || compareVersions(v.Number, rel.latest) > 0) {
+			rel.latest = v.Number
+		}
+	}
+	return rel, nil
+}
+
+// goProxyReleases reads a module's versions from the module proxy. The
+// latest stable release is the highest listed version without a
+// prerelease; modules with only pseudo-versions use @latest.
+func (r *registryClient) goProxyReleases(module, current string) (*packageReleases, error) {
+	escaped := escapeModulePath(module)
+	body, _, err := r.get(r.opts.GoProxyURL, "/"+escaped+"/@v/list")
+	if err != nil {
+		return nil, err
+	}
+	rel := &packageReleases{published: make(map[string]time.Time)}
+	for _, v := range strings.Fields(string(body)) {
+		if !strings.Contains(strings.TrimSuffix(v, "+incompatible"), "-") &&
+			(rel.latest == "" || compareGoVersions(v, rel.latest) > 0) {
+			rel.latest = v
+		}
+	}
+
+	var info struct {
+		Version string    `json:"Version"`
+		Time    time.Time `json:"Time"`
+	}
+	if rel.latest == "" {
+		if err := r.getJSON(r.opts.GoProxyURL, "/"+escaped+"/@latest", &info); err != nil {
+			return nil, err
+		}
+		rel.latest, rel.published[info.Version] = info.Version, info.Time
+	}
+	for _, v := range []string{rel.latest, current} {
+		if _, ok := rel.published[v]; ok || v == "" {
+			continue
+		}
+		if err := r.getJSON(r.opts.GoProxyURL, "/"+escaped+"/@v/"+escapeModulePath(v)+".info", &info); err != nil {
+			if errors.Is(err, errNotInRegistry) {
+				continue
+			}
+			return nil, err
+		}
+		rel.published[v] = info.Time
+	}
+	return rel, nil
+}
+
+// escapeModulePath applies the module proxy's case encoding: each
+// upper-case letter becomes "!" followed by the letter in lower case
+func escapeModulePath(path string) string {
+	var b strings.Builder
+	for _, c := range path {
+		if 'A' <= c && c <= 'Z' {
+			b.WriteByte('!')
+			c += 'a' - 'A'
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
+
+// joinDeclaredLicenses combines a list of licenses, which registries use to
+// mean a choice between them, into an SPDX expression. Names that are not
+// SPDX IDs are converted where possible.
+func joinDeclaredLicenses(licenses []string) string {
+	var ids []string
+	for _, l := range licenses {
+		if id := spdxFromDeclared(l); id != "" && !contains(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) > 1 {
+		for i, id := range ids {
+			if strings.Contains(id, " ") {
+				ids[i] = "(" + id + ")"
+			}
+		}
+	}
+	return strings.Join(ids, " OR ")
+}
+
+var (
+	githubRepoURL = regexp.MustCompile(`(?i)^(?:git\+)?(?:https?://|git://|ssh://git@|git@)?(?:www\.)?github\.com[/:]([\w.-]+)/([\w.-]+?)(?:\.git)?(?:[/#?].*)?$`)
+	// extraSPDXIDs are SPDX IDs seen in registry metadata that have no dash,
+	// so don't look like IDs, and are not in knownLicenses
+	extraSPDXIDs = map[string]bool{"ruby": true, "x11": true, "json": true, "curl": true, "vim": true, "ncsa": true, "postgresql": true, "openssl": true, "beerware": true}
+	// dashedLicenseID matches other SPDX-style IDs, such as "Python-2.0"
+	dashedLicenseID = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9.]*-[A-Za-z0-9.-]+\+?$`)
+	// licenseNameWord splits a license name into words and version numbers
+	licenseNameWord    = regexp.MustCompile(`[a-z]+|[0-9]+(\.[0-9]+)*`)
+	licenseNameSkip    = map[string]bool{"the": true, "license": true, "licence": true, "version": true, "v": true, "gnu": true}
+	licenseNamePhrases = strings.NewReplacer("lesser general public", "lgpl", "library general public", "lgpl",
+		"affero general public", "agpl", "general public", "gpl", "gplv", "gpl ")
+	// licenseAliases are common license names that are neither an SPDX ID nor
+	// the name in knownLicenses, keyed by licenseNameKey
+	licenseAliases = map[string]string{
+		"apache 2": "Apache-2.0", "apache software": "Apache-2.0", "asl 2.0": "Apache-2.0",
+		"python software foundation": "PSF-2.0", "zlib libpng": "Zlib", "boost": "BSL-1.0",
+	}
+	licenseNamesOnce sync.Once
+	licenseNames     map[string]string
+)
+
+// githubRepoFromURL extracts the owner and name of a GitHub repository from
+// a repository URL in any of the forms registries record, such as
+// "git+https://github.com/o/r.git", "git@github.com:o/r.git" or "github:o/r"
+func githubRepoFromURL(u string) (owner, repo string, ok bool) {
+	u = strings.TrimSpace(u)
+	if rest, found := strings.CutPrefix(u, "github:"); found {
+		u = "github.com/" + rest
+	}
+	m := githubRepoURL.FindStringSubmatch(u)
+	if m == nil {
+		return "", "", false
+	}
+	return m[1], m[2], true
+}
+
+// licenseNameKey reduces a license name to a comparable form: "GNU General
+// Public License v3 (GPLv3)" and "GNU GPL v3" both become "gpl 3"
+func licenseNameKey(name string) string {
+	name = strings.ToLower(name)
+	if i := strings.Index(name, "("); i > 0 {
+		name = name[:i]
+	}
+	name = licenseNamePhrases.Replace(name)
+	var words []string
+	for _, w := range licenseNameWord.FindAllString(name, -1) {
+		if !licenseNameSkip[w] {
+			words = append(words, w)
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// spdxFromDeclared converts a license as a registry or package manifest
+// declares it to an SPDX expression. SPDX expressions are kept, common
+// license names are converted, and full license texts are matched against
+// the bundled licenses. It returns "" if the license can't be identified.
+func spdxFromDeclared(declared string) string {
+	declared = strings.TrimSpace(declared)
+	switch upper := strings.ToUpper(declared); {
+	case declared == "", upper == "UNKNOWN", upper == "NOASSERTION", upper == "NONE",
+		strings.HasPrefix(upper, "SEE LICEN"), strings.HasPrefix(upper, "OTHER"):
+		return ""
+	case upper == "UNLICENSED":
+		// npm's marker for packages not licensed for use by others
+		return "UNLICENSED"
+	}
+	if isSPDXExpression(declared) {
+		return strings.Join(strings.Fields(declared), " ")
+	}
+	if len(declared) > 200 {
+		var ids []string
+		for _, m := range matchLicenseText(declared) {
+			ids = append(ids, m.id)
+		}
+		return strings.Join(ids, " AND ")
+	}
+
+	licenseNamesOnce.Do(func() {
+		licenseNames = make(map[string]string)
+		for id, info := range knownLicenses {
+			licenseNames[licenseNameKey(id)] = id
+			licenseNames[licenseNameKey(info.Name)] = id
+		}
+		for alias, id := range licenseAliases {
+			licenseNames[alias] = id
+		}
+	})
+	return licenseNames[licenseNameKey(declared)]
+}
+
+// isSPDXExpression reports whether s looks like an SPDX license expression
+// rather than a license name
+func isSPDXExpression(s string) bool {
+	tokens := strings.Fields(strings.NewReplacer("(", " ", ")", " ").Replace(s))
+	if len(tokens) == 0 {
+		return false
+	}
+	afterWith := false
+	for _, token := range tokens {
+		switch {
+		case afterWith:
+			afterWith = false
+		case strings.EqualFold(token, "WITH"):
+			afterWith = true
+		case strings.EqualFold(token, "AND"), strings.EqualFold(token, "OR"):
+		case licenseBySPDX(token) != nil, extraSPDXIDs[strings.ToLower(token)],
+			strings.HasPrefix(token, "LicenseRef-"), dashedLicenseID.MatchString(token) && !strings.Contains(token, "--"):
+		default:
+			return false
+		}
+	}
+	return true
+}
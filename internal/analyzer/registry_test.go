@@ -0,0 +1,130 @@
+package analyzer
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// fakeRegistries serves canned responses for every supported registry by path
+func fakeRegistries(t *testing.T) *httptest.Server {
+	responses := map[string]string{
+		"/express/4.18.2":            `{"license":"MIT","repository":{"type":"git","url":"git+https://github.com/expressjs/express.git"}}`,
+		"/old-pkg/latest":            `{"licenses":[{"type":"MIT"},{"type":"Apache 2.0"}]}`,
+		"/no-license/1.0.0":          `{"repository":"github:acme/no-license"}`,
+		"/pypi/requests/2.31.0/json": `{"info":{"license":"Apache 2.0","project_urls":{"Source":"https://github.com/psf/requests"}}}`,
+		"/pypi/numpy/json":           `{"info":{"license":"","license_expression":"BSD-3-Clause"}}`,
+		"/pypi/chardet/json": `{"info":{"license":"LGPL","classifiers":["Programming Language :: Python",
+			"License :: OSI Approved :: GNU Lesser General Public License v2 or later (LGPLv2+)",
+			"License :: OSI Approved :: GNU General Public License v3 (GPLv3)"]}}`,
+		"/api/v1/crates/serde": `{"crate":{"repository":"https://github.com/serde-rs/serde"},
+			"versions":[{"num":"1.0.200","license":"MIT OR Apache-2.0"},{"num":"0.1.0","license":"MIT"}]}`,
+		"/api/v2/rubygems/rails/versions/7.1.2.json": `{"licenses":["MIT"],"source_code_uri":"https://github.com/rails/rails/tree/v7.1.2"}`,
+		"/api/v1/gems/json.json":                     `{"licenses":["Ruby","BSD-2-Clause"]}`,
+		"/p2/monolog/monolog.json": `{"packages":{"monolog/monolog":[{"version":"3.5.0","license":["MIT"]},
+			{"version":"1.0.0","license":["LGPL-3.0-or-later"]}]}}`,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("User-Agent") == "" {
+			http.Error(w, "user agent required", http.StatusForbidden)
+			return
+		}
+		body, ok := responses[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestRegistryMetadata(t *testing.T) {
+	server := fakeRegistries(t)
+	client := newRegistryClient(RegistryOptions{
+		NPMURL: server.URL, PyPIURL: server.URL, CratesURL: server.URL + "/",
+		RubyGemsURL: server.URL, PackagistURL: server.URL,
+	})
+
+	tests := []struct {
+		fileType, name, version string
+		license, repository     string
+	}{
+		{"npm", "express", "4.18.2", "MIT", "git+https://github.com/expressjs/express.git"},
+		{"npm", "old-pkg", "", "MIT OR Apache-2.0", ""},
+		{"npm", "no-license", "1.0.0", "", "github:acme/no-license"},
+		{"python", "requests", "2.31.0", "Apache 2.0", "https://github.com/psf/requests"},
+		{"python", "numpy", "", "BSD-3-Clause", ""},
+		{"python", "chardet", "", "GPL-3.0", ""},
+		{"rust", "serde", "0.1.0", "MIT", "https://github.com/serde-rs/serde"},
+		{"rust", "serde", "", "MIT OR Apache-2.0", "https://github.com/serde-rs/serde"},
+		{"ruby", "rails", "7.1.2", "MIT", "https://github.com/rails/rails/tree/v7.1.2"},
+		{"ruby", "json", "", "Ruby OR BSD-2-Clause", ""},
+		{"composer", "monolog/monolog", "1.0.0", "LGPL-3.0-or-later", ""},
+		{"composer", "monolog/monolog", "v9.9.9", "MIT", ""},
+	}
+	for _, tt := range tests {
+		meta, err := client.metadata(tt.fileType, tt.name, tt.version)
+		if err != nil {
+			t.Errorf("%s %s: %v", tt.fileType, tt.name, err)
+			continue
+		}
+		if meta.license != tt.license || meta.repository != tt.repository {
+			t.Errorf("%s %s@%s = %+v", tt.fileType, tt.name, tt.version, meta)
+		}
+	}
+
+	if meta, err := client.metadata("go", "golang.org/x/net", "v0.10.0"); meta != nil || err != nil {
+		t.Errorf("go modules have no registry metadata, got %+v, %v", meta, err)
+	}
+}
+
+func TestSpdxFromDeclared(t *testing.T) {
+	tests := map[string]string{
+		"MIT":                                    "MIT",
+		"(MIT OR Apache-2.0)":                    "(MIT OR Apache-2.0)",
+		"mit or  Apache-2.0":                     "mit or Apache-2.0",
+		"Python-2.0":                             "Python-2.0",
+		"Apache License, Version 2.0":            "Apache-2.0",
+		"MIT License":                            "MIT",
+		"GNU General Public License v3 (GPLv3)":  "GPL-3.0",
+		"GPLv2":                                  "GPL-2.0",
+		"GNU Lesser General Public License v2.1": "LGPL-2.1",
+		"BSD 3-Clause":                           "BSD-3-Clause",
+		"UNLICENSED":                             "UNLICENSED",
+		"SEE LICENSE IN LICENSE.md":              "",
+		"UNKNOWN":                                "",
+		"BSD":                                    "",
+		"Dual License":                           "",
+	}
+	for declared, want := range tests {
+		if got := spdxFromDeclared(declared); got != want {
+			t.Errorf("spdxFromDeclared(%q) = %q, want %q", declared, got, want)
+		}
+	}
+
+	// Some packages put the whole license text in the metadata
+	if got := spdxFromDeclared(bundledLicense(t, "ISC")); got != "ISC" {
+		t.Errorf("ISC text = %q", got)
+	}
+}
+
+func TestGithubRepoFromURL(t *testing.T) {
+	tests := map[string]string{
+		"git+https://github.com/expressjs/express.git": "expressjs/express",
+		"git@github.com:serde-rs/serde.git":            "serde-rs/serde",
+		"github:acme/widget":                           "acme/widget",
+		"https://github.com/rails/rails/tree/v7.1.2":   "rails/rails",
+		"http://www.github.com/psf/requests#readme":    "psf/requests",
+		"https://gitlab.com/acme/widget":               "",
+		"":                                             "",
+	}
+	for u, want := range tests {
+		owner, repo, ok := githubRepoFromURL(u)
+		if got := strings.TrimPrefix(owner+"/"+repo, "/"); got != want || ok != (want != "") {
+			t.Errorf("githubRepoFromURL(%q) = %q, %v", u, got, ok)
+		}
+	}
+}
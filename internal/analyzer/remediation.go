@@ -0,0 +1,274 @@
+package analyzer
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Remediation is the fix for one vulnerable package version
+type Remediation struct {
+	Ecosystem       string   `json:"ecosystem"` // OSV ecosystem, e.g. "npm"
+	Package         string   `json:"package"`
+	Version         string   `json:"version"`
+	Severity        string   `json:"severity"` // highest severity among its vulnerabilities
+	Vulnerabilities []string `json:"vulnerabilities"`
+	// FixVersion is the lowest release fixing every vulnerability that has
+	// a fix; Unfixed lists the ones that have none yet
+	FixVersion   string   `json:"fix_version,omitempty"`
+	Unfixed      []string `json:"unfixed,omitempty"`
+	MajorUpgrade bool     `json:"major_upgrade,omitempty"` // the fix crosses a major version
+	Direct       bool     `json:"direct"`
+	// Bump lists the direct dependencies ("name@version") that pull in a
+	// transitive package; upgrading them is how the fix reaches the tree
+	Bump []string `json:"bump,omitempty"`
+}
+
+// ManifestEdit is a suggested change to a dependency manifest. Edits for
+// transitive packages have no target version, only a note.
+type ManifestEdit struct {
+	File    string `json:"file"`
+	Package string `json:"package"`
+	From    string `json:"from"`
+	To      string `json:"to,omitempty"`
+	Note    string `json:"note,omitempty"`
+}
+
+// RemediationPlan groups the vulnerabilities of a scan by package and
+// lists the upgrades that fix them
+type RemediationPlan struct {
+	Items         []Remediation  `json:"items"`
+	Edits         []ManifestEdit `json:"edits,omitempty"`
+	Fixable       int            `json:"fixable"`
+	Unfixable     int            `json:"unfixable"` // packages with no fix for any vulnerability
+	MajorUpgrades int            `json:"major_upgrades"`
+}
+
+// BuildRemediationPlan works out, for every vulnerable package, the minimal
+// upgrade fixing all of its known vulnerabilities and where to apply it.
+// The graph is optional; without it transitive packages have no Bump list.
+func BuildRemediationPlan(sec *SecurityScanResult, deps *DependencyAnalysis, graph *DependencyGraph) *RemediationPlan {
+	plan := &RemediationPlan{}
+	if sec == nil {
+		return plan
+	}
+
+	type key struct{ ecosystem, name, version string }
+	items := make(map[key]*Remediation)
+	var order []key
+	for _, v := range sec.Vulnerabilities {
+		k := key{v.Ecosystem, v.Package, v.Version}
+		item, ok := items[k]
+		if !ok {
+			item = &Remediation{Ecosystem: v.Ecosystem, Package: v.Package, Version: v.Version, Severity: v.Severity}
+			items[k] = item
+			order = append(order, k)
+		}
+		if contains(item.Vulnerabilities, v.ID) {
+			continue
+		}
+		item.Vulnerabilities = append(item.Vulnerabilities, v.ID)
+		if severityRank(v.Severity) > severityRank(item.Severity) {
+			item.Severity = v.Severity
+		}
+		switch {
+		case v.FixedIn == "":
+			item.Unfixed = append(item.Unfixed, v.ID)
+		case item.FixVersion == "" || CompareEcosystemVersions(v.Ecosystem, v.FixedIn, item.FixVersion) > 0:
+			item.FixVersion = v.FixedIn
+		}
+	}
+
+	for _, k := range order {
+		item := items[k]
+		item.MajorUpgrade = item.FixVersion != "" && crossesMajor(queryVersion(item.Version), item.FixVersion)
+		declared := declaringFiles(deps, item.Ecosystem, item.Package, item.Version)
+		item.Direct = len(declared) > 0
+		if item.Direct {
+			for _, d := range declared {
+				if item.FixVersion != "" {
+					plan.Edits = append(plan.Edits, ManifestEdit{
+						File: d.file, Package: item.Package, From: d.dep.Version,
+						To: upgradeSpec(d.dep.Version, item.FixVersion),
+					})
+				}
+			}
+		} else {
+			item.Bump = bumpTargets(graph, item.Ecosystem, item.Package, queryVersion(item.Version))
+			plan.Edits = append(plan.Edits, transitiveEdits(deps, item)...)
+		}
+
+		switch {
+		case item.FixVersion == "":
+			plan.Unfixable++
+		default:
+			plan.Fixable++
+			if item.MajorUpgrade {
+				plan.MajorUpgrades++
+			}
+		}
+		plan.Items = append(plan.Items, *item)
+	}
+
+	sort.SliceStable(plan.Items, func(i, j int) bool {
+		a, b := plan.Items[i], plan.Items[j]
+		if ra, rb := severityRank(a.Severity), severityRank(b.Severity); ra != rb {
+			return ra > rb
+		}
+		if a.Direct != b.Direct {
+			return a.Direct
+		}
+		return a.Package < b.Package
+	})
+	sort.SliceStable(plan.Edits, func(i, j int) bool {
+		if plan.Edits[i].File != plan.Edits[j].File {
+			return plan.Edits[i].File < plan.Edits[j].File
+		}
+		return plan.Edits[i].Package < plan.Edits[j].Package
+	})
+	return plan
+}
+
+// declaredDependency is a direct dependency and the manifest declaring it
+type declaredDependency struct {
+	file string
+	dep  Dependency
+}
+
+// declaringFiles finds the manifests that declare a package directly, at
+// the version the scan looked up
+func declaringFiles(deps *DependencyAnalysis, ecosystem, name, version string) []declaredDependency {
+	if deps == nil {
+		return nil
+	}
+	var found []declaredDependency
+	for _, file := range deps.Files {
+		if mapEcosystem(file.FileType) != ecosystem {
+			continue
+		}
+		for _, dep := range file.Dependencies {
+			scanned := dep.Version
+			if dep.Resolved != "" {
+				scanned = dep.Resolved
+			}
+			if !dep.Transitive && scanned == version && lockKey(file.FileType, dep.Name) == lockKey(file.FileType, name) {
+				found = append(found, declaredDependency{file.Filename, dep})
+			}
+		}
+	}
+	return found
+}
+
+// bumpTargets returns the direct dependencies that pull in a package
+func bumpTargets(graph *DependencyGraph, ecosystem, name, version string) []string {
+	if graph == nil {
+		return nil
+	}
+	seen := make(map[string]bool)
+	var targets []string
+	for _, node := range graph.FindNodes(name, version) {
+		if mapEcosystem(node.Ecosystem) != ecosystem {
+			continue
+		}
+		for _, id := range graph.IntroducedBy(node.ID) {
+			root := graph.Node(id)
+			target := root.Name + "@" + root.Version
+			if !seen[target] {
+				seen[target] = true
+				targets = append(targets, target)
+			}
+		}
+	}
+	sort.Strings(targets)
+	return targets
+}
+
+// transitiveEdits notes, in the manifests declaring each direct dependency
+// to bump, that it needs a release depending on the fixed package
+func transitiveEdits(deps *DependencyAnalysis, item *Remediation) []ManifestEdit {
+	need := "a fixed release"
+	if item.FixVersion != "" {
+		need = item.Package + " >= " + item.FixVersion
+	}
+	var edits []ManifestEdit
+	for _, target := range item.Bump {
+		name, version := target, ""
+		if i := strings.LastIndex(target, "@"); i > 0 {
+			name, version = target[:i], target[i+1:]
+		}
+		for _, d := range declaringFiles(deps, item.Ecosystem, name, version) {
+			edits = append(edits, ManifestEdit{
+				File: d.file, Package: name, From: d.dep.Version,
+				Note: fmt.Sprintf("upgrade to a release that requires %s (pulls in %s@%s)", need, item.Package, item.Version),
+			})
+		}
+	}
+	return edits
+}
+
+// specPattern splits a single-version manifest spec into its operator and
+// version, e.g. "^4.17.15", "~> 6.1", ">=2.0", "==1.2.3", "v1.9.1"
+var specPattern = regexp.MustCompile(`^(\s*(?:\^|~>|~=|~|>=|==|=)?\s*)(v?)[0-9][0-9A-Za-z.+-]*\s*$`)
+
+// upgradeSpec rewrites a manifest version spec to require the fix, keeping
+// its operator and "v" prefix. Compound ranges are replaced by ">=fix".
+func upgradeSpec(spec, fix string) string {
+	m := specPattern.FindStringSubmatch(spec)
+	if m == nil {
+		return ">=" + fix
+	}
+	fix = strings.TrimPrefix(fix, "v")
+	return m[1] + m[2] + fix
+}
+
+// crossesMajor reports whether upgrading between two versions crosses a
+// major version. Below 1.0 every minor release counts as major, as in
+// semver's "0.y.z is for initial development".
+func crossesMajor(from, to string) bool {
+	a, okA := versionLine(from)
+	b, okB := versionLine(to)
+	return okA && okB && a != b
+}
+
+// versionLine returns the release line of a version: its major number, or
+// "0.minor" before 1.0
+func versionLine(v string) (string, bool) {
+	parts := strings.Split(strings.TrimPrefix(strings.TrimSpace(v), "v"), ".")
+	major := strings.TrimLeft(parts[0], "0")
+	if !isDigits(parts[0]) {
+		return "", false
+	}
+	if major != "" {
+		return major, true
+	}
+	if len(parts) > 1 && isDigits(parts[1]) {
+		return "0." + strings.TrimLeft(parts[1], "0"), true
+	}
+	return "0", true
+}
+
+// Patch renders the manifest edits as a patch-style listing. It is a guide
+// for a person editing the files and is not meant for `git apply`.
+func (p *RemediationPlan) Patch() string {
+	if p == nil || len(p.Edits) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	file := ""
+	for _, e := range p.Edits {
+		if e.File != file {
+			if file != "" {
+				b.WriteString("\n")
+			}
+			file = e.File
+			fmt.Fprintf(&b, "--- %s\n+++ %s\n", file, file)
+		}
+		if e.To == "" {
+			fmt.Fprintf(&b, "# %s %s: %s\n", e.Package, e.From, e.Note)
+			continue
+		}
+		fmt.Fprintf(&b, "- %s %s\n+ %s %s\n", e.Package, e.From, e.Package, e.To)
+	}
+	return b.String()
+}
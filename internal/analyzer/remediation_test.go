@@ -0,0 +1,79 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBuildRemediationPlan(t *testing.T) {
+	deps := &DependencyAnalysis{}
+	deps.AddFiles(
+		DependencyFile{Filename: "package.json", FileType: "npm", Dependencies: []Dependency{
+			{Name: "express", Version: "^4.18.0", Type: "production"},
+			{Name: "jest", Version: "^29.0.0", Type: "dev"},
+		}},
+		DependencyFile{Filename: "go.mod", FileType: "go", Dependencies: []Dependency{
+			{Name: "golang.org/x/net", Version: "v0.7.0"},
+		}},
+	)
+	deps.ApplyLockFiles(ParseLockFile("package-lock.json", loadFixture(t, "lockfiles/package-lock.json")))
+	graph := BuildDependencyGraph(deps)
+
+	sec := &SecurityScanResult{Vulnerabilities: []Vulnerability{
+		{ID: "GHSA-a", Severity: "MEDIUM", Ecosystem: "npm", Package: "express", Version: "4.18.2", FixedIn: "4.19.2"},
+		{ID: "GHSA-b", Severity: "HIGH", Ecosystem: "npm", Package: "express", Version: "4.18.2", FixedIn: "4.20.0"},
+		{ID: "GHSA-c", Severity: "LOW", Ecosystem: "npm", Package: "debug", Version: "2.6.9", FixedIn: "3.1.0"},
+		{ID: "GO-1", Severity: "CRITICAL", Ecosystem: "Go", Package: "golang.org/x/net", Version: "v0.7.0", FixedIn: "0.17.0"},
+		{ID: "GO-2", Severity: "LOW", Ecosystem: "Go", Package: "golang.org/x/net", Version: "v0.7.0"},
+	}}
+	plan := BuildRemediationPlan(sec, deps, graph)
+
+	if len(plan.Items) != 3 || plan.Fixable != 3 || plan.Unfixable != 0 || plan.MajorUpgrades != 2 {
+		t.Fatalf("plan = %+v", plan)
+	}
+	net, express, debug := plan.Items[0], plan.Items[1], plan.Items[2]
+
+	// The fix covers every vulnerability that has one
+	if express.FixVersion != "4.20.0" || express.Severity != "HIGH" || !express.Direct || express.MajorUpgrade {
+		t.Errorf("express = %+v", express)
+	}
+	// Before 1.0 a minor release is a major upgrade
+	if net.FixVersion != "0.17.0" || !net.MajorUpgrade || strings.Join(net.Unfixed, ",") != "GO-2" {
+		t.Errorf("x/net = %+v", net)
+	}
+	if debug.Direct || !debug.MajorUpgrade || strings.Join(debug.Bump, ",") != "express@4.18.2" {
+		t.Errorf("debug = %+v", debug)
+	}
+
+	want := `--- go.mod
++++ go.mod
+- golang.org/x/net v0.7.0
++ golang.org/x/net v0.17.0
+
+--- package.json
++++ package.json
+- express ^4.18.0
++ express ^4.20.0
+# express ^4.18.0: upgrade to a release that requires debug >= 3.1.0 (pulls in debug@2.6.9)
+`
+	if got := plan.Patch(); got != want {
+		t.Errorf("patch =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestUpgradeSpec(t *testing.T) {
+	tests := []struct{ spec, fix, want string }{
+		{"^4.17.15", "4.17.21", "^4.17.21"},
+		{"~> 6.1", "6.1.7", "~> 6.1.7"},
+		{"==2.25.0", "2.31.0", "==2.31.0"},
+		{"v1.9.1", "1.9.2", "v1.9.2"},
+		{"1.2.0", "v1.2.1", "1.2.1"},
+		{">=2.0,<3", "2.3.1", ">=2.3.1"},
+		{"", "1.0.1", ">=1.0.1"},
+	}
+	for _, tt := range tests {
+		if got := upgradeSpec(tt.spec, tt.fix); got != tt.want {
+			t.Errorf("upgradeSpec(%q, %q) = %q, want %q", tt.spec, tt.fix, got, tt.want)
+		}
+	}
+}
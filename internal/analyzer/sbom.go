@@ -0,0 +1,577 @@
+package analyzer
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// This file generates Software Bills of Materials from a dependency
+// analysis, in CycloneDX 1.5 JSON and SPDX 2.3 JSON or tag-value form.
+
+// SBOM formats accepted by EncodeSBOM
+const (
+	SBOMCycloneDXJSON = "cyclonedx-json"
+	SBOMSPDXJSON      = "spdx-json"
+	SBOMSPDXTag       = "spdx-tag"
+)
+
+// SBOMFormats lists the supported formats
+var SBOMFormats = []string{SBOMCycloneDXJSON, SBOMSPDXJSON, SBOMSPDXTag}
+
+// SBOMSubject describes the project an SBOM is generated for
+type SBOMSubject struct {
+	Name    string // "owner/repo"
+	Version string // branch, tag or commit the dependencies were read from
+	URL     string
+	License string // SPDX license expression; empty when unknown
+}
+
+// SBOMComponent is one package in an SBOM
+type SBOMComponent struct {
+	Ref       string // unique reference within the document
+	Name      string
+	Version   string // as recorded, which may be a manifest range
+	Ecosystem string // dependency file type, e.g. "npm"
+	PURL      string
+	License   string // SPDX license expression; empty when unknown
+	Direct    bool
+	Dev       bool
+}
+
+// SBOM is a format-neutral bill of materials. DependsOn maps a component
+// Ref, or RootRef for the project itself, to the Refs it depends on.
+type SBOM struct {
+	Subject    SBOMSubject
+	Components []SBOMComponent
+	DependsOn  map[string][]string
+	Created    time.Time
+	Serial     string // random UUID identifying this document
+}
+
+// RootRef is the reference of the project component in an SBOM
+const RootRef = "root"
+
+// BuildSBOM collects the packages of a dependency graph into an SBOM. The
+// graph is built from deps when nil.
+func BuildSBOM(subject SBOMSubject, deps *DependencyAnalysis, graph *DependencyGraph) *SBOM {
+	if graph == nil {
+		graph = BuildDependencyGraph(deps)
+	}
+	// Licenses recorded on the dependencies, by graph node ID
+	licenses := make(map[string]string)
+	if deps != nil {
+		for _, file := range deps.Files {
+			for _, dep := range file.Dependencies {
+				if dep.License == "" {
+					continue
+				}
+				version := dep.Resolved
+				if version == "" {
+					version = dep.Version
+				}
+				licenses[graphNodeID(file.FileType, dep.Name, version)] = dep.License
+			}
+		}
+	}
+	sbom := &SBOM{
+		Subject:   subject,
+		DependsOn: make(map[string][]string),
+		Created:   time.Now().UTC().Truncate(time.Second),
+		Serial:    newUUID(),
+	}
+	for _, node := range graph.Nodes {
+		sbom.Components = append(sbom.Components, SBOMComponent{
+			Ref:       node.ID,
+			Name:      node.Name,
+			Version:   node.Version,
+			Ecosystem: node.Ecosystem,
+			PURL:      PackageURL(node.Ecosystem, node.Name, node.Version),
+			License:   licenses[node.ID],
+			Direct:    node.Direct,
+			Dev:       node.Dev,
+		})
+		if node.Direct {
+			sbom.DependsOn[RootRef] = append(sbom.DependsOn[RootRef], node.ID)
+		}
+	}
+	for _, e := range graph.Edges {
+		sbom.DependsOn[e.From] = append(sbom.DependsOn[e.From], e.To)
+	}
+	for ref := range sbom.DependsOn {
+		sort.Strings(sbom.DependsOn[ref])
+	}
+	return sbom
+}
+
+// EncodeSBOM renders an SBOM in one of SBOMFormats
+func EncodeSBOM(sbom *SBOM, format string) ([]byte, error) {
+	switch format {
+	case SBOMCycloneDXJSON:
+		return sbom.CycloneDXJSON()
+	case SBOMSPDXJSON:
+		return sbom.SPDXJSON()
+	case SBOMSPDXTag:
+		return []byte(sbom.SPDXTagValue()), nil
+	}
+	return nil, fmt.Errorf("unknown SBOM format %q (use %s)", format, strings.Join(SBOMFormats, ", "))
+}
+
+// purlTypes maps dependency file types to Package URL types
+var purlTypes = map[string]string{
+	"npm": "npm", "go": "golang", "python": "pypi", "rust": "cargo", "ruby": "gem",
+	"maven": "maven", "gradle": "maven", "nuget": "nuget", "composer": "composer",
+	"pub": "pub", "docker": "docker",
+}
+
+// PackageURL returns the Package URL (https://github.com/package-url/purl-spec)
+// of a dependency. The version is only included when it is exact.
+func PackageURL(fileType, name, version string) string {
+	typ, ok := purlTypes[fileType]
+	if !ok {
+		typ = "generic"
+	}
+	namespace := ""
+	switch typ {
+	case "npm":
+		if strings.HasPrefix(name, "@") {
+			namespace, name, _ = strings.Cut(name, "/")
+		}
+	case "maven":
+		if group, artifact, ok := strings.Cut(name, ":"); ok {
+			namespace, name = group, artifact
+		}
+	case "pypi":
+		name = normalizePythonName(name)
+	case "golang", "docker":
+		if i := strings.LastIndex(name, "/"); i >= 0 {
+			namespace, name = name[:i], name[i+1:]
+		}
+	case "composer":
+		name = strings.ToLower(name)
+		if i := strings.LastIndex(name, "/"); i >= 0 {
+			namespace, name = name[:i], name[i+1:]
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString("pkg:" + typ + "/")
+	if namespace != "" {
+		for _, segment := range strings.Split(namespace, "/") {
+			b.WriteString(purlEscape(segment) + "/")
+		}
+	}
+	b.WriteString(purlEscape(name))
+	if v := exactVersion(version); v != "" {
+		b.WriteString("@" + purlEscape(v))
+	}
+	return b.String()
+}
+
+// purlEscape percent-encodes everything but unreserved characters
+func purlEscape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || strings.IndexByte(".-_~", c) >= 0 {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+// exactVersionPattern matches a single pinned version
+var exactVersionPattern = regexp.MustCompile(`^v?[0-9A-Za-z][0-9A-Za-z.+_-]*$`)
+
+// exactVersion returns the version a spec pins ("==1.2.3" -> "1.2.3"), or
+// "" for ranges and wildcards
+func exactVersion(spec string) string {
+	v := strings.TrimSpace(spec)
+	v = strings.TrimPrefix(strings.TrimPrefix(v, "=="), "=")
+	if !exactVersionPattern.MatchString(v) {
+		return ""
+	}
+	return v
+}
+
+// newUUID returns a random (version 4) UUID
+func newUUID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// subjectName is the project's short name: "repo" for "owner/repo"
+func (s *SBOM) subjectName() string {
+	if i := strings.LastIndex(s.Subject.Name, "/"); i >= 0 {
+		return s.Subject.Name[i+1:]
+	}
+	return s.Subject.Name
+}
+
+// subjectPURL identifies the project itself
+func (s *SBOM) subjectPURL() string {
+	owner, repo, ok := strings.Cut(s.Subject.Name, "/")
+	if !ok {
+		return ""
+	}
+	purl := "pkg:github/" + purlEscape(strings.ToLower(owner)) + "/" + purlEscape(strings.ToLower(repo))
+	if s.Subject.Version != "" {
+		purl += "@" + purlEscape(s.Subject.Version)
+	}
+	return purl
+}
+
+// cdxLicense is a CycloneDX license choice: a license from the SPDX list
+// or named otherwise, or an SPDX expression
+type cdxLicense struct {
+	License    *cdxLicenseID `json:"license,omitempty"`
+	Expression string        `json:"expression,omitempty"`
+}
+
+// cdxLicenseID holds either an ID from the SPDX license list or the name
+// of any other license
+type cdxLicenseID struct {
+	ID   string `json:"id,omitempty"`
+	Name string `json:"name,omitempty"`
+}
+
+type cdxExternalRef struct {
+	Type string `json:"type"`
+	URL  string `json:"url"`
+}
+
+// listedSPDXID returns s as written on the SPDX license list, if it is a
+// single identifier from the list
+func listedSPDXID(s string) (string, bool) {
+	if info := licenseBySPDX(s); info != nil && !strings.HasSuffix(s, "+") {
+		base := strings.TrimSuffix(strings.TrimSuffix(s, "-only"), "-or-later")
+		return info.SPDX + s[len(base):], true
+	}
+	return s, extraSPDXIDs[strings.ToLower(s)]
+}
+
+// isCompoundSPDXExpression reports whether s is an SPDX expression made of
+// more than a single license ID. Unlike isSPDXExpression, which only tells
+// expressions from license names, every license in it must be on the SPDX
+// list or a LicenseRef, so the SBOM stays valid.
+func isCompoundSPDXExpression(s string) bool {
+	if !strings.ContainsAny(s, " ()+") {
+		return false
+	}
+	tokens := strings.Fields(strings.NewReplacer("(", " ", ")", " ").Replace(s))
+	if len(tokens) == 0 {
+		return false
+	}
+	afterWith := false
+	for _, token := range tokens {
+		switch {
+		case afterWith:
+			afterWith = false
+		case strings.EqualFold(token, "WITH"):
+			afterWith = true
+		case strings.EqualFold(token, "AND"), strings.EqualFold(token, "OR"), strings.HasPrefix(token, "LicenseRef-"):
+		default:
+			if _, ok := listedSPDXID(strings.TrimSuffix(token, "+")); !ok {
+				return false
+			}
+		}
+	}
+	return !afterWith
+}
+
+func cdxLicenses(license string) []cdxLicense {
+	if license == "" {
+		return nil
+	}
+	if id, ok := listedSPDXID(license); ok {
+		return []cdxLicense{{License: &cdxLicenseID{ID: id}}}
+	}
+	if isCompoundSPDXExpression(license) {
+		return []cdxLicense{{Expression: license}}
+	}
+	// Anything else, like "SEE LICENSE IN LICENSE.md", is only a name
+	return []cdxLicense{{License: &cdxLicenseID{Name: license}}}
+}
+
+type cdxComponent struct {
+	Type         string           `json:"type"`
+	BOMRef       string           `json:"bom-ref"`
+	Group        string           `json:"group,omitempty"`
+	Name         string           `json:"name"`
+	Version      string           `json:"version,omitempty"`
+	Scope        string           `json:"scope,omitempty"`
+	Licenses     []cdxLicense     `json:"licenses,omitempty"`
+	PURL         string           `json:"purl,omitempty"`
+	ExternalRefs []cdxExternalRef `json:"externalReferences,omitempty"`
+}
+
+// CycloneDXJSON renders the SBOM as a CycloneDX 1.5 JSON document
+func (s *SBOM) CycloneDXJSON() ([]byte, error) {
+	type dependency struct {
+		Ref       string   `json:"ref"`
+		DependsOn []string `json:"dependsOn"`
+	}
+	type document struct {
+		BOMFormat    string `json:"bomFormat"`
+		SpecVersion  string `json:"specVersion"`
+		SerialNumber string `json:"serialNumber"`
+		Version      int    `json:"version"`
+		Metadata     struct {
+			Timestamp string `json:"timestamp"`
+			Tools     struct {
+				Components []cdxComponent `json:"components"`
+			} `json:"tools"`
+			Component cdxComponent `json:"component"`
+		} `json:"metadata"`
+		Components   []cdxComponent `json:"components"`
+		Dependencies []dependency   `json:"dependencies"`
+	}
+
+	doc := document{BOMFormat: "CycloneDX", SpecVersion: "1.5", SerialNumber: "urn:uuid:" + s.Serial, Version: 1}
+	doc.Metadata.Timestamp = s.Created.Format(time.RFC3339)
+	doc.Metadata.Tools.Components = []cdxComponent{{Type: "application", BOMRef: "repo-lyzer", Name: "repo-lyzer"}}
+	root := cdxComponent{
+		Type: "application", BOMRef: RootRef, Name: s.subjectName(), Version: s.Subject.Version,
+		Licenses: cdxLicenses(s.Subject.License), PURL: s.subjectPURL(),
+	}
+	if owner, _, ok := strings.Cut(s.Subject.Name, "/"); ok {
+		root.Group = owner
+	}
+	if s.Subject.URL != "" {
+		root.ExternalRefs = []cdxExternalRef{{"vcs", s.Subject.URL}}
+	}
+	doc.Metadata.Component = root
+
+	doc.Components = []cdxComponent{}
+	doc.Dependencies = []dependency{{Ref: RootRef, DependsOn: append([]string{}, s.DependsOn[RootRef]...)}}
+	for _, c := range s.Components {
+		component := cdxComponent{
+			Type: "library", BOMRef: c.Ref, Name: c.Name, Version: c.Version,
+			Licenses: cdxLicenses(c.License), PURL: c.PURL,
+		}
+		if c.Ecosystem == "docker" {
+			component.Type = "container"
+		}
+		if c.Dev {
+			// Not part of what ships
+			component.Scope = "excluded"
+		}
+		doc.Components = append(doc.Components, component)
+		doc.Dependencies = append(doc.Dependencies, dependency{Ref: c.Ref, DependsOn: append([]string{}, s.DependsOn[c.Ref]...)})
+	}
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+// spdxPackage is a package in an SPDX 2.3 document
+type spdxPackage struct {
+	Name             string            `json:"name"`
+	SPDXID           string            `json:"SPDXID"`
+	VersionInfo      string            `json:"versionInfo,omitempty"`
+	DownloadLocation string            `json:"downloadLocation"`
+	FilesAnalyzed    bool              `json:"filesAnalyzed"`
+	LicenseConcluded string            `json:"licenseConcluded"`
+	LicenseDeclared  string            `json:"licenseDeclared"`
+	CopyrightText    string            `json:"copyrightText"`
+	PrimaryPurpose   string            `json:"primaryPackagePurpose,omitempty"`
+	ExternalRefs     []spdxExternalRef `json:"externalRefs,omitempty"`
+}
+
+type spdxExternalRef struct {
+	Category string `json:"referenceCategory"`
+	Type     string `json:"referenceType"`
+	Locator  string `json:"referenceLocator"`
+}
+
+// spdxExtractedLicense declares a license that is not on the SPDX list
+type spdxExtractedLicense struct {
+	LicenseID     string `json:"licenseId"`
+	ExtractedText string `json:"extractedText"`
+	Name          string `json:"name"`
+}
+
+type spdxRelationship struct {
+	Element string `json:"spdxElementId"`
+	Type    string `json:"relationshipType"`
+	Related string `json:"relatedSpdxElement"`
+}
+
+// spdxDocument is the content of an SPDX 2.3 document, shared by the JSON
+// and tag-value encodings
+type spdxDocument struct {
+	SPDXVersion       string `json:"spdxVersion"`
+	DataLicense       string `json:"dataLicense"`
+	SPDXID            string `json:"SPDXID"`
+	Name              string `json:"name"`
+	DocumentNamespace string `json:"documentNamespace"`
+	CreationInfo      struct {
+		Created  string   `json:"created"`
+		Creators []string `json:"creators"`
+	} `json:"creationInfo"`
+	DocumentDescribes []string           `json:"documentDescribes"`
+	Packages          []spdxPackage      `json:"packages"`
+	Relationships     []spdxRelationship `json:"relationships"`
+	// ExtractedLicenses declares the LicenseRefs used by packages
+	ExtractedLicenses []spdxExtractedLicense `json:"hasExtractedLicensingInfos,omitempty"`
+}
+
+// spdxRefUnsafe matches characters not allowed in SPDX element IDs
+var spdxRefUnsafe = regexp.MustCompile(`[^A-Za-z0-9.-]+`)
+
+// spdx builds the SPDX document. Element IDs are derived from package
+// names, with a counter when two sanitize to the same ID.
+func (s *SBOM) spdx() spdxDocument {
+	var doc spdxDocument
+	doc.SPDXVersion = "SPDX-2.3"
+	doc.DataLicense = "CC0-1.0"
+	doc.SPDXID = "SPDXRef-DOCUMENT"
+	doc.Name = s.Subject.Name
+	namespace := "https://github.com/" + s.Subject.Name
+	if s.Subject.URL != "" {
+		namespace = strings.TrimSuffix(s.Subject.URL, "/")
+	}
+	doc.DocumentNamespace = namespace + "/sbom/" + s.Serial
+	doc.CreationInfo.Created = s.Created.Format("2006-01-02T15:04:05Z")
+	doc.CreationInfo.Creators = []string{"Tool: repo-lyzer"}
+
+	ids := map[string]string{RootRef: "SPDXRef-Root"}
+	used := map[string]bool{"SPDXRef-Root": true, "SPDXRef-DOCUMENT": true}
+	for _, c := range s.Components {
+		base := "SPDXRef-Package-" + strings.Trim(spdxRefUnsafe.ReplaceAllString(c.Ecosystem+"-"+c.Name+"-"+c.Version, "-"), "-")
+		id := base
+		for n := 2; used[id]; n++ {
+			id = fmt.Sprintf("%s-%d", base, n)
+		}
+		used[id] = true
+		ids[c.Ref] = id
+	}
+
+	// Licenses that are neither on the SPDX list nor an expression are
+	// declared once as a LicenseRef
+	declared := make(map[string]bool)
+	declare := func(license string) string {
+		if license == "" {
+			return "NOASSERTION"
+		}
+		if id, ok := listedSPDXID(license); ok {
+			return id
+		}
+		if isCompoundSPDXExpression(license) {
+			return license
+		}
+		ref := "LicenseRef-" + strings.Trim(spdxRefUnsafe.ReplaceAllString(license, "-"), "-")
+		if !declared[ref] {
+			declared[ref] = true
+			doc.ExtractedLicenses = append(doc.ExtractedLicenses, spdxExtractedLicense{ref, license, license})
+		}
+		return ref
+	}
+	newPackage := func(name, id, version, license, purl string) spdxPackage {
+		p := spdxPackage{
+			Name: name, SPDXID: id, VersionInfo: version, DownloadLocation: "NOASSERTION",
+			LicenseConcluded: "NOASSERTION", LicenseDeclared: declare(license), CopyrightText: "NOASSERTION",
+		}
+		if purl != "" {
+			p.ExternalRefs = []spdxExternalRef{{"PACKAGE-MANAGER", "purl", purl}}
+		}
+		return p
+	}
+
+	root := newPackage(s.Subject.Name, "SPDXRef-Root", s.Subject.Version, s.Subject.License, s.subjectPURL())
+	root.PrimaryPurpose = "APPLICATION"
+	if s.Subject.URL != "" {
+		root.DownloadLocation = "git+" + s.Subject.URL
+	}
+	doc.Packages = []spdxPackage{root}
+	doc.DocumentDescribes = []string{"SPDXRef-Root"}
+	doc.Relationships = []spdxRelationship{{"SPDXRef-DOCUMENT", "DESCRIBES", "SPDXRef-Root"}}
+	for _, c := range s.Components {
+		p := newPackage(c.Name, ids[c.Ref], c.Version, c.License, c.PURL)
+		if c.Ecosystem == "docker" {
+			p.PrimaryPurpose = "CONTAINER"
+		} else {
+			p.PrimaryPurpose = "LIBRARY"
+		}
+		doc.Packages = append(doc.Packages, p)
+	}
+
+	dev := make(map[string]bool)
+	for _, c := range s.Components {
+		dev[c.Ref] = c.Dev
+	}
+	froms := []string{RootRef}
+	for _, ref := range sortedKeys(s.DependsOn) {
+		if ref != RootRef {
+			froms = append(froms, ref)
+		}
+	}
+	for _, from := range froms {
+		for _, to := range s.DependsOn[from] {
+			if from == RootRef && dev[to] {
+				doc.Relationships = append(doc.Relationships, spdxRelationship{ids[to], "DEV_DEPENDENCY_OF", ids[from]})
+				continue
+			}
+			doc.Relationships = append(doc.Relationships, spdxRelationship{ids[from], "DEPENDS_ON", ids[to]})
+		}
+	}
+	return doc
+}
+
+// SPDXJSON renders the SBOM as an SPDX 2.3 JSON document
+func (s *SBOM) SPDXJSON() ([]byte, error) {
+	return json.MarshalIndent(s.spdx(), "", "  ")
+}
+
+// SPDXTagValue renders the SBOM as an SPDX 2.3 tag-value document
+func (s *SBOM) SPDXTagValue() string {
+	doc := s.spdx()
+	var b strings.Builder
+	fmt.Fprintf(&b, "SPDXVersion: %s\n", doc.SPDXVersion)
+	fmt.Fprintf(&b, "DataLicense: %s\n", doc.DataLicense)
+	fmt.Fprintf(&b, "SPDXID: %s\n", doc.SPDXID)
+	fmt.Fprintf(&b, "DocumentName: %s\n", doc.Name)
+	fmt.Fprintf(&b, "DocumentNamespace: %s\n", doc.DocumentNamespace)
+	for _, creator := range doc.CreationInfo.Creators {
+		fmt.Fprintf(&b, "Creator: %s\n", creator)
+	}
+	fmt.Fprintf(&b, "Created: %s\n", doc.CreationInfo.Created)
+
+	for _, p := range doc.Packages {
+		fmt.Fprintf(&b, "\nPackageName: %s\n", p.Name)
+		fmt.Fprintf(&b, "SPDXID: %s\n", p.SPDXID)
+		if p.VersionInfo != "" {
+			fmt.Fprintf(&b, "PackageVersion: %s\n", p.VersionInfo)
+		}
+		fmt.Fprintf(&b, "PackageDownloadLocation: %s\n", p.DownloadLocation)
+		fmt.Fprintf(&b, "FilesAnalyzed: %t\n", p.FilesAnalyzed)
+		if p.PrimaryPurpose != "" {
+			fmt.Fprintf(&b, "PrimaryPackagePurpose: %s\n", p.PrimaryPurpose)
+		}
+		fmt.Fprintf(&b, "PackageLicenseConcluded: %s\n", p.LicenseConcluded)
+		fmt.Fprintf(&b, "PackageLicenseDeclared: %s\n", p.LicenseDeclared)
+		fmt.Fprintf(&b, "PackageCopyrightText: %s\n", p.CopyrightText)
+		for _, ref := range p.ExternalRefs {
+			fmt.Fprintf(&b, "ExternalRef: %s %s %s\n", ref.Category, ref.Type, ref.Locator)
+		}
+	}
+
+	for _, l := range doc.ExtractedLicenses {
+		fmt.Fprintf(&b, "\nLicenseID: %s\n", l.LicenseID)
+		fmt.Fprintf(&b, "ExtractedText: <text>%s</text>\n", l.ExtractedText)
+		fmt.Fprintf(&b, "LicenseName: %s\n", l.Name)
+	}
+
+	b.WriteString("\n")
+	for _, r := range doc.Relationships {
+		fmt.Fprintf(&b, "Relationship: %s %s %s\n", r.Element, r.Type, r.Related)
+	}
+	return b.String()
+}
@@ -0,0 +1,408 @@
+package analyzer
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+)
+
+// This file reads CycloneDX and SPDX JSON documents back into a
+// DependencyAnalysis, so that an SBOM can stand in for a repository.
+
+// ImportedSBOM is the result of reading an SBOM document
+type ImportedSBOM struct {
+	Format       string // SBOMCycloneDXJSON or SBOMSPDXJSON
+	Subject      SBOMSubject
+	Dependencies *DependencyAnalysis
+	Components   int      // packages in the document
+	Skipped      []string // components that could not be mapped to a package ecosystem
+}
+
+// ImportSBOM parses a CycloneDX or SPDX JSON document. Each package
+// ecosystem becomes a dependency file named after the document, and the
+// document's dependency relationships are kept as a lockfile so that the
+// dependency graph has its edges. Packages the subject depends on directly
+// are direct dependencies; the others are transitive.
+func ImportSBOM(filename string, data []byte) (*ImportedSBOM, error) {
+	var probe struct {
+		BOMFormat   string `json:"bomFormat"`
+		SPDXVersion string `json:"spdxVersion"`
+	}
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return nil, fmt.Errorf("%s is not a JSON SBOM: %w", filename, err)
+	}
+
+	var doc *sbomDocument
+	var err error
+	switch {
+	case probe.BOMFormat == "CycloneDX":
+		doc, err = readCycloneDX(data)
+	case strings.HasPrefix(probe.SPDXVersion, "SPDX-2."):
+		doc, err = readSPDX(data)
+	default:
+		return nil, fmt.Errorf("%s is neither a CycloneDX nor an SPDX 2.x JSON document", filename)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", filename, err)
+	}
+	return doc.analysis(path.Base(filename)), nil
+}
+
+// sbomPackage is a component as read from either format
+type sbomPackage struct {
+	ref, name, version, purl, license string
+	dev                               bool
+}
+
+// sbomDocument is the format-neutral content of a parsed SBOM
+type sbomDocument struct {
+	format    string
+	subject   SBOMSubject
+	rootRefs  []string // references of the subject itself
+	packages  []sbomPackage
+	dependsOn map[string][]string
+}
+
+// analysis converts the document into dependency files and lockfiles
+func (d *sbomDocument) analysis(filename string) *ImportedSBOM {
+	imported := &ImportedSBOM{Format: d.format, Subject: d.subject, Components: len(d.packages)}
+
+	type resolved struct {
+		fileType, name string
+	}
+	byRef := make(map[string]resolved)
+	versions := make(map[string]string)
+	for _, p := range d.packages {
+		versions[p.ref] = p.version
+		fileType, name := "generic", p.name
+		if p.purl != "" {
+			if t, n, ok := purlPackage(p.purl); ok {
+				fileType, name = t, n
+			}
+		}
+		if fileType == "generic" {
+			imported.Skipped = append(imported.Skipped, strings.TrimSpace(p.name+" "+p.version))
+		}
+		byRef[p.ref] = resolved{fileType, name}
+	}
+
+	// Without relationships every package is taken as direct
+	direct := make(map[string]bool)
+	for _, root := range d.rootRefs {
+		for _, ref := range d.dependsOn[root] {
+			direct[ref] = true
+		}
+	}
+	hasGraph := len(direct) > 0
+
+	files := make(map[string]*DependencyFile)
+	locks := make(map[string]*LockFile)
+	var fileTypes []string
+	seen := make(map[string]bool)
+	for _, p := range d.packages {
+		r := byRef[p.ref]
+		if files[r.fileType] == nil {
+			files[r.fileType] = &DependencyFile{Filename: filename, FileType: r.fileType, LockFile: filename}
+			locks[r.fileType] = &LockFile{Filename: filename, FileType: r.fileType}
+			fileTypes = append(fileTypes, r.fileType)
+		}
+		isDirect := !hasGraph || direct[p.ref]
+
+		locked := LockedPackage{Name: r.name, Version: p.version, Dev: p.dev, Direct: isDirect}
+		for _, ref := range d.dependsOn[p.ref] {
+			child, ok := byRef[ref]
+			if !ok || child.fileType != r.fileType {
+				continue
+			}
+			locked.Requires = append(locked.Requires, child.name)
+			if locked.Pinned == nil {
+				locked.Pinned = make(map[string]string)
+			}
+			locked.Pinned[child.name] = versions[ref]
+		}
+		locks[r.fileType].Packages = append(locks[r.fileType].Packages, locked)
+
+		key := r.fileType + ":" + lockKey(r.fileType, r.name) + "@" + p.version
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		depType := "production"
+		if p.dev {
+			depType = "dev"
+		}
+		files[r.fileType].Dependencies = append(files[r.fileType].Dependencies, Dependency{
+			Name: r.name, Version: p.version, Resolved: p.version, Type: depType,
+			License: p.license, Transitive: !isDirect,
+		})
+	}
+
+	analysis := &DependencyAnalysis{HasLockFile: hasGraph}
+	sort.Strings(fileTypes)
+	for _, fileType := range fileTypes {
+		file := files[fileType]
+		file.TotalCount = len(file.Dependencies)
+		analysis.AddFiles(*file)
+		analysis.LockFiles = append(analysis.LockFiles, locks[fileType])
+	}
+	imported.Dependencies = analysis
+	return imported
+}
+
+// purlFileTypes maps Package URL types back to dependency file types
+var purlFileTypes = map[string]string{
+	"npm": "npm", "golang": "go", "pypi": "python", "cargo": "rust", "gem": "ruby",
+	"maven": "maven", "nuget": "nuget", "composer": "composer", "pub": "pub", "docker": "docker",
+}
+
+// purlPackage maps a Package URL to a dependency file type and the package
+// name as that ecosystem's manifests write it
+func purlPackage(purl string) (fileType, name string, ok bool) {
+	rest, found := strings.CutPrefix(purl, "pkg:")
+	if !found {
+		return "", "", false
+	}
+	rest, _, _ = strings.Cut(rest, "#")
+	rest, _, _ = strings.Cut(rest, "?")
+	typ, rest, found := strings.Cut(strings.TrimLeft(rest, "/"), "/")
+	if !found {
+		return "", "", false
+	}
+	if i := strings.LastIndex(rest, "@"); i > 0 {
+		rest = rest[:i]
+	}
+	segments := strings.Split(strings.Trim(rest, "/"), "/")
+	for i, s := range segments {
+		if unescaped, err := url.PathUnescape(s); err == nil {
+			segments[i] = unescaped
+		}
+	}
+
+	fileType, known := purlFileTypes[strings.ToLower(typ)]
+	if !known || segments[len(segments)-1] == "" {
+		return "", "", false
+	}
+	if fileType == "maven" && len(segments) == 2 {
+		return fileType, segments[0] + ":" + segments[1], true
+	}
+	return fileType, strings.Join(segments, "/"), true
+}
+
+// cdxDocument is the part of a CycloneDX document that is imported
+type cdxDocument struct {
+	Metadata struct {
+		Component *cdxImportComponent `json:"component"`
+	} `json:"metadata"`
+	Components   []cdxImportComponent `json:"components"`
+	Dependencies []struct {
+		Ref       string   `json:"ref"`
+		DependsOn []string `json:"dependsOn"`
+	} `json:"dependencies"`
+}
+
+type cdxImportComponent struct {
+	BOMRef   string `json:"bom-ref"`
+	Type     string `json:"type"`
+	Group    string `json:"group"`
+	Name     string `json:"name"`
+	Version  string `json:"version"`
+	Scope    string `json:"scope"`
+	PURL     string `json:"purl"`
+	Licenses []struct {
+		License *struct {
+			ID   string `json:"id"`
+			Name string `json:"name"`
+		} `json:"license"`
+		Expression string `json:"expression"`
+	} `json:"licenses"`
+	Components   []cdxImportComponent `json:"components"`
+	ExternalRefs []cdxExternalRef     `json:"externalReferences"`
+}
+
+// license joins the component's license choices into one expression
+func (c cdxImportComponent) license() string {
+	var parts []string
+	for _, l := range c.Licenses {
+		switch {
+		case l.Expression != "":
+			parts = append(parts, l.Expression)
+		case l.License != nil && l.License.ID != "":
+			parts = append(parts, l.License.ID)
+		case l.License != nil && l.License.Name != "":
+			parts = append(parts, "LicenseRef-"+spdxRefUnsafe.ReplaceAllString(l.License.Name, "-"))
+		}
+	}
+	if len(parts) > 1 {
+		for i, p := range parts {
+			if strings.Contains(p, " ") {
+				parts[i] = "(" + p + ")"
+			}
+		}
+	}
+	return strings.Join(parts, " AND ")
+}
+
+func readCycloneDX(data []byte) (*sbomDocument, error) {
+	var cdx cdxDocument
+	if err := json.Unmarshal(data, &cdx); err != nil {
+		return nil, err
+	}
+	doc := &sbomDocument{format: SBOMCycloneDXJSON, dependsOn: make(map[string][]string)}
+
+	if root := cdx.Metadata.Component; root != nil {
+		doc.subject = SBOMSubject{Name: root.Name, Version: root.Version, License: root.license()}
+		if root.Group != "" {
+			doc.subject.Name = root.Group + "/" + root.Name
+		}
+		for _, ref := range root.ExternalRefs {
+			if ref.Type == "vcs" || ref.Type == "website" && doc.subject.URL == "" {
+				doc.subject.URL = ref.URL
+			}
+		}
+		if root.BOMRef != "" {
+			doc.rootRefs = append(doc.rootRefs, root.BOMRef)
+		}
+	}
+
+	// Components may nest; references default to the purl or name@version
+	var walk func([]cdxImportComponent)
+	walk = func(components []cdxImportComponent) {
+		for _, c := range components {
+			ref := c.BOMRef
+			if ref == "" {
+				ref = c.PURL
+			}
+			if ref == "" {
+				ref = c.Name + "@" + c.Version
+			}
+			name := c.Name
+			if c.Group != "" && c.PURL == "" {
+				name = c.Group + "/" + c.Name
+			}
+			doc.packages = append(doc.packages, sbomPackage{
+				ref: ref, name: name, version: c.Version, purl: c.PURL,
+				license: c.license(), dev: c.Scope == "excluded",
+			})
+			walk(c.Components)
+		}
+	}
+	walk(cdx.Components)
+
+	for _, d := range cdx.Dependencies {
+		doc.dependsOn[d.Ref] = append(doc.dependsOn[d.Ref], d.DependsOn...)
+	}
+	return doc, nil
+}
+
+// spdxImportDocument is the part of an SPDX 2.x JSON document that is
+// imported. Packages reuse the export type.
+type spdxImportDocument struct {
+	Name              string             `json:"name"`
+	DocumentDescribes []string           `json:"documentDescribes"`
+	Packages          []spdxPackage      `json:"packages"`
+	Relationships     []spdxRelationship `json:"relationships"`
+}
+
+// spdxLicense picks the most reliable license of a package
+func spdxLicense(p spdxPackage) string {
+	for _, l := range []string{p.LicenseConcluded, p.LicenseDeclared} {
+		if l != "" && l != "NOASSERTION" && l != "NONE" {
+			return l
+		}
+	}
+	return ""
+}
+
+func readSPDX(data []byte) (*sbomDocument, error) {
+	var spdx spdxImportDocument
+	if err := json.Unmarshal(data, &spdx); err != nil {
+		return nil, err
+	}
+	doc := &sbomDocument{format: SBOMSPDXJSON, dependsOn: make(map[string][]string)}
+
+	roots := append([]string(nil), spdx.DocumentDescribes...)
+	dev := make(map[string]bool)
+	for _, r := range spdx.Relationships {
+		switch r.Type {
+		case "DESCRIBES":
+			if r.Element == "SPDXRef-DOCUMENT" && !contains(roots, r.Related) {
+				roots = append(roots, r.Related)
+			}
+		case "DESCRIBED_BY":
+			if r.Related == "SPDXRef-DOCUMENT" && !contains(roots, r.Element) {
+				roots = append(roots, r.Element)
+			}
+		case "DEPENDS_ON", "CONTAINS":
+			doc.dependsOn[r.Element] = append(doc.dependsOn[r.Element], r.Related)
+		case "DEPENDENCY_OF", "CONTAINED_BY", "RUNTIME_DEPENDENCY_OF", "BUILD_DEPENDENCY_OF":
+			doc.dependsOn[r.Related] = append(doc.dependsOn[r.Related], r.Element)
+		case "DEV_DEPENDENCY_OF", "TEST_DEPENDENCY_OF":
+			doc.dependsOn[r.Related] = append(doc.dependsOn[r.Related], r.Element)
+			dev[r.Element] = true
+		}
+	}
+	doc.rootRefs = roots
+	if len(dev) > 0 {
+		dev = devOnly(roots, doc.dependsOn, dev)
+	}
+
+	for _, p := range spdx.Packages {
+		if contains(roots, p.SPDXID) {
+			if doc.subject.Name == "" {
+				doc.subject = SBOMSubject{Name: p.Name, Version: p.VersionInfo, License: spdxLicense(p)}
+				if loc := strings.TrimPrefix(p.DownloadLocation, "git+"); strings.HasPrefix(loc, "https://") {
+					doc.subject.URL = loc
+				}
+			}
+			continue
+		}
+		purl := ""
+		for _, ref := range p.ExternalRefs {
+			if ref.Type == "purl" {
+				purl = ref.Locator
+				break
+			}
+		}
+		doc.packages = append(doc.packages, sbomPackage{
+			ref: p.SPDXID, name: p.Name, version: p.VersionInfo, purl: purl,
+			license: spdxLicense(p), dev: dev[p.SPDXID],
+		})
+	}
+	if doc.subject.Name == "" {
+		doc.subject.Name = spdx.Name
+	}
+	return doc, nil
+}
+
+// devOnly extends the packages marked as dev dependencies with everything
+// reachable from the roots only through them, since SPDX marks just the
+// direct relationship
+func devOnly(roots []string, dependsOn map[string][]string, dev map[string]bool) map[string]bool {
+	walk := func(skipDev bool) map[string]bool {
+		seen := make(map[string]bool)
+		queue := append([]string(nil), roots...)
+		for len(queue) > 0 {
+			ref := queue[0]
+			queue = queue[1:]
+			for _, to := range dependsOn[ref] {
+				if seen[to] || (skipDev && dev[to]) {
+					continue
+				}
+				seen[to] = true
+				queue = append(queue, to)
+			}
+		}
+		return seen
+	}
+	prod, all := walk(true), walk(false)
+	only := make(map[string]bool)
+	for ref := range all {
+		if !prod[ref] {
+			only[ref] = true
+		}
+	}
+	return only
+}
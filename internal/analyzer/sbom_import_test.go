@@ -0,0 +1,135 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestImportSBOMRoundTrip(t *testing.T) {
+	sbom := testSBOM(t)
+	sbom.Components[0].License = "MIT"
+	for _, format := range []string{SBOMCycloneDXJSON, SBOMSPDXJSON} {
+		data, err := EncodeSBOM(sbom, format)
+		if err != nil {
+			t.Fatal(err)
+		}
+		imported, err := ImportSBOM("out/web.json", data)
+		if err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+		if imported.Format != format || imported.Subject.Name != "acme/web" || imported.Subject.License != "MIT AND Apache-2.0" {
+			t.Errorf("%s: subject = %+v", format, imported.Subject)
+		}
+		if imported.Components != len(sbom.Components) || len(imported.Skipped) != 0 {
+			t.Errorf("%s: %d components, skipped %v", format, imported.Components, imported.Skipped)
+		}
+
+		// The graph built from the import matches the original one
+		original, graph := testGraph(t), BuildDependencyGraph(imported.Dependencies)
+		if len(graph.Nodes) != len(original.Nodes) || len(graph.Edges) != len(original.Edges) || len(graph.Roots()) != len(original.Roots()) {
+			t.Errorf("%s: graph has %d nodes, %d edges, %d roots; want %d, %d, %d", format,
+				len(graph.Nodes), len(graph.Edges), len(graph.Roots()), len(original.Nodes), len(original.Edges), len(original.Roots()))
+		}
+		for _, n := range original.Nodes {
+			if got := graph.Node(n.ID); got == nil || got.Direct != n.Direct || got.Dev != n.Dev {
+				t.Errorf("%s: node %s = %+v, want %+v", format, n.ID, got, n)
+			}
+		}
+
+		deps := imported.Dependencies
+		if deps.Files[0].Filename != "web.json" || deps.TransitiveDeps == 0 || deps.TransitiveDeps == deps.TotalDeps {
+			t.Errorf("%s: dependencies = %+v", format, deps)
+		}
+		if d := deps.Files[0].Dependencies[0]; d.License != "MIT" {
+			t.Errorf("%s: license of %s = %q", format, d.Name, d.License)
+		}
+	}
+}
+
+func TestImportSPDXFromOtherTools(t *testing.T) {
+	// A document in the style of container scanners: the root CONTAINS its
+	// packages, one of which has no package URL
+	doc := `{
+	  "spdxVersion": "SPDX-2.3",
+	  "name": "vendor-app",
+	  "documentDescribes": ["SPDXRef-app"],
+	  "packages": [
+	    {"SPDXID": "SPDXRef-app", "name": "vendor-app", "versionInfo": "2.1.0", "licenseDeclared": "NOASSERTION"},
+	    {"SPDXID": "SPDXRef-a", "name": "requests", "versionInfo": "2.25.0", "licenseConcluded": "NOASSERTION", "licenseDeclared": "Apache-2.0",
+	     "externalRefs": [{"referenceCategory": "PACKAGE-MANAGER", "referenceType": "purl", "referenceLocator": "pkg:pypi/requests@2.25.0"}]},
+	    {"SPDXID": "SPDXRef-b", "name": "urllib3", "versionInfo": "1.26.4", "licenseConcluded": "MIT",
+	     "externalRefs": [{"referenceCategory": "PACKAGE-MANAGER", "referenceType": "purl", "referenceLocator": "pkg:pypi/urllib3@1.26.4"}]},
+	    {"SPDXID": "SPDXRef-c", "name": "openssl", "versionInfo": "3.0.2"}
+	  ],
+	  "relationships": [
+	    {"spdxElementId": "SPDXRef-app", "relationshipType": "CONTAINS", "relatedSpdxElement": "SPDXRef-a"},
+	    {"spdxElementId": "SPDXRef-app", "relationshipType": "CONTAINS", "relatedSpdxElement": "SPDXRef-c"},
+	    {"spdxElementId": "SPDXRef-b", "relationshipType": "DEPENDENCY_OF", "relatedSpdxElement": "SPDXRef-a"}
+	  ]
+	}`
+	imported, err := ImportSBOM("vendor.spdx.json", []byte(doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if imported.Subject.Name != "vendor-app" || imported.Subject.Version != "2.1.0" || imported.Subject.License != "" {
+		t.Errorf("subject = %+v", imported.Subject)
+	}
+	if strings.Join(imported.Skipped, ",") != "openssl 3.0.2" {
+		t.Errorf("skipped = %v", imported.Skipped)
+	}
+
+	var python *DependencyFile
+	for i, f := range imported.Dependencies.Files {
+		if f.FileType == "python" {
+			python = &imported.Dependencies.Files[i]
+		}
+	}
+	if python == nil || len(python.Dependencies) != 2 {
+		t.Fatalf("files = %+v", imported.Dependencies.Files)
+	}
+	requests, urllib3 := python.Dependencies[0], python.Dependencies[1]
+	if requests.Transitive || requests.License != "Apache-2.0" || !urllib3.Transitive || urllib3.Resolved != "1.26.4" {
+		t.Errorf("dependencies = %+v", python.Dependencies)
+	}
+
+	graph := BuildDependencyGraph(imported.Dependencies)
+	if path := graph.PathTo("python:urllib3@1.26.4"); strings.Join(path, " ") != "python:requests@2.25.0 python:urllib3@1.26.4" {
+		t.Errorf("path to urllib3 = %v", path)
+	}
+}
+
+func TestImportSBOMRejectsOtherDocuments(t *testing.T) {
+	for _, doc := range []string{`{"name": "package.json"}`, `not json`, `{"spdxVersion": "SPDX-3.0"}`} {
+		if _, err := ImportSBOM("x.json", []byte(doc)); err == nil {
+			t.Errorf("ImportSBOM(%s) should fail", doc)
+		}
+	}
+}
+
+func TestAnalyzeDeclaredLicenses(t *testing.T) {
+	deps := &DependencyAnalysis{}
+	deps.AddFiles(DependencyFile{Filename: "sbom.json", FileType: "npm", Dependencies: []Dependency{
+		{Name: "a", License: "MIT"},
+		{Name: "b", License: "(MIT OR GPL-3.0-or-later)"}, // the permissive choice is taken
+		{Name: "c", License: "GPL-2.0-only WITH Classpath-exception-2.0"},
+		{Name: "d"},
+	}})
+	analysis := AnalyzeDeclaredLicenses("Apache-2.0", deps)
+	if analysis.MainLicense == nil || analysis.MainLicense.SPDX != "Apache-2.0" || analysis.Compatibility != "conflict" {
+		t.Fatalf("analysis = %+v", analysis)
+	}
+	var others []string
+	for _, l := range analysis.OtherLicenses {
+		others = append(others, l.SPDX)
+	}
+	if strings.Join(others, ",") != "MIT,GPL-2.0" {
+		t.Errorf("dependency licenses = %v", others)
+	}
+	if last := analysis.Warnings[len(analysis.Warnings)-1]; last != "1 dependencies have no declared license" {
+		t.Errorf("warnings = %v", analysis.Warnings)
+	}
+
+	if a := AnalyzeDeclaredLicenses("", nil); a.MainLicense != nil || a.LicenseScore != 60 {
+		t.Errorf("no license: %+v", a)
+	}
+}
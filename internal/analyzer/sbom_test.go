@@ -0,0 +1,192 @@
+package analyzer
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestPackageURL(t *testing.T) {
+	tests := []struct{ fileType, name, version, want string }{
+		{"npm", "lodash", "4.17.21", "pkg:npm/lodash@4.17.21"},
+		{"npm", "@babel/core", "7.23.0", "pkg:npm/%40babel/core@7.23.0"},
+		{"npm", "express", "^4.18.0", "pkg:npm/express"},
+		{"go", "github.com/gin-gonic/gin", "v1.9.1", "pkg:golang/github.com/gin-gonic/gin@v1.9.1"},
+		{"python", "Django_REST", "==3.14.0", "pkg:pypi/django-rest@3.14.0"},
+		{"python", "flask", ">=2.0", "pkg:pypi/flask"},
+		{"maven", "org.slf4j:slf4j-api", "2.0.9", "pkg:maven/org.slf4j/slf4j-api@2.0.9"},
+		{"rust", "serde", "1.0.193", "pkg:cargo/serde@1.0.193"},
+		{"ruby", "rails", "7.1.0", "pkg:gem/rails@7.1.0"},
+		{"composer", "Monolog/Monolog", "3.5.0", "pkg:composer/monolog/monolog@3.5.0"},
+		{"nuget", "Newtonsoft.Json", "13.0.3", "pkg:nuget/Newtonsoft.Json@13.0.3"},
+		{"docker", "ghcr.io/owner/app", "1.2", "pkg:docker/ghcr.io/owner/app@1.2"},
+		{"make", "thing", "1.0+build", "pkg:generic/thing@1.0%2Bbuild"},
+	}
+	for _, tt := range tests {
+		if got := PackageURL(tt.fileType, tt.name, tt.version); got != tt.want {
+			t.Errorf("PackageURL(%s, %s, %s) = %s, want %s", tt.fileType, tt.name, tt.version, got, tt.want)
+		}
+	}
+}
+
+func testSBOM(t *testing.T) *SBOM {
+	sbom := BuildSBOM(SBOMSubject{
+		Name: "acme/web", Version: "main", URL: "https://github.com/acme/web", License: "MIT AND Apache-2.0",
+	}, nil, testGraph(t))
+	sbom.Serial = "00000000-0000-4000-8000-000000000000"
+	return sbom
+}
+
+func TestCycloneDXJSON(t *testing.T) {
+	data, err := testSBOM(t).CycloneDXJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc struct {
+		BOMFormat    string `json:"bomFormat"`
+		SpecVersion  string `json:"specVersion"`
+		SerialNumber string `json:"serialNumber"`
+		Metadata     struct {
+			Component cdxComponent `json:"component"`
+		} `json:"metadata"`
+		Components   []cdxComponent `json:"components"`
+		Dependencies []struct {
+			Ref       string   `json:"ref"`
+			DependsOn []string `json:"dependsOn"`
+		} `json:"dependencies"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.BOMFormat != "CycloneDX" || doc.SpecVersion != "1.5" || !strings.HasPrefix(doc.SerialNumber, "urn:uuid:") {
+		t.Errorf("header = %s %s %s", doc.BOMFormat, doc.SpecVersion, doc.SerialNumber)
+	}
+	root := doc.Metadata.Component
+	if root.PURL != "pkg:github/acme/web@main" || len(root.Licenses) != 1 || root.Licenses[0].Expression != "MIT AND Apache-2.0" {
+		t.Errorf("root = %+v", root)
+	}
+
+	// Every reference resolves to a component
+	refs := map[string]cdxComponent{RootRef: root}
+	for _, c := range doc.Components {
+		if _, dup := refs[c.BOMRef]; dup {
+			t.Errorf("duplicate bom-ref %s", c.BOMRef)
+		}
+		refs[c.BOMRef] = c
+	}
+	if len(doc.Dependencies) != len(refs) {
+		t.Errorf("%d dependency entries for %d components", len(doc.Dependencies), len(refs))
+	}
+	for _, d := range doc.Dependencies {
+		for _, to := range d.DependsOn {
+			if _, ok := refs[to]; !ok {
+				t.Errorf("%s depends on unknown %s", d.Ref, to)
+			}
+		}
+	}
+
+	if jest := refs["npm:jest@29.7.0"]; jest.Scope != "excluded" || jest.PURL != "pkg:npm/jest@29.7.0" {
+		t.Errorf("jest = %+v", jest)
+	}
+	if d := doc.Dependencies[0]; d.Ref != RootRef || !contains(d.DependsOn, "npm:express@4.18.2") {
+		t.Errorf("root dependencies = %+v", d)
+	}
+}
+
+func TestSPDX(t *testing.T) {
+	sbom := testSBOM(t)
+	data, err := sbom.SPDXJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc spdxDocument
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.SPDXVersion != "SPDX-2.3" || doc.DataLicense != "CC0-1.0" || !strings.HasSuffix(doc.DocumentNamespace, "/sbom/"+sbom.Serial) {
+		t.Errorf("header = %+v", doc)
+	}
+	if len(doc.Packages) != len(sbom.Components)+1 || doc.Packages[0].LicenseDeclared != "MIT AND Apache-2.0" {
+		t.Errorf("packages = %+v", doc.Packages)
+	}
+	ids := make(map[string]bool)
+	for _, p := range doc.Packages {
+		if ids[p.SPDXID] || spdxRefUnsafe.MatchString(strings.TrimPrefix(p.SPDXID, "SPDXRef-")) {
+			t.Errorf("bad or duplicate SPDXID %s", p.SPDXID)
+		}
+		ids[p.SPDXID] = true
+	}
+	var sawDev, sawDepends bool
+	for _, r := range doc.Relationships {
+		if r.Element != "SPDXRef-DOCUMENT" && (!ids[r.Element] || !ids[r.Related]) {
+			t.Errorf("dangling relationship %+v", r)
+		}
+		sawDev = sawDev || r.Type == "DEV_DEPENDENCY_OF" && r.Related == "SPDXRef-Root"
+		sawDepends = sawDepends || r.Type == "DEPENDS_ON" && r.Element != "SPDXRef-Root"
+	}
+	if !sawDev || !sawDepends {
+		t.Errorf("relationships = %+v", doc.Relationships)
+	}
+
+	tag := sbom.SPDXTagValue()
+	for _, want := range []string{
+		"SPDXVersion: SPDX-2.3\n",
+		"DocumentName: acme/web\n",
+		"PackageLicenseDeclared: MIT AND Apache-2.0\n",
+		"ExternalRef: PACKAGE-MANAGER purl pkg:npm/express@4.18.2\n",
+		"Relationship: SPDXRef-DOCUMENT DESCRIBES SPDXRef-Root\n",
+	} {
+		if !strings.Contains(tag, want) {
+			t.Errorf("tag-value output lacks %q", want)
+		}
+	}
+}
+
+func TestSBOMNonSPDXLicenses(t *testing.T) {
+	tests := []struct {
+		license  string
+		want     cdxLicense
+		declared string
+	}{
+		{"mit", cdxLicense{License: &cdxLicenseID{ID: "MIT"}}, "MIT"},
+		{"GPL-3.0-or-later", cdxLicense{License: &cdxLicenseID{ID: "GPL-3.0-or-later"}}, "GPL-3.0-or-later"},
+		{"MIT OR Apache-2.0", cdxLicense{Expression: "MIT OR Apache-2.0"}, "MIT OR Apache-2.0"},
+		{"BSD", cdxLicense{License: &cdxLicenseID{Name: "BSD"}}, "LicenseRef-BSD"},
+		{"GPL-2.0+ OR LicenseRef-Custom", cdxLicense{Expression: "GPL-2.0+ OR LicenseRef-Custom"}, "GPL-2.0+ OR LicenseRef-Custom"},
+		// An expression with a license off the SPDX list is only a name
+		{"Apache-2.0 OR Custom-1.0", cdxLicense{License: &cdxLicenseID{Name: "Apache-2.0 OR Custom-1.0"}}, "LicenseRef-Apache-2.0-OR-Custom-1.0"},
+		{"SEE LICENSE IN LICENSE.md", cdxLicense{License: &cdxLicenseID{Name: "SEE LICENSE IN LICENSE.md"}}, "LicenseRef-SEE-LICENSE-IN-LICENSE.md"},
+	}
+	sbom := testSBOM(t)
+	if len(sbom.Components) < len(tests) {
+		t.Fatalf("%d components", len(sbom.Components))
+	}
+	for i, tt := range tests {
+		sbom.Components[i].License = tt.license
+		got := cdxLicenses(tt.license)
+		if len(got) != 1 || got[0].Expression != tt.want.Expression || (got[0].License == nil) != (tt.want.License == nil) ||
+			got[0].License != nil && *got[0].License != *tt.want.License {
+			t.Errorf("cdxLicenses(%q) = %+v", tt.license, got)
+		}
+	}
+
+	doc := sbom.spdx()
+	for i, tt := range tests {
+		if got := doc.Packages[i+1].LicenseDeclared; got != tt.declared {
+			t.Errorf("SPDX license of %q = %s, want %s", tt.license, got, tt.declared)
+		}
+	}
+	// Each LicenseRef is declared with the original text
+	if len(doc.ExtractedLicenses) != 3 || doc.ExtractedLicenses[2].ExtractedText != "SEE LICENSE IN LICENSE.md" {
+		t.Errorf("extracted licenses = %+v", doc.ExtractedLicenses)
+	}
+	if tag := sbom.SPDXTagValue(); !strings.Contains(tag, "LicenseID: LicenseRef-BSD\nExtractedText: <text>BSD</text>\n") {
+		t.Errorf("tag-value output lacks the LicenseRef declaration")
+	}
+}
+
+func TestEncodeSBOMUnknownFormat(t *testing.T) {
+	if _, err := EncodeSBOM(testSBOM(t), "xml"); err == nil {
+		t.Error("expected an error")
+	}
+}
@@ -2,10 +2,15 @@
 package analyzer
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"math"
 	"net/http"
+	"net/url"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -14,9 +19,12 @@ type Vulnerability struct {
 	ID          string   `json:"id"`
 	Summary     string   `json:"summary"`
 	Severity    string   `json:"severity"`
+	Ecosystem   string   `json:"ecosystem,omitempty"` // OSV ecosystem, e.g. "PyPI"
 	Package     string   `json:"package"`
 	Version     string   `json:"version"`
-	FixedIn     string   `json:"fixed_in"`
+	FixedIn     string   `json:"fixed_in"` // earliest release fixing Version
+	CVSSScore   float64  `json:"cvss_score,omitempty"`
+	CVSSVector  string   `json:"cvss_vector,omitempty"`
 	References  []string `json:"references"`
 	PublishedAt string   `json:"published_at"`
 }
@@ -32,6 +40,48 @@ type SecurityScanResult struct {
 	ScannedPackages int             `json:"scanned_packages"`
 	ScanTime        time.Time       `json:"scan_time"`
 	SecurityScore   int             `json:"security_score"`
+	// Lookups that failed; the score only reflects the packages that were checked
+	Errors         []string `json:"errors,omitempty"`
+	FailedPackages int      `json:"failed_packages,omitempty"`
+	// Where advisories came from: "osv.dev" or "offline database", and for
+	// the latter when it was last synced
+	Source          string    `json:"source,omitempty"`
+	DatabaseUpdated time.Time `json:"database_updated,omitempty"`
+}
+
+// Failed reports whether no package could be checked at all. SecurityScore
+// is meaningless then and left at 0.
+func (r *SecurityScanResult) Failed() bool {
+	return r != nil && r.ScannedPackages > 0 && r.FailedPackages == r.ScannedPackages
+}
+
+// Incomplete reports whether some packages could not be checked
+func (r *SecurityScanResult) Incomplete() bool {
+	return r != nil && (r.FailedPackages > 0 || len(r.Errors) > 0)
+}
+
+// OSVScanOptions configures how dependencies are checked against OSV
+type OSVScanOptions struct {
+	BaseURL     string        // API root, e.g. "https://api.osv.dev"
+	BatchSize   int           // queries per querybatch request (OSV accepts up to 1000)
+	Concurrency int           // maximum number of requests in flight
+	Timeout     time.Duration // per request
+	HTTPClient  *http.Client  // optional; overrides Timeout
+
+	// VulnDB is a local copy of the advisories. It is used instead of the
+	// API when Offline is set, and as a fallback when the API is unreachable.
+	VulnDB  *VulnDB
+	Offline bool
+}
+
+// DefaultOSVScanOptions returns options for the public OSV API
+func DefaultOSVScanOptions() OSVScanOptions {
+	return OSVScanOptions{
+		BaseURL:     "https://api.osv.dev",
+		BatchSize:   1000,
+		Concurrency: 4,
+		Timeout:     30 * time.Second,
+	}
 }
 
 type osvQuery struct {
@@ -39,149 +89,534 @@ type osvQuery struct {
 		Name      string `json:"name"`
 		Ecosystem string `json:"ecosystem"`
 	} `json:"package"`
-	Version string `json:"version,omitempty"`
+	Version   string `json:"version,omitempty"`
+	PageToken string `json:"page_token,omitempty"`
 }
 
-type osvResponse struct {
-	Vulns []osvVuln `json:"vulns"`
+// osvBatchResponse lists the IDs of the vulnerabilities matching each query
+// of a querybatch request, in query order. Details are fetched separately.
+type osvBatchResponse struct {
+	Results []struct {
+		Vulns []struct {
+			ID string `json:"id"`
+		} `json:"vulns"`
+		NextPageToken string `json:"next_page_token"`
+	} `json:"results"`
 }
 
+// osvVuln is an advisory in the OSV schema (https://ossf.github.io/osv-schema/)
 type osvVuln struct {
-	ID       string `json:"id"`
-	Summary  string `json:"summary"`
-	Severity []struct {
-		Type  string `json:"type"`
-		Score string `json:"score"`
-	} `json:"severity"`
-	Affected []struct {
-		Ranges []struct {
-			Events []struct {
-				Fixed string `json:"fixed,omitempty"`
-			} `json:"events"`
-		} `json:"ranges"`
-	} `json:"affected"`
+	ID         string        `json:"id"`
+	Summary    string        `json:"summary,omitempty"`
+	Severity   []osvSeverity `json:"severity,omitempty"`
+	Affected   []osvAffected `json:"affected"`
 	References []struct {
 		URL string `json:"url"`
-	} `json:"references"`
-	Published string `json:"published"`
+	} `json:"references,omitempty"`
+	Published string `json:"published,omitempty"`
+	Withdrawn string `json:"withdrawn,omitempty"`
+	// DatabaseSpecific is free-form; GitHub advisories put their
+	// severity rating here
+	DatabaseSpecific json.RawMessage `json:"database_specific,omitempty"`
+}
+
+// osvSeverity is a severity score; for the CVSS types Score is a vector
+type osvSeverity struct {
+	Type  string `json:"type"` // CVSS_V2, CVSS_V3, CVSS_V4 or Ubuntu
+	Score string `json:"score"`
+}
+
+// osvAffected lists the affected versions of one package
+type osvAffected struct {
+	Package struct {
+		Ecosystem string `json:"ecosystem"`
+		Name      string `json:"name"`
+	} `json:"package"`
+	Ranges []struct {
+		Type   string     `json:"type"` // SEMVER, ECOSYSTEM or GIT
+		Events []osvEvent `json:"events"`
+	} `json:"ranges,omitempty"`
+	Versions []string      `json:"versions,omitempty"`
+	Severity []osvSeverity `json:"severity,omitempty"`
 }
 
-// ScanDependencies scans dependencies for vulnerabilities
+// osvEvent is a point in an affected range; exactly one field is set
+type osvEvent struct {
+	Introduced   string `json:"introduced,omitempty"`
+	Fixed        string `json:"fixed,omitempty"`
+	LastAffected string `json:"last_affected,omitempty"`
+	Limit        string `json:"limit,omitempty"`
+}
+
+// osvPackage is a unique (ecosystem, name, version) tuple to look up
+type osvPackage struct {
+	ecosystem, name, version string
+}
+
+// osvMaxPages caps how many pages of results are read for one package
+const osvMaxPages = 10
+
+// ScanDependencies scans dependencies for vulnerabilities using the public
+// OSV API
 func ScanDependencies(deps *DependencyAnalysis) (*SecurityScanResult, error) {
-	if deps == nil || len(deps.Files) == 0 {
-		return &SecurityScanResult{
-			Vulnerabilities: []Vulnerability{},
-			ScanTime:        time.Now(),
-			SecurityScore:   100,
-		}, nil
-	}
+	return ScanDependenciesWith(deps, DefaultOSVScanOptions())
+}
 
+// ScanDependenciesWith scans dependencies for vulnerabilities. Identical
+// packages listed in several files are looked up once, all lookups go
+// through OSV's querybatch endpoint, and the details of each matching
+// vulnerability are then fetched once. Lookups that fail are reported in
+// Errors; an error is returned only when no package could be checked.
+func ScanDependenciesWith(deps *DependencyAnalysis, opts OSVScanOptions) (*SecurityScanResult, error) {
 	result := &SecurityScanResult{
 		Vulnerabilities: []Vulnerability{},
 		ScanTime:        time.Now(),
 	}
+	if deps == nil || len(deps.Files) == 0 {
+		result.SecurityScore = 100
+		return result, nil
+	}
 
-	client := &http.Client{Timeout: 10 * time.Second}
-
+	var packages []osvPackage
+	seen := make(map[osvPackage]bool)
 	for _, file := range deps.Files {
 		ecosystem := mapEcosystem(file.FileType)
 		if ecosystem == "" {
 			continue
 		}
-
 		for _, dep := range file.Dependencies {
-			result.ScannedPackages++
-			vulns, _ := queryOSV(client, dep.Name, dep.Version, ecosystem)
-
-			for _, v := range vulns {
-				vuln := convertVuln(v, dep.Name, dep.Version)
-				result.Vulnerabilities = append(result.Vulnerabilities, vuln)
-
-				switch vuln.Severity {
-				case "CRITICAL":
-					result.CriticalCount++
-				case "HIGH":
-					result.HighCount++
-				case "MEDIUM":
-					result.MediumCount++
-				case "LOW":
-					result.LowCount++
-				}
+			// An exact lockfile version gives precise results; manifest
+			// ranges only approximate the installed version
+			version := dep.Version
+			if dep.Resolved != "" {
+				version = dep.Resolved
+			}
+			pkg := osvPackage{ecosystem, dep.Name, version}
+			if !seen[pkg] {
+				seen[pkg] = true
+				packages = append(packages, pkg)
+			}
+		}
+	}
+	result.ScannedPackages = len(packages)
+
+	scanner := newOSVScanner(opts)
+	var matches [][]string
+	var details map[string]osvVuln
+	if !opts.Offline {
+		result.Source = "osv.dev"
+		matches = scanner.queryBatches(packages)
+		details = scanner.fetchDetails(matches)
+	}
+	// Without network access every lookup fails; the local copy is the
+	// next best thing
+	offline := opts.Offline || (len(packages) > 0 && scanner.failed == len(packages) && !opts.VulnDB.Empty())
+	if offline {
+		if !opts.Offline {
+			scanner.errors = []string{"osv.dev unreachable (" + scanner.errors[0] + "); used the offline database"}
+			scanner.failed = 0
+		}
+		result.Source = "offline database"
+		result.DatabaseUpdated = opts.VulnDB.UpdatedAt()
+		matches, details = scanner.queryLocal(opts.VulnDB, packages)
+	}
+
+	for i, pkg := range packages {
+		for _, id := range matches[i] {
+			o, ok := details[id]
+			if !ok {
+				o = osvVuln{ID: id} // details could not be fetched; reported in Errors
+			}
+			vuln := convertVuln(o, pkg)
+			result.Vulnerabilities = append(result.Vulnerabilities, vuln)
+
+			switch vuln.Severity {
+			case "CRITICAL":
+				result.CriticalCount++
+			case "HIGH":
+				result.HighCount++
+			case "MEDIUM":
+				result.MediumCount++
+			case "LOW":
+				result.LowCount++
 			}
 		}
 	}
 
+	sortVulnerabilities(result.Vulnerabilities)
+	result.Errors = scanner.errors
+	result.FailedPackages = scanner.failed
 	result.TotalCount = len(result.Vulnerabilities)
+	if result.Failed() {
+		return result, fmt.Errorf("vulnerability scan failed: %s", scanner.errors[0])
+	}
 	result.SecurityScore = calcSecurityScore(result)
 	return result, nil
 }
 
-func mapEcosystem(fileType string) string {
-	m := map[string]string{"npm": "npm", "go": "Go", "python": "PyPI", "rust": "crates.io", "ruby": "RubyGems"}
-	return m[fileType]
+// osvScanner runs OSV requests with bounded concurrency and collects errors
+type osvScanner struct {
+	opts   OSVScanOptions
+	client *http.Client
+
+	mu     sync.Mutex
+	errors []string
+	failed int // packages that could not be looked up
+}
+
+func newOSVScanner(opts OSVScanOptions) *osvScanner {
+	defaults := DefaultOSVScanOptions()
+	if opts.BaseURL == "" {
+		opts.BaseURL = defaults.BaseURL
+	}
+	opts.BaseURL = strings.TrimSuffix(opts.BaseURL, "/")
+	if opts.BatchSize <= 0 || opts.BatchSize > defaults.BatchSize {
+		opts.BatchSize = defaults.BatchSize
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 1
+	}
+	client := opts.HTTPClient
+	if client == nil {
+		if opts.Timeout <= 0 {
+			opts.Timeout = defaults.Timeout
+		}
+		client = &http.Client{Timeout: opts.Timeout}
+	}
+	return &osvScanner{opts: opts, client: client}
+}
+
+// fail records an error, and the number of packages it left unchecked
+func (s *osvScanner) fail(err error, packages int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.errors = append(s.errors, err.Error())
+	s.failed += packages
+}
+
+// parallel runs fn for 0..n-1 with at most opts.Concurrency calls at once
+func (s *osvScanner) parallel(n int, fn func(i int)) {
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, s.opts.Concurrency)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			fn(i)
+		}(i)
+	}
+	wg.Wait()
+}
+
+// queryBatches returns the IDs of the vulnerabilities affecting each package
+func (s *osvScanner) queryBatches(packages []osvPackage) [][]string {
+	matches := make([][]string, len(packages))
+	batches := (len(packages) + s.opts.BatchSize - 1) / s.opts.BatchSize
+	s.parallel(batches, func(b int) {
+		start := b * s.opts.BatchSize
+		end := min(start+s.opts.BatchSize, len(packages))
+
+		// Results are written to distinct indexes, so no locking is needed
+		pending := make([]int, 0, end-start)
+		tokens := make(map[int]string)
+		for i := start; i < end; i++ {
+			pending = append(pending, i)
+		}
+		for page := 0; len(pending) > 0; page++ {
+			if page == osvMaxPages {
+				s.fail(fmt.Errorf("too many OSV result pages; results for %d packages are incomplete", len(pending)), 0)
+				return
+			}
+			queries := make([]osvQuery, len(pending))
+			for j, i := range pending {
+				queries[j] = newOSVQuery(packages[i], tokens[i])
+			}
+			var resp osvBatchResponse
+			if err := s.post("/v1/querybatch", map[string]interface{}{"queries": queries}, &resp); err != nil {
+				s.fail(err, len(pending))
+				return
+			}
+			if len(resp.Results) != len(pending) {
+				s.fail(fmt.Errorf("OSV querybatch returned %d results for %d queries", len(resp.Results), len(pending)), len(pending))
+				return
+			}
+			var next []int
+			for j, i := range pending {
+				for _, v := range resp.Results[j].Vulns {
+					matches[i] = append(matches[i], v.ID)
+				}
+				if token := resp.Results[j].NextPageToken; token != "" {
+					tokens[i] = token
+					next = append(next, i)
+				}
+			}
+			pending = next
+		}
+	})
+	return matches
 }
 
-func queryOSV(client *http.Client, pkg, ver, eco string) ([]osvVuln, error) {
-	query := osvQuery{}
-	query.Package.Name = pkg
-	query.Package.Ecosystem = eco
-	ver = strings.TrimPrefix(strings.TrimPrefix(ver, "^"), "~")
-	if ver != "" && ver != "*" {
-		query.Version = ver
+// fetchDetails loads every distinct vulnerability referenced in matches
+func (s *osvScanner) fetchDetails(matches [][]string) map[string]osvVuln {
+	var ids []string
+	seen := make(map[string]bool)
+	for _, list := range matches {
+		for _, id := range list {
+			if !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+	}
+
+	details := make(map[string]osvVuln, len(ids))
+	var mu sync.Mutex
+	s.parallel(len(ids), func(i int) {
+		var v osvVuln
+		if err := s.get("/v1/vulns/"+url.PathEscape(ids[i]), &v); err != nil {
+			s.fail(err, 0)
+			return
+		}
+		mu.Lock()
+		details[ids[i]] = v
+		mu.Unlock()
+	})
+	return details
+}
+
+// queryLocal looks packages up in the offline database
+func (s *osvScanner) queryLocal(db *VulnDB, packages []osvPackage) ([][]string, map[string]osvVuln) {
+	matches := make([][]string, len(packages))
+	details := make(map[string]osvVuln)
+	reported := make(map[string]bool)
+	for i, pkg := range packages {
+		query := newOSVQuery(pkg, "")
+		vulns, err := db.Query(pkg.ecosystem, pkg.name, query.Version)
+		if err != nil {
+			// One message per ecosystem rather than per package
+			s.failed++
+			if !reported[err.Error()] {
+				reported[err.Error()] = true
+				s.errors = append(s.errors, err.Error())
+			}
+			continue
+		}
+		for _, v := range vulns {
+			matches[i] = append(matches[i], v.ID)
+			details[v.ID] = v
+		}
 	}
+	return matches, details
+}
+
+// newOSVQuery builds the query for a package. Range operators are dropped
+// from manifest versions; wildcards query all versions.
+func newOSVQuery(pkg osvPackage, pageToken string) osvQuery {
+	query := osvQuery{PageToken: pageToken}
+	query.Package.Name = pkg.name
+	query.Package.Ecosystem = pkg.ecosystem
+	query.Version = queryVersion(pkg.version)
+	return query
+}
 
-	data, _ := json.Marshal(query)
-	resp, err := client.Post("https://api.osv.dev/v1/query", "application/json", strings.NewReader(string(data)))
+// queryVersion strips caret and tilde operators from a manifest version;
+// wildcards become empty, meaning any version
+func queryVersion(version string) string {
+	version = strings.TrimPrefix(strings.TrimPrefix(version, "^"), "~")
+	if version == "*" {
+		return ""
+	}
+	return version
+}
+
+func (s *osvScanner) post(path string, body, out interface{}) error {
+	data, err := json.Marshal(body)
 	if err != nil {
-		return nil, err
+		return err
 	}
+	resp, err := s.client.Post(s.opts.BaseURL+path, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("OSV request failed: %w", err)
+	}
+	return decodeOSVResponse(resp, out)
+}
+
+func (s *osvScanner) get(path string, out interface{}) error {
+	resp, err := s.client.Get(s.opts.BaseURL + path)
+	if err != nil {
+		return fmt.Errorf("OSV request failed: %w", err)
+	}
+	return decodeOSVResponse(resp, out)
+}
+
+func decodeOSVResponse(resp *http.Response, out interface{}) error {
 	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("OSV %s returned %s", resp.Request.URL.Path, resp.Status)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decoding OSV response: %w", err)
+	}
+	return nil
+}
+
+func mapEcosystem(fileType string) string {
+	m := map[string]string{
+		"npm": "npm", "go": "Go", "python": "PyPI", "rust": "crates.io", "ruby": "RubyGems",
+		"maven": "Maven", "gradle": "Maven", "nuget": "NuGet", "composer": "Packagist", "pub": "Pub",
+	}
+	return m[fileType]
+}
 
-	var r osvResponse
-	json.NewDecoder(resp.Body).Decode(&r)
-	return r.Vulns, nil
+func convertVuln(o osvVuln, pkg osvPackage) Vulnerability {
+	v := Vulnerability{ID: o.ID, Summary: o.Summary, Ecosystem: pkg.ecosystem, Package: pkg.name, Version: pkg.version, PublishedAt: o.Published}
+	v.Severity, v.CVSSScore, v.CVSSVector = getSeverity(o)
+	v.FixedIn = fixedVersion(o, pkg)
+	for _, ref := range o.References {
+		if ref.URL != "" && len(v.References) < 3 {
+			v.References = append(v.References, ref.URL)
+		}
+	}
+	return v
 }
 
-func convertVuln(o osvVuln, pkg, ver string) Vulnerability {
-	v := Vulnerability{ID: o.ID, Summary: o.Summary, Package: pkg, Version: ver, PublishedAt: o.Published}
-	v.Severity = getSeverity(o)
+// fixedVersion returns the earliest fixed release of the package above its
+// scanned version. An advisory can have several ranges (1.x fixed in 1.2.3,
+// 2.x fixed in 2.1.1), so the first fix after the version is the one for
+// its release line. Without an exact version the highest fix is returned.
+func fixedVersion(o osvVuln, pkg osvPackage) string {
+	var fixes []string
 	for _, a := range o.Affected {
+		if a.Package.Name != "" && osvPackageKey(pkg.ecosystem, a.Package.Name) != osvPackageKey(pkg.ecosystem, pkg.name) {
+			continue
+		}
 		for _, r := range a.Ranges {
+			if r.Type == "GIT" {
+				continue
+			}
 			for _, e := range r.Events {
 				if e.Fixed != "" {
-					v.FixedIn = e.Fixed
+					fixes = append(fixes, e.Fixed)
 				}
 			}
 		}
 	}
-	for _, ref := range o.References {
-		if ref.URL != "" && len(v.References) < 3 {
-			v.References = append(v.References, ref.URL)
+	if len(fixes) == 0 {
+		return ""
+	}
+	sort.SliceStable(fixes, func(i, j int) bool {
+		return CompareEcosystemVersions(pkg.ecosystem, fixes[i], fixes[j]) < 0
+	})
+
+	version := queryVersion(pkg.version)
+	if version != "" && !strings.ContainsAny(version, "<>=!*|, ") {
+		for _, fix := range fixes {
+			if CompareEcosystemVersions(pkg.ecosystem, fix, version) > 0 {
+				return fix
+			}
 		}
 	}
-	return v
+	return fixes[len(fixes)-1]
 }
 
-func getSeverity(o osvVuln) string {
-	for _, s := range o.Severity {
-		if s.Type == "CVSS_V3" {
-			var score float64
-			fmt.Sscanf(s.Score, "%f", &score)
-			if score >= 9.0 {
-				return "CRITICAL"
-			} else if score >= 7.0 {
-				return "HIGH"
-			} else if score >= 4.0 {
-				return "MEDIUM"
+// getSeverity rates an advisory from its newest CVSS vector, falling back
+// to the GitHub severity rating and then to MEDIUM. The score and vector
+// are empty when no vector could be scored.
+func getSeverity(o osvVuln) (string, float64, string) {
+	severities := o.Severity
+	for _, a := range o.Affected {
+		severities = append(severities, a.Severity...)
+	}
+	for _, typ := range []string{"CVSS_V4", "CVSS_V3", "CVSS_V2"} {
+		for _, s := range severities {
+			if s.Type != typ {
+				continue
+			}
+			score, version, err := CVSSScore(s.Score)
+			if err != nil {
+				continue
+			}
+			if version == "2.0" {
+				return cvss2Severity(score), score, s.Score
 			}
-			return "LOW"
+			if score == 0 {
+				return "LOW", score, s.Score
+			}
+			return CVSSSeverity(score), score, s.Score
+		}
+	}
+
+	var ghsa struct {
+		Severity string `json:"severity"`
+	}
+	if json.Unmarshal(o.DatabaseSpecific, &ghsa) == nil {
+		switch sev := strings.ToUpper(ghsa.Severity); sev {
+		case "CRITICAL", "HIGH", "LOW":
+			return sev, 0, ""
+		case "MODERATE", "MEDIUM":
+			return "MEDIUM", 0, ""
 		}
 	}
-	return "MEDIUM"
+	return "MEDIUM", 0, ""
 }
 
+// severityRank orders severities from CRITICAL (4) down to unknown (0)
+func severityRank(sev string) int {
+	switch sev {
+	case "CRITICAL":
+		return 4
+	case "HIGH":
+		return 3
+	case "MEDIUM":
+		return 2
+	case "LOW":
+		return 1
+	}
+	return 0
+}
+
+// sortVulnerabilities puts the riskiest vulnerabilities first: by
+// severity, then CVSS score, then package and ID for a stable order
+func sortVulnerabilities(vulns []Vulnerability) {
+	sort.SliceStable(vulns, func(i, j int) bool {
+		a, b := vulns[i], vulns[j]
+		if ra, rb := severityRank(a.Severity), severityRank(b.Severity); ra != rb {
+			return ra > rb
+		}
+		if a.CVSSScore != b.CVSSScore {
+			return a.CVSSScore > b.CVSSScore
+		}
+		if a.Package != b.Package {
+			return a.Package < b.Package
+		}
+		return a.ID < b.ID
+	})
+}
+
+// calcSecurityScore deducts points per vulnerability. Scored vulnerabilities
+// cost score²/4 (9.8 → 24, 7.5 → 14, 5.0 → 6), so two criticals weigh more
+// than two barely-high ones; unscored ones use a fixed cost per severity.
 func calcSecurityScore(r *SecurityScanResult) int {
-	score := 100 - r.CriticalCount*25 - r.HighCount*15 - r.MediumCount*5 - r.LowCount*2
+	penalty := 0.0
+	for _, v := range r.Vulnerabilities {
+		if v.CVSSScore > 0 {
+			penalty += v.CVSSScore * v.CVSSScore / 4
+			continue
+		}
+		switch v.Severity {
+		case "CRITICAL":
+			penalty += 25
+		case "HIGH":
+			penalty += 15
+		case "MEDIUM":
+			penalty += 5
+		case "LOW":
+			penalty += 2
+		}
+	}
+	score := 100 - int(math.Round(penalty))
 	if score < 0 {
 		score = 0
 	}
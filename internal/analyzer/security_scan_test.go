@@ -0,0 +1,216 @@
+package analyzer
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeOSV is a stand-in for the OSV API. vulns maps "ecosystem/name@version"
+// to the IDs of the vulnerabilities affecting it.
+type fakeOSV struct {
+	vulns    map[string][]string
+	pageSize int // results per page; 0 returns everything at once
+
+	mu            sync.Mutex
+	batchRequests int
+	queries       int
+	detailFetches map[string]int
+}
+
+func (f *fakeOSV) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if id, ok := strings.CutPrefix(r.URL.Path, "/v1/vulns/"); ok {
+		f.detailFetches[id]++
+		severity := "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H"
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"id":       id,
+			"summary":  "summary of " + id,
+			"severity": []map[string]string{{"type": "CVSS_V3", "score": severity}},
+		})
+		return
+	}
+	if r.URL.Path != "/v1/querybatch" || r.Method != http.MethodPost {
+		http.NotFound(w, r)
+		return
+	}
+
+	var req struct {
+		Queries []osvQuery `json:"queries"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	f.batchRequests++
+	f.queries += len(req.Queries)
+
+	type result struct {
+		Vulns         []map[string]string `json:"vulns,omitempty"`
+		NextPageToken string              `json:"next_page_token,omitempty"`
+	}
+	var results []result
+	for _, q := range req.Queries {
+		ids := f.vulns[q.Package.Ecosystem+"/"+q.Package.Name+"@"+q.Version]
+		var res result
+		start := 0
+		if q.PageToken != "" {
+			start = len(q.PageToken) // the token encodes the offset
+		}
+		end := len(ids)
+		if f.pageSize > 0 && start+f.pageSize < end {
+			end = start + f.pageSize
+			res.NextPageToken = strings.Repeat("x", end)
+		}
+		for _, id := range ids[start:end] {
+			res.Vulns = append(res.Vulns, map[string]string{"id": id})
+		}
+		results = append(results, res)
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{"results": results})
+}
+
+func newFakeOSV(vulns map[string][]string) (*fakeOSV, *httptest.Server) {
+	f := &fakeOSV{vulns: vulns, detailFetches: make(map[string]int)}
+	return f, httptest.NewServer(f)
+}
+
+func scanTestDeps() *DependencyAnalysis {
+	deps := &DependencyAnalysis{}
+	deps.AddFiles(
+		DependencyFile{Filename: "package.json", FileType: "npm", Dependencies: []Dependency{
+			{Name: "lodash", Version: "^4.17.15", Resolved: "4.17.20"},
+			{Name: "minimist", Version: "1.2.0"},
+			{Name: "express", Version: "4.18.2"},
+		}},
+		DependencyFile{Filename: "web/package.json", FileType: "npm", Dependencies: []Dependency{
+			{Name: "lodash", Version: "4.17.20"},
+			{Name: "minimist", Version: "1.2.0"},
+		}},
+		DependencyFile{Filename: "requirements.txt", FileType: "python", Dependencies: []Dependency{
+			{Name: "django", Version: "*"},
+		}},
+		DependencyFile{Filename: "Makefile", FileType: "make"},
+	)
+	return deps
+}
+
+func TestScanDependenciesWith(t *testing.T) {
+	fake, server := newFakeOSV(map[string][]string{
+		"npm/lodash@4.17.20": {"GHSA-1", "GHSA-2"},
+		"npm/minimist@1.2.0": {"GHSA-2"},
+		"PyPI/django@":       {"PYSEC-1"},
+	})
+	defer server.Close()
+
+	opts := DefaultOSVScanOptions()
+	opts.BaseURL = server.URL + "/"
+	opts.BatchSize = 2
+	result, err := ScanDependenciesWith(scanTestDeps(), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// lodash and minimist are listed twice but looked up once
+	if result.ScannedPackages != 4 || fake.queries != 4 || fake.batchRequests != 2 {
+		t.Errorf("scanned %d packages with %d queries in %d requests", result.ScannedPackages, fake.queries, fake.batchRequests)
+	}
+	// GHSA-2 affects two packages but its details are fetched once
+	if len(fake.detailFetches) != 3 || fake.detailFetches["GHSA-2"] != 1 {
+		t.Errorf("detail fetches = %v", fake.detailFetches)
+	}
+	if result.TotalCount != 4 || result.Incomplete() {
+		t.Errorf("result = %+v", result)
+	}
+	for _, v := range result.Vulnerabilities {
+		if v.Summary != "summary of "+v.ID {
+			t.Errorf("%s has no details: %+v", v.ID, v)
+		}
+	}
+}
+
+func TestScanDependenciesPagination(t *testing.T) {
+	fake, server := newFakeOSV(map[string][]string{
+		"npm/express@4.18.2": {"A", "B", "C", "D", "E"},
+	})
+	defer server.Close()
+	fake.pageSize = 2
+
+	opts := DefaultOSVScanOptions()
+	opts.BaseURL = server.URL
+	result, err := ScanDependenciesWith(scanTestDeps(), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.TotalCount != 5 || fake.batchRequests != 3 {
+		t.Errorf("got %d vulns in %d requests", result.TotalCount, fake.batchRequests)
+	}
+}
+
+func TestScanDependenciesErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	opts := DefaultOSVScanOptions()
+	opts.BaseURL = server.URL
+	result, err := ScanDependenciesWith(scanTestDeps(), opts)
+	if err == nil {
+		t.Error("expected an error when no package could be checked")
+	}
+	if !result.Failed() || result.SecurityScore != 0 || result.FailedPackages != 4 || !strings.Contains(result.Errors[0], "503") {
+		t.Errorf("result = %+v", result)
+	}
+
+	// Failed detail lookups keep the vulnerability, without details
+	fake := &fakeOSV{vulns: map[string][]string{"npm/express@4.18.2": {"GHSA-9"}}, detailFetches: make(map[string]int)}
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/v1/vulns/") {
+			http.Error(w, "boom", http.StatusInternalServerError)
+			return
+		}
+		fake.ServeHTTP(w, r)
+	}))
+	defer failing.Close()
+	opts.BaseURL = failing.URL
+	result, err = ScanDependenciesWith(scanTestDeps(), opts)
+	if err != nil || result.Failed() || result.TotalCount != 1 || result.Vulnerabilities[0].ID != "GHSA-9" || len(result.Errors) != 1 {
+		t.Errorf("result = %+v, err = %v", result, err)
+	}
+}
+
+func TestScanDependenciesEmpty(t *testing.T) {
+	result, err := ScanDependenciesWith(nil, OSVScanOptions{BaseURL: "http://127.0.0.1:0"})
+	if err != nil || result.SecurityScore != 100 || result.Incomplete() {
+		t.Errorf("result = %+v, err = %v", result, err)
+	}
+}
+
+func TestFixedVersion(t *testing.T) {
+	var o osvVuln
+	err := json.Unmarshal([]byte(`{"affected":[
+		{"package":{"ecosystem":"npm","name":"lodash"},"ranges":[{"type":"SEMVER","events":[
+			{"introduced":"0"},{"fixed":"4.17.21"},{"introduced":"5.0.0"},{"fixed":"5.0.3"}]}]},
+		{"package":{"ecosystem":"npm","name":"lodash-es"},"ranges":[{"type":"SEMVER","events":[
+			{"introduced":"0"},{"fixed":"9.9.9"}]}]}]}`), &o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := map[string]string{
+		"4.17.20":  "4.17.21",
+		"^4.17.15": "4.17.21",
+		"5.0.1":    "5.0.3",
+		"*":        "5.0.3", // unknown version: the highest fix
+	}
+	for version, want := range tests {
+		if got := fixedVersion(o, osvPackage{"npm", "lodash", version}); got != want {
+			t.Errorf("fixedVersion(%s) = %s, want %s", version, got, want)
+		}
+	}
+}
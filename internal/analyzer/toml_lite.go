@@ -0,0 +1,375 @@
+package analyzer
+
+import (
+	"strconv"
+	"strings"
+)
+
+// This file implements a small TOML reader for package manifests and
+// lockfiles such as pyproject.toml, Pipfile and Cargo.toml. It supports
+// tables, arrays of tables, dotted and quoted keys, basic, literal and
+// multi-line strings, arrays and inline tables. As with the YAML reader,
+// non-string scalars (numbers, booleans, dates) are returned as their literal
+// text so documents can be walked with yamlGet, yamlString and yamlList.
+// Malformed lines are skipped rather than failing the whole document.
+
+// tomlParser holds the read position within a document
+type tomlParser struct {
+	src string
+	pos int
+}
+
+// parseTOML parses content into nested maps
+func parseTOML(content string) map[string]interface{} {
+	p := &tomlParser{src: strings.ReplaceAll(content, "\r\n", "\n")}
+	root := make(map[string]interface{})
+	current := root
+
+	for {
+		p.skipBlank()
+		if p.eof() {
+			return root
+		}
+		start := p.pos
+
+		switch {
+		case strings.HasPrefix(p.src[p.pos:], "[["):
+			p.pos += 2
+			keys, ok := p.parseKey()
+			if ok && p.consume("]]") {
+				if table := appendTOMLTable(root, keys); table != nil {
+					current = table
+				}
+			}
+		case p.peek() == '[':
+			p.pos++
+			keys, ok := p.parseKey()
+			if ok && p.consume("]") {
+				if table := ensureTOMLTable(root, keys); table != nil {
+					current = table
+				}
+			}
+		default:
+			keys, ok := p.parseKey()
+			if ok && p.consume("=") {
+				if value, ok := p.parseValue(); ok {
+					setTOMLValue(current, keys, value)
+				}
+			}
+		}
+
+		p.skipLine()
+		if p.pos == start {
+			p.pos++ // guarantee progress on garbage
+		}
+	}
+}
+
+func (p *tomlParser) eof() bool { return p.pos >= len(p.src) }
+
+func (p *tomlParser) peek() byte {
+	if p.eof() {
+		return 0
+	}
+	return p.src[p.pos]
+}
+
+// skipSpace skips spaces and tabs on the current line
+func (p *tomlParser) skipSpace() {
+	for !p.eof() && (p.src[p.pos] == ' ' || p.src[p.pos] == '\t') {
+		p.pos++
+	}
+}
+
+// skipBlank skips whitespace, newlines and comments
+func (p *tomlParser) skipBlank() {
+	for !p.eof() {
+		switch p.src[p.pos] {
+		case ' ', '\t', '\n', '\r':
+			p.pos++
+		case '#':
+			p.skipLine()
+		default:
+			return
+		}
+	}
+}
+
+// skipLine moves past the end of the current line
+func (p *tomlParser) skipLine() {
+	if i := strings.IndexByte(p.src[p.pos:], '\n'); i >= 0 {
+		p.pos += i + 1
+	} else {
+		p.pos = len(p.src)
+	}
+}
+
+// consume skips spaces and then the literal token, reporting whether it was there
+func (p *tomlParser) consume(token string) bool {
+	p.skipSpace()
+	if strings.HasPrefix(p.src[p.pos:], token) {
+		p.pos += len(token)
+		return true
+	}
+	return false
+}
+
+// parseKey reads a bare, quoted or dotted key
+func (p *tomlParser) parseKey() ([]string, bool) {
+	var keys []string
+	for {
+		p.skipSpace()
+		switch c := p.peek(); {
+		case c == '"' || c == '\'':
+			s, ok := p.parseString()
+			if !ok {
+				return nil, false
+			}
+			keys = append(keys, s)
+		default:
+			start := p.pos
+			for !p.eof() && isTOMLBareKeyChar(p.src[p.pos]) {
+				p.pos++
+			}
+			if p.pos == start {
+				return nil, false
+			}
+			keys = append(keys, p.src[start:p.pos])
+		}
+		p.skipSpace()
+		if p.peek() != '.' {
+			return keys, true
+		}
+		p.pos++
+	}
+}
+
+func isTOMLBareKeyChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '-'
+}
+
+// parseValue reads a string, array, inline table or bare scalar
+func (p *tomlParser) parseValue() (interface{}, bool) {
+	p.skipSpace()
+	switch p.peek() {
+	case '"', '\'':
+		return p.parseString()
+	case '[':
+		p.pos++
+		items := []interface{}{}
+		for {
+			p.skipBlank()
+			if p.peek() == ']' {
+				p.pos++
+				return items, true
+			}
+			item, ok := p.parseValue()
+			if !ok {
+				return nil, false
+			}
+			items = append(items, item)
+			p.skipBlank()
+			if p.peek() == ',' {
+				p.pos++
+			} else if p.peek() != ']' {
+				return nil, false
+			}
+		}
+	case '{':
+		p.pos++
+		table := make(map[string]interface{})
+		for {
+			p.skipBlank()
+			if p.peek() == '}' {
+				p.pos++
+				return table, true
+			}
+			keys, ok := p.parseKey()
+			if !ok || !p.consume("=") {
+				return nil, false
+			}
+			value, ok := p.parseValue()
+			if !ok {
+				return nil, false
+			}
+			setTOMLValue(table, keys, value)
+			p.skipBlank()
+			if p.peek() == ',' {
+				p.pos++
+			} else if p.peek() != '}' {
+				return nil, false
+			}
+		}
+	}
+
+	start := p.pos
+	for !p.eof() && !strings.ContainsRune(",]}\n#", rune(p.src[p.pos])) {
+		p.pos++
+	}
+	value := strings.TrimSpace(p.src[start:p.pos])
+	return value, value != ""
+}
+
+// parseString reads any of the four TOML string forms
+func (p *tomlParser) parseString() (string, bool) {
+	rest := p.src[p.pos:]
+	for _, delim := range []string{`"""`, `'''`} {
+		if !strings.HasPrefix(rest, delim) {
+			continue
+		}
+		end := strings.Index(rest[3:], delim)
+		if end < 0 {
+			return "", false
+		}
+		// Up to two quotes may directly precede the closing delimiter
+		for 3+end+3 < len(rest) && rest[3+end+3] == delim[0] {
+			end++
+		}
+		body := strings.TrimPrefix(rest[3:3+end], "\n")
+		p.pos += 3 + end + 3
+		if delim == `'''` {
+			return body, true
+		}
+		return unescapeTOML(body, true)
+	}
+
+	quote := rest[0]
+	for i := 1; i < len(rest); i++ {
+		switch rest[i] {
+		case '\n':
+			return "", false
+		case '\\':
+			if quote == '"' {
+				i++
+			}
+		case quote:
+			p.pos += i + 1
+			if quote == '\'' {
+				return rest[1:i], true
+			}
+			return unescapeTOML(rest[1:i], false)
+		}
+	}
+	return "", false
+}
+
+// unescapeTOML expands escape sequences in a basic string. In multi-line
+// strings a backslash at the end of a line trims the following whitespace.
+func unescapeTOML(s string, multiline bool) (string, bool) {
+	if !strings.Contains(s, `\`) {
+		return s, true
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		if i >= len(s) {
+			return "", false
+		}
+		switch s[i] {
+		case 'b':
+			b.WriteByte('\b')
+		case 't':
+			b.WriteByte('\t')
+		case 'n':
+			b.WriteByte('\n')
+		case 'f':
+			b.WriteByte('\f')
+		case 'r':
+			b.WriteByte('\r')
+		case 'e':
+			b.WriteByte(0x1b)
+		case '"', '\\':
+			b.WriteByte(s[i])
+		case 'u', 'U':
+			size := 4
+			if s[i] == 'U' {
+				size = 8
+			}
+			if i+1+size > len(s) {
+				return "", false
+			}
+			r, err := strconv.ParseUint(s[i+1:i+1+size], 16, 32)
+			if err != nil {
+				return "", false
+			}
+			b.WriteRune(rune(r))
+			i += size
+		default:
+			if nl := strings.IndexByte(s[i:], '\n'); multiline && nl >= 0 && strings.TrimSpace(s[i:i+nl]) == "" {
+				for i < len(s) && strings.ContainsRune(" \t\n", rune(s[i])) {
+					i++
+				}
+				i--
+				continue
+			}
+			return "", false
+		}
+	}
+	return b.String(), true
+}
+
+// ensureTOMLTable returns the table at keys, creating it as needed. Keys that
+// name an array of tables resolve to its most recent element.
+func ensureTOMLTable(root map[string]interface{}, keys []string) map[string]interface{} {
+	node := root
+	for _, key := range keys {
+		switch v := node[key].(type) {
+		case map[string]interface{}:
+			node = v
+		case []interface{}:
+			if len(v) == 0 {
+				return nil
+			}
+			last, ok := v[len(v)-1].(map[string]interface{})
+			if !ok {
+				return nil
+			}
+			node = last
+		case nil:
+			table := make(map[string]interface{})
+			node[key] = table
+			node = table
+		default:
+			return nil
+		}
+	}
+	return node
+}
+
+// appendTOMLTable adds a new table to the array of tables at keys
+func appendTOMLTable(root map[string]interface{}, keys []string) map[string]interface{} {
+	parent := ensureTOMLTable(root, keys[:len(keys)-1])
+	if parent == nil {
+		return nil
+	}
+	last := keys[len(keys)-1]
+	list, ok := parent[last].([]interface{})
+	if !ok && parent[last] != nil {
+		return nil
+	}
+	table := make(map[string]interface{})
+	parent[last] = append(list, table)
+	return table
+}
+
+// setTOMLValue assigns value at a dotted key relative to table
+func setTOMLValue(table map[string]interface{}, keys []string, value interface{}) {
+	if parent := ensureTOMLTable(table, keys[:len(keys)-1]); parent != nil {
+		parent[keys[len(keys)-1]] = value
+	}
+}
+
+// tomlStrings returns the strings in an array node
+func tomlStrings(node interface{}) []string {
+	var values []string
+	for _, item := range yamlList(node) {
+		if s, ok := item.(string); ok {
+			values = append(values, s)
+		}
+	}
+	return values
+}
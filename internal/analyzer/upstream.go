@@ -0,0 +1,388 @@
+// Package analyzer provides analysis functions for GitHub repositories.
+// This file checks whether the projects a repository depends on are still
+// maintained, by running a light analysis on their GitHub repositories.
+package analyzer
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/agnivo988/Repo-lyzer/internal/github"
+)
+
+// Upstream risk flags
+const (
+	UpstreamArchived         = "archived"
+	UpstreamSingleMaintainer = "single maintainer"
+	UpstreamStale            = "stale"
+)
+
+// upstreamRequests is the number of GitHub requests one upstream analysis
+// takes: the repository, its commits of the last year and its top
+// contributors
+const upstreamRequests = 3
+
+// UpstreamClient is the part of the GitHub client the upstream analysis
+// uses; *github.Client implements it
+type UpstreamClient interface {
+	GetRepo(owner, repo string) (*github.Repo, error)
+	GetCommits(owner, repo string, days int) ([]github.Commit, error)
+	GetTopContributors(owner, repo string, n int) ([]github.Contributor, error)
+}
+
+// UpstreamCache stores upstream analyses between runs, keyed by
+// "owner/repo". Implementations must be safe for concurrent use.
+type UpstreamCache interface {
+	Get(repo string) (*UpstreamHealth, bool)
+	Set(repo string, health *UpstreamHealth)
+}
+
+// UpstreamOptions configures CheckUpstreamHealth
+type UpstreamOptions struct {
+	Registry RegistryOptions // maps packages to repositories through their metadata
+	// Budget is the most GitHub requests the check may spend. Each upstream
+	// repository that isn't cached takes three.
+	Budget      int
+	Cache       UpstreamCache // optional
+	StaleAfter  time.Duration // an upstream without a push for this long is stale
+	Concurrency int
+}
+
+// DefaultUpstreamOptions returns options for the public registries with a
+// budget of 90 requests, enough for 30 upstream repositories
+func DefaultUpstreamOptions() UpstreamOptions {
+	return UpstreamOptions{
+		Registry:    DefaultRegistryOptions(),
+		Budget:      90,
+		StaleAfter:  365 * 24 * time.Hour,
+		Concurrency: 4,
+	}
+}
+
+// UpstreamHealth is the light analysis of the repository a dependency is
+// developed in
+type UpstreamHealth struct {
+	Repository    string    `json:"repository"` // owner/repo
+	HealthScore   int       `json:"health_score"`
+	BusFactor     int       `json:"bus_factor"`
+	BusRisk       string    `json:"bus_risk"`
+	Contributors  int       `json:"contributors"`   // counting at most the top 100
+	RecentCommits int       `json:"recent_commits"` // in the last year, counting at most 30
+	Stars         int       `json:"stars"`
+	PushedAt      time.Time `json:"pushed_at"`
+	Archived      bool      `json:"archived"`
+	AnalyzedAt    time.Time `json:"analyzed_at"`
+}
+
+// SummarizeUpstream runs the light analysis on data already fetched for a
+// repository. Commits older than a year are ignored.
+func SummarizeUpstream(repo *github.Repo, commits []github.Commit, contributors []github.Contributor) *UpstreamHealth {
+	since := time.Now().AddDate(-1, 0, 0)
+	var recent []github.Commit
+	for _, c := range commits {
+		if c.Commit.Author.Date.After(since) {
+			recent = append(recent, c)
+		}
+	}
+	busFactor, busRisk := BusFactor(contributors)
+	return &UpstreamHealth{
+		Repository:    repo.FullName,
+		HealthScore:   CalculateHealth(repo, recent),
+		BusFactor:     busFactor,
+		BusRisk:       busRisk,
+		Contributors:  len(contributors),
+		RecentCommits: len(recent),
+		Stars:         repo.Stars,
+		PushedAt:      repo.PushedAt,
+		Archived:      repo.Archived,
+		AnalyzedAt:    time.Now(),
+	}
+}
+
+// DependencyUpstream is a direct dependency together with the health of its
+// upstream repository
+type DependencyUpstream struct {
+	FileType   string          `json:"file_type"`
+	Name       string          `json:"name"`
+	Dev        bool            `json:"dev"`
+	Repository string          `json:"repository,omitempty"` // owner/repo, empty if unmapped
+	MappedBy   string          `json:"mapped_by,omitempty"`  // "module path" or "registry"
+	Health     *UpstreamHealth `json:"health,omitempty"`     // nil if not analyzed
+	Cached     bool            `json:"cached,omitempty"`
+	Risks      []string        `json:"risks,omitempty"`
+	Risk       string          `json:"risk"` // HIGH, MEDIUM, LOW or UNKNOWN
+	Error      string          `json:"error,omitempty"`
+}
+
+// UpstreamAnalysis is the dependency risk table of a project
+type UpstreamAnalysis struct {
+	Packages         []DependencyUpstream `json:"packages"` // riskiest first
+	Mapped           int                  `json:"mapped"`
+	Analyzed         int                  `json:"analyzed"`
+	Cached           int                  `json:"cached"`
+	Skipped          int                  `json:"skipped"` // mapped, but left out to stay within the budget
+	RequestsUsed     int                  `json:"requests_used"`
+	Budget           int                  `json:"budget"`
+	ArchivedCount    int                  `json:"archived_count"`
+	SingleMaintainer int                  `json:"single_maintainer_count"`
+	StaleCount       int                  `json:"stale_count"`
+	Errors           []string             `json:"errors,omitempty"`
+}
+
+// AtRisk returns the dependencies with at least one risk flag
+func (u *UpstreamAnalysis) AtRisk() []DependencyUpstream {
+	var risky []DependencyUpstream
+	for _, p := range u.Packages {
+		if len(p.Risks) > 0 {
+			risky = append(risky, p)
+		}
+	}
+	return risky
+}
+
+// CheckUpstreamHealth analyzes the GitHub repositories of a project's
+// direct dependencies. Go modules are mapped through their module path,
+// other packages through the repository URL in their registry metadata.
+// Repositories shared by several packages, like monorepos, are analyzed
+// once. Production dependencies are analyzed before dev dependencies, and
+// once the budget is spent the rest are skipped; cached analyses are free.
+func CheckUpstreamHealth(client UpstreamClient, deps *DependencyAnalysis, opts UpstreamOptions) *UpstreamAnalysis {
+	result := &UpstreamAnalysis{Packages: []DependencyUpstream{}, Budget: opts.Budget}
+	if deps == nil {
+		return result
+	}
+	if opts.StaleAfter <= 0 {
+		opts.StaleAfter = DefaultUpstreamOptions().StaleAfter
+	}
+
+	index := make(map[string]int)
+	for _, file := range deps.Files {
+		if file.FileType == "docker" {
+			continue
+		}
+		for _, dep := range file.Dependencies {
+			if dep.Transitive {
+				continue
+			}
+			key := file.FileType + ":" + lockKey(file.FileType, dep.Name)
+			if i, ok := index[key]; ok {
+				result.Packages[i].Dev = result.Packages[i].Dev && dep.Type == "dev"
+				continue
+			}
+			index[key] = len(result.Packages)
+			result.Packages = append(result.Packages, DependencyUpstream{FileType: file.FileType, Name: dep.Name, Dev: dep.Type == "dev"})
+		}
+	}
+
+	mapUpstreams(result, opts)
+
+	// One analysis per repository, production dependencies first
+	order := make([]int, len(result.Packages))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		a, b := result.Packages[order[i]], result.Packages[order[j]]
+		if a.Dev != b.Dev {
+			return !a.Dev
+		}
+		return a.Name < b.Name
+	})
+	var repos []string
+	healths := make(map[string]*UpstreamHealth)
+	cached := make(map[string]bool)
+	for _, i := range order {
+		repo := result.Packages[i].Repository
+		if repo == "" {
+			continue
+		}
+		result.Mapped++
+		if _, seen := healths[repo]; seen || contains(repos, repo) {
+			continue
+		}
+		if opts.Cache != nil {
+			if h, ok := opts.Cache.Get(repo); ok {
+				healths[repo], cached[repo] = h, true
+				result.Cached++
+				continue
+			}
+		}
+		repos = append(repos, repo)
+	}
+
+	// Reserve the budget in order so the same repositories are analyzed on
+	// every run
+	affordable := opts.Budget / upstreamRequests
+	if affordable < len(repos) {
+		repos = repos[:max(affordable, 0)]
+	}
+	errs := make(map[string]string)
+	if client != nil {
+		var requests atomic.Int32
+		var mu sync.Mutex
+		forEachConcurrently(len(repos), opts.Concurrency, func(i int) {
+			h, n, err := analyzeUpstream(client, repos[i])
+			requests.Add(int32(n))
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				errs[repos[i]] = err.Error()
+				return
+			}
+			healths[repos[i]] = h
+			if opts.Cache != nil {
+				opts.Cache.Set(repos[i], h)
+			}
+		})
+		result.RequestsUsed = int(requests.Load())
+	}
+	result.Analyzed = len(healths) - result.Cached
+
+	now := time.Now()
+	for i := range result.Packages {
+		p := &result.Packages[i]
+		p.Risk = "UNKNOWN"
+		if p.Repository == "" {
+			continue
+		}
+		p.Health, p.Cached = healths[p.Repository], cached[p.Repository]
+		if msg, failed := errs[p.Repository]; failed {
+			p.Error = msg
+			if !contains(result.Errors, p.Repository+": "+msg) && len(result.Errors) < maxComplianceErrors {
+				result.Errors = append(result.Errors, p.Repository+": "+msg)
+			}
+			continue
+		}
+		if p.Health == nil {
+			result.Skipped++
+			continue
+		}
+		p.Risks, p.Risk = upstreamRisks(p.Health, now, opts.StaleAfter)
+		for _, r := range p.Risks {
+			switch r {
+			case UpstreamArchived:
+				result.ArchivedCount++
+			case UpstreamSingleMaintainer:
+				result.SingleMaintainer++
+			case UpstreamStale:
+				result.StaleCount++
+			}
+		}
+	}
+
+	rank := map[string]int{"HIGH": 0, "MEDIUM": 1, "LOW": 2, "UNKNOWN": 3}
+	sort.SliceStable(result.Packages, func(i, j int) bool {
+		a, b := result.Packages[i], result.Packages[j]
+		if rank[a.Risk] != rank[b.Risk] {
+			return rank[a.Risk] < rank[b.Risk]
+		}
+		if a.Dev != b.Dev {
+			return !a.Dev
+		}
+		return a.Name < b.Name
+	})
+	return result
+}
+
+// mapUpstreams finds the GitHub repository of every package. Registry
+// lookups don't count against the GitHub budget.
+func mapUpstreams(result *UpstreamAnalysis, opts UpstreamOptions) {
+	registry := newRegistryClient(opts.Registry)
+	var mu sync.Mutex
+	forEachConcurrently(len(result.Packages), opts.Concurrency, func(i int) {
+		p := &result.Packages[i]
+		// Each goroutine writes only its own package
+		if p.FileType == "go" {
+			if owner, repo, ok := githubRepoFromURL(goModuleRepository(p.Name)); ok {
+				p.Repository, p.MappedBy = owner+"/"+repo, "module path"
+			}
+			return
+		}
+		meta, err := registry.metadata(p.FileType, p.Name, "")
+		if err != nil && !errors.Is(err, errNotInRegistry) {
+			mu.Lock()
+			defer mu.Unlock()
+			if len(result.Errors) < maxComplianceErrors {
+				result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", p.Name, err))
+			}
+			return
+		}
+		if meta == nil {
+			return
+		}
+		if owner, repo, ok := githubRepoFromURL(meta.repository); ok {
+			p.Repository, p.MappedBy = owner+"/"+repo, "registry"
+		}
+	})
+}
+
+// analyzeUpstream fetches and analyzes one repository. It returns the
+// number of requests it made.
+func analyzeUpstream(client UpstreamClient, fullName string) (*UpstreamHealth, int, error) {
+	owner, name, _ := strings.Cut(fullName, "/")
+	repo, err := client.GetRepo(owner, name)
+	if err != nil {
+		return nil, 1, err
+	}
+	commits, err := client.GetCommits(owner, name, 365)
+	if err != nil {
+		return nil, 2, err
+	}
+	contributors, err := client.GetTopContributors(owner, name, 100)
+	if err != nil {
+		return nil, 3, err
+	}
+	h := SummarizeUpstream(repo, commits, contributors)
+	if h.Repository == "" {
+		h.Repository = fullName
+	}
+	return h, upstreamRequests, nil
+}
+
+// upstreamRisks flags archived, single-maintainer and stale upstreams. An
+// archived upstream, or one that is both stale and maintained by one
+// person, is a high risk.
+func upstreamRisks(h *UpstreamHealth, now time.Time, staleAfter time.Duration) ([]string, string) {
+	var risks []string
+	if h.Archived {
+		risks = append(risks, UpstreamArchived)
+	}
+	if h.BusFactor == 1 || h.Contributors == 1 {
+		risks = append(risks, UpstreamSingleMaintainer)
+	}
+	if !h.PushedAt.IsZero() && now.Sub(h.PushedAt) > staleAfter {
+		risks = append(risks, UpstreamStale)
+	}
+	switch {
+	case h.Archived || len(risks) >= 2:
+		return risks, "HIGH"
+	case len(risks) == 1:
+		return risks, "MEDIUM"
+	}
+	return risks, "LOW"
+}
+
+// forEachConcurrently calls fn for 0..n-1 with at most concurrency calls in
+// flight
+func forEachConcurrently(n, concurrency int, fn func(i int)) {
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, concurrency)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			fn(i)
+		}(i)
+	}
+	wg.Wait()
+}
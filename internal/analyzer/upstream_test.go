@@ -0,0 +1,223 @@
+package analyzer
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/agnivo988/Repo-lyzer/internal/github"
+)
+
+// fakeUpstreams serves repositories from memory and counts requests
+type fakeUpstreams struct {
+	mu       sync.Mutex
+	repos    map[string]*github.Repo
+	owners   map[string][]github.Contributor
+	requests int
+}
+
+func (f *fakeUpstreams) GetRepo(owner, repo string) (*github.Repo, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.requests++
+	r, ok := f.repos[owner+"/"+repo]
+	if !ok {
+		return nil, errors.New("API returned status 404")
+	}
+	return r, nil
+}
+
+func (f *fakeUpstreams) GetCommits(owner, repo string, days int) ([]github.Commit, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.requests++
+	var commits []github.Commit
+	if r := f.repos[owner+"/"+repo]; time.Since(r.PushedAt) < 30*24*time.Hour {
+		for i := 0; i < 20; i++ {
+			var c github.Commit
+			c.Commit.Author.Date = time.Now().AddDate(0, 0, -i)
+			commits = append(commits, c)
+		}
+	}
+	return commits, nil
+}
+
+func (f *fakeUpstreams) GetTopContributors(owner, repo string, n int) ([]github.Contributor, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.requests++
+	if c, ok := f.owners[owner+"/"+repo]; ok {
+		return c, nil
+	}
+	return []github.Contributor{{Login: "a", Commits: 50}, {Login: "b", Commits: 40}, {Login: "c", Commits: 30}}, nil
+}
+
+type memoryUpstreamCache struct {
+	mu      sync.Mutex
+	entries map[string]*UpstreamHealth
+}
+
+func (c *memoryUpstreamCache) Get(repo string) (*UpstreamHealth, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	h, ok := c.entries[repo]
+	return h, ok
+}
+
+func (c *memoryUpstreamCache) Set(repo string, h *UpstreamHealth) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[repo] = h
+}
+
+func upstreamTestSetup(t *testing.T) (*fakeUpstreams, *DependencyAnalysis, UpstreamOptions) {
+	responses := map[string]string{
+		"/express/latest":       `{"repository":{"type":"git","url":"git+https://github.com/expressjs/express.git"}}`,
+		"/left-pad/latest":      `{"repository":"github:stevemao/left-pad"}`,
+		"/@babel/core/latest":   `{"repository":{"url":"https://github.com/babel/babel.git","directory":"packages/babel-core"}}`,
+		"/@babel/parser/latest": `{"repository":{"url":"https://github.com/babel/babel.git"}}`,
+		"/mocha/latest":         `{"repository":"https://github.com/mochajs/mocha"}`,
+		"/pypi/requests/json":   `{"info":{"project_urls":{"Source":"https://github.com/psf/requests"}}}`,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := responses[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+
+	recent := time.Now().AddDate(0, 0, -3)
+	client := &fakeUpstreams{
+		repos: map[string]*github.Repo{
+			"expressjs/express": {FullName: "expressjs/express", Description: "web framework", Stars: 60000, PushedAt: recent},
+			"stevemao/left-pad": {FullName: "stevemao/left-pad", PushedAt: time.Now().AddDate(-3, 0, 0)},
+			"babel/babel":       {FullName: "babel/babel", Stars: 40000, PushedAt: recent},
+			"mochajs/mocha":     {FullName: "mochajs/mocha", PushedAt: recent, Archived: true},
+			"pkg/errors":        {FullName: "pkg/errors", Stars: 8000, PushedAt: time.Now().AddDate(-2, 0, 0), Archived: true},
+		},
+		owners: map[string][]github.Contributor{
+			"stevemao/left-pad": {{Login: "stevemao", Commits: 40}},
+		},
+	}
+
+	deps := &DependencyAnalysis{}
+	deps.AddFiles(
+		DependencyFile{Filename: "package.json", FileType: "npm", Dependencies: []Dependency{
+			{Name: "express", Version: "^4.18.0", Type: "production"},
+			{Name: "left-pad", Version: "1.3.0", Type: "production"},
+			{Name: "@babel/core", Version: "^7.0.0", Type: "dev"},
+			{Name: "@babel/parser", Version: "^7.0.0", Type: "dev"},
+			{Name: "mocha", Version: "^10.0.0", Type: "dev"},
+			{Name: "private-pkg", Version: "1.0.0", Type: "production"},
+			{Name: "ms", Version: "2.1.3", Type: "production", Transitive: true},
+		}},
+		DependencyFile{Filename: "go.mod", FileType: "go", Dependencies: []Dependency{
+			{Name: "github.com/pkg/errors", Version: "v0.9.1", Type: "production"},
+			{Name: "example.com/internal/lib", Version: "v1.0.0", Type: "production"},
+		}},
+	)
+
+	opts := DefaultUpstreamOptions()
+	opts.Registry = RegistryOptions{NPMURL: server.URL, PyPIURL: server.URL}
+	opts.Concurrency = 2
+	return client, deps, opts
+}
+
+func TestCheckUpstreamHealth(t *testing.T) {
+	client, deps, opts := upstreamTestSetup(t)
+	cache := &memoryUpstreamCache{entries: make(map[string]*UpstreamHealth)}
+	opts.Cache = cache
+
+	result := CheckUpstreamHealth(client, deps, opts)
+	byName := make(map[string]DependencyUpstream)
+	for _, p := range result.Packages {
+		byName[p.Name] = p
+	}
+	if len(result.Packages) != 8 || byName["ms"].Name != "" {
+		t.Fatalf("direct dependencies only: %+v", result.Packages)
+	}
+	// babel/babel is analyzed once for both packages
+	if result.Mapped != 6 || result.Analyzed != 5 || result.RequestsUsed != 15 || client.requests != 15 {
+		t.Errorf("mapped %d, analyzed %d, requests %d/%d", result.Mapped, result.Analyzed, result.RequestsUsed, client.requests)
+	}
+
+	if p := byName["left-pad"]; p.Risk != "HIGH" || strings.Join(p.Risks, ",") != "single maintainer,stale" || p.MappedBy != "registry" {
+		t.Errorf("left-pad = %+v", p)
+	}
+	if p := byName["github.com/pkg/errors"]; p.Risk != "HIGH" || p.Repository != "pkg/errors" || p.MappedBy != "module path" ||
+		!contains(p.Risks, UpstreamArchived) {
+		t.Errorf("pkg/errors = %+v", p)
+	}
+	if p := byName["express"]; p.Risk != "LOW" || p.Health.HealthScore != 100 || p.Health.RecentCommits != 20 {
+		t.Errorf("express = %+v %+v", p, p.Health)
+	}
+	if p := byName["@babel/parser"]; p.Repository != "babel/babel" || p.Health == nil || !p.Dev {
+		t.Errorf("@babel/parser = %+v", p)
+	}
+	if p := byName["private-pkg"]; p.Risk != "UNKNOWN" || p.Repository != "" {
+		t.Errorf("private-pkg = %+v", p)
+	}
+	if result.ArchivedCount != 2 || result.SingleMaintainer != 1 || result.StaleCount != 2 || len(result.AtRisk()) != 3 {
+		t.Errorf("counts = %+v", result)
+	}
+	// Production dependencies come before dev ones at the same risk
+	if result.Packages[0].Name != "github.com/pkg/errors" || result.Packages[1].Name != "left-pad" {
+		t.Errorf("order = %s, %s", result.Packages[0].Name, result.Packages[1].Name)
+	}
+
+	// A second run is served from the cache
+	client.requests = 0
+	again := CheckUpstreamHealth(client, deps, opts)
+	if client.requests != 0 || again.Cached != 5 || again.Analyzed != 0 || len(again.AtRisk()) != 3 {
+		t.Errorf("cached run: requests %d, %+v", client.requests, again)
+	}
+}
+
+func TestCheckUpstreamHealthBudget(t *testing.T) {
+	client, deps, opts := upstreamTestSetup(t)
+	opts.Budget = 8 // two repositories
+
+	result := CheckUpstreamHealth(client, deps, opts)
+	if result.Analyzed != 2 || result.RequestsUsed != 6 || result.Skipped != 4 {
+		t.Errorf("result = %+v", result)
+	}
+	// Production dependencies are analyzed first, in name order
+	for _, p := range result.Packages {
+		analyzed := p.Health != nil
+		want := p.Name == "express" || p.Name == "github.com/pkg/errors"
+		if analyzed != want {
+			t.Errorf("%s analyzed = %v", p.Name, analyzed)
+		}
+	}
+
+	none := CheckUpstreamHealth(nil, deps, DefaultUpstreamOptions())
+	if none.Analyzed != 0 || none.RequestsUsed != 0 {
+		t.Errorf("without a client nothing is analyzed: %+v", none)
+	}
+}
+
+func TestCheckUpstreamHealthErrors(t *testing.T) {
+	client, deps, opts := upstreamTestSetup(t)
+	delete(client.repos, "mochajs/mocha")
+
+	result := CheckUpstreamHealth(client, deps, opts)
+	var mocha DependencyUpstream
+	for _, p := range result.Packages {
+		if p.Name == "mocha" {
+			mocha = p
+		}
+	}
+	if mocha.Error == "" || mocha.Risk != "UNKNOWN" || len(result.Errors) != 1 || !strings.HasPrefix(result.Errors[0], "mochajs/mocha") {
+		t.Errorf("mocha = %+v, errors = %v", mocha, result.Errors)
+	}
+	if result.RequestsUsed != 13 {
+		t.Errorf("a failed analysis stops after the first request: %d", result.RequestsUsed)
+	}
+}
@@ -0,0 +1,270 @@
+package analyzer
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// This file implements version ordering for the package ecosystems that
+// OSV advisories cover: Semantic Versioning 2.0 (npm, crates.io, Pub,
+// NuGet), Go module versions, and PEP 440 for PyPI. Other ecosystems fall
+// back to compareVersions.
+
+// CompareEcosystemVersions orders two versions of a package using the rules
+// of its OSV ecosystem. It returns -1, 0 or 1.
+func CompareEcosystemVersions(ecosystem, a, b string) int {
+	switch ecosystem {
+	case "PyPI":
+		return comparePEP440(a, b)
+	case "Go":
+		return compareGoVersions(a, b)
+	case "npm", "crates.io", "Pub", "NuGet":
+		return compareSemver(a, b)
+	}
+	return compareVersions(a, b)
+}
+
+// compareSemver orders versions by Semantic Versioning 2.0 precedence.
+// Build metadata is ignored, and missing minor or patch numbers count as 0
+// so NuGet's four-part versions also compare sensibly.
+func compareSemver(a, b string) int {
+	a, _, _ = strings.Cut(strings.TrimPrefix(strings.TrimSpace(a), "v"), "+")
+	b, _, _ = strings.Cut(strings.TrimPrefix(strings.TrimSpace(b), "v"), "+")
+	aCore, aPre, aHasPre := strings.Cut(a, "-")
+	bCore, bPre, bHasPre := strings.Cut(b, "-")
+
+	aParts, bParts := strings.Split(aCore, "."), strings.Split(bCore, ".")
+	for i := 0; i < len(aParts) || i < len(bParts); i++ {
+		x, y := "0", "0"
+		if i < len(aParts) {
+			x = aParts[i]
+		}
+		if i < len(bParts) {
+			y = bParts[i]
+		}
+		if c := compareIdentifiers(x, y); c != 0 {
+			return c
+		}
+	}
+
+	// A pre-release has lower precedence than the release itself
+	switch {
+	case !aHasPre && !bHasPre:
+		return 0
+	case !aHasPre:
+		return 1
+	case !bHasPre:
+		return -1
+	}
+	aIDs, bIDs := strings.Split(aPre, "."), strings.Split(bPre, ".")
+	for i := 0; i < len(aIDs) && i < len(bIDs); i++ {
+		if c := compareIdentifiers(aIDs[i], bIDs[i]); c != 0 {
+			return c
+		}
+	}
+	return compareInts(len(aIDs), len(bIDs))
+}
+
+// compareIdentifiers compares semver identifiers: numbers numerically,
+// numbers before alphanumerics, alphanumerics in ASCII order
+func compareIdentifiers(x, y string) int {
+	xNum, yNum := isDigits(x), isDigits(y)
+	switch {
+	case xNum && yNum:
+		// Compare by length first so long numbers cannot overflow
+		x, y = strings.TrimLeft(x, "0"), strings.TrimLeft(y, "0")
+		if len(x) != len(y) {
+			return compareInts(len(x), len(y))
+		}
+		return strings.Compare(x, y)
+	case xNum:
+		return -1
+	case yNum:
+		return 1
+	}
+	return strings.Compare(x, y)
+}
+
+// compareGoVersions orders Go module versions. These are semver with a "v"
+// prefix; pseudo-versions are pre-releases and "+incompatible" is build
+// metadata, so both fall out of semver precedence.
+func compareGoVersions(a, b string) int {
+	return compareSemver(a, b)
+}
+
+// pep440Pattern is the version pattern from PEP 440, Appendix B
+var pep440Pattern = regexp.MustCompile(`(?i)^\s*v?(?:(?:([0-9]+)!)?([0-9]+(?:\.[0-9]+)*)` +
+	`([-_.]?(a|b|c|rc|alpha|beta|pre|preview)[-_.]?([0-9]+)?)?` +
+	`((?:-([0-9]+))|(?:[-_.]?(post|rev|r)[-_.]?([0-9]+)?))?` +
+	`([-_.]?(dev)[-_.]?([0-9]+)?)?)` +
+	`(?:\+([a-z0-9]+(?:[-_.][a-z0-9]+)*))?\s*$`)
+
+// pep440Version is a parsed PEP 440 version. Absent optional parts are -1.
+type pep440Version struct {
+	epoch   int
+	release []int
+	preKind int // 0 alpha, 1 beta, 2 release candidate; -1 when absent
+	preNum  int
+	post    int
+	dev     int
+	local   []string
+}
+
+// parsePEP440 parses and normalizes a PEP 440 version
+func parsePEP440(s string) (pep440Version, bool) {
+	m := pep440Pattern.FindStringSubmatch(s)
+	if m == nil {
+		return pep440Version{}, false
+	}
+	v := pep440Version{preKind: -1, post: -1, dev: -1}
+	v.epoch, _ = strconv.Atoi(m[1])
+	for _, part := range strings.Split(m[2], ".") {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return pep440Version{}, false
+		}
+		v.release = append(v.release, n)
+	}
+	// Trailing zeros do not change the version: 1.0 == 1.0.0
+	for len(v.release) > 1 && v.release[len(v.release)-1] == 0 {
+		v.release = v.release[:len(v.release)-1]
+	}
+
+	if m[3] != "" {
+		switch strings.ToLower(m[4]) {
+		case "a", "alpha":
+			v.preKind = 0
+		case "b", "beta":
+			v.preKind = 1
+		default: // c, rc, pre, preview
+			v.preKind = 2
+		}
+		v.preNum, _ = strconv.Atoi(m[5])
+	}
+	switch {
+	case m[7] != "":
+		v.post, _ = strconv.Atoi(m[7])
+	case m[6] != "":
+		v.post, _ = strconv.Atoi(m[9])
+	}
+	if m[10] != "" {
+		v.dev, _ = strconv.Atoi(m[12])
+	}
+	if m[13] != "" {
+		v.local = strings.FieldsFunc(strings.ToLower(m[13]), func(r rune) bool {
+			return r == '-' || r == '_' || r == '.'
+		})
+	}
+	return v, true
+}
+
+// comparePEP440 orders versions as pip does. Unparseable versions fall
+// back to compareVersions.
+func comparePEP440(a, b string) int {
+	x, okX := parsePEP440(a)
+	y, okY := parsePEP440(b)
+	if !okX || !okY {
+		return compareVersions(a, b)
+	}
+
+	if c := compareInts(x.epoch, y.epoch); c != 0 {
+		return c
+	}
+	for i := 0; i < len(x.release) || i < len(y.release); i++ {
+		var p, q int
+		if i < len(x.release) {
+			p = x.release[i]
+		}
+		if i < len(y.release) {
+			q = y.release[i]
+		}
+		if c := compareInts(p, q); c != 0 {
+			return c
+		}
+	}
+	if c := compareInts(x.preRank(), y.preRank()); c != 0 {
+		return c
+	}
+	if x.preKind >= 0 && y.preKind >= 0 {
+		if c := compareInts(x.preNum, y.preNum); c != 0 {
+			return c
+		}
+	}
+	// No post-release sorts first; no dev release sorts last
+	if c := compareInts(x.post, y.post); c != 0 {
+		return c
+	}
+	if c := compareInts(devRank(x.dev), devRank(y.dev)); c != 0 {
+		return c
+	}
+	return compareLocal(x.local, y.local)
+}
+
+// preRank places a version's pre-release phase: a bare dev release of a
+// final version (1.0.dev1) sorts before its pre-releases, and the final
+// version after them
+func (v pep440Version) preRank() int {
+	switch {
+	case v.preKind >= 0:
+		return v.preKind
+	case v.post < 0 && v.dev >= 0:
+		return -1
+	}
+	return 3
+}
+
+// devRank sorts a release without a dev segment after its dev releases
+func devRank(dev int) int {
+	if dev < 0 {
+		return int(^uint(0) >> 1)
+	}
+	return dev
+}
+
+// compareLocal orders local version labels: no label first, numeric
+// segments after alphanumeric ones, then by length
+func compareLocal(x, y []string) int {
+	for i := 0; i < len(x) && i < len(y); i++ {
+		xNum, yNum := isDigits(x[i]), isDigits(y[i])
+		switch {
+		case xNum && yNum:
+			p, _ := strconv.Atoi(x[i])
+			q, _ := strconv.Atoi(y[i])
+			if c := compareInts(p, q); c != 0 {
+				return c
+			}
+		case xNum:
+			return 1
+		case yNum:
+			return -1
+		default:
+			if c := strings.Compare(x[i], y[i]); c != 0 {
+				return c
+			}
+		}
+	}
+	return compareInts(len(x), len(y))
+}
+
+func compareInts(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
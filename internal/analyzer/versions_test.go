@@ -0,0 +1,52 @@
+package analyzer
+
+import "testing"
+
+func TestCompareEcosystemVersions(t *testing.T) {
+	testCases := []struct {
+		ecosystem, a, b string
+		want            int
+	}{
+		// Semantic Versioning 2.0 precedence
+		{"npm", "1.0.0-alpha", "1.0.0-alpha.1", -1},
+		{"npm", "1.0.0-alpha.1", "1.0.0-alpha.beta", -1},
+		{"npm", "1.0.0-beta.2", "1.0.0-beta.11", -1},
+		{"npm", "1.0.0-rc.1", "1.0.0", -1},
+		{"npm", "1.0.0+build.5", "1.0.0", 0},
+		{"crates.io", "0.10.0", "0.9.9", 1},
+		{"npm", "18446744073709551616.0.0", "18446744073709551615.0.0", 1},
+		{"NuGet", "4.0.0.1", "4.0.0", 1},
+
+		// Go module versions, including pseudo-versions
+		{"Go", "v0.0.0-20230101000000-abcdef123456", "v0.0.0-20240101000000-abcdef123456", -1},
+		{"Go", "v1.2.4-0.20230101000000-abcdef123456", "v1.2.4", -1},
+		{"Go", "v1.2.4-0.20230101000000-abcdef123456", "v1.2.3", 1},
+		{"Go", "v2.0.0+incompatible", "v2.0.0", 0},
+
+		// PEP 440
+		{"PyPI", "1.0.dev1", "1.0a1", -1},
+		{"PyPI", "1.0a1", "1.0b1", -1},
+		{"PyPI", "1.0b2", "1.0rc1", -1},
+		{"PyPI", "1.0rc1", "1.0", -1},
+		{"PyPI", "1.0", "1.0.post1", -1},
+		{"PyPI", "1.0.post1.dev1", "1.0.post1", -1},
+		{"PyPI", "1.0", "1.0.0", 0},
+		{"PyPI", "1.0-1", "1.0.post1", 0},
+		{"PyPI", "1.0alpha1", "1.0a1", 0},
+		{"PyPI", "1!0.1", "2.0", 1},
+		{"PyPI", "1.0+abc", "1.0", 1},
+		{"PyPI", "1.0+5", "1.0+abc", 1},
+		{"PyPI", "2.0.0", "10.0", -1},
+
+		// Fallback
+		{"Maven", "2.10.0", "2.9.1", 1},
+	}
+	for _, tc := range testCases {
+		if got := CompareEcosystemVersions(tc.ecosystem, tc.a, tc.b); got != tc.want {
+			t.Errorf("%s: compare(%q, %q) = %d, want %d", tc.ecosystem, tc.a, tc.b, got, tc.want)
+		}
+		if got := CompareEcosystemVersions(tc.ecosystem, tc.b, tc.a); got != -tc.want {
+			t.Errorf("%s: compare(%q, %q) = %d, want %d", tc.ecosystem, tc.b, tc.a, got, -tc.want)
+		}
+	}
+}
@@ -0,0 +1,404 @@
+// Package analyzer provides analysis functions for GitHub repositories.
+// This file implements the offline vulnerability database, a local copy of
+// the OSV ecosystem dumps used when api.osv.dev cannot be reached.
+package analyzer
+
+import (
+	"archive/zip"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// vulnDBDumpURL is where OSV publishes a zip of all advisories per ecosystem
+const vulnDBDumpURL = "https://osv-vulnerabilities.storage.googleapis.com/%s/all.zip"
+
+// VulnDB is a local store of OSV advisories. Each ecosystem is kept in its
+// own gzipped index keyed by package name, and loaded on first use.
+type VulnDB struct {
+	Dir  string
+	Meta VulnDBMeta
+
+	mu      sync.Mutex
+	indexes map[string]map[string][]osvVuln
+}
+
+// VulnDBMeta records what has been imported, stored as meta.json
+type VulnDBMeta struct {
+	Ecosystems map[string]VulnDBEcosystem `json:"ecosystems"`
+}
+
+// VulnDBEcosystem describes the imported advisories of one ecosystem
+type VulnDBEcosystem struct {
+	UpdatedAt  time.Time `json:"updated_at"`
+	Source     string    `json:"source"`
+	Advisories int       `json:"advisories"`
+	Packages   int       `json:"packages"`
+}
+
+// VulnDBEcosystems returns the OSV ecosystems the scanner can query
+func VulnDBEcosystems() []string {
+	seen := make(map[string]bool)
+	var ecosystems []string
+	for _, fileType := range []string{"npm", "go", "python", "rust", "ruby", "maven", "nuget", "composer", "pub"} {
+		if eco := mapEcosystem(fileType); !seen[eco] {
+			seen[eco] = true
+			ecosystems = append(ecosystems, eco)
+		}
+	}
+	return ecosystems
+}
+
+// VulnDBDumpURL returns the URL of the OSV dump for an ecosystem
+func VulnDBDumpURL(ecosystem string) string {
+	return fmt.Sprintf(vulnDBDumpURL, url.PathEscape(ecosystem))
+}
+
+// OpenVulnDB opens the database in dir. A missing database opens empty.
+func OpenVulnDB(dir string) (*VulnDB, error) {
+	db := &VulnDB{
+		Dir:     dir,
+		Meta:    VulnDBMeta{Ecosystems: make(map[string]VulnDBEcosystem)},
+		indexes: make(map[string]map[string][]osvVuln),
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "meta.json"))
+	if errors.Is(err, os.ErrNotExist) {
+		return db, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &db.Meta); err != nil {
+		return nil, fmt.Errorf("reading vulnerability database: %w", err)
+	}
+	if db.Meta.Ecosystems == nil {
+		db.Meta.Ecosystems = make(map[string]VulnDBEcosystem)
+	}
+	return db, nil
+}
+
+// Empty reports whether nothing has been imported yet
+func (db *VulnDB) Empty() bool {
+	return db == nil || len(db.Meta.Ecosystems) == 0
+}
+
+// HasEcosystem reports whether advisories for an ecosystem were imported
+func (db *VulnDB) HasEcosystem(ecosystem string) bool {
+	if db == nil {
+		return false
+	}
+	_, ok := db.Meta.Ecosystems[ecosystem]
+	return ok
+}
+
+// UpdatedAt returns when the least recently synced ecosystem was imported
+func (db *VulnDB) UpdatedAt() time.Time {
+	var oldest time.Time
+	if db == nil {
+		return oldest
+	}
+	for _, eco := range db.Meta.Ecosystems {
+		if oldest.IsZero() || eco.UpdatedAt.Before(oldest) {
+			oldest = eco.UpdatedAt
+		}
+	}
+	return oldest
+}
+
+// Sync imports an OSV zip dump from a file path or an http(s) URL and
+// returns the number of advisories imported per ecosystem. Ecosystems in
+// the dump replace what was stored for them before.
+func (db *VulnDB) Sync(source string, client *http.Client) (map[string]int, error) {
+	if !strings.HasPrefix(source, "http://") && !strings.HasPrefix(source, "https://") {
+		return db.ImportZip(source, source)
+	}
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Minute}
+	}
+
+	resp, err := client.Get(source)
+	if err != nil {
+		return nil, fmt.Errorf("downloading %s: %w", source, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("downloading %s: %s", source, resp.Status)
+	}
+
+	// zip needs random access, so the dump is spooled to disk first
+	tmp, err := os.CreateTemp("", "osv-*.zip")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+	if _, err := io.Copy(tmp, resp.Body); err != nil {
+		return nil, fmt.Errorf("downloading %s: %w", source, err)
+	}
+	return db.ImportZip(tmp.Name(), source)
+}
+
+// ImportZip imports the advisories in an OSV zip dump. Withdrawn
+// advisories and ecosystems the scanner does not query are skipped.
+func (db *VulnDB) ImportZip(path, source string) (map[string]int, error) {
+	archive, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening %s: %w", path, err)
+	}
+	defer archive.Close()
+
+	supported := make(map[string]bool)
+	for _, eco := range VulnDBEcosystems() {
+		supported[eco] = true
+	}
+
+	indexes := make(map[string]map[string][]osvVuln)
+	counts := make(map[string]int)
+	for _, f := range archive.File {
+		if !strings.HasSuffix(f.Name, ".json") {
+			continue
+		}
+		vuln, err := readZipAdvisory(f)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", f.Name, err)
+		}
+		if vuln.Withdrawn != "" {
+			continue
+		}
+
+		// Store a copy per affected package holding only its own entries
+		byPackage := make(map[[2]string][]osvAffected)
+		var order [][2]string
+		for _, a := range vuln.Affected {
+			if !supported[a.Package.Ecosystem] || a.Package.Name == "" {
+				continue
+			}
+			key := [2]string{a.Package.Ecosystem, osvPackageKey(a.Package.Ecosystem, a.Package.Name)}
+			if _, ok := byPackage[key]; !ok {
+				order = append(order, key)
+			}
+			byPackage[key] = append(byPackage[key], a)
+		}
+		counted := make(map[string]bool)
+		for _, key := range order {
+			eco, name := key[0], key[1]
+			if indexes[eco] == nil {
+				indexes[eco] = make(map[string][]osvVuln)
+			}
+			entry := vuln
+			entry.Affected = byPackage[key]
+			indexes[eco][name] = append(indexes[eco][name], entry)
+			if !counted[eco] {
+				counted[eco] = true
+				counts[eco]++
+			}
+		}
+	}
+	if len(indexes) == 0 {
+		return nil, fmt.Errorf("%s contains no advisories for supported ecosystems", path)
+	}
+
+	if err := os.MkdirAll(db.Dir, 0755); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	for eco, index := range indexes {
+		if err := writeVulnIndex(db.indexPath(eco), index); err != nil {
+			return nil, err
+		}
+		db.mu.Lock()
+		db.indexes[eco] = index
+		db.mu.Unlock()
+		db.Meta.Ecosystems[eco] = VulnDBEcosystem{
+			UpdatedAt:  now,
+			Source:     source,
+			Advisories: counts[eco],
+			Packages:   len(index),
+		}
+	}
+
+	data, err := json.MarshalIndent(db.Meta, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := writeFileAtomic(filepath.Join(db.Dir, "meta.json"), data); err != nil {
+		return nil, err
+	}
+	return counts, nil
+}
+
+// readZipAdvisory decodes one advisory from a dump
+func readZipAdvisory(f *zip.File) (osvVuln, error) {
+	var vuln osvVuln
+	r, err := f.Open()
+	if err != nil {
+		return vuln, err
+	}
+	defer r.Close()
+	err = json.NewDecoder(r).Decode(&vuln)
+	return vuln, err
+}
+
+// indexPath returns the file holding an ecosystem's index
+func (db *VulnDB) indexPath(ecosystem string) string {
+	return filepath.Join(db.Dir, url.PathEscape(ecosystem)+".json.gz")
+}
+
+// writeVulnIndex stores an index as gzipped JSON
+func writeVulnIndex(path string, index map[string][]osvVuln) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".index-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	zw := gzip.NewWriter(tmp)
+	if err := json.NewEncoder(zw).Encode(index); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// writeFileAtomic replaces path with data without leaving a partial file
+func writeFileAtomic(path string, data []byte) error {
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// loadIndex returns an ecosystem's index, reading it on first use
+func (db *VulnDB) loadIndex(ecosystem string) (map[string][]osvVuln, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if index, ok := db.indexes[ecosystem]; ok {
+		return index, nil
+	}
+
+	f, err := os.Open(db.indexPath(ecosystem))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, err
+	}
+	var index map[string][]osvVuln
+	if err := json.NewDecoder(zr).Decode(&index); err != nil {
+		return nil, fmt.Errorf("reading %s advisories: %w", ecosystem, err)
+	}
+	db.indexes[ecosystem] = index
+	return index, nil
+}
+
+// Query returns the advisories affecting a package version. As with the
+// OSV API, an empty version matches every advisory for the package and a
+// version range matches none.
+func (db *VulnDB) Query(ecosystem, name, version string) ([]osvVuln, error) {
+	if !db.HasEcosystem(ecosystem) {
+		return nil, fmt.Errorf("no offline advisories for %s; run `repo-lyzer vulndb sync`", ecosystem)
+	}
+	index, err := db.loadIndex(ecosystem)
+	if err != nil {
+		return nil, err
+	}
+	if strings.ContainsAny(version, "<>=!*|, ") {
+		return nil, nil
+	}
+
+	var matches []osvVuln
+	for _, vuln := range index[osvPackageKey(ecosystem, name)] {
+		for _, a := range vuln.Affected {
+			if version == "" || affectsVersion(ecosystem, a, version) {
+				matches = append(matches, vuln)
+				break
+			}
+		}
+	}
+	return matches, nil
+}
+
+// osvPackageKey normalizes a package name for lookups. PyPI names are
+// case-insensitive and treat "-", "_" and "." alike; other ecosystems
+// compare names exactly.
+func osvPackageKey(ecosystem, name string) string {
+	if ecosystem == "PyPI" {
+		return normalizePythonName(name)
+	}
+	return name
+}
+
+// affectsVersion reports whether version is listed in, or falls inside a
+// SEMVER or ECOSYSTEM range of, an affected entry. GIT ranges name commits
+// and cannot be matched against versions.
+func affectsVersion(ecosystem string, a osvAffected, version string) bool {
+	if contains(a.Versions, version) {
+		return true
+	}
+	for _, r := range a.Ranges {
+		if r.Type != "GIT" && rangeAffects(ecosystem, r.Events, version) {
+			return true
+		}
+	}
+	return false
+}
+
+// rangeAffects evaluates an OSV range: the version is affected after an
+// "introduced" event it has reached, until a "fixed" or "limit" version it
+// has reached or a "last_affected" version it is past
+func rangeAffects(ecosystem string, events []osvEvent, version string) bool {
+	eventVersion := func(e osvEvent) string {
+		return e.Introduced + e.Fixed + e.LastAffected + e.Limit
+	}
+	sorted := append([]osvEvent(nil), events...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := eventVersion(sorted[i]), eventVersion(sorted[j])
+		if a == "0" || b == "0" {
+			return a == "0" && b != "0"
+		}
+		return CompareEcosystemVersions(ecosystem, a, b) < 0
+	})
+
+	affected := false
+	for _, e := range sorted {
+		switch {
+		case e.Introduced != "":
+			if e.Introduced == "0" || CompareEcosystemVersions(ecosystem, version, e.Introduced) >= 0 {
+				affected = true
+			}
+		case e.Fixed != "":
+			if CompareEcosystemVersions(ecosystem, version, e.Fixed) >= 0 {
+				affected = false
+			}
+		case e.LastAffected != "":
+			if CompareEcosystemVersions(ecosystem, version, e.LastAffected) > 0 {
+				affected = false
+			}
+		case e.Limit != "" && e.Limit != "*":
+			if CompareEcosystemVersions(ecosystem, version, e.Limit) >= 0 {
+				affected = false
+			}
+		}
+	}
+	return affected
+}
@@ -0,0 +1,169 @@
+package analyzer
+
+import (
+	"archive/zip"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// testAdvisories are OSV records keyed by their file name in the dump
+var testAdvisories = map[string]string{
+	"GHSA-lodash.json": `{"id": "GHSA-lodash", "summary": "Prototype pollution",
+		"affected": [{"package": {"ecosystem": "npm", "name": "lodash"},
+			"ranges": [{"type": "SEMVER", "events": [{"introduced": "0"}, {"fixed": "4.17.21"}]}]}]}`,
+	"GHSA-minimist.json": `{"id": "GHSA-minimist",
+		"affected": [{"package": {"ecosystem": "npm", "name": "minimist"}, "versions": ["0.0.8"],
+			"ranges": [{"type": "SEMVER", "events": [{"introduced": "1.0.0"}, {"last_affected": "1.2.5"}]}]}]}`,
+	"PYSEC-django.json": `{"id": "PYSEC-django",
+		"affected": [{"package": {"ecosystem": "PyPI", "name": "Django"},
+			"ranges": [{"type": "ECOSYSTEM", "events": [
+				{"introduced": "5.0"}, {"fixed": "5.0.3"}, {"introduced": "4.0"}, {"fixed": "4.2.11"}]}]}]}`,
+	"GO-net.json": `{"id": "GO-net",
+		"affected": [{"package": {"ecosystem": "Go", "name": "golang.org/x/net"},
+			"ranges": [{"type": "SEMVER", "events": [{"introduced": "0"}, {"fixed": "0.17.0"}]},
+				{"type": "GIT", "events": [{"introduced": "abc123"}]}]}]}`,
+	"GHSA-withdrawn.json": `{"id": "GHSA-withdrawn", "withdrawn": "2024-01-01T00:00:00Z",
+		"affected": [{"package": {"ecosystem": "npm", "name": "lodash"}, "ranges": [{"type": "SEMVER", "events": [{"introduced": "0"}]}]}]}`,
+	"DSA-1.json": `{"id": "DSA-1",
+		"affected": [{"package": {"ecosystem": "Debian:12", "name": "openssl"}, "versions": ["3.0.0"]}]}`,
+}
+
+// writeTestDump writes the advisories to a zip like OSV's all.zip
+func writeTestDump(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "all.zip")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := zip.NewWriter(f)
+	for _, name := range sortedKeys(testAdvisories) {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(testAdvisories[name]))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	return path
+}
+
+func syncTestDB(t *testing.T) *VulnDB {
+	t.Helper()
+	dir := filepath.Join(t.TempDir(), "vulndb")
+	db, err := OpenVulnDB(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !db.Empty() {
+		t.Fatal("a new database should be empty")
+	}
+	counts, err := db.Sync(writeTestDump(t), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if counts["npm"] != 2 || counts["PyPI"] != 1 || counts["Go"] != 1 || len(counts) != 3 {
+		t.Errorf("imported %v", counts)
+	}
+
+	// Reopen so queries read the stored indexes
+	db, err = OpenVulnDB(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestVulnDBQuery(t *testing.T) {
+	db := syncTestDB(t)
+	if db.UpdatedAt().IsZero() || !db.HasEcosystem("Go") || db.HasEcosystem("Maven") {
+		t.Errorf("meta = %+v", db.Meta)
+	}
+
+	testCases := []struct {
+		ecosystem, name, version string
+		want                     string
+	}{
+		{"npm", "lodash", "4.17.20", "GHSA-lodash"},
+		{"npm", "lodash", "4.17.21", ""},
+		{"npm", "minimist", "1.2.5", "GHSA-minimist"},
+		{"npm", "minimist", "1.2.6", ""},
+		{"npm", "minimist", "0.0.8", "GHSA-minimist"},
+		{"npm", "minimist", "0.0.9", ""},
+		{"PyPI", "django", "4.2.10", "PYSEC-django"},
+		{"PyPI", "Django", "4.2.11", ""},
+		{"PyPI", "django", "5.0rc1", ""},
+		{"PyPI", "django", "5.0.2", "PYSEC-django"},
+		{"PyPI", "django", "3.2", ""},
+		{"Go", "golang.org/x/net", "v0.0.0-20220722155237-a158d28d115b", "GO-net"},
+		{"Go", "golang.org/x/net", "v0.17.0", ""},
+		{"npm", "lodash", "", "GHSA-lodash"}, // no version: every advisory
+		{"npm", "lodash", ">=4.0.0", ""},     // a range cannot be matched
+	}
+	for _, tc := range testCases {
+		vulns, err := db.Query(tc.ecosystem, tc.name, tc.version)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var ids []string
+		for _, v := range vulns {
+			ids = append(ids, v.ID)
+		}
+		if got := strings.Join(ids, ","); got != tc.want {
+			t.Errorf("Query(%s, %s, %s) = %q, want %q", tc.ecosystem, tc.name, tc.version, got, tc.want)
+		}
+	}
+
+	if _, err := db.Query("Maven", "org.example:lib", "1.0"); err == nil {
+		t.Error("querying an ecosystem that was not synced should fail")
+	}
+}
+
+func TestScanDependenciesOffline(t *testing.T) {
+	db := syncTestDB(t)
+	deps := &DependencyAnalysis{}
+	deps.AddFiles(
+		DependencyFile{Filename: "package.json", FileType: "npm", Dependencies: []Dependency{
+			{Name: "lodash", Version: "^4.17.0", Resolved: "4.17.20"},
+			{Name: "minimist", Version: "1.2.6"},
+		}},
+		DependencyFile{Filename: "pom.xml", FileType: "maven", Dependencies: []Dependency{
+			{Name: "org.example:lib", Version: "1.0"},
+		}},
+	)
+
+	opts := DefaultOSVScanOptions()
+	opts.VulnDB = db
+	opts.Offline = true
+	result, err := ScanDependenciesWith(deps, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Source != "offline database" || result.DatabaseUpdated.IsZero() {
+		t.Errorf("source = %q, updated %v", result.Source, result.DatabaseUpdated)
+	}
+	if result.TotalCount != 1 || result.Vulnerabilities[0].FixedIn != "4.17.21" {
+		t.Errorf("vulnerabilities = %+v", result.Vulnerabilities)
+	}
+	// Maven was not synced, so the scan is incomplete rather than clean
+	if result.FailedPackages != 1 || !strings.Contains(result.Errors[0], "Maven") {
+		t.Errorf("failed = %d, errors = %v", result.FailedPackages, result.Errors)
+	}
+
+	// An unreachable API falls back to the offline database
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+	opts.Offline = false
+	opts.BaseURL = server.URL
+	result, _ = ScanDependenciesWith(deps, opts)
+	if result.Source != "offline database" || result.TotalCount != 1 || !strings.Contains(result.Errors[0], "unreachable") {
+		t.Errorf("fallback result = %+v", result)
+	}
+}
@@ -0,0 +1,304 @@
+package analyzer
+
+import (
+	"strings"
+)
+
+// This file implements a small YAML reader that covers the subset used by
+// Kubernetes manifests, Helm values and package manager lockfiles: block
+// mappings and sequences, quoted and plain scalars, simple flow collections,
+// block scalars and multiple documents. Scalars are always returned as
+// strings; anchors, tags and complex keys are not supported.
+
+// yamlDocument is a single parsed document with the line it starts on
+type yamlDocument struct {
+	Line  int
+	Value interface{}
+}
+
+// yamlLine is a significant (non-blank, non-comment) line of input
+type yamlLine struct {
+	indent int
+	text   string
+	num    int
+}
+
+// parseYAMLDocuments parses every document in content. Lines consisting only
+// of Helm/Go template actions are ignored so chart templates can be read.
+func parseYAMLDocuments(content string) []yamlDocument {
+	var docs []yamlDocument
+	var lines []yamlLine
+	start := 1
+
+	flush := func() {
+		if len(lines) > 0 {
+			value, _ := parseYAMLNode(lines, 0)
+			docs = append(docs, yamlDocument{Line: start, Value: value})
+		}
+		lines = nil
+	}
+
+	rawLines := strings.Split(content, "\n")
+	for i := 0; i < len(rawLines); i++ {
+		raw := strings.TrimRight(rawLines[i], "\r")
+		trimmed := strings.TrimSpace(raw)
+
+		if trimmed == "---" || strings.HasPrefix(trimmed, "--- ") || trimmed == "..." {
+			flush()
+			start = i + 2
+			continue
+		}
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") ||
+			(strings.HasPrefix(trimmed, "{{") && strings.HasSuffix(trimmed, "}}")) {
+			continue
+		}
+
+		indent := len(raw) - len(strings.TrimLeft(raw, " "))
+		text := stripYAMLComment(trimmed)
+
+		// Block scalars keep their raw lines, so gather them here
+		if key, val, ok := splitYAMLKey(text); ok && (strings.HasPrefix(val, "|") || strings.HasPrefix(val, ">")) {
+			var block []string
+			for i+1 < len(rawLines) {
+				next := strings.TrimRight(rawLines[i+1], "\r")
+				nextIndent := len(next) - len(strings.TrimLeft(next, " "))
+				if strings.TrimSpace(next) != "" && nextIndent <= indent {
+					break
+				}
+				block = append(block, strings.TrimSpace(next))
+				i++
+			}
+			sep := "\n"
+			if strings.HasPrefix(val, ">") {
+				sep = " "
+			}
+			text = key + ": " + quoteYAMLScalar(strings.TrimSpace(strings.Join(block, sep)))
+		}
+
+		if len(lines) == 0 {
+			start = i + 1
+		}
+		lines = append(lines, yamlLine{indent: indent, text: text, num: i + 1})
+	}
+	flush()
+
+	return docs
+}
+
+// parseYAMLNode parses the block starting at lines[i]
+func parseYAMLNode(lines []yamlLine, i int) (interface{}, int) {
+	if i >= len(lines) {
+		return nil, i
+	}
+	if isYAMLSeqItem(lines[i].text) {
+		return parseYAMLSeq(lines, i, lines[i].indent)
+	}
+	if _, _, ok := splitYAMLKey(lines[i].text); ok {
+		return parseYAMLMap(lines, i, lines[i].indent)
+	}
+	return parseYAMLScalar(lines[i].text), i + 1
+}
+
+// parseYAMLMap parses a block mapping whose keys sit at the given indent
+func parseYAMLMap(lines []yamlLine, i, indent int) (map[string]interface{}, int) {
+	m := make(map[string]interface{})
+	for i < len(lines) && lines[i].indent == indent && !isYAMLSeqItem(lines[i].text) {
+		key, val, ok := splitYAMLKey(lines[i].text)
+		if !ok {
+			i++
+			continue
+		}
+		if val != "" {
+			m[key] = parseYAMLScalar(val)
+			i = skipYAMLContinuation(lines, i+1, indent)
+			continue
+		}
+
+		// Nested block: either more indented, or a sequence at the same indent
+		if i+1 < len(lines) && (lines[i+1].indent > indent ||
+			(lines[i+1].indent == indent && isYAMLSeqItem(lines[i+1].text))) {
+			m[key], i = parseYAMLNode(lines, i+1)
+		} else {
+			m[key] = nil
+			i++
+		}
+	}
+	return m, i
+}
+
+// parseYAMLSeq parses a block sequence whose dashes sit at the given indent
+func parseYAMLSeq(lines []yamlLine, i, indent int) ([]interface{}, int) {
+	seq := []interface{}{}
+	for i < len(lines) && lines[i].indent == indent && isYAMLSeqItem(lines[i].text) {
+		rest := strings.TrimLeft(lines[i].text[1:], " ")
+		if rest == "" {
+			var v interface{}
+			if i+1 < len(lines) && lines[i+1].indent > indent {
+				v, i = parseYAMLNode(lines, i+1)
+			} else {
+				i++
+			}
+			seq = append(seq, v)
+			continue
+		}
+
+		// "- key: value" starts a mapping indented to the column of key
+		if _, _, ok := splitYAMLKey(rest); ok || isYAMLSeqItem(rest) {
+			col := indent + len(lines[i].text) - len(rest)
+			lines[i] = yamlLine{indent: col, text: rest, num: lines[i].num}
+			var v interface{}
+			v, i = parseYAMLNode(lines, i)
+			seq = append(seq, v)
+			continue
+		}
+
+		seq = append(seq, parseYAMLScalar(rest))
+		i = skipYAMLContinuation(lines, i+1, indent)
+	}
+	return seq, i
+}
+
+// skipYAMLContinuation skips multi-line plain scalar continuations, which
+// are more indented than their key but carry no structure of their own
+func skipYAMLContinuation(lines []yamlLine, i, indent int) int {
+	for i < len(lines) && lines[i].indent > indent {
+		i++
+	}
+	return i
+}
+
+// isYAMLSeqItem reports whether a line is a sequence entry
+func isYAMLSeqItem(text string) bool {
+	return text == "-" || strings.HasPrefix(text, "- ")
+}
+
+// splitYAMLKey splits "key: value" outside of quotes and flow collections
+func splitYAMLKey(text string) (string, string, bool) {
+	if strings.HasPrefix(text, "[") || strings.HasPrefix(text, "{") {
+		return "", "", false
+	}
+	inQuote := byte(0)
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case inQuote != 0:
+			if c == inQuote {
+				inQuote = 0
+			}
+		case c == '"' || c == '\'':
+			if i == 0 {
+				inQuote = c
+			}
+		case c == ':' && (i+1 == len(text) || text[i+1] == ' '):
+			key := strings.Trim(strings.TrimSpace(text[:i]), `"'`)
+			return key, strings.TrimSpace(text[i+1:]), true
+		}
+	}
+	return "", "", false
+}
+
+// stripYAMLComment removes a trailing " # comment" outside of quotes
+func stripYAMLComment(text string) string {
+	inQuote := byte(0)
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case inQuote != 0:
+			if c == inQuote {
+				inQuote = 0
+			}
+		case c == '"' || c == '\'':
+			inQuote = c
+		case c == '#' && i > 0 && (text[i-1] == ' ' || text[i-1] == '\t'):
+			return strings.TrimSpace(text[:i])
+		}
+	}
+	return text
+}
+
+// parseYAMLScalar converts a scalar or simple flow collection
+func parseYAMLScalar(text string) interface{} {
+	text = strings.TrimSpace(text)
+	switch {
+	case strings.HasPrefix(text, "[") && strings.HasSuffix(text, "]"):
+		items := []interface{}{}
+		for _, part := range splitFlowItems(text[1 : len(text)-1]) {
+			items = append(items, parseYAMLScalar(part))
+		}
+		return items
+	case strings.HasPrefix(text, "{") && strings.HasSuffix(text, "}"):
+		m := make(map[string]interface{})
+		for _, part := range splitFlowItems(text[1 : len(text)-1]) {
+			if key, val, ok := splitYAMLKey(part); ok {
+				m[key] = parseYAMLScalar(val)
+			}
+		}
+		return m
+	case len(text) >= 2 && text[0] == '"' && text[len(text)-1] == '"':
+		return strings.ReplaceAll(text[1:len(text)-1], `\"`, `"`)
+	case len(text) >= 2 && text[0] == '\'' && text[len(text)-1] == '\'':
+		return strings.ReplaceAll(text[1:len(text)-1], "''", "'")
+	case text == "~" || text == "null":
+		return nil
+	}
+	return text
+}
+
+// splitFlowItems splits the inside of a flow collection on top-level commas
+func splitFlowItems(text string) []string {
+	var items []string
+	depth := 0
+	inQuote := byte(0)
+	start := 0
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case inQuote != 0:
+			if c == inQuote {
+				inQuote = 0
+			}
+		case c == '"' || c == '\'':
+			inQuote = c
+		case c == '[' || c == '{':
+			depth++
+		case c == ']' || c == '}':
+			depth--
+		case c == ',' && depth == 0:
+			items = append(items, strings.TrimSpace(text[start:i]))
+			start = i + 1
+		}
+	}
+	if last := strings.TrimSpace(text[start:]); last != "" {
+		items = append(items, last)
+	}
+	return items
+}
+
+// quoteYAMLScalar wraps a block scalar's text so it parses back as one string
+func quoteYAMLScalar(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, `\"`) + `"`
+}
+
+// yamlGet walks nested mappings by key and returns the value found, if any
+func yamlGet(node interface{}, keys ...string) interface{} {
+	for _, key := range keys {
+		m, ok := node.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		node = m[key]
+	}
+	return node
+}
+
+// yamlString returns a node as a string, or "" if it is not a scalar
+func yamlString(node interface{}) string {
+	s, _ := node.(string)
+	return s
+}
+
+// yamlList returns a node as a sequence, or nil if it is not one
+func yamlList(node interface{}) []interface{} {
+	l, _ := node.([]interface{})
+	return l
+}
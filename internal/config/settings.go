@@ -41,6 +41,19 @@ type AppSettings struct {
 
 	// Analysis settings
 	DefaultAnalysisType string `json:"default_analysis_type"` // "quick", "detailed", "custom"
+
+	// OfflineVulnDB scans dependencies against the local vulnerability
+	// database (see `repo-lyzer vulndb sync`) instead of api.osv.dev
+	OfflineVulnDB bool `json:"offline_vulndb"`
+
+	// OfflineRegistries keeps the license and freshness checks from
+	// querying the package registries
+	OfflineRegistries bool `json:"offline_registries"`
+
+	// UpstreamBudget is the most GitHub requests the dependency health check
+	// may spend per analysis. 0 uses the default, which only checks when a
+	// token is set; a negative value turns the check off.
+	UpstreamBudget int `json:"upstream_budget"`
 }
 
 // DefaultSettings returns the default application settings
@@ -73,6 +86,33 @@ func getSettingsPath() (string, error) {
 	return filepath.Join(dir, "settings.json"), nil
 }
 
+// SecretsAllowlistPath returns the path of the user's secret scan allowlist
+func SecretsAllowlistPath() (string, error) {
+	dir, err := getSettingsDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "secrets_allowlist.txt"), nil
+}
+
+// LicensePolicyPath returns the path of the user's dependency license policy
+func LicensePolicyPath() (string, error) {
+	dir, err := getSettingsDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "license_policy.txt"), nil
+}
+
+// VulnDBDir returns the directory of the offline vulnerability database
+func VulnDBDir() (string, error) {
+	dir, err := getSettingsDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "vulndb"), nil
+}
+
 // LoadSettings loads settings from disk, or returns defaults if not found
 func LoadSettings() (*AppSettings, error) {
 	settingsPath, err := getSettingsPath()
@@ -155,6 +195,20 @@ func (s *AppSettings) ClearGitHubToken() error {
 	return s.SaveSettings()
 }
 
+// SetOfflineVulnDB updates whether vulnerabilities are looked up in the
+// local database only, and saves
+func (s *AppSettings) SetOfflineVulnDB(offline bool) error {
+	s.OfflineVulnDB = offline
+	return s.SaveSettings()
+}
+
+// SetOfflineRegistries updates whether the package registries are left
+// alone, and saves
+func (s *AppSettings) SetOfflineRegistries(offline bool) error {
+	s.OfflineRegistries = offline
+	return s.SaveSettings()
+}
+
 // HasGitHubToken returns true if a GitHub token is configured
 func (s *AppSettings) HasGitHubToken() bool {
 	return s.GitHubToken != ""
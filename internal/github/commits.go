@@ -1,6 +1,9 @@
 package github
 
-import "time"
+import (
+	"net/url"
+	"time"
+)
 
 type Commit struct {
 	SHA    string `json:"sha"`
@@ -9,6 +12,11 @@ type Commit struct {
 			Date time.Time `json:"date"`
 		} `json:"author"`
 	} `json:"commit"`
+	// Author is the GitHub account linked to the commit; empty when the
+	// commit email does not belong to an account
+	Author struct {
+		Login string `json:"login"`
+	} `json:"author"`
 }
 
 func (c *Client) GetCommits(owner, repo string, days int) ([]Commit, error) {
@@ -19,3 +27,15 @@ func (c *Client) GetCommits(owner, repo string, days int) ([]Commit, error) {
 	err := c.get(url, &commits)
 	return commits, err
 }
+
+// GetCommitsForPath fetches up to 100 commits from the last days that touch
+// files under path
+func (c *Client) GetCommitsForPath(owner, repo, path string, days int) ([]Commit, error) {
+	var commits []Commit
+	since := time.Now().AddDate(0, 0, -days).Format(time.RFC3339)
+
+	endpoint := "https://api.github.com/repos/" + owner + "/" + repo + "/commits?per_page=100&since=" + since +
+		"&path=" + url.QueryEscape(path)
+	err := c.get(endpoint, &commits)
+	return commits, err
+}
@@ -38,3 +38,12 @@ func (c *Client) GetContributors(owner, repo string) ([]Contributor, error) {
 
 	return allContributors, nil
 }
+
+// GetTopContributors fetches the n contributors with the most commits in a
+// single request; n is at most 100
+func (c *Client) GetTopContributors(owner, repo string, n int) ([]Contributor, error) {
+	var contributors []Contributor
+	url := fmt.Sprintf("https://api.github.com/repos/%s/%s/contributors?per_page=%d", owner, repo, n)
+	err := c.get(url, &contributors)
+	return contributors, err
+}
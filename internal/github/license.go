@@ -0,0 +1,16 @@
+package github
+
+import "strings"
+
+// GetLicenseFile fetches the license file GitHub detected for a repository.
+// It returns the file's path and its base64 encoded content.
+func (c *Client) GetLicenseFile(owner, repo string) (string, string, error) {
+	var result struct {
+		Path    string `json:"path"`
+		Content string `json:"content"`
+	}
+	if err := c.get("https://api.github.com/repos/"+owner+"/"+repo+"/license", &result); err != nil {
+		return "", "", err
+	}
+	return result.Path, strings.ReplaceAll(result.Content, "\n", ""), nil
+}
@@ -1,5 +1,7 @@
 package github
 
+import "strings"
+
 type TreeEntry struct {
 	Path string `json:"path"`
 	Mode string `json:"mode"`
@@ -21,3 +23,16 @@ func (c *Client) GetFileTree(owner, repo, branch string) ([]TreeEntry, error) {
 	err := c.get("https://api.github.com/repos/"+owner+"/"+repo+"/git/trees/"+branch+"?recursive=1", &t)
 	return t.Tree, err
 }
+
+// GetBlob fetches a blob by SHA. Returns the base64 encoded content.
+// Unlike the contents API it works for any tree, including those of tags.
+func (c *Client) GetBlob(owner, repo, sha string) (string, error) {
+	var result struct {
+		Content  string `json:"content"`
+		Encoding string `json:"encoding"`
+	}
+	if err := c.get("https://api.github.com/repos/"+owner+"/"+repo+"/git/blobs/"+sha, &result); err != nil {
+		return "", err
+	}
+	return strings.ReplaceAll(result.Content, "\n", ""), nil
+}
@@ -0,0 +1,51 @@
+package output
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/agnivo988/Repo-lyzer/internal/analyzer"
+	"github.com/olekukonko/tablewriter"
+)
+
+// PrintAPIDiff prints the API changes between two refs. Compatible changes
+// are only listed when showCompatible is set.
+func PrintAPIDiff(report *analyzer.APIDiffReport, showCompatible bool) {
+	fmt.Println(SectionStyle.Render(fmt.Sprintf("\n🔍 API changes in %s: %s → %s", report.Module, report.OldRef, report.NewRef)))
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.Header([]string{"Package", "Identifier", "Change", "Impact", "Detail"})
+	rows := 0
+	for _, c := range report.Changes {
+		if !c.Breaking && !showCompatible {
+			continue
+		}
+		impact := "compatible"
+		if c.Breaking {
+			impact = "BREAKING"
+		}
+		name := c.Name
+		if name == "" {
+			name = "(package)"
+		}
+		table.Append([]string{c.Package, name, c.Change, impact, c.Detail})
+		rows++
+	}
+	if rows > 0 {
+		table.Render()
+	}
+
+	summary := fmt.Sprintf("%d breaking, %d compatible changes", report.BreakingCount, report.CompatibleCount)
+	if report.BreakingCount > 0 {
+		fmt.Println(ErrorStyle.Render("❌ " + summary))
+	} else {
+		fmt.Println(SuccessStyle.Render("✅ " + summary))
+	}
+	if report.TypeErrors > 0 {
+		fmt.Println(WarningStyle.Render(fmt.Sprintf("⚠️ %d type errors were ignored; results for affected declarations may be incomplete", report.TypeErrors)))
+	}
+
+	for _, v := range report.SemverViolations {
+		fmt.Println(ErrorStyle.Render("🚫 Semver violation: " + v))
+	}
+}
@@ -0,0 +1,50 @@
+package output
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/agnivo988/Repo-lyzer/internal/analyzer"
+	"github.com/olekukonko/tablewriter"
+)
+
+// PrintFreshness prints the outdated direct dependencies, most drift first.
+// Up-to-date dependencies are only listed when showAll is set.
+func PrintFreshness(f *analyzer.FreshnessReport, showAll bool) {
+	fmt.Println(SectionStyle.Render(fmt.Sprintf("\n📅 Dependency freshness: %d/100", f.Score)))
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.Header([]string{"Package", "Current", "Latest", "Drift", "Majors Behind", "Libyear"})
+	rows := 0
+	for _, p := range f.Packages {
+		if p.Drift == analyzer.DriftCurrent && !showAll {
+			continue
+		}
+		name, current := p.Name, p.Current
+		if p.Dev {
+			name += " (dev)"
+		}
+		if !p.Locked {
+			current += " (declared)"
+		}
+		table.Append([]string{name, current, p.Latest, p.Drift, fmt.Sprintf("%d", p.MajorsBehind), fmt.Sprintf("%.2f", p.Libyear)})
+		rows++
+	}
+	if rows > 0 {
+		table.Render()
+	}
+
+	summary := fmt.Sprintf("%d of %d dependencies outdated, %d by a major version, %.1f libyears behind",
+		f.OutdatedCount, f.Checked, f.MajorCount, f.TotalLibyear)
+	if f.OutdatedCount > 0 {
+		fmt.Println(WarningStyle.Render("⚠️ " + summary))
+	} else {
+		fmt.Println(SuccessStyle.Render("✅ " + summary))
+	}
+	if f.FailedLookups > 0 {
+		fmt.Println(WarningStyle.Render(fmt.Sprintf("⚠️ %d registry lookups failed", f.FailedLookups)))
+		for _, e := range f.Errors {
+			fmt.Println(WarningStyle.Render("   " + e))
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package output
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/agnivo988/Repo-lyzer/internal/analyzer"
+	"github.com/olekukonko/tablewriter"
+)
+
+// PrintLicenseCompliance prints the licenses of a project's dependencies.
+// Allowed licenses are only listed when showAllowed is set.
+func PrintLicenseCompliance(c *analyzer.LicenseCompliance, showAllowed bool) {
+	project := c.ProjectLicense
+	if project == "" {
+		project = "no license"
+	}
+	fmt.Println(SectionStyle.Render(fmt.Sprintf("\n📜 Dependency licenses (project: %s)", project)))
+	if c.PolicySource != "" {
+		fmt.Println("Policy: " + c.PolicySource)
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.Header([]string{"Package", "Version", "License", "Source", "Status", "Reason"})
+	rows := 0
+	for _, p := range c.Packages {
+		if p.Status == analyzer.LicenseAllowed && p.Conflict == "" && !showAllowed {
+			continue
+		}
+		name, status, reason := p.Name, p.Status, p.Reason
+		if p.Dev {
+			name += " (dev)"
+		}
+		if p.Conflict != "" {
+			status, reason = "CONFLICT", p.Conflict
+		}
+		table.Append([]string{name, p.Version, p.License, p.Source, status, reason})
+		rows++
+	}
+	if rows > 0 {
+		table.Render()
+	}
+
+	summary := fmt.Sprintf("%d allowed, %d to review, %d denied, %d unknown, %d copyleft conflicts",
+		c.AllowedCount, c.ReviewCount, c.DeniedCount, c.UnknownCount, c.ConflictCount)
+	if len(c.Violations()) > 0 {
+		fmt.Println(ErrorStyle.Render("❌ " + summary))
+	} else {
+		fmt.Println(SuccessStyle.Render("✅ " + summary))
+	}
+	if c.FailedLookups > 0 {
+		fmt.Println(WarningStyle.Render(fmt.Sprintf("⚠️ %d license lookups failed; those packages are reported as unknown", c.FailedLookups)))
+		for _, e := range c.Errors {
+			fmt.Println(WarningStyle.Render("   " + e))
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package output
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/agnivo988/Repo-lyzer/internal/analyzer"
+	"github.com/olekukonko/tablewriter"
+)
+
+// PrintUpstreamHealth prints the dependency risk table. Dependencies without
+// risk flags are only listed when showAll is set.
+func PrintUpstreamHealth(u *analyzer.UpstreamAnalysis, showAll bool) {
+	fmt.Println(SectionStyle.Render("\n🩺 Upstream health of direct dependencies"))
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.Header([]string{"Package", "Repository", "Risk", "Flags", "Health", "Bus Factor", "Last Push", "Stars"})
+	rows := 0
+	for _, p := range u.Packages {
+		if p.Health == nil || (len(p.Risks) == 0 && !showAll) {
+			continue
+		}
+		name := p.Name
+		if p.Dev {
+			name += " (dev)"
+		}
+		h := p.Health
+		table.Append([]string{
+			name, p.Repository, p.Risk, strings.Join(p.Risks, ", "), fmt.Sprintf("%d", h.HealthScore),
+			fmt.Sprintf("%d (%s)", h.BusFactor, h.BusRisk), h.PushedAt.Format("2006-01-02"), fmt.Sprintf("%d", h.Stars),
+		})
+		rows++
+	}
+	if rows > 0 {
+		table.Render()
+	}
+
+	summary := fmt.Sprintf("%d archived, %d single maintainer, %d stale upstreams (%d analyzed, %d cached, %d GitHub requests)",
+		u.ArchivedCount, u.SingleMaintainer, u.StaleCount, u.Analyzed, u.Cached, u.RequestsUsed)
+	if len(u.AtRisk()) > 0 {
+		fmt.Println(WarningStyle.Render("⚠️ " + summary))
+	} else {
+		fmt.Println(SuccessStyle.Render("✅ " + summary))
+	}
+	if unmapped := len(u.Packages) - u.Mapped; unmapped > 0 {
+		fmt.Printf("%d dependencies have no known GitHub repository\n", unmapped)
+	}
+	if u.Skipped > 0 {
+		fmt.Println(WarningStyle.Render(fmt.Sprintf("⚠️ Budget of %d requests reached; %d dependencies were not checked", u.Budget, u.Skipped)))
+	}
+	for _, e := range u.Errors {
+		fmt.Println(WarningStyle.Render("   " + e))
+	}
+}
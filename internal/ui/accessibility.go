@@ -99,6 +99,7 @@ var KeyBindings = []KeyBinding{
 	{Key: "O", AltKeys: []string{}, Action: "expand_all", Description: "Expand all", Context: "tree", Category: "File Tree"},
 	{Key: "c", AltKeys: []string{}, Action: "collapse", Description: "Collapse folder", Context: "tree", Category: "File Tree"},
 	{Key: "C", AltKeys: []string{}, Action: "collapse_all", Description: "Collapse all", Context: "tree", Category: "File Tree"},
+	{Key: "m", AltKeys: []string{}, Action: "size_map", Description: "Toggle size map", Context: "tree", Category: "File Tree"},
 }
 
 // GetBindingsForContext returns all key bindings for a specific context
@@ -2,6 +2,7 @@ package ui
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
@@ -227,7 +228,7 @@ func (m MainModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			case 5: // Settings
 				if m.menu.submenuType == "settings" {
 					// Settings option selection
-					settingsOptions := []string{"theme", "cache", "export", "token", "reset"}
+					settingsOptions := []string{"theme", "cache", "export", "token", "offline", "reset"}
 					if m.menu.submenuCursor < len(settingsOptions) {
 						m.settingsOption = settingsOptions[m.menu.submenuCursor]
 					}
@@ -636,6 +637,26 @@ func (m MainModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					newFormat := m.appConfig.CycleExportFormat()
 					m.err = fmt.Errorf("Export format: %s", newFormat.DisplayName())
 				}
+			case "v":
+				// Toggle the local vulnerability database (offline settings)
+				if m.settingsOption == "offline" && m.appConfig != nil {
+					m.appConfig.SetOfflineVulnDB(!m.appConfig.OfflineVulnDB)
+					if m.appConfig.OfflineVulnDB {
+						m.err = fmt.Errorf("Offline vulnerability scan enabled")
+					} else {
+						m.err = fmt.Errorf("Offline vulnerability scan disabled")
+					}
+				}
+			case "r":
+				// Toggle package registry lookups (offline settings)
+				if m.settingsOption == "offline" && m.appConfig != nil {
+					m.appConfig.SetOfflineRegistries(!m.appConfig.OfflineRegistries)
+					if m.appConfig.OfflineRegistries {
+						m.err = fmt.Errorf("Registry lookups disabled")
+					} else {
+						m.err = fmt.Errorf("Registry lookups enabled")
+					}
+				}
 			case "i":
 				// Enter token input mode (token settings)
 				if m.settingsOption == "token" {
@@ -977,10 +998,84 @@ func (m MainModel) analyzeRepo(repoName string) tea.Cmd {
 		deps, _ := analyzer.AnalyzeDependencies(client, parts[0], parts[1], repo.DefaultBranch, fileTree)
 		contributorInsights := analyzer.AnalyzeContributors(contributors)
 
+		// Container base images are scanned together with package dependencies
+		docker, _ := analyzer.AnalyzeDocker(client, parts[0], parts[1], fileTree)
+		if deps != nil {
+			deps.AddFiles(docker.Dependencies()...)
+		}
+
 		// Stage 7: Security vulnerability scan
-		security, _ := analyzer.ScanDependencies(deps)
+		security, scanErr := analyzer.ScanDependenciesWith(deps, vulnScanOptions(m.appConfig))
+		securityError := ""
+		if scanErr != nil {
+			securityError = scanErr.Error()
+		}
+		depGraph := analyzer.BuildDependencyGraph(deps)
+		remediation := analyzer.BuildRemediationPlan(security, deps, depGraph)
+		license, _ := analyzer.AnalyzeLicense(client, parts[0], parts[1], fileTree)
+		compliance := checkLicenseCompliance(license, deps, m.appConfig, client)
+		suspicious := analyzer.DetectSuspiciousPackages(deps)
+		var freshness *analyzer.FreshnessReport
+		if m.appConfig == nil || !m.appConfig.OfflineRegistries {
+			freshness = analyzer.AnalyzeFreshness(deps, analyzer.DefaultFreshnessOptions())
+		}
+		var upstream *analyzer.UpstreamAnalysis
+		if opts, ok := upstreamOptions(m.appConfig, client, m.cache); ok {
+			upstream = analyzer.CheckUpstreamHealth(client, deps, opts)
+		}
+		secrets, _ := analyzer.ScanSecrets(client, parts[0], parts[1], fileTree, secretScanOptions())
+		iac, _ := analyzer.AnalyzeIaC(client, parts[0], parts[1], fileTree, 25)
 		tracker.NextStage()
 
+		// Line counts and Go source analysis use the Desktop clone when there
+		// is one, so every file can be read without spending API requests
+		source, sourceTree, local := contentSource(client, parts[0], parts[1], fileTree)
+		locOpts := analyzer.DefaultLOCOptions()
+		goOpts := analyzer.DefaultGoAnalysisOptions()
+		if local {
+			locOpts.MaxFiles, goOpts.MaxFiles = 0, 0
+		}
+
+		// Vendored and generated files are left out of line counts and
+		// quality statistics so they don't skew the scores
+		files := analyzer.ClassifyFiles(fileTree)
+		headerBudget := 15
+		if local {
+			headerBudget = 0
+		}
+		files.CheckGeneratedHeaders(source, headerBudget)
+		ownTree := files.FilterTree(sourceTree)
+
+		loc, _ := analyzer.CountLOC(source, ownTree, locOpts)
+
+		var goStats *analyzer.GoAnalysis
+		if _, isGo := languages["Go"]; isGo {
+			goStats, _ = analyzer.AnalyzeGo(source, ownTree, goOpts)
+		}
+		codeQuality := analyzer.AnalyzeCodeQualityWith(repo, fileTree, languages, analyzer.QualityInputs{Go: goStats, Files: files})
+		bloat, _ := analyzer.AnalyzeBloat(source, fileTree, analyzer.DefaultBloatOptions())
+
+		// Every module's go.mod and go.sum is read; without a clone the
+		// number of modules is capped to bound API requests
+		maxModules := 20
+		if local {
+			maxModules = 0
+		}
+		goModules, _ := analyzer.InspectGoModules(source, fileTree, repo.FullName, maxModules)
+		if len(goModules.Modules) == 0 {
+			goModules = nil
+		}
+
+		// Workspaces get their own quality, dependency, license and
+		// contributor analysis per package
+		monoOpts := analyzer.DefaultMonorepoOptions()
+		monorepo := analyzer.DetectMonorepo(source, fileTree, monoOpts.MaxPackages)
+		if monorepo.IsMonorepo() {
+			monorepo.AnalyzePackages(client, parts[0], parts[1], repo, fileTree, deps, monoOpts)
+		} else {
+			monorepo = nil
+		}
+
 		// Mark complete
 		tracker.NextStage()
 
@@ -996,8 +1091,25 @@ func (m MainModel) analyzeRepo(repoName string) tea.Cmd {
 			MaturityScore:       maturityScore,
 			MaturityLevel:       maturityLevel,
 			Dependencies:        deps,
+			DependencyGraph:     depGraph,
 			ContributorInsights: contributorInsights,
 			Security:            security,
+			SecurityError:       securityError,
+			Remediation:         remediation,
+			License:             license,
+			Compliance:          compliance,
+			Secrets:             secrets,
+			SuspiciousPackages:  suspicious,
+			Upstream:            upstream,
+			Freshness:           freshness,
+			GoModules:           goModules,
+			Docker:              docker,
+			IaC:                 iac,
+			LOC:                 loc,
+			CodeQuality:         codeQuality,
+			Files:               files,
+			Bloat:               bloat,
+			Monorepo:            monorepo,
 		}
 
 		// Save to cache
@@ -1009,6 +1121,108 @@ func (m MainModel) analyzeRepo(repoName string) tea.Cmd {
 	}
 }
 
+// secretScanOptions returns the default secret scan options together with
+// the user's allowlist from ~/.repo-lyzer/secrets_allowlist.txt, if present.
+func secretScanOptions() analyzer.SecretScanOptions {
+	opts := analyzer.DefaultSecretScanOptions()
+	if path, err := config.SecretsAllowlistPath(); err == nil {
+		if allowlist, err := analyzer.LoadSecretAllowlist(path); err == nil {
+			opts.Allowlist = allowlist
+		}
+	}
+	return opts
+}
+
+// vulnScanOptions returns the OSV scan options with the offline database
+// from ~/.repo-lyzer/vulndb attached, if one has been synced. The database
+// is used on its own when the settings ask for offline scanning.
+func vulnScanOptions(settings *config.AppSettings) analyzer.OSVScanOptions {
+	opts := analyzer.DefaultOSVScanOptions()
+	if dir, err := config.VulnDBDir(); err == nil {
+		if db, err := analyzer.OpenVulnDB(dir); err == nil && !db.Empty() {
+			opts.VulnDB = db
+		}
+	}
+	opts.Offline = settings != nil && settings.OfflineVulnDB
+	return opts
+}
+
+// checkLicenseCompliance runs the license check with licenseComplianceOptions.
+// A policy file that can't be read or parsed is reported in the result
+// rather than silently ignored.
+func checkLicenseCompliance(license *analyzer.LicenseAnalysis, deps *analyzer.DependencyAnalysis, settings *config.AppSettings, client *github.Client) *analyzer.LicenseCompliance {
+	opts, policyErr := licenseComplianceOptions(settings, client)
+	compliance := analyzer.CheckLicenseCompliance(license, deps, opts)
+	if policyErr != nil {
+		compliance.PolicyError = policyErr.Error()
+	}
+	return compliance
+}
+
+// licenseComplianceOptions returns the license check options with the
+// user's policy from ~/.repo-lyzer/license_policy.txt, if present, and the
+// error if that file can't be used. Licenses are looked up in the package
+// registries unless the settings turn them off, and in LICENSE
+// files on GitHub when a token is set.
+func licenseComplianceOptions(settings *config.AppSettings, client *github.Client) (analyzer.LicenseComplianceOptions, error) {
+	opts := analyzer.DefaultLicenseComplianceOptions()
+	var policyErr error
+	if path, err := config.LicensePolicyPath(); err == nil {
+		policy, err := analyzer.LoadLicensePolicy(path)
+		switch {
+		case err == nil:
+			opts.Policy = policy
+		case !errors.Is(err, os.ErrNotExist):
+			policyErr = err
+		}
+	}
+	if settings == nil || !settings.OfflineRegistries {
+		githubLookups := 0
+		if client != nil && client.HasToken() {
+			githubLookups = 50
+		}
+		opts.Resolver = analyzer.NewRegistryLicenseResolver(analyzer.DefaultRegistryOptions(), client, githubLookups)
+	}
+	return opts, policyErr
+}
+
+// upstreamOptions returns the dependency health check options with the
+// budget from the settings, and whether to run the check. Without a token
+// it only runs when the settings set a budget, as the anonymous rate limit
+// is spent on the analysis itself.
+func upstreamOptions(settings *config.AppSettings, client *github.Client, repoCache *cache.Cache) (analyzer.UpstreamOptions, bool) {
+	opts := analyzer.DefaultUpstreamOptions()
+	budget := 0
+	if settings != nil {
+		budget = settings.UpstreamBudget
+	}
+	switch {
+	case budget < 0:
+		return opts, false
+	case budget > 0:
+		opts.Budget = budget
+	case client == nil || !client.HasToken():
+		return opts, false
+	}
+	if repoCache != nil {
+		opts.Cache = NewUpstreamCache(repoCache)
+	}
+	return opts, true
+}
+
+// contentSource picks where file contents are read from: the repository's
+// clone on the Desktop if it exists, otherwise the GitHub API. The returned
+// tree matches the source, and local reports whether the clone is used.
+func contentSource(client *github.Client, owner, repo string, fileTree []github.TreeEntry) (analyzer.FileSource, []github.TreeEntry, bool) {
+	clonePath := filepath.Join(getDesktopPath(), repo)
+	if _, err := os.Stat(filepath.Join(clonePath, ".git")); err == nil {
+		if localTree, err := analyzer.LocalFileTree(clonePath); err == nil {
+			return analyzer.LocalFileSource{Root: clonePath}, localTree, true
+		}
+	}
+	return analyzer.GitHubFileSource{Client: client, Owner: owner, Repo: repo}, fileTree, false
+}
+
 func (m MainModel) checkOwnership() bool {
 	client := github.NewClient()
 	user, err := client.GetUser()
@@ -1705,6 +1919,31 @@ Benefits of using a token:
   • More detailed analysis
 `, tokenStatus, tokenDisplay, envStatus)
 		}
+	case "offline":
+		title = "📴 Offline Mode"
+
+		vulnDB, registries := false, false
+		if m.appConfig != nil {
+			vulnDB, registries = m.appConfig.OfflineVulnDB, m.appConfig.OfflineRegistries
+		}
+		dbStatus := "Not synced"
+		if dir, err := config.VulnDBDir(); err == nil {
+			if db, err := analyzer.OpenVulnDB(dir); err == nil && !db.Empty() {
+				dbStatus = "Synced, in " + dir
+			}
+		}
+
+		content = fmt.Sprintf(`
+Scan against the local database only: %s
+Local database: %s
+Query package registries: %s
+
+Keybindings:
+  • Press 'v' to scan against the local database only
+    (run 'repo-lyzer vulndb sync' to fill it)
+  • Press 'r' to turn package registry lookups on or off;
+    the license and freshness checks use them
+`, boolToYesNo(vulnDB), dbStatus, boolToYesNo(!registries))
 	case "reset":
 		title = "🔄 Reset to Defaults"
 		content = `
@@ -26,6 +26,9 @@ const (
 	viewSecurity
 	viewRecruiter
 	viewAPIStatus
+	// Views after the ones bound to 1-0
+	viewCompliance
+	viewPackages
 )
 
 type DashboardModel struct {
@@ -38,6 +41,10 @@ type DashboardModel struct {
 	currentView dashboardView
 	showHelp    bool
 	cacheStatus string // "fresh", "cached", or ""
+	selectedPkg int    // package highlighted in the Packages view
+
+	depCursor    int             // row highlighted in the dependency tree
+	expandedDeps map[string]bool // expanded dependency tree rows, by path
 }
 
 func NewDashboardModel() DashboardModel {
@@ -50,6 +57,9 @@ func (m DashboardModel) Init() tea.Cmd { return nil }
 
 func (m *DashboardModel) SetData(data AnalysisResult) {
 	m.data = data
+	m.selectedPkg = 0
+	m.depCursor = 0
+	m.expandedDeps = make(map[string]bool)
 }
 
 func (m *DashboardModel) SetCacheStatus(status string) {
@@ -135,6 +145,28 @@ func (m DashboardModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				}
 			}
 
+		case "g":
+			if m.showExport {
+				return m, func() tea.Msg {
+					filename, err := ExportDependencyGraph(m.data)
+					if err != nil {
+						return exportMsg{err, ""}
+					}
+					return exportMsg{nil, "✓ Exported dependency graph to " + filename}
+				}
+			}
+
+		case "s":
+			if m.showExport {
+				return m, func() tea.Msg {
+					filename, err := ExportSBOM(m.data)
+					if err != nil {
+						return exportMsg{err, ""}
+					}
+					return exportMsg{nil, "✓ Exported SBOM to " + filename}
+				}
+			}
+
 		case "f":
 			return m, func() tea.Msg { return "switch_to_tree" }
 
@@ -168,10 +200,31 @@ func (m DashboardModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.currentView = viewRecruiter
 		case "0":
 			m.currentView = viewAPIStatus
+		case "c":
+			m.currentView = viewCompliance
+
+		case "up":
+			if m.currentView == viewPackages && m.selectedPkg > 0 {
+				m.selectedPkg--
+			}
+			if m.currentView == viewDependencies && m.depCursor > 0 {
+				m.depCursor--
+			}
+		case "down":
+			if m.currentView == viewPackages && m.data.Monorepo != nil && m.selectedPkg < len(m.data.Monorepo.Packages)-1 {
+				m.selectedPkg++
+			}
+			if m.currentView == viewDependencies && m.depCursor < len(m.depTreeVisibleRows())-1 {
+				m.depCursor++
+			}
+		case "enter", " ":
+			if m.currentView == viewDependencies {
+				m.toggleDepTreeRow()
+			}
 
 		case "right", "l":
 			if !m.showHelp && !m.showExport {
-				if m.currentView < viewAPIStatus {
+				if m.currentView < viewPackages {
 					m.currentView++
 				}
 			}
@@ -222,17 +275,21 @@ func (m DashboardModel) View() string {
 		content = m.dependenciesView()
 	case viewSecurity:
 		content = m.securityView()
+	case viewCompliance:
+		content = m.complianceView()
 	case viewRecruiter:
 		content = m.recruiterView()
 	case viewAPIStatus:
 		content = m.apiStatusView()
+	case viewPackages:
+		content = m.packagesView()
 	}
 
 	if m.showExport {
 		content = lipgloss.JoinVertical(
 			lipgloss.Left,
 			content,
-			CardStyle.Render("📥 Export Options:\n[J] JSON  [M] Markdown  [P] PDF"),
+			CardStyle.Render("📥 Export Options:\n[J] JSON  [M] Markdown  [P] PDF  [G] Dependency graph  [S] SBOM"),
 		)
 	}
 
@@ -242,7 +299,7 @@ func (m DashboardModel) View() string {
 
 	tabs := m.renderTabs()
 	
-	footer := SubtleStyle.Render("←→: switch view • f: files • e: export • ?: help • q: back")
+	footer := SubtleStyle.Render("←→: switch view • c: compliance • f: files • e: export • ?: help • q: back")
 
 	fullContent := lipgloss.JoinVertical(
 		lipgloss.Left,
@@ -267,7 +324,7 @@ func (m DashboardModel) View() string {
 }
 
 func (m DashboardModel) renderTabs() string {
-	views := []string{"Overview", "Repo", "Langs", "Activity", "Contribs", "Insights", "Deps", "Security", "Recruiter", "API"}
+	views := []string{"Overview", "Repo", "Langs", "Activity", "Contribs", "Insights", "Deps", "Security", "Recruiter", "API", "Compliance", "Packages"}
 	var renderedTabs []string
 
 	for i, name := range views {
@@ -349,18 +406,102 @@ func (m DashboardModel) repoView() string {
 		m.data.Repo.HTMLURL,
 	)
 
-	return lipgloss.JoinVertical(lipgloss.Left, header, CardStyle.Render(info))
+	return lipgloss.JoinVertical(lipgloss.Left, header, CardStyle.Render(info), m.bloatCard())
+}
+
+// bloatCard shows where the repository's weight is and what belongs in Git LFS
+func (m DashboardModel) bloatCard() string {
+	b := m.data.Bloat
+	if b == nil || b.TotalFiles == 0 {
+		return ""
+	}
+
+	rows := []string{
+		TitleStyle.Render("💾 Repository Size"),
+		fmt.Sprintf("Total:           %s in %d files", analyzer.FormatSize(b.TotalSize), b.TotalFiles),
+		fmt.Sprintf("Binaries:        %d (%s)%s", len(b.Binaries), analyzer.FormatSize(b.BinarySize), formatBinaryTypes(b.BinariesByType)),
+		fmt.Sprintf("LFS candidates:  %d (%s)", len(b.LFSCandidates), analyzer.FormatSize(b.CandidateSize)),
+	}
+	if len(b.LFSPatterns) > 0 {
+		status := SuccessStyle.Render("honoured")
+		if len(b.LFSNotHonoured) > 0 {
+			status = ErrorStyle.Render(fmt.Sprintf("%d files committed as regular blobs", len(b.LFSNotHonoured)))
+		}
+		rows = append(rows, fmt.Sprintf("LFS patterns:    %d, %d files tracked, %s", len(b.LFSPatterns), b.LFSTracked, status))
+	}
+
+	rows = append(rows, "", "By directory:")
+	maxShow := 8
+	if len(b.Directories) < maxShow {
+		maxShow = len(b.Directories)
+	}
+	for _, d := range b.Directories[:maxShow] {
+		barLen := 0
+		if b.TotalSize > 0 {
+			barLen = d.Size * 30 / b.TotalSize
+		}
+		if barLen < 1 && d.Size > 0 {
+			barLen = 1
+		}
+		name := d.Path
+		if len(name) > 20 {
+			name = name[:19] + "…"
+		}
+		rows = append(rows, fmt.Sprintf("  %-20s %9s %s", name, analyzer.FormatSize(d.Size), strings.Repeat("█", barLen)))
+	}
+	if len(b.Directories) > maxShow {
+		rows = append(rows, SubtleStyle.Render(fmt.Sprintf("  ... %d more", len(b.Directories)-maxShow)))
+	}
+
+	rows = append(rows, "", "Largest files:")
+	maxShow = 5
+	if len(b.LargestFiles) < maxShow {
+		maxShow = len(b.LargestFiles)
+	}
+	for _, f := range b.LargestFiles[:maxShow] {
+		rows = append(rows, fmt.Sprintf("  %9s  %s", analyzer.FormatSize(f.Size), f.Path))
+	}
+
+	for i, f := range b.LFSNotHonoured {
+		if i == 0 {
+			rows = append(rows, "", ErrorStyle.Render("Matches LFS pattern but not stored in LFS:"))
+		}
+		if i == 5 {
+			rows = append(rows, SubtleStyle.Render(fmt.Sprintf("  ... %d more", len(b.LFSNotHonoured)-5)))
+			break
+		}
+		rows = append(rows, fmt.Sprintf("  %9s  %s", analyzer.FormatSize(f.Size), f.Path))
+	}
+
+	rows = append(rows, "", SubtleStyle.Render("Press f for the file tree, then m for a size treemap"))
+	return CardStyle.Render(strings.Join(rows, "\n"))
+}
+
+// formatBinaryTypes renders binary counts by category, e.g. " — 3 media, 1 archive"
+func formatBinaryTypes(byType map[string]int) string {
+	if len(byType) == 0 {
+		return ""
+	}
+	var parts []string
+	for _, category := range []string{"archive", "executable", "media", "model", "database"} {
+		if n := byType[category]; n > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", n, category))
+		}
+	}
+	return " — " + strings.Join(parts, ", ")
 }
 
 func (m DashboardModel) languagesView() string {
 	header := TitleStyle.Render(" Languages ")
 
-	if len(m.data.Languages) == 0 {
-		return lipgloss.JoinVertical(lipgloss.Left, header, CardStyle.Render("No language data available"))
+	// Vendored and generated files are left out here as in the line counts
+	languages := m.data.Files.OwnLanguages(m.data.Languages)
+	if len(languages) == 0 {
+		return lipgloss.JoinVertical(lipgloss.Left, header, CardStyle.Render("No language data available"), m.locTable(), m.goAnalysisCard(), m.fileClassificationCard())
 	}
 
 	total := 0
-	for _, bytes := range m.data.Languages {
+	for _, bytes := range languages {
 		total += bytes
 	}
 
@@ -369,7 +510,7 @@ func (m DashboardModel) languagesView() string {
 		bytes int
 	}
 	var langs []langStat
-	for name, bytes := range m.data.Languages {
+	for name, bytes := range languages {
 		langs = append(langs, langStat{name, bytes})
 	}
 	sort.Slice(langs, func(i, j int) bool {
@@ -387,7 +528,99 @@ func (m DashboardModel) languagesView() string {
 		lines = append(lines, fmt.Sprintf("%-15s %s %.1f%%", lang.name, bar, pct))
 	}
 
-	return lipgloss.JoinVertical(lipgloss.Left, header, CardStyle.Render(strings.Join(lines, "\n")))
+	return lipgloss.JoinVertical(lipgloss.Left, header, CardStyle.Render(strings.Join(lines, "\n")), m.locTable(), m.goAnalysisCard(), m.fileClassificationCard())
+}
+
+// locTable renders code, comment and blank line counts per language
+func (m DashboardModel) locTable() string {
+	loc := m.data.LOC
+	if loc == nil || len(loc.Languages) == 0 {
+		return CardStyle.Render(SubtleStyle.Render("No line counts available"))
+	}
+
+	rows := []string{
+		TitleStyle.Render("📏 Lines of Code"),
+		fmt.Sprintf("%-14s %6s %8s %8s %8s %8s", "Language", "Files", "Code", "Comment", "Blank", "Comm.%"),
+	}
+	for _, l := range loc.Rows() {
+		rows = append(rows, fmt.Sprintf("%-14s %6d %8d %8d %8d %7.1f%%",
+			l.Language, l.Files, l.Code, l.Comment, l.Blank, l.CommentDensity()))
+	}
+
+	switch {
+	case loc.Local:
+		rows = append(rows, SubtleStyle.Render("Counted from the local clone"))
+	case loc.BudgetExhausted:
+		rows = append(rows, SubtleStyle.Render(fmt.Sprintf("Sampled %d of %d source files (API budget); clone the repo for full counts",
+			loc.FilesCounted, loc.FilesEligible)))
+	}
+
+	return CardStyle.Render(strings.Join(rows, "\n"))
+}
+
+// fileClassificationCard summarises duplicate, vendored and generated files
+func (m DashboardModel) fileClassificationCard() string {
+	files := m.data.Files
+	if files == nil || (len(files.DuplicateGroups) == 0 && files.VendoredFiles == 0 && len(files.GeneratedFiles) == 0) {
+		return ""
+	}
+
+	rows := []string{
+		TitleStyle.Render("🗂️ Files Excluded From Stats"),
+		fmt.Sprintf("Vendored:   %d files (%s) in %d dirs", files.VendoredFiles, analyzer.FormatSize(files.VendoredBytes), len(files.VendoredDirs)),
+		fmt.Sprintf("Generated:  %d files (%s)", len(files.GeneratedFiles), analyzer.FormatSize(files.GeneratedBytes)),
+		fmt.Sprintf("Duplicates: %d files in %d groups, %s wasted", files.DuplicateFiles, len(files.DuplicateGroups), analyzer.FormatSize(files.WastedBytes)),
+	}
+
+	maxShow := 5
+	if len(files.DuplicateGroups) < maxShow {
+		maxShow = len(files.DuplicateGroups)
+	}
+	for _, g := range files.DuplicateGroups[:maxShow] {
+		rows = append(rows, fmt.Sprintf("  %dx %s  %s", len(g.Paths), analyzer.FormatSize(g.Size), SubtleStyle.Render(strings.Join(g.Paths, ", "))))
+	}
+	if len(files.DuplicateGroups) > maxShow {
+		rows = append(rows, SubtleStyle.Render(fmt.Sprintf("  ... %d more groups", len(files.DuplicateGroups)-maxShow)))
+	}
+
+	return CardStyle.Render(strings.Join(rows, "\n"))
+}
+
+// goAnalysisCard renders metrics from parsing the repository's Go source
+func (m DashboardModel) goAnalysisCard() string {
+	if m.data.CodeQuality == nil || m.data.CodeQuality.Go == nil || m.data.CodeQuality.Go.FilesParsed == 0 {
+		return ""
+	}
+	g := m.data.CodeQuality.Go
+
+	rows := []string{
+		TitleStyle.Render("🐹 Go Analysis"),
+		fmt.Sprintf("Files parsed:      %d of %d", g.FilesParsed, g.FilesEligible),
+		fmt.Sprintf("Packages:          %d (%d without doc comment)", g.Packages, len(g.PackagesWithoutDoc)),
+		fmt.Sprintf("Exported API:      %d (%d funcs, %d types, %d consts/vars)", g.ExportedAPI(), g.ExportedFuncs, g.ExportedTypes, g.ExportedValues),
+		fmt.Sprintf("Tests:             %d tests, %d benchmarks, %d fuzz, %d examples", g.TestFuncs, g.BenchmarkFuncs, g.FuzzFuncs, g.ExampleFuncs),
+		fmt.Sprintf("Tests per export:  %.2f", g.TestToExportedRatio()),
+		fmt.Sprintf("Avg complexity:    %.1f", g.AvgComplexity),
+		fmt.Sprintf("unsafe / cgo:      %d / %d files", len(g.UnsafeFiles), len(g.CgoFiles)),
+		fmt.Sprintf("init() functions:  %d", len(g.InitFuncs)),
+	}
+
+	if len(g.MostComplex) > 0 {
+		rows = append(rows, "", "Most complex functions:")
+		maxShow := 5
+		if len(g.MostComplex) < maxShow {
+			maxShow = len(g.MostComplex)
+		}
+		for _, fn := range g.MostComplex[:maxShow] {
+			rows = append(rows, fmt.Sprintf("  %3d  %s  %s", fn.Complexity, fn.Name, SubtleStyle.Render(fn.Location)))
+		}
+	}
+
+	if g.BudgetExhausted {
+		rows = append(rows, SubtleStyle.Render("Sampled within the API budget; clone the repo for a full analysis"))
+	}
+
+	return CardStyle.Render(strings.Join(rows, "\n"))
 }
 
 func (m DashboardModel) activityView() string {
@@ -447,22 +680,64 @@ func (m DashboardModel) dependenciesView() string {
 
 	deps := m.data.Dependencies
 	summary := fmt.Sprintf(
-		"Total Deps:       %d\nPackage Managers: %s\nLock File:        %s",
+		"Total Deps:       %d (%d direct, %d transitive)\nPackage Managers: %s\nLock File:        %s",
 		deps.TotalDeps,
+		deps.TotalDeps-deps.TransitiveDeps,
+		deps.TransitiveDeps,
 		strings.Join(deps.Languages, ", "),
 		boolToYesNo(deps.HasLockFile),
 	)
+	if g := m.data.DependencyGraph; g != nil && len(g.Edges) > 0 {
+		summary += fmt.Sprintf("\nTree Depth:       %d\nDuplicates:       %d", g.MaxDepth, len(g.Duplicates))
+		if len(g.MostDepended) > 0 {
+			top := g.Node(g.MostDepended[0])
+			summary += fmt.Sprintf("\nMost Depended On: %s (%d dependents)", top.Name, top.FanIn)
+		}
+	}
+	if lic := m.data.License; lic != nil {
+		license := "not detected"
+		if lic.MainLicense != nil {
+			license = formatLicense(*lic.MainLicense)
+		}
+		summary += fmt.Sprintf("\nLicenses:         %s, %s", license, lic.Compatibility)
+		for _, w := range lic.Warnings {
+			summary += "\n" + SubtleStyle.Render("⚠️  "+w)
+		}
+	}
 
 	var depLines []string
 	for _, file := range deps.Files {
-		depLines = append(depLines, fmt.Sprintf("\n📄 %s (%d)", file.Filename, file.TotalCount))
+		title := fmt.Sprintf("\n📄 %s (%d)", file.Filename, file.TotalCount)
+		if file.LockFile != "" && file.LockFile != file.Filename {
+			title += SubtleStyle.Render("  🔒 " + file.LockFile)
+		}
+		depLines = append(depLines, title)
+
+		// Direct dependencies are listed first; transitive ones only counted
+		var direct []analyzer.Dependency
+		for _, d := range file.Dependencies {
+			if !d.Transitive {
+				direct = append(direct, d)
+			}
+		}
+		if len(direct) == 0 {
+			direct = file.Dependencies
+		}
 		maxShow := 5
-		if len(file.Dependencies) < maxShow {
-			maxShow = len(file.Dependencies)
+		if len(direct) < maxShow {
+			maxShow = len(direct)
 		}
 		for i := 0; i < maxShow; i++ {
-			d := file.Dependencies[i]
-			depLines = append(depLines, fmt.Sprintf("  • %s %s", d.Name, d.Version))
+			d := direct[i]
+			version := d.Version
+			if d.Resolved != "" && d.Resolved != d.Version {
+				version += " → " + d.Resolved
+			}
+			line := fmt.Sprintf("  • %s %s", d.Name, version)
+			if d.License != "" {
+				line += SubtleStyle.Render("  " + d.License)
+			}
+			depLines = append(depLines, line)
 		}
 		if len(file.Dependencies) > maxShow {
 			depLines = append(depLines, fmt.Sprintf("  ... %d more", len(file.Dependencies)-maxShow))
@@ -470,9 +745,224 @@ func (m DashboardModel) dependenciesView() string {
 	}
 
 	content := CardStyle.Render(summary) + "\n" + CardStyle.Render(strings.Join(depLines, "\n"))
+	if tree := m.dependencyTreeCard(); tree != "" {
+		content = lipgloss.JoinHorizontal(lipgloss.Top, content, tree)
+	}
+	if g := m.data.GoModules; g != nil {
+		content += "\n" + CardStyle.Render(goModulesSummary(g))
+	}
+	if f := m.data.Freshness; f != nil && f.Checked > 0 {
+		content += "\n" + CardStyle.Render(freshnessSummary(f))
+	}
+	if u := m.data.Upstream; u != nil && u.Mapped > 0 {
+		content += "\n" + CardStyle.Render(upstreamSummary(u))
+	}
 	return lipgloss.JoinVertical(lipgloss.Left, header, content)
 }
 
+// goModulesSummary renders each Go module's directives and the warnings
+// found in its go.mod and go.sum, most severe first
+func goModulesSummary(g *analyzer.GoModuleAnalysis) string {
+	title := fmt.Sprintf("🐹 Go modules: %d", len(g.Modules))
+	if g.Workspace != "" {
+		title += ", workspace " + g.Workspace
+	}
+	lines := []string{title}
+	for _, mod := range g.Modules {
+		f := mod.Module
+		line := fmt.Sprintf("  • %s", f.Module)
+		detail := fmt.Sprintf("%s, %d direct, %d indirect", mod.File, mod.Direct, mod.Indirect)
+		if f.GoVersion != "" {
+			detail += ", go " + f.GoVersion
+		}
+		if f.Toolchain != "" {
+			detail += ", " + f.Toolchain
+		}
+		if n := len(f.Replaces); n > 0 {
+			detail += fmt.Sprintf(", %d replaced", n)
+		}
+		if n := len(f.Excludes); n > 0 {
+			detail += fmt.Sprintf(", %d excluded", n)
+		}
+		if n := len(f.Retracts); n > 0 {
+			detail += fmt.Sprintf(", %d retracted", n)
+		}
+		lines = append(lines, line+SubtleStyle.Render("  "+detail))
+		if f.Deprecated != "" {
+			lines = append(lines, SubtleStyle.Render("    deprecated: "+f.Deprecated))
+		}
+	}
+	if g.Truncated {
+		lines = append(lines, SubtleStyle.Render("more modules not inspected"))
+	}
+
+	if len(g.Warnings) == 0 {
+		lines = append(lines, "✅ go.mod and go.sum look consistent")
+		return strings.Join(lines, "\n")
+	}
+	maxShow := 5
+	if len(g.Warnings) < maxShow {
+		maxShow = len(g.Warnings)
+	}
+	for i := 0; i < maxShow; i++ {
+		w := g.Warnings[i]
+		lines = append(lines, fmt.Sprintf("%s %s", analyzer.GetSeverityEmoji(w.Severity), w.Message)+SubtleStyle.Render("  "+w.File))
+	}
+	if len(g.Warnings) > maxShow {
+		lines = append(lines, fmt.Sprintf("... %d more", len(g.Warnings)-maxShow))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// freshnessSummary renders the outdated direct dependencies, most drift
+// first
+func freshnessSummary(f *analyzer.FreshnessReport) string {
+	lines := []string{fmt.Sprintf("📅 Freshness: %d/100, %d of %d outdated (%d major), %.1f libyears behind",
+		f.Score, f.OutdatedCount, f.Checked, f.MajorCount, f.TotalLibyear)}
+	if f.FailedLookups > 0 {
+		lines = append(lines, SubtleStyle.Render(fmt.Sprintf("%d registry lookups failed", f.FailedLookups)))
+	}
+
+	outdated := f.Outdated()
+	if len(outdated) == 0 {
+		lines = append(lines, "✅ All checked dependencies are on their latest release")
+		return strings.Join(lines, "\n")
+	}
+	maxShow := 8
+	if len(outdated) < maxShow {
+		maxShow = len(outdated)
+	}
+	for i := 0; i < maxShow; i++ {
+		p := outdated[i]
+		line := fmt.Sprintf("  • %s %s → %s", p.Name, p.Current, p.Latest)
+		detail := p.Drift
+		if p.MajorsBehind > 1 {
+			detail = fmt.Sprintf("%d majors", p.MajorsBehind)
+		}
+		if p.Libyear > 0 {
+			detail += fmt.Sprintf(", %.1f libyears", p.Libyear)
+		}
+		lines = append(lines, line+SubtleStyle.Render("  "+detail))
+	}
+	if len(outdated) > maxShow {
+		lines = append(lines, fmt.Sprintf("  ... %d more", len(outdated)-maxShow))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// upstreamSummary renders the dependency risk table: direct dependencies
+// whose upstream repository is archived, kept by one maintainer or stale
+func upstreamSummary(u *analyzer.UpstreamAnalysis) string {
+	lines := []string{fmt.Sprintf("🩺 Upstream health: %d archived, %d single maintainer, %d stale (%d repositories checked)",
+		u.ArchivedCount, u.SingleMaintainer, u.StaleCount, u.Analyzed+u.Cached)}
+	if u.Skipped > 0 {
+		lines = append(lines, SubtleStyle.Render(fmt.Sprintf("API budget of %d requests reached, %d dependencies not checked", u.Budget, u.Skipped)))
+	}
+
+	risky := u.AtRisk()
+	if len(risky) == 0 {
+		lines = append(lines, "✅ No archived, single-maintainer or stale upstreams")
+		return strings.Join(lines, "\n")
+	}
+	maxShow := 5
+	if len(risky) < maxShow {
+		maxShow = len(risky)
+	}
+	for i := 0; i < maxShow; i++ {
+		p := risky[i]
+		line := fmt.Sprintf("%s %s (%s): %s", analyzer.GetSeverityEmoji(p.Risk), p.Name, p.Repository, strings.Join(p.Risks, ", "))
+		line += SubtleStyle.Render(fmt.Sprintf("  health %d, last push %s", p.Health.HealthScore, formatAge(time.Since(p.Health.PushedAt))))
+		lines = append(lines, line)
+	}
+	if len(risky) > maxShow {
+		lines = append(lines, fmt.Sprintf("... %d more", len(risky)-maxShow))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// packagesView lists the packages of a monorepo and shows the analysis of
+// the one selected with the up and down keys
+func (m DashboardModel) packagesView() string {
+	header := TitleStyle.Render(" Packages ")
+
+	mono := m.data.Monorepo
+	if !mono.IsMonorepo() {
+		return lipgloss.JoinVertical(lipgloss.Left, header, CardStyle.Render("No monorepo workspace detected"))
+	}
+
+	list := []string{
+		fmt.Sprintf("📦 %d packages (%s)", len(mono.Packages), strings.Join(mono.Tools, ", ")),
+		"",
+	}
+	start := 0
+	if m.selectedPkg >= 15 {
+		start = m.selectedPkg - 14
+	}
+	for i := start; i < len(mono.Packages) && i < start+15; i++ {
+		pkg := mono.Packages[i]
+		line := fmt.Sprintf("  %-28s %9s", pkg.Dir, analyzer.FormatSize(pkg.Size))
+		if i == m.selectedPkg {
+			line = SelectedStyle.Render("▶ " + line[2:])
+		}
+		list = append(list, line)
+	}
+	if len(mono.Packages) > start+15 {
+		list = append(list, SubtleStyle.Render(fmt.Sprintf("  ... %d more", len(mono.Packages)-start-15)))
+	}
+	if mono.Truncated {
+		list = append(list, "", SubtleStyle.Render("Only the first packages were fully analysed (API budget)"))
+	}
+	list = append(list, "", SubtleStyle.Render("↑↓ select package"))
+
+	if m.selectedPkg >= len(mono.Packages) {
+		return lipgloss.JoinVertical(lipgloss.Left, header, CardStyle.Render(strings.Join(list, "\n")))
+	}
+	pkg := mono.Packages[m.selectedPkg]
+
+	details := []string{
+		TitleStyle.Render(pkg.Name),
+		fmt.Sprintf("Directory:    %s", pkg.Dir),
+		fmt.Sprintf("Ecosystem:    %s", pkg.Ecosystem),
+		fmt.Sprintf("Files:        %d (%s)", pkg.Files, analyzer.FormatSize(pkg.Size)),
+	}
+	if q := pkg.CodeQuality; q != nil {
+		details = append(details,
+			fmt.Sprintf("Quality:      %d/100 (%s)", q.OverallScore, q.Grade),
+			fmt.Sprintf("Tests / Docs: %s / %s", boolToYesNo(q.HasTests), boolToYesNo(q.HasReadme)))
+	}
+	if d := pkg.Dependencies; d != nil {
+		details = append(details, fmt.Sprintf("Dependencies: %d", d.TotalDeps))
+	}
+
+	license := "inherits repository license"
+	switch {
+	case pkg.License != nil && pkg.License.MainLicense != nil:
+		license = formatLicense(*pkg.License.MainLicense) + " in " + pkg.License.MainLicense.SourceFile
+	case pkg.DeclaredLicense != "":
+		license = pkg.DeclaredLicense + " (manifest)"
+	}
+	details = append(details, fmt.Sprintf("License:      %s", license))
+
+	if len(pkg.Contributors) > 0 {
+		details = append(details, "", fmt.Sprintf("👥 Contributors (%d commits, last year)", pkg.Commits))
+		maxShow := 5
+		if len(pkg.Contributors) < maxShow {
+			maxShow = len(pkg.Contributors)
+		}
+		for _, c := range pkg.Contributors[:maxShow] {
+			details = append(details, fmt.Sprintf("  %-20s %4d  %5.1f%%", c.Login, c.Commits, c.Percentage))
+		}
+		if len(pkg.Contributors) > maxShow {
+			details = append(details, SubtleStyle.Render(fmt.Sprintf("  ... %d more", len(pkg.Contributors)-maxShow)))
+		}
+	}
+
+	return lipgloss.JoinVertical(lipgloss.Left, header,
+		lipgloss.JoinHorizontal(lipgloss.Top,
+			CardStyle.Render(strings.Join(list, "\n")),
+			CardStyle.Render(strings.Join(details, "\n"))))
+}
+
 func (m DashboardModel) contributorInsightsView() string {
 	header := TitleStyle.Render(" Insights ")
 
@@ -535,6 +1025,15 @@ func (m DashboardModel) securityView() string {
 	}
 
 	sec := m.data.Security
+	if sec.Failed() {
+		// Without a single checked package there is no score to show
+		reason := m.data.SecurityError
+		if reason == "" && len(sec.Errors) > 0 {
+			reason = sec.Errors[0]
+		}
+		failed := fmt.Sprintf("❌ Vulnerability scan failed: none of %d packages could be checked\n", sec.ScannedPackages)
+		return lipgloss.JoinVertical(lipgloss.Left, header, CardStyle.Render(failed+SubtleStyle.Render(reason)))
+	}
 	grade := analyzer.GetSecurityGrade(sec.SecurityScore)
 
 	summary := fmt.Sprintf(
@@ -543,8 +1042,26 @@ func (m DashboardModel) securityView() string {
 		sec.CriticalCount, sec.HighCount, sec.MediumCount, sec.LowCount,
 	)
 
+	switch {
+	case sec.Source == "offline database" && !sec.DatabaseUpdated.IsZero():
+		summary += fmt.Sprintf("\nSource: offline database, synced %s", formatAge(time.Since(sec.DatabaseUpdated)))
+		if time.Since(sec.DatabaseUpdated) > 7*24*time.Hour {
+			summary += "\n" + SubtleStyle.Render("Run `repo-lyzer vulndb sync` to refresh it")
+		}
+	case sec.Source != "":
+		summary += "\nSource: " + sec.Source
+	}
+	if sec.Incomplete() {
+		summary += fmt.Sprintf("\n\n⚠️  Scan incomplete: %d packages not checked", sec.FailedPackages)
+		if len(sec.Errors) > 0 {
+			summary += "\n" + SubtleStyle.Render(sec.Errors[0])
+		}
+	}
+
 	var vulnLines []string
-	if len(sec.Vulnerabilities) == 0 {
+	if len(sec.Vulnerabilities) == 0 && sec.Incomplete() {
+		vulnLines = append(vulnLines, "No vulnerabilities found in the packages that were checked")
+	} else if len(sec.Vulnerabilities) == 0 {
 		vulnLines = append(vulnLines, "✅ No known vulnerabilities found")
 	} else {
 		maxShow := 5
@@ -553,7 +1070,11 @@ func (m DashboardModel) securityView() string {
 		}
 		for i := 0; i < maxShow; i++ {
 			v := sec.Vulnerabilities[i]
-			vulnLines = append(vulnLines, fmt.Sprintf("%s %s - %s", analyzer.GetSeverityEmoji(v.Severity), v.ID, v.Package))
+			line := fmt.Sprintf("%s %s - %s", analyzer.GetSeverityEmoji(v.Severity), v.ID, v.Package)
+			if v.CVSSScore > 0 {
+				line += fmt.Sprintf(" (CVSS %.1f)", v.CVSSScore)
+			}
+			vulnLines = append(vulnLines, line)
 		}
 		if len(sec.Vulnerabilities) > maxShow {
 			vulnLines = append(vulnLines, fmt.Sprintf("... %d more", len(sec.Vulnerabilities)-maxShow))
@@ -561,9 +1082,250 @@ func (m DashboardModel) securityView() string {
 	}
 
 	content := CardStyle.Render(summary) + "\n" + CardStyle.Render(strings.Join(vulnLines, "\n"))
+	if plan := m.data.Remediation; plan != nil && len(plan.Items) > 0 {
+		content += "\n" + CardStyle.Render(remediationSummary(plan))
+	}
+	if s := m.data.SuspiciousPackages; s != nil && len(s.Packages) > 0 {
+		content += "\n" + CardStyle.Render(suspiciousSummary(s))
+	}
+	if m.data.Secrets != nil {
+		content += "\n" + CardStyle.Render(m.secretsSummary())
+	}
+	if m.data.Docker != nil && len(m.data.Docker.Files) > 0 {
+		content += "\n" + CardStyle.Render(m.containersSummary())
+	}
+	if m.data.IaC != nil && len(m.data.IaC.Files) > 0 {
+		content += "\n" + CardStyle.Render(m.iacSummary())
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, header, content)
+}
+
+func (m DashboardModel) complianceView() string {
+	header := TitleStyle.Render(" License Compliance ")
+
+	c := m.data.Compliance
+	if c == nil {
+		return lipgloss.JoinVertical(lipgloss.Left, header, CardStyle.Render("No license compliance data"))
+	}
+
+	project := c.ProjectLicense
+	if project == "" {
+		project = "none (treated as proprietary)"
+	}
+	policy := c.PolicySource
+	switch {
+	case c.PolicyError != "":
+		policy = "❌ not applied, only copyleft conflicts are checked"
+	case policy == "":
+		policy = "none, only copyleft conflicts are checked"
+	}
+	summary := fmt.Sprintf(
+		"Project license: %s\nPolicy: %s\nPackages: %d\n\n✅ %d allowed  🔍 %d review  ⛔ %d denied  ❓ %d unknown  ⚠️  %d conflicts",
+		project, policy, len(c.Packages),
+		c.AllowedCount, c.ReviewCount, c.DeniedCount, c.UnknownCount, c.ConflictCount,
+	)
+	if c.PolicyError != "" {
+		summary += "\n" + ErrorStyle.Render(c.PolicyError)
+	} else if c.PolicySource == "" {
+		summary += "\n" + SubtleStyle.Render("Add allow:, deny: and review: lines to ~/.repo-lyzer/license_policy.txt")
+	}
+	if c.FailedLookups > 0 {
+		summary += fmt.Sprintf("\n\n⚠️  %d license lookups failed", c.FailedLookups)
+		if len(c.Errors) > 0 {
+			summary += "\n" + SubtleStyle.Render(c.Errors[0])
+		}
+	}
+
+	var lines []string
+	if c.Passed(false) {
+		lines = append(lines, "✅ No license violations")
+	}
+	var attention []analyzer.PackageLicense
+	for _, p := range c.Packages {
+		if p.Status != analyzer.LicenseAllowed || p.Conflict != "" {
+			attention = append(attention, p)
+		}
+	}
+	maxShow := 10
+	if len(attention) < maxShow {
+		maxShow = len(attention)
+	}
+	for _, p := range attention[:maxShow] {
+		lines = append(lines, complianceLine(p))
+	}
+	if len(attention) > maxShow {
+		lines = append(lines, fmt.Sprintf("... %d more", len(attention)-maxShow))
+	}
+
+	content := CardStyle.Render(summary) + "\n" + CardStyle.Render(strings.Join(lines, "\n"))
 	return lipgloss.JoinVertical(lipgloss.Left, header, content)
 }
 
+// complianceLine describes a package that needs attention, e.g.
+// "⛔ left-pad 1.3.0 GPL-3.0: GPL-3.0 is denied by the policy"
+func complianceLine(p analyzer.PackageLicense) string {
+	icons := map[string]string{
+		analyzer.LicenseReview: "🔍", analyzer.LicenseDenied: "⛔", analyzer.LicenseUnknown: "❓", analyzer.LicenseAllowed: "✅",
+	}
+	icon, reason := icons[p.Status], p.Reason
+	if p.Conflict != "" {
+		icon, reason = "⚠️ ", p.Conflict
+	}
+	line := fmt.Sprintf("%s %s %s", icon, p.Name, p.Version)
+	if p.License != "" {
+		line += " " + p.License
+	}
+	if p.Dev {
+		line += " (dev)"
+	}
+	return line + ": " + reason
+}
+
+// remediationSummary renders the upgrades that fix the vulnerable packages,
+// most severe first
+func remediationSummary(plan *analyzer.RemediationPlan) string {
+	lines := []string{fmt.Sprintf("🩹 Remediation plan: %d fixable, %d without a fix, %d major upgrades",
+		plan.Fixable, plan.Unfixable, plan.MajorUpgrades)}
+	maxShow := 8
+	if len(plan.Items) < maxShow {
+		maxShow = len(plan.Items)
+	}
+	for _, item := range plan.Items[:maxShow] {
+		lines = append(lines, analyzer.GetSeverityEmoji(item.Severity)+" "+remediationLine(item))
+	}
+	if len(plan.Items) > maxShow {
+		lines = append(lines, fmt.Sprintf("... %d more", len(plan.Items)-maxShow))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// remediationLine describes the fix for one package, e.g.
+// "lodash 4.17.20 → 4.17.21 (2 vulns)" or "debug 2.6.9 → 3.1.0 ⚠ major, via express@4.18.2"
+func remediationLine(item analyzer.Remediation) string {
+	line := fmt.Sprintf("%s %s", item.Package, item.Version)
+	if item.FixVersion == "" {
+		line += " - no fixed release yet"
+	} else {
+		line += " → " + item.FixVersion
+		if item.MajorUpgrade {
+			line += " ⚠ major"
+		}
+	}
+	line += fmt.Sprintf(" (%d vulns", len(item.Vulnerabilities))
+	if len(item.Unfixed) > 0 && item.FixVersion != "" {
+		line += fmt.Sprintf(", %d unfixed", len(item.Unfixed))
+	}
+	line += ")"
+	if !item.Direct && len(item.Bump) > 0 {
+		line += ", bump " + strings.Join(item.Bump, ", ")
+	}
+	return line
+}
+
+// secretsSummary renders the secret scan findings. Values are already redacted.
+func (m DashboardModel) secretsSummary() string {
+	secrets := m.data.Secrets
+	lines := []string{fmt.Sprintf("🔑 Secrets: %d findings in %d files scanned", len(secrets.Findings), secrets.FilesScanned)}
+	if secrets.BudgetExhausted {
+		lines = append(lines, SubtleStyle.Render("File budget reached, not every file was scanned"))
+	}
+	if len(secrets.Findings) == 0 {
+		lines = append(lines, "✅ No hard-coded credentials found")
+		return strings.Join(lines, "\n")
+	}
+
+	maxShow := 5
+	if len(secrets.Findings) < maxShow {
+		maxShow = len(secrets.Findings)
+	}
+	for i := 0; i < maxShow; i++ {
+		f := secrets.Findings[i]
+		lines = append(lines, fmt.Sprintf("%s %s %s:%d %s", analyzer.GetSeverityEmoji(f.Severity), f.Description, f.Path, f.Line, f.Redacted))
+	}
+	if len(secrets.Findings) > maxShow {
+		lines = append(lines, fmt.Sprintf("... %d more", len(secrets.Findings)-maxShow))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// suspiciousSummary renders dependencies that imitate popular packages and
+// packages that run scripts on install
+func suspiciousSummary(s *analyzer.SuspiciousPackageAnalysis) string {
+	lines := []string{fmt.Sprintf("🕵️ Suspicious packages: %d possible typosquats, %d install scripts (%d packages checked)",
+		s.TyposquatCount, s.InstallScriptCount, s.Checked)}
+
+	maxShow := 5
+	if len(s.Packages) < maxShow {
+		maxShow = len(s.Packages)
+	}
+	for i := 0; i < maxShow; i++ {
+		p := s.Packages[i]
+		line := fmt.Sprintf("%s %s", analyzer.GetSeverityEmoji(p.Severity), p.Detail)
+		if p.Intended != "" {
+			line = fmt.Sprintf("%s %s → did you mean %s? (%s)", analyzer.GetSeverityEmoji(p.Severity), p.Name, p.Intended, p.Heuristic)
+		} else if p.Name == "" {
+			line += " (" + p.File + ")"
+		}
+		lines = append(lines, line)
+	}
+	if len(s.Packages) > maxShow {
+		lines = append(lines, fmt.Sprintf("... %d more", len(s.Packages)-maxShow))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// containersSummary renders base images and Dockerfile/Compose findings
+func (m DashboardModel) containersSummary() string {
+	docker := m.data.Docker
+	lines := []string{fmt.Sprintf("🐳 Containers: %d files, %d base images, multi-stage: %s",
+		len(docker.Files), len(docker.BaseImages), boolToYesNo(docker.UsesMultiStage))}
+
+	var images []string
+	seen := make(map[string]bool)
+	for _, img := range docker.BaseImages {
+		if !seen[img.Reference()] {
+			seen[img.Reference()] = true
+			images = append(images, img.Reference())
+		}
+	}
+	if len(images) > 0 {
+		lines = append(lines, "Images: "+strings.Join(images, ", "))
+	}
+
+	maxShow := 5
+	if len(docker.Findings) < maxShow {
+		maxShow = len(docker.Findings)
+	}
+	for i := 0; i < maxShow; i++ {
+		f := docker.Findings[i]
+		lines = append(lines, fmt.Sprintf("• [%s] %s (%s)", f.Severity, f.Description, f.Location))
+	}
+	if len(docker.Findings) > maxShow {
+		lines = append(lines, fmt.Sprintf("... %d more", len(docker.Findings)-maxShow))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// iacSummary renders infrastructure-as-code frameworks and findings
+func (m DashboardModel) iacSummary() string {
+	iac := m.data.IaC
+	lines := []string{fmt.Sprintf("🏗️ Infrastructure as Code: %s (%d files, %d findings)",
+		strings.Join(iac.Frameworks, ", "), len(iac.Files), len(iac.Findings))}
+
+	maxShow := 5
+	if len(iac.Findings) < maxShow {
+		maxShow = len(iac.Findings)
+	}
+	for i := 0; i < maxShow; i++ {
+		f := iac.Findings[i]
+		lines = append(lines, fmt.Sprintf("• [%s] %s (%s)", f.Severity, f.Description, f.Location))
+	}
+	if len(iac.Findings) > maxShow {
+		lines = append(lines, fmt.Sprintf("... %d more", len(iac.Findings)-maxShow))
+	}
+	return strings.Join(lines, "\n")
+}
+
 func (m DashboardModel) recruiterView() string {
 	header := TitleStyle.Render(" Recruiter Summary ")
 
@@ -644,9 +1406,12 @@ func (m DashboardModel) helpView() string {
 NAVIGATION
   ←/→       Switch view
   1-0       Jump to view
+  c         License compliance
+  ↑/↓       Select package / dependency
+  Enter     Expand dependency (Deps view)
   
 ACTIONS
-  e         Export menu
+  e         Export menu (j/m/p, g graph, s SBOM)
   f         File tree
   r         Refresh
   ?         Toggle help
@@ -657,4 +1422,27 @@ ACTIONS
 		lipgloss.Center, lipgloss.Center,
 		lipgloss.JoinVertical(lipgloss.Left, header, CardStyle.Render(help)),
 	)
-}
\ No newline at end of file
+}
+// formatAge renders a duration as a rough age such as "3 days ago"
+func formatAge(d time.Duration) string {
+	switch {
+	case d < time.Hour:
+		return "just now"
+	case d < 2*time.Hour:
+		return "1 hour ago"
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%d hours ago", int(d.Hours()))
+	case d < 48*time.Hour:
+		return "1 day ago"
+	}
+	return fmt.Sprintf("%d days ago", int(d.Hours()/24))
+}
+
+// formatLicense renders a detected license with how closely its text
+// matched, e.g. "MIT (97% match)"
+func formatLicense(l analyzer.LicenseInfo) string {
+	if l.Confidence == 0 {
+		return l.SPDX
+	}
+	return fmt.Sprintf("%s (%.0f%% match)", l.SPDX, l.Confidence*100)
+}
@@ -0,0 +1,176 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/agnivo988/Repo-lyzer/internal/analyzer"
+)
+
+// depTreeRows is how many rows of the dependency tree are shown at once
+const depTreeRows = 15
+
+// depTreeRow is a visible line of the collapsible dependency tree. Rows are
+// identified by their path from the root so a package reached through two
+// parents can be expanded independently in each place.
+type depTreeRow struct {
+	path     string
+	node     *analyzer.GraphNode
+	level    int
+	children int
+	expanded bool
+	cycle    bool // already expanded further up this branch
+}
+
+// depTreeVisibleRows flattens the expanded part of the dependency tree
+func (m DashboardModel) depTreeVisibleRows() []depTreeRow {
+	graph := m.data.DependencyGraph
+	if graph == nil {
+		return nil
+	}
+	var rows []depTreeRow
+	var walk func(node *analyzer.GraphNode, path string, level int, ancestors map[string]bool)
+	walk = func(node *analyzer.GraphNode, path string, level int, ancestors map[string]bool) {
+		children := graph.Children(node.ID)
+		row := depTreeRow{
+			path:     path,
+			node:     node,
+			level:    level,
+			children: len(children),
+			expanded: m.expandedDeps[path],
+			cycle:    ancestors[node.ID],
+		}
+		rows = append(rows, row)
+		if !row.expanded || row.cycle {
+			return
+		}
+		ancestors[node.ID] = true
+		for _, child := range children {
+			walk(child, path+" > "+child.ID, level+1, ancestors)
+		}
+		delete(ancestors, node.ID)
+	}
+	for _, root := range graph.Roots() {
+		walk(root, root.ID, 0, make(map[string]bool))
+	}
+	return rows
+}
+
+// toggleDepTreeRow expands or collapses the row under the cursor
+func (m *DashboardModel) toggleDepTreeRow() {
+	rows := m.depTreeVisibleRows()
+	if m.depCursor >= len(rows) || rows[m.depCursor].children == 0 {
+		return
+	}
+	if m.expandedDeps == nil {
+		m.expandedDeps = make(map[string]bool)
+	}
+	path := rows[m.depCursor].path
+	m.expandedDeps[path] = !m.expandedDeps[path]
+}
+
+// vulnerableGraphNodes maps graph node IDs to the highest severity of the
+// vulnerabilities found in that package version
+func (m DashboardModel) vulnerableGraphNodes() map[string]string {
+	vulnerable := make(map[string]string)
+	if m.data.Security == nil || m.data.DependencyGraph == nil {
+		return vulnerable
+	}
+	rank := map[string]int{"LOW": 1, "MEDIUM": 2, "HIGH": 3, "CRITICAL": 4}
+	for _, v := range m.data.Security.Vulnerabilities {
+		for _, node := range m.data.DependencyGraph.FindNodes(v.Package, v.Version) {
+			if rank[strings.ToUpper(v.Severity)] >= rank[strings.ToUpper(vulnerable[node.ID])] {
+				vulnerable[node.ID] = v.Severity
+			}
+		}
+	}
+	return vulnerable
+}
+
+// dependencyTreeCard renders the collapsible tree of direct dependencies and
+// what they pull in, followed by the path to each vulnerable package
+func (m DashboardModel) dependencyTreeCard() string {
+	graph := m.data.DependencyGraph
+	rows := m.depTreeVisibleRows()
+	if len(rows) == 0 {
+		return ""
+	}
+	vulnerable := m.vulnerableGraphNodes()
+
+	// Direct dependencies are flagged when anything below them is vulnerable
+	pullsIn := make(map[string]int)
+	for id := range vulnerable {
+		for _, root := range graph.IntroducedBy(id) {
+			if root != id {
+				pullsIn[root]++
+			}
+		}
+	}
+
+	lines := []string{fmt.Sprintf("🌳 Dependency Tree (depth %d, %d packages)", graph.MaxDepth, len(graph.Nodes)), ""}
+	start := 0
+	if m.depCursor >= depTreeRows {
+		start = m.depCursor - depTreeRows + 1
+	}
+	for i := start; i < len(rows) && i < start+depTreeRows; i++ {
+		row := rows[i]
+		marker := "  "
+		switch {
+		case row.cycle:
+			marker = "↺ "
+		case row.children > 0 && row.expanded:
+			marker = "▾ "
+		case row.children > 0:
+			marker = "▸ "
+		}
+		line := strings.Repeat("  ", row.level) + marker + row.node.Name + " " + row.node.Version
+		if row.node.Dev {
+			line += SubtleStyle.Render(" dev")
+		}
+		if severity, ok := vulnerable[row.node.ID]; ok {
+			line += " " + analyzer.GetSeverityEmoji(severity)
+		}
+		if n := pullsIn[row.node.ID]; n > 0 && row.level == 0 {
+			line += SubtleStyle.Render(fmt.Sprintf(" (%d vulnerable below)", n))
+		}
+		if i == m.depCursor {
+			line = SelectedStyle.Render(line)
+		}
+		lines = append(lines, line)
+	}
+	if len(rows) > start+depTreeRows {
+		lines = append(lines, SubtleStyle.Render(fmt.Sprintf("  ... %d more", len(rows)-start-depTreeRows)))
+	}
+
+	if len(vulnerable) > 0 {
+		lines = append(lines, "", "⚠️  Vulnerable packages")
+		for _, node := range graph.Nodes {
+			severity, ok := vulnerable[node.ID]
+			if !ok {
+				continue
+			}
+			line := fmt.Sprintf("  %s %s@%s", analyzer.GetSeverityEmoji(severity), node.Name, node.Version)
+			if node.Direct {
+				line += SubtleStyle.Render(" (direct)")
+			} else if path := graph.PathTo(node.ID); len(path) > 0 {
+				var names []string
+				for _, id := range path {
+					names = append(names, graph.Node(id).Name)
+				}
+				line += SubtleStyle.Render(" ← " + strings.Join(names, " › "))
+			}
+			lines = append(lines, line)
+		}
+	}
+
+	if len(graph.Duplicates) > 0 {
+		var dups []string
+		for _, d := range graph.Duplicates {
+			dups = append(dups, fmt.Sprintf("%s (%s)", d.Name, strings.Join(d.Versions, ", ")))
+		}
+		lines = append(lines, "", "Duplicate versions: "+strings.Join(dups, "; "))
+	}
+
+	lines = append(lines, "", SubtleStyle.Render("↑↓ select • enter expand/collapse"))
+	return CardStyle.Render(strings.Join(lines, "\n"))
+}
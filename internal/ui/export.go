@@ -9,6 +9,8 @@ import (
 	"runtime"
 	"strings"
 	"time" 
+
+	"github.com/agnivo988/Repo-lyzer/internal/analyzer"
 	"github.com/jung-kurt/gofpdf"
 )
 
@@ -20,6 +22,9 @@ type ExportData struct {
 	Languages       map[string]int      `json:"languages"`
 	TopContributors []ContributorExport `json:"top_contributors"`
 	CommitCount     int                 `json:"commit_count_1y"`
+	LinesOfCode     *analyzer.LOCAnalysis `json:"lines_of_code,omitempty"`
+	Size            *analyzer.BloatAnalysis `json:"size,omitempty"`
+	Monorepo        *analyzer.MonorepoAnalysis `json:"monorepo,omitempty"`
 }
 
 type RepoExport struct {
@@ -128,6 +133,9 @@ func ExportJSON(data AnalysisResult, _ string) (string, error) {
 		Languages:       data.Languages,
 		TopContributors: topContribs,
 		CommitCount:     len(data.Commits),
+		LinesOfCode:     data.LOC,
+		Size:            data.Bloat,
+		Monorepo:        data.Monorepo,
 	}
 
 	file, err := os.Create(filename)
@@ -189,6 +197,172 @@ func ExportMarkdown(data AnalysisResult, _ string) (string, error) {
 	}
 	md += "\n"
 
+	if data.LOC != nil && len(data.LOC.Languages) > 0 {
+		md += "## Lines of Code\n\n"
+		md += "| Language | Files | Code | Comment | Blank | Comment % |\n"
+		md += "|----------|-------|------|---------|-------|-----------|\n"
+		for _, l := range data.LOC.Rows() {
+			md += fmt.Sprintf("| %s | %d | %d | %d | %d | %.1f%% |\n", l.Language, l.Files, l.Code, l.Comment, l.Blank, l.CommentDensity())
+		}
+		if data.LOC.BudgetExhausted {
+			md += fmt.Sprintf("\n*Sampled %d of %d source files.*\n", data.LOC.FilesCounted, data.LOC.FilesEligible)
+		}
+		md += "\n"
+	}
+
+	if data.Bloat != nil && data.Bloat.TotalFiles > 0 {
+		md += "## Repository Size\n\n"
+		md += fmt.Sprintf("- Total: %s in %d files\n", analyzer.FormatSize(data.Bloat.TotalSize), data.Bloat.TotalFiles)
+		md += fmt.Sprintf("- Committed binaries: %d (%s)\n", len(data.Bloat.Binaries), analyzer.FormatSize(data.Bloat.BinarySize))
+		md += fmt.Sprintf("- Git LFS candidates: %d (%s)\n", len(data.Bloat.LFSCandidates), analyzer.FormatSize(data.Bloat.CandidateSize))
+		if len(data.Bloat.LFSPatterns) > 0 {
+			md += fmt.Sprintf("- LFS: %d files tracked, %d matching files committed as regular blobs\n", data.Bloat.LFSTracked, len(data.Bloat.LFSNotHonoured))
+		}
+		md += "\n| Largest File | Size |\n|--------------|------|\n"
+		for _, f := range data.Bloat.LargestFiles {
+			md += fmt.Sprintf("| %s | %s |\n", f.Path, analyzer.FormatSize(f.Size))
+		}
+		md += "\n"
+	}
+
+	if g := data.DependencyGraph; g != nil && len(g.Nodes) > 0 {
+		md += "## Dependency Graph\n\n"
+		md += fmt.Sprintf("- Packages: %d (%d direct)\n", len(g.Nodes), len(g.Roots()))
+		md += fmt.Sprintf("- Maximum depth: %d\n", g.MaxDepth)
+		if len(g.MostDepended) > 0 {
+			var top []string
+			for _, id := range g.MostDepended {
+				node := g.Node(id)
+				top = append(top, fmt.Sprintf("%s (%d)", node.Name, node.FanIn))
+			}
+			md += fmt.Sprintf("- Most depended on: %s\n", strings.Join(top, ", "))
+		}
+		for _, d := range g.Duplicates {
+			md += fmt.Sprintf("- Duplicate: %s %s\n", d.Name, strings.Join(d.Versions, ", "))
+		}
+		// Large graphs are unreadable inline; they can be exported separately
+		if len(g.Edges) > 0 && len(g.Nodes) <= maxInlineGraphNodes {
+			md += "\n```mermaid\n" + g.ToMermaid() + "```\n"
+		}
+		md += "\n"
+	}
+
+	if sec := data.Security; sec.Failed() {
+		md += "## Security\n\n"
+		md += fmt.Sprintf("- **Scan failed:** none of %d packages could be checked (%s)\n\n", sec.ScannedPackages, data.SecurityError)
+	} else if sec != nil {
+		md += "## Security\n\n"
+		md += fmt.Sprintf("- **Security Score:** %d/100 (%s)\n", sec.SecurityScore, analyzer.GetSecurityGrade(sec.SecurityScore))
+		md += fmt.Sprintf("- **Vulnerabilities:** %d (%d critical, %d high, %d medium, %d low) in %d packages scanned\n",
+			sec.TotalCount, sec.CriticalCount, sec.HighCount, sec.MediumCount, sec.LowCount, sec.ScannedPackages)
+		if sec.Incomplete() {
+			md += fmt.Sprintf("- **Incomplete:** %d packages not checked\n", sec.FailedPackages)
+		}
+		md += "\n"
+	}
+
+	if plan := data.Remediation; plan != nil && len(plan.Items) > 0 {
+		md += "## Remediation Plan\n\n"
+		md += "| Package | Version | Upgrade To | Major | Severity | Vulnerabilities | Via |\n"
+		md += "|---------|---------|------------|-------|----------|-----------------|-----|\n"
+		for _, item := range plan.Items {
+			fix, major, via := item.FixVersion, "", "direct"
+			if fix == "" {
+				fix = "no fix yet"
+			}
+			if item.MajorUpgrade {
+				major = "yes"
+			}
+			if !item.Direct {
+				via = strings.Join(item.Bump, ", ")
+			}
+			md += fmt.Sprintf("| %s | %s | %s | %s | %s | %s | %s |\n", item.Package, item.Version, fix, major,
+				item.Severity, strings.Join(item.Vulnerabilities, ", "), via)
+		}
+		if patch := plan.Patch(); patch != "" {
+			md += "\nSuggested manifest edits (not applied):\n\n```diff\n" + patch + "```\n"
+		}
+		md += "\n"
+	}
+
+	if c := data.Compliance; c != nil && len(c.Packages) > 0 {
+		md += "## License Compliance\n\n"
+		md += fmt.Sprintf("- **Allowed:** %d, **review:** %d, **denied:** %d, **unknown:** %d, **copyleft conflicts:** %d\n",
+			c.AllowedCount, c.ReviewCount, c.DeniedCount, c.UnknownCount, c.ConflictCount)
+		if c.PolicySource != "" {
+			md += fmt.Sprintf("- **Policy:** %s\n", c.PolicySource)
+		}
+		if c.PolicyError != "" {
+			md += fmt.Sprintf("- **Policy not applied:** %s\n", c.PolicyError)
+		}
+		md += "\n| Package | Version | License | Status | Reason |\n"
+		md += "|---------|---------|---------|--------|--------|\n"
+		for _, p := range c.Packages {
+			if p.Status == analyzer.LicenseAllowed && p.Conflict == "" {
+				continue
+			}
+			reason := p.Reason
+			if p.Conflict != "" {
+				reason = p.Conflict
+			}
+			md += fmt.Sprintf("| %s | %s | %s | %s | %s |\n", p.Name, p.Version, p.License, p.Status, reason)
+		}
+		md += "\n"
+	}
+
+	if f := data.Freshness; f != nil && f.OutdatedCount > 0 {
+		md += "## Dependency Freshness\n\n"
+		md += fmt.Sprintf("- **Score:** %d/100\n- **Outdated:** %d of %d (%d major)\n- **Libyears behind:** %.1f\n\n",
+			f.Score, f.OutdatedCount, f.Checked, f.MajorCount, f.TotalLibyear)
+		md += "| Package | Current | Latest | Drift | Majors Behind | Libyear |\n"
+		md += "|---------|---------|--------|-------|---------------|---------|\n"
+		for _, p := range f.Outdated() {
+			md += fmt.Sprintf("| %s | %s | %s | %s | %d | %.2f |\n", p.Name, p.Current, p.Latest, p.Drift, p.MajorsBehind, p.Libyear)
+		}
+		md += "\n"
+	}
+
+	if g := data.GoModules; g != nil && len(g.Warnings) > 0 {
+		md += "## Go Modules\n\n"
+		for _, mod := range g.Modules {
+			md += fmt.Sprintf("- **%s** (%s): go %s, %d direct, %d indirect\n", mod.Module.Module, mod.File,
+				mod.Module.GoVersion, mod.Direct, mod.Indirect)
+		}
+		md += "\n| Severity | File | Warning |\n"
+		md += "|----------|------|---------|\n"
+		for _, w := range g.Warnings {
+			md += fmt.Sprintf("| %s | %s | %s |\n", w.Severity, w.File, w.Message)
+		}
+		md += "\n"
+	}
+
+	if u := data.Upstream; u != nil && len(u.AtRisk()) > 0 {
+		md += "## Dependency Risk\n\n"
+		md += fmt.Sprintf("- **Archived:** %d, **single maintainer:** %d, **stale:** %d\n\n", u.ArchivedCount, u.SingleMaintainer, u.StaleCount)
+		md += "| Risk | Package | Repository | Flags | Health | Last Push |\n"
+		md += "|------|---------|------------|-------|--------|-----------|\n"
+		for _, p := range u.AtRisk() {
+			md += fmt.Sprintf("| %s | %s | %s | %s | %d | %s |\n", p.Risk, p.Name, p.Repository,
+				strings.Join(p.Risks, ", "), p.Health.HealthScore, p.Health.PushedAt.Format("2006-01-02"))
+		}
+		md += "\n"
+	}
+
+	if s := data.SuspiciousPackages; s != nil && len(s.Packages) > 0 {
+		md += "## Suspicious Packages\n\n"
+		md += fmt.Sprintf("- **Possible typosquats:** %d, **install scripts:** %d\n\n", s.TyposquatCount, s.InstallScriptCount)
+		md += "| Severity | Package | Suspected intended package | Detail |\n"
+		md += "|----------|---------|----------------------------|--------|\n"
+		for _, p := range s.Packages {
+			name := p.Name
+			if name == "" {
+				name = p.File
+			}
+			md += fmt.Sprintf("| %s | %s | %s | %s |\n", p.Severity, name, p.Intended, p.Detail)
+		}
+		md += "\n"
+	}
+
 	md += "## Top Contributors\n"
 	maxContribs := 10
 	if len(data.Contributors) < maxContribs {
@@ -209,6 +383,92 @@ func ExportMarkdown(data AnalysisResult, _ string) (string, error) {
 	return filename, nil
 }
 
+// maxInlineGraphNodes is the largest dependency graph embedded in Markdown
+// exports as a Mermaid diagram
+const maxInlineGraphNodes = 60
+
+// ExportDependencyGraph writes the dependency graph as Graphviz DOT, Mermaid
+// and JSON files next to each other and returns the DOT file's path
+func ExportDependencyGraph(data AnalysisResult) (string, error) {
+	g := data.DependencyGraph
+	if g == nil || len(g.Nodes) == 0 {
+		return "", fmt.Errorf("no dependency graph available")
+	}
+	downloadsDir, err := getDownloadsDir()
+	if err != nil {
+		return "", err
+	}
+
+	graphJSON, err := g.ToJSON()
+	if err != nil {
+		return "", err
+	}
+	base := strings.TrimSuffix(generateFilename(data.Repo.FullName+"_deps", "dot"), ".dot")
+	files := []struct {
+		ext     string
+		content []byte
+	}{
+		{"dot", []byte(g.ToDOT())},
+		{"mmd", []byte(g.ToMermaid())},
+		{"json", graphJSON},
+	}
+	for _, f := range files {
+		if err := os.WriteFile(filepath.Join(downloadsDir, base+"."+f.ext), f.content, 0644); err != nil {
+			return "", err
+		}
+	}
+
+	filename := filepath.Join(downloadsDir, base+".dot")
+	_ = openFileManager(filename)
+	return filename, nil
+}
+
+// ExportSBOM writes the repository's SBOM as CycloneDX JSON, SPDX JSON and
+// SPDX tag-value files and returns the CycloneDX file's path
+func ExportSBOM(data AnalysisResult) (string, error) {
+	if data.Repo == nil || data.Dependencies == nil {
+		return "", fmt.Errorf("no dependency data available")
+	}
+	downloadsDir, err := getDownloadsDir()
+	if err != nil {
+		return "", err
+	}
+
+	sbom := analyzer.BuildSBOM(sbomSubject(data), data.Dependencies, data.DependencyGraph)
+	base := strings.TrimSuffix(generateFilename(data.Repo.FullName+"_sbom", "json"), ".json")
+	files := []struct {
+		name   string
+		format string
+	}{
+		{base + ".cdx.json", analyzer.SBOMCycloneDXJSON},
+		{base + ".spdx.json", analyzer.SBOMSPDXJSON},
+		{base + ".spdx", analyzer.SBOMSPDXTag},
+	}
+	for _, f := range files {
+		content, err := analyzer.EncodeSBOM(sbom, f.format)
+		if err != nil {
+			return "", err
+		}
+		if err := os.WriteFile(filepath.Join(downloadsDir, f.name), content, 0644); err != nil {
+			return "", err
+		}
+	}
+
+	filename := filepath.Join(downloadsDir, files[0].name)
+	_ = openFileManager(filename)
+	return filename, nil
+}
+
+// sbomSubject describes the analyzed repository for an SBOM
+func sbomSubject(data AnalysisResult) analyzer.SBOMSubject {
+	return analyzer.SBOMSubject{
+		Name:    data.Repo.FullName,
+		Version: data.Repo.DefaultBranch,
+		URL:     data.Repo.HTMLURL,
+		License: data.License.SPDXExpression(),
+	}
+}
+
 func ExportPDF(data AnalysisResult, _ string) (string, error) {
 	downloadsDir, err := getDownloadsDir()
 	if err != nil {
@@ -281,6 +541,21 @@ func ExportPDF(data AnalysisResult, _ string) (string, error) {
 	}  
 	pdf.Ln(9)
 
+	if data.LOC != nil && len(data.LOC.Languages) > 0 {
+		pdf.SetFont("Arial", "B", 14)
+		pdf.Cell(0, 10, "Lines of Code")
+		pdf.Ln(10)
+
+		pdf.SetFont("Courier", "", 10)
+		pdf.Cell(0, 8, fmt.Sprintf("%-14s %6s %8s %8s %8s %8s", "Language", "Files", "Code", "Comment", "Blank", "Comm.%"))
+		pdf.Ln(6)
+		for _, l := range data.LOC.Rows() {
+			pdf.Cell(0, 8, fmt.Sprintf("%-14s %6d %8d %8d %8d %7.1f%%", l.Language, l.Files, l.Code, l.Comment, l.Blank, l.CommentDensity()))
+			pdf.Ln(6)
+		}
+		pdf.Ln(9)
+	}
+
 	pdf.SetFont("Arial", "B", 14)
 	pdf.Cell(0, 10, "Top Contributors")
 	pdf.Ln(10)
@@ -350,6 +625,9 @@ func buildExportData(data AnalysisResult) ExportData {
 		Languages:       data.Languages,
 		TopContributors: topContribs,
 		CommitCount:     len(data.Commits),
+		LinesOfCode:     data.LOC,
+		Size:            data.Bloat,
+		Monorepo:        data.Monorepo,
 	}
 }
 
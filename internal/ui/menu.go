@@ -187,6 +187,7 @@ func (m *MenuModel) enterSubmenu() {
 			"Cache Settings",
 			"Export Options",
 			"GitHub Token",
+			"Offline Mode",
 			"Reset to Defaults",
 		}
 		m.inSubmenu = true
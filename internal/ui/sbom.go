@@ -0,0 +1,101 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/agnivo988/Repo-lyzer/internal/analyzer"
+	"github.com/agnivo988/Repo-lyzer/internal/config"
+	"github.com/agnivo988/Repo-lyzer/internal/github"
+)
+
+// AnalyzeSBOM reads a CycloneDX or SPDX JSON file and runs the dependency
+// checks on it: the vulnerability scan, remediation plan, dependency graph,
+// declared license check, license policy check, typosquatting check and,
+// unless the registries are off, the freshness check. There is no repository,
+// so the result's Repo only describes the SBOM's subject.
+func AnalyzeSBOM(path string) (AnalysisResult, *analyzer.ImportedSBOM, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return AnalysisResult{}, nil, err
+	}
+	imported, err := analyzer.ImportSBOM(path, data)
+	if err != nil {
+		return AnalysisResult{}, nil, err
+	}
+
+	settings, _ := config.LoadSettings()
+	deps := imported.Dependencies
+	security, scanErr := analyzer.ScanDependenciesWith(deps, vulnScanOptions(settings))
+	graph := analyzer.BuildDependencyGraph(deps)
+	license := analyzer.AnalyzeDeclaredLicenses(imported.Subject.License, deps)
+
+	name := imported.Subject.Name
+	if name == "" {
+		name = filepath.Base(path)
+	}
+	result := AnalysisResult{
+		Repo: &github.Repo{
+			FullName:      name,
+			Description:   fmt.Sprintf("Imported from %s (%s, %d components)", filepath.Base(path), imported.Format, imported.Components),
+			HTMLURL:       imported.Subject.URL,
+			DefaultBranch: imported.Subject.Version,
+		},
+		Languages:          map[string]int{},
+		Dependencies:       deps,
+		DependencyGraph:    graph,
+		Security:           security,
+		Remediation:        analyzer.BuildRemediationPlan(security, deps, graph),
+		License:            license,
+		Compliance:         checkLicenseCompliance(license, deps, settings, github.NewClient()),
+		SuspiciousPackages: analyzer.DetectSuspiciousPackages(deps),
+	}
+	if scanErr != nil {
+		result.SecurityError = scanErr.Error()
+	}
+	if settings == nil || !settings.OfflineRegistries {
+		result.Freshness = analyzer.AnalyzeFreshness(deps, analyzer.DefaultFreshnessOptions())
+	}
+	return result, imported, scanErr
+}
+
+// standaloneDashboard runs the dashboard on its own, outside the main menu.
+// Leaving the dashboard quits, and actions that need a repository are
+// ignored.
+type standaloneDashboard struct {
+	dashboard DashboardModel
+}
+
+func (m standaloneDashboard) Init() tea.Cmd { return nil }
+
+func (m standaloneDashboard) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if key, ok := msg.(tea.KeyMsg); ok && key.String() == "ctrl+c" {
+		return m, tea.Quit
+	}
+	if s, ok := msg.(string); ok && s != "clear_status" {
+		return m, nil // switch_to_tree, refresh_data and add_to_favorites
+	}
+
+	updated, cmd := m.dashboard.Update(msg)
+	m.dashboard = updated.(DashboardModel)
+	if m.dashboard.BackToMenu {
+		return m, tea.Quit
+	}
+	return m, cmd
+}
+
+func (m standaloneDashboard) View() string { return m.dashboard.View() }
+
+// RunDashboard shows analysis results in the dashboard, opening on the
+// Security view
+func RunDashboard(data AnalysisResult) error {
+	dashboard := NewDashboardModel()
+	dashboard.SetData(data)
+	dashboard.currentView = viewSecurity
+	p := tea.NewProgram(standaloneDashboard{dashboard}, tea.WithAltScreen())
+	_, err := p.Run()
+	return err
+}
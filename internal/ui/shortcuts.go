@@ -52,6 +52,9 @@ func GetDashboardShortcuts() []KeyboardShortcut {
 		{Key: "e", AltKey: "", Description: "Export menu", Category: "Actions"},
 		{Key: "j", AltKey: "", Description: "Export JSON", Category: "Actions"},
 		{Key: "m", AltKey: "", Description: "Export Markdown", Category: "Actions"},
+		{Key: "g", AltKey: "", Description: "Export dependency graph", Category: "Actions"},
+		{Key: "s", AltKey: "", Description: "Export SBOM", Category: "Actions"},
+		{Key: "Enter", AltKey: "Space", Description: "Expand dependency", Category: "Navigation"},
 		{Key: "f", AltKey: "", Description: "File tree", Category: "Actions"},
 		{Key: "r", AltKey: "F5", Description: "Refresh data", Category: "Actions"},
 		{Key: "b", AltKey: "", Description: "Toggle bookmark", Category: "Actions"},
@@ -107,6 +110,7 @@ func GetFileTreeShortcuts() []KeyboardShortcut {
 		{Key: "O", AltKey: "", Description: "Expand all", Category: "Navigation"},
 		{Key: "C", AltKey: "", Description: "Collapse all", Category: "Navigation"},
 		{Key: "Enter", AltKey: "", Description: "View file", Category: "Actions"},
+		{Key: "m", AltKey: "", Description: "Toggle size map", Category: "Actions"},
 		{Key: "/", AltKey: "Ctrl+F", Description: "Search files", Category: "Actions"},
 		{Key: "ESC", AltKey: "q", Description: "Go back", Category: "System"},
 	}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/agnivo988/Repo-lyzer/internal/analyzer"
 	"github.com/agnivo988/Repo-lyzer/internal/github"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
@@ -28,6 +29,7 @@ type TreeModel struct {
 	height       int
 	Done         bool
 	SelectedPath string
+	showTreemap  bool
 }
 
 // NewTreeModel creates a new tree model for displaying the repository file structure.
@@ -104,6 +106,8 @@ func (m TreeModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					m.updateVisibleList()
 				}
 			}
+		case "m":
+			m.showTreemap = !m.showTreemap
 		case "enter":
 			if m.cursor < len(m.visibleList) {
 				node := m.visibleList[m.cursor]
@@ -113,7 +117,11 @@ func (m TreeModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				}
 			}
 		case "esc":
-			m.Done = true
+			if m.showTreemap {
+				m.showTreemap = false
+			} else {
+				m.Done = true
+			}
 		}
 	}
 
@@ -125,6 +133,10 @@ func (m TreeModel) View() string {
 		return "Initializing..."
 	}
 
+	if m.showTreemap {
+		return m.treemapView()
+	}
+
 	content := TitleStyle.Render("📁 REPOSITORY FILE TREE") + "\n\n"
 
 	// Display visible nodes
@@ -156,11 +168,11 @@ func (m TreeModel) View() string {
 			style = SelectedStyle
 		}
 
-		line := fmt.Sprintf("%s%s%s %s", prefix, indent, icon, node.Name)
+		line := fmt.Sprintf("%s%s%s %s  %s", prefix, indent, icon, node.Name, analyzer.FormatSize(int(node.Size)))
 		content += style.Render(line) + "\n"
 	}
 
-	footer := SubtleStyle.Render("↑↓ navigate • ← → expand/collapse • Enter edit file • ESC back")
+	footer := SubtleStyle.Render("↑↓ navigate • ← → expand/collapse • m size map • Enter edit file • ESC back")
 	content += "\n" + footer
 
 	return lipgloss.Place(
@@ -213,10 +225,24 @@ func BuildFileTree(result AnalysisResult) *FileNode {
 	for _, entry := range result.FileTree {
 		addEntryToTree(root, entry)
 	}
+	computeDirSizes(root)
 
 	return root
 }
 
+// computeDirSizes sets each directory's size to the total of the files below it
+func computeDirSizes(node *FileNode) int64 {
+	if node.Type != "dir" {
+		return node.Size
+	}
+	var total int64
+	for _, child := range node.Children {
+		total += computeDirSizes(child)
+	}
+	node.Size = total
+	return total
+}
+
 // addEntryToTree recursively adds a TreeEntry to the FileNode tree
 func addEntryToTree(root *FileNode, entry github.TreeEntry) {
 	parts := strings.Split(strings.Trim(entry.Path, "/"), "/")
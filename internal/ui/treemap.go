@@ -0,0 +1,181 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/agnivo988/Repo-lyzer/internal/analyzer"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// treemapColors are cycled through so neighbouring blocks stay distinguishable
+var treemapColors = []string{"#bd93f9", "#8be9fd", "#50fa7b", "#ffb86c", "#ff79c6", "#f1fa8c", "#6272a4"}
+
+// treemapRect is the area of the terminal assigned to one node
+type treemapRect struct {
+	node       *FileNode
+	x, y, w, h int
+}
+
+// treemapView renders the children of the selected directory as blocks
+// whose area is proportional to their size
+func (m TreeModel) treemapView() string {
+	target := m.root
+	if m.cursor < len(m.visibleList) {
+		target = m.visibleList[m.cursor]
+		if target.Type != "dir" {
+			if parent := findParent(m.root, target); parent != nil {
+				target = parent
+			}
+		}
+	}
+
+	title := TitleStyle.Render(fmt.Sprintf("🗺️ SIZE MAP: %s (%s)", target.Path, analyzer.FormatSize(int(target.Size))))
+	footer := SubtleStyle.Render("↑↓ select directory in tree • m / ESC back to tree")
+
+	width, height := m.width-6, m.height-8
+	if width < 10 || height < 3 {
+		return lipgloss.JoinVertical(lipgloss.Left, title, "Window too small for the size map", footer)
+	}
+
+	content := renderTreemap(target.Children, width, height)
+	if content == "" {
+		content = SubtleStyle.Render("No file sizes available for this directory")
+	}
+
+	return lipgloss.Place(
+		m.width, m.height,
+		lipgloss.Left, lipgloss.Top,
+		BoxStyle.Render(lipgloss.JoinVertical(lipgloss.Left, title, "", content, "", footer)),
+	)
+}
+
+// renderTreemap lays nodes out in a width x height grid of cells and labels
+// each block with its name and size where there is room
+func renderTreemap(nodes []*FileNode, width, height int) string {
+	var rects []treemapRect
+	layoutTreemap(sortedBySize(nodes), 0, 0, width, height, &rects)
+	if len(rects) == 0 {
+		return ""
+	}
+
+	owner := make([][]int, height)
+	cells := make([][]rune, height)
+	for y := range cells {
+		owner[y] = make([]int, width)
+		cells[y] = []rune(strings.Repeat(" ", width))
+		for x := range owner[y] {
+			owner[y][x] = -1
+		}
+	}
+	for i, r := range rects {
+		for y := r.y; y < r.y+r.h; y++ {
+			for x := r.x; x < r.x+r.w; x++ {
+				owner[y][x] = i
+			}
+		}
+		labels := []string{r.node.Name, analyzer.FormatSize(int(r.node.Size))}
+		if r.node.Type == "dir" {
+			labels[0] += "/"
+		}
+		for line, label := range labels {
+			if line >= r.h {
+				break
+			}
+			runes := []rune(label)
+			if len(runes) > r.w {
+				runes = runes[:r.w]
+			}
+			copy(cells[r.y+line][r.x:], runes)
+		}
+	}
+
+	var b strings.Builder
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; {
+			start, idx := x, owner[y][x]
+			for x < width && owner[y][x] == idx {
+				x++
+			}
+			segment := string(cells[y][start:x])
+			if idx < 0 {
+				b.WriteString(segment)
+				continue
+			}
+			color := treemapColors[idx%len(treemapColors)]
+			b.WriteString(lipgloss.NewStyle().
+				Background(lipgloss.Color(color)).
+				Foreground(lipgloss.Color("#282a36")).
+				Render(segment))
+		}
+		if y < height-1 {
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
+// layoutTreemap splits the nodes into two groups of roughly equal size and
+// divides the rectangle between them along its longer side, recursively.
+// Terminal cells are about twice as tall as they are wide, which the split
+// direction accounts for. Nodes too small for a single cell are dropped.
+func layoutTreemap(nodes []*FileNode, x, y, w, h int, out *[]treemapRect) {
+	if len(nodes) == 0 || w <= 0 || h <= 0 {
+		return
+	}
+	if len(nodes) == 1 {
+		*out = append(*out, treemapRect{node: nodes[0], x: x, y: y, w: w, h: h})
+		return
+	}
+
+	var total int64
+	for _, n := range nodes {
+		total += n.Size
+	}
+	if total == 0 {
+		return
+	}
+	split, first := 1, nodes[0].Size
+	for split < len(nodes)-1 && first*2 < total {
+		first += nodes[split].Size
+		split++
+	}
+
+	if w >= 2*h {
+		firstW := int(int64(w) * first / total)
+		layoutTreemap(nodes[:split], x, y, firstW, h, out)
+		layoutTreemap(nodes[split:], x+firstW, y, w-firstW, h, out)
+		return
+	}
+	firstH := int(int64(h) * first / total)
+	layoutTreemap(nodes[:split], x, y, w, firstH, out)
+	layoutTreemap(nodes[split:], x, y+firstH, w, h-firstH, out)
+}
+
+// sortedBySize returns the non-empty nodes, largest first
+func sortedBySize(nodes []*FileNode) []*FileNode {
+	sorted := make([]*FileNode, 0, len(nodes))
+	for _, n := range nodes {
+		if n.Size > 0 {
+			sorted = append(sorted, n)
+		}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Size > sorted[j].Size
+	})
+	return sorted
+}
+
+// findParent returns the directory containing target, or nil for the root
+func findParent(parent, target *FileNode) *FileNode {
+	for _, child := range parent.Children {
+		if child == target {
+			return parent
+		}
+		if found := findParent(child, target); found != nil {
+			return found
+		}
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package ui
+
+import (
+	"testing"
+
+	"github.com/agnivo988/Repo-lyzer/internal/github"
+)
+
+func TestBuildFileTreeDirSizes(t *testing.T) {
+	root := BuildFileTree(AnalysisResult{FileTree: []github.TreeEntry{
+		{Path: "src", Type: "tree"},
+		{Path: "src/a.go", Type: "blob", Size: 100},
+		{Path: "src/sub/b.go", Type: "blob", Size: 50},
+		{Path: "README.md", Type: "blob", Size: 10},
+	}})
+
+	if root.Size != 160 {
+		t.Errorf("root size = %d, want 160", root.Size)
+	}
+	if src := root.Children[0]; src.Name != "src" || src.Size != 150 {
+		t.Errorf("src = %s (%d), want 150 bytes", src.Name, src.Size)
+	}
+}
+
+func TestLayoutTreemap(t *testing.T) {
+	nodes := []*FileNode{
+		{Name: "a", Size: 600},
+		{Name: "b", Size: 300},
+		{Name: "c", Size: 100},
+		{Name: "empty", Size: 0},
+	}
+	var rects []treemapRect
+	layoutTreemap(sortedBySize(nodes), 0, 0, 40, 10, &rects)
+
+	if len(rects) != 3 {
+		t.Fatalf("got %d blocks, want 3 (empty nodes dropped)", len(rects))
+	}
+	area := 0
+	for _, r := range rects {
+		area += r.w * r.h
+	}
+	if area != 400 {
+		t.Errorf("blocks cover %d cells, want the whole 400", area)
+	}
+	if rects[0].node.Name != "a" || rects[0].w*rects[0].h < 200 {
+		t.Errorf("largest block = %+v, want a with about 60%% of the area", rects[0])
+	}
+}
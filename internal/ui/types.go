@@ -19,10 +19,25 @@ type AnalysisResult struct {
 	MaturityScore        int
 	MaturityLevel        string
 	Dependencies         *analyzer.DependencyAnalysis
+	DependencyGraph      *analyzer.DependencyGraph
 	ContributorInsights  *analyzer.ContributorInsights
 	Security             *analyzer.SecurityScanResult
+	SecurityError        string // why the vulnerability scan failed, if no package could be checked
+	Remediation          *analyzer.RemediationPlan
 	CodeQuality          *analyzer.CodeQualityMetrics
 	License              *analyzer.LicenseAnalysis
+	Compliance           *analyzer.LicenseCompliance
+	Secrets              *analyzer.SecretScanResult
+	SuspiciousPackages   *analyzer.SuspiciousPackageAnalysis
+	Upstream             *analyzer.UpstreamAnalysis
+	Freshness            *analyzer.FreshnessReport
+	GoModules            *analyzer.GoModuleAnalysis
+	Docker               *analyzer.DockerAnalysis
+	IaC                  *analyzer.IaCAnalysis
+	LOC                  *analyzer.LOCAnalysis
+	Files                *analyzer.FileClassification
+	Bloat                *analyzer.BloatAnalysis
+	Monorepo             *analyzer.MonorepoAnalysis
 }
 
 // CachedAnalysisResult wraps AnalysisResult with cache metadata
@@ -0,0 +1,52 @@
+package ui
+
+import (
+	"encoding/json"
+	"sync"
+
+	"github.com/agnivo988/Repo-lyzer/internal/analyzer"
+	"github.com/agnivo988/Repo-lyzer/internal/cache"
+)
+
+// upstreamCachePrefix keeps dependency health entries apart from full
+// analyses in the cache
+const upstreamCachePrefix = "upstream/"
+
+// upstreamCache stores dependency health in the analysis cache, so it
+// expires with the same TTL. A full analysis of the upstream repository is
+// used too, if one is cached.
+type upstreamCache struct {
+	mu    sync.Mutex // cache.Cache is not safe for concurrent use
+	cache *cache.Cache
+}
+
+// NewUpstreamCache returns an analyzer.UpstreamCache backed by c
+func NewUpstreamCache(c *cache.Cache) analyzer.UpstreamCache {
+	return &upstreamCache{cache: c}
+}
+
+func (u *upstreamCache) Get(repo string) (*analyzer.UpstreamHealth, bool) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if entry, found := u.cache.Get(upstreamCachePrefix + repo); found {
+		var health analyzer.UpstreamHealth
+		if err := json.Unmarshal(entry.Analysis, &health); err == nil {
+			return &health, true
+		}
+	}
+	if entry, found := u.cache.Get(repo); found {
+		var result AnalysisResult
+		if err := json.Unmarshal(entry.Analysis, &result); err == nil && result.Repo != nil {
+			health := analyzer.SummarizeUpstream(result.Repo, result.Commits, result.Contributors)
+			health.AnalyzedAt = entry.CachedAt
+			return health, true
+		}
+	}
+	return nil, false
+}
+
+func (u *upstreamCache) Set(repo string, health *analyzer.UpstreamHealth) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.cache.Set(upstreamCachePrefix+repo, health)
+}
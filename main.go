@@ -3,10 +3,19 @@
 // into code health, contributor activity, and project maturity.
 package main
 
-import "github.com/agnivo988/Repo-lyzer/cmd"
+import (
+	"os"
+
+	"github.com/agnivo988/Repo-lyzer/cmd"
+)
 
 // main initializes and runs the Repo-lyzer application.
-// It starts the interactive menu interface for repository analysis.
+// Without arguments it starts the interactive menu interface for repository
+// analysis; with arguments it runs the corresponding CLI subcommand.
 func main() {
+	if len(os.Args) > 1 {
+		cmd.Execute()
+		return
+	}
 	cmd.RunMenu()
 }